@@ -4,6 +4,7 @@
 package di
 
 import (
+	"go-fiber-core/internal/adapters"
 	"go-fiber-core/internal/database/connections/gorm"
 	"go-fiber-core/internal/database/connections/pgx"
 	redis2 "go-fiber-core/internal/database/connections/redis"
@@ -11,18 +12,30 @@ import (
 	"go-fiber-core/internal/dtos/connect"
 	"go-fiber-core/internal/handlers"
 	"go-fiber-core/internal/models"
+	"go-fiber-core/internal/repositories/apikey"
 	"go-fiber-core/internal/repositories/bank"
 	"go-fiber-core/internal/repositories/menu"
+	"go-fiber-core/internal/repositories/permission"
 
 	"go-fiber-core/internal/repositories/refreshtoken"
+	"go-fiber-core/internal/repositories/role"
+	"go-fiber-core/internal/repositories/savedview"
+	"go-fiber-core/internal/repositories/twofactor"
 	"go-fiber-core/internal/repositories/user"
+	"go-fiber-core/internal/repositories/usertoken"
 	"go-fiber-core/internal/server"
 	"go-fiber-core/internal/services"
+	"go-fiber-core/internal/services/account"
 	"go-fiber-core/internal/services/auth"
 	bank2 "go-fiber-core/internal/services/bank"
+	"go-fiber-core/internal/services/email"
 	menu2 "go-fiber-core/internal/services/menu"
 	"go-fiber-core/internal/services/pagination"
+	permission2 "go-fiber-core/internal/services/permission"
+	role2 "go-fiber-core/internal/services/role"
+	savedview2 "go-fiber-core/internal/services/savedview"
 	user2 "go-fiber-core/internal/services/user"
+	"go-fiber-core/internal/utils"
 
 	"github.com/google/wire"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -83,16 +96,47 @@ func provideConnectDTO(
 	}
 }
 
-func provideTokenService(cfg *config.AppConfig) auth.TokenService {
-	return auth.NewTokenService(cfg)
+func provideTokenService(cfg *config.AppConfig, keys auth.KeyRing) auth.TokenService {
+	return auth.NewTokenService(cfg, keys)
 }
 
-func provideUserPaginationService() *pagination.PaginationService[models.User] {
-	return pagination.NewPaginationService[models.User]()
+// provideSessionRevoker expone el cierre de sesiones de AuthService a UserWriterService.
+func provideSessionRevoker(s auth.AuthService) auth.SessionRevoker {
+	return s
 }
 
-func provideBankPaginationService() *pagination.PaginationService[models.Bank] {
-	return pagination.NewPaginationService[models.Bank]()
+// provideUserInviter expone la invitación de AccountService a UserImportService.
+func provideUserInviter(s account.AccountService) user2.Inviter {
+	return s
+}
+
+// provideEmailSender envía por SMTP en producción; en el resto de los entornos imprime los correos.
+func provideEmailSender(cfg *config.AppConfig) email.EmailSender {
+	if utils.IsProduction(*cfg) {
+		return email.NewGomailService(cfg.EmailConfig)
+	}
+	return email.NewLogSender(cfg.EmailConfig)
+}
+
+func provideTemplateSender(sender email.EmailSender) (email.TemplateSender, error) {
+	return email.NewTemplateSender(sender, email.DefaultTemplatesDir)
+}
+
+// provideDiscordAdapter envía los avisos (ej: bloqueo de cuentas) a la API de notificaciones.
+func provideDiscordAdapter(cfg *config.AppConfig) *adapters.DiscordAdapter {
+	return adapters.NewDiscordAdapter(cfg.ApiDiscord)
+}
+
+func provideUserPaginationService(cfg *config.AppConfig) *pagination.PaginationService[models.User] {
+	return pagination.NewPaginationService[models.User]().WithCursorSecret([]byte(cfg.Pagination.CursorSecret))
+}
+
+func provideBankPaginationService(cfg *config.AppConfig) *pagination.PaginationService[models.Bank] {
+	return pagination.NewPaginationService[models.Bank]().WithCursorSecret([]byte(cfg.Pagination.CursorSecret))
+}
+
+func provideRolePaginationService(cfg *config.AppConfig) *pagination.PaginationService[models.Role] {
+	return pagination.NewPaginationService[models.Role]().WithCursorSecret([]byte(cfg.Pagination.CursorSecret))
 }
 
 var connectionSet = wire.NewSet(
@@ -121,6 +165,27 @@ var repositorySet = wire.NewSet(
 	refreshtoken.NewRefreshTokenWriterRepo,
 	refreshtoken.NewRefreshTokenRepository,
 
+	usertoken.NewUserTokenReaderRepo,
+	usertoken.NewUserTokenWriterRepo,
+	usertoken.NewUserTokenRepository,
+
+	twofactor.NewTwoFactorReaderRepo,
+	twofactor.NewTwoFactorWriterRepo,
+	twofactor.NewTwoFactorRepository,
+
+	apikey.NewAPIKeyReaderRepo,
+	apikey.NewAPIKeyWriterRepo,
+	apikey.NewAPIKeyRepository,
+
+	savedview.NewSavedViewReaderRepo,
+	savedview.NewSavedViewWriterRepo,
+
+	permission.NewPermissionReaderRepo,
+
+	role.NewRoleReaderRepo,
+	role.NewRoleWriterRepo,
+	role.NewRolePaginationRepo,
+
 	// --- Repositorios de Menú (Solo Lector) ---
 	// Cambiamos el nombre del constructor a la implementación existente:
 	// Comentamos los constructores de escritura y CRUD por ahora:
@@ -129,35 +194,64 @@ var repositorySet = wire.NewSet(
 )
 
 var serviceSet = wire.NewSet(
+	auth.NewKeyRing,
 	provideTokenService,
 	auth.NewAuthService,
+	auth.NewTokenDenylist,
+	auth.NewTwoFactorService,
+	auth.NewTwoFactorChallenges,
+	auth.NewLoginGuard,
+	auth.NewAPIKeyService,
+	auth.NewImpersonationService,
+	provideDiscordAdapter,
+	provideSessionRevoker,
+
+	provideEmailSender,
+	provideTemplateSender,
+	account.NewAccountService,
+	provideUserInviter,
 
 	provideUserPaginationService,
 	provideBankPaginationService,
+	provideRolePaginationService,
 
 	services.NewTransactionManager,
 	services.NewDatabaseService,
 
 	user2.NewUserReaderService,
 	user2.NewUserWriterService,
+	user2.NewUserImportService,
 
 	bank2.NewBankReaderService,
 	bank2.NewBankWriterService,
 	bank2.NewBankPaginationService,
 	bank2.NewDeactivationService,
 
+	menu2.NewMenuCache,
 	menu2.NewMenuReaderService,
 	menu2.NewMenuWriterService,
+
+	savedview2.NewSavedViewService,
+
+	permission2.NewPermissionService,
+
+	role2.NewRoleReaderService,
+	role2.NewRoleWriterService,
+	role2.NewRolePaginationService,
 	// Comentamos el servicio de escritura de menús:
 	// menu2.NewMenuWriterService,
 )
 
 var handlerSet = wire.NewSet(
 	handlers.NewAuthHandler,
+	handlers.NewAccountHandler,
 	handlers.NewUserHandler,
 	handlers.NewBankHandler,
 	handlers.NewDatabaseHandler,
 	handlers.NewMenuHandler,
+	handlers.NewSavedViewHandler,
+	handlers.NewRoleHandler,
+	handlers.NewAPIKeyHandler,
 	// NOTA: Si handlers.NewMenuHandler inyecta MenuWriterService,
 	// necesitarás actualizar su constructor también.
 	// handlers.NewMenuHandler,
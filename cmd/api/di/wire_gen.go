@@ -10,6 +10,7 @@ import (
 	"github.com/google/wire"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
+	"go-fiber-core/internal/adapters"
 	"go-fiber-core/internal/database/connections/gorm"
 	"go-fiber-core/internal/database/connections/pgx"
 	redis2 "go-fiber-core/internal/database/connections/redis"
@@ -17,17 +18,29 @@ import (
 	"go-fiber-core/internal/dtos/connect"
 	"go-fiber-core/internal/handlers"
 	"go-fiber-core/internal/models"
+	"go-fiber-core/internal/repositories/apikey"
 	"go-fiber-core/internal/repositories/bank"
 	"go-fiber-core/internal/repositories/menu"
+	"go-fiber-core/internal/repositories/permission"
 	"go-fiber-core/internal/repositories/refreshtoken"
+	"go-fiber-core/internal/repositories/role"
+	"go-fiber-core/internal/repositories/savedview"
+	"go-fiber-core/internal/repositories/twofactor"
 	"go-fiber-core/internal/repositories/user"
+	"go-fiber-core/internal/repositories/usertoken"
 	"go-fiber-core/internal/server"
 	"go-fiber-core/internal/services"
+	"go-fiber-core/internal/services/account"
 	"go-fiber-core/internal/services/auth"
 	bank2 "go-fiber-core/internal/services/bank"
+	"go-fiber-core/internal/services/email"
 	menu2 "go-fiber-core/internal/services/menu"
 	"go-fiber-core/internal/services/pagination"
+	permission2 "go-fiber-core/internal/services/permission"
+	role2 "go-fiber-core/internal/services/role"
+	savedview2 "go-fiber-core/internal/services/savedview"
 	user2 "go-fiber-core/internal/services/user"
+	"go-fiber-core/internal/utils"
 )
 
 // Injectors from wire.go:
@@ -64,31 +77,92 @@ func InitializeServer(configPath string) (*server.FiberServer, func(), error) {
 	refreshTokenReader := refreshtoken.NewRefreshTokenReaderRepo()
 	refreshTokenWriter := refreshtoken.NewRefreshTokenWriterRepo()
 	refreshTokenRepository := refreshtoken.NewRefreshTokenRepository(refreshTokenReader, refreshTokenWriter)
-	tokenService := provideTokenService(appConfig)
+	keyRing, err := auth.NewKeyRing(appConfig)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	tokenService := provideTokenService(appConfig, keyRing)
+	tokenDenylist := auth.NewTokenDenylist(connectDTO, appConfig)
 	menuReader := menu.NewMenuReaderRepository(connectDTO)
-	menuReaderService := menu2.NewMenuReaderService(menuReader)
-	authService := auth.NewAuthService(userReader, refreshTokenRepository, tokenService, menuReaderService, connectDTO)
-	authHandler := handlers.NewAuthHandler(authService)
+	menuCache := menu2.NewMenuCache(connectDTO, appConfig)
+	menuReaderService := menu2.NewMenuReaderService(menuReader, menuCache)
+	twoFactorReader := twofactor.NewTwoFactorReaderRepo()
+	twoFactorWriter := twofactor.NewTwoFactorWriterRepo()
+	twoFactorRepository := twofactor.NewTwoFactorRepository(twoFactorReader, twoFactorWriter)
+	twoFactorService, err := auth.NewTwoFactorService(connectDTO, twoFactorRepository, userReader, appConfig)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	twoFactorChallenges := auth.NewTwoFactorChallenges(connectDTO)
+	discordAdapter := provideDiscordAdapter(appConfig)
+	loginGuard := auth.NewLoginGuard(connectDTO, appConfig, discordAdapter)
+	authService := auth.NewAuthService(userReader, refreshTokenRepository, tokenService, tokenDenylist, menuReaderService, twoFactorService, twoFactorChallenges, loginGuard, appConfig, connectDTO)
+	permissionReader := permission.NewPermissionReaderRepo()
+	impersonationService := auth.NewImpersonationService(connectDTO, userReader, permissionReader, tokenService, tokenDenylist, appConfig)
+	authHandler := handlers.NewAuthHandler(authService, twoFactorService, impersonationService, keyRing)
 	userWriter := user.NewUserWriterRepo()
-	userWriterService := user2.NewUserWriterService(connectDTO, userWriter, userReader)
-	paginationService := provideUserPaginationService()
+	sessionRevoker := provideSessionRevoker(authService)
+	userWriterService := user2.NewUserWriterService(connectDTO, userWriter, userReader, sessionRevoker, loginGuard, permissionReader)
+	userTokenReader := usertoken.NewUserTokenReaderRepo()
+	userTokenWriter := usertoken.NewUserTokenWriterRepo()
+	userTokenRepository := usertoken.NewUserTokenRepository(userTokenReader, userTokenWriter)
+	emailSender := provideEmailSender(appConfig)
+	templateSender, err := provideTemplateSender(emailSender)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	accountService := account.NewAccountService(connectDTO, userReader, userWriterService, userTokenRepository, templateSender, appConfig)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	paginationService := provideUserPaginationService(appConfig)
 	userPaginator := user.NewUserPaginatorRepo(paginationService)
 	userReaderService := user2.NewUserReaderService(connectDTO, userReader, userPaginator)
-	userHandler := handlers.NewUserHandler(userWriterService, userReaderService)
+	savedViewReader := savedview.NewSavedViewReaderRepo()
+	savedViewWriter := savedview.NewSavedViewWriterRepo()
+	paginationPaginationService := provideBankPaginationService(appConfig)
+	savedViewService := savedview2.NewSavedViewService(connectDTO, savedViewReader, savedViewWriter, paginationPaginationService, paginationService)
+	roleReader := role.NewRoleReaderRepo()
+	roleWriter := role.NewRoleWriterRepo()
+	inviter := provideUserInviter(accountService)
+	userImportService := user2.NewUserImportService(connectDTO, userReader, userWriter, roleReader, roleWriter, permissionReader, inviter)
+	userHandler := handlers.NewUserHandler(userWriterService, userReaderService, savedViewService, userImportService)
 	bankWriter := bank.NewBankWriterRepo()
 	bankReader := bank.NewBankReaderRepo()
 	bankWriterService := bank2.NewBankWriterService(connectDTO, bankWriter, bankReader)
 	bankReaderService := bank2.NewBankReaderService(connectDTO, bankReader)
-	paginationPaginationService := provideBankPaginationService()
 	bankPagination := bank.NewBankPaginationRepo(paginationPaginationService)
 	bankPaginationService := bank2.NewBankPaginationService(connectDTO, bankPagination)
-	bankHandler := handlers.NewBankHandler(bankWriterService, bankReaderService, bankPaginationService)
+	bankHandler := handlers.NewBankHandler(bankWriterService, bankReaderService, bankPaginationService, savedViewService)
 	menuWriter := menu.NewMenuWriterRepository(connectDTO)
-	menuWriterService := menu2.NewMenuWriterService(menuWriter, connectDTO)
+	menuWriterService := menu2.NewMenuWriterService(menuWriter, menuReader, roleReader, menuCache, connectDTO)
 	menuHandler := handlers.NewMenuHandler(menuWriterService, menuReaderService)
 	databaseService := services.NewDatabaseService(appConfig, connectDTO)
 	databaseHandler := handlers.NewDatabaseHandler(databaseService)
-	fiberServer, cleanup5, err := server.NewFiberServer(appConfig, connectDTO, authHandler, userHandler, bankHandler, menuHandler, databaseHandler, tokenService, userWriterService)
+	savedViewHandler := handlers.NewSavedViewHandler(savedViewService)
+	roleWriterService := role2.NewRoleWriterService(connectDTO, roleWriter, roleReader, permissionReader, menuCache)
+	roleReaderService := role2.NewRoleReaderService(connectDTO, roleReader)
+	paginationService2 := provideRolePaginationService(appConfig)
+	rolePagination := role.NewRolePaginationRepo(paginationService2)
+	rolePaginationService := role2.NewRolePaginationService(connectDTO, rolePagination)
+	permissionService := permission2.NewPermissionService(connectDTO, permissionReader)
+	roleHandler := handlers.NewRoleHandler(roleWriterService, roleReaderService, rolePaginationService, permissionService)
+	apiKeyReader := apikey.NewAPIKeyReaderRepo()
+	apiKeyWriter := apikey.NewAPIKeyWriterRepo()
+	apiKeyRepository := apikey.NewAPIKeyRepository(apiKeyReader, apiKeyWriter)
+	apiKeyService := auth.NewAPIKeyService(connectDTO, apiKeyRepository, userReader, permissionReader)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	fiberServer, cleanup5, err := server.NewFiberServer(appConfig, connectDTO, authHandler, accountHandler, userHandler, bankHandler, menuHandler, databaseHandler, savedViewHandler, roleHandler, apiKeyHandler, tokenService, tokenDenylist, apiKeyService, permissionService, menuReaderService, userWriterService)
 	if err != nil {
 		cleanup4()
 		cleanup3()
@@ -148,16 +222,47 @@ func provideConnectDTO(
 	}
 }
 
-func provideTokenService(cfg *config.AppConfig) auth.TokenService {
-	return auth.NewTokenService(cfg)
+func provideTokenService(cfg *config.AppConfig, keys auth.KeyRing) auth.TokenService {
+	return auth.NewTokenService(cfg, keys)
+}
+
+// provideSessionRevoker expone el cierre de sesiones de AuthService a UserWriterService.
+func provideSessionRevoker(s auth.AuthService) auth.SessionRevoker {
+	return s
+}
+
+// provideUserInviter expone la invitación de AccountService a UserImportService.
+func provideUserInviter(s account.AccountService) user2.Inviter {
+	return s
+}
+
+// provideEmailSender envía por SMTP en producción; en el resto de los entornos imprime los correos.
+func provideEmailSender(cfg *config.AppConfig) email.EmailSender {
+	if utils.IsProduction(*cfg) {
+		return email.NewGomailService(cfg.EmailConfig)
+	}
+	return email.NewLogSender(cfg.EmailConfig)
+}
+
+func provideTemplateSender(sender email.EmailSender) (email.TemplateSender, error) {
+	return email.NewTemplateSender(sender, email.DefaultTemplatesDir)
+}
+
+// provideDiscordAdapter envía los avisos (ej: bloqueo de cuentas) a la API de notificaciones.
+func provideDiscordAdapter(cfg *config.AppConfig) *adapters.DiscordAdapter {
+	return adapters.NewDiscordAdapter(cfg.ApiDiscord)
 }
 
-func provideUserPaginationService() *pagination.PaginationService[models.User] {
-	return pagination.NewPaginationService[models.User]()
+func provideUserPaginationService(cfg *config.AppConfig) *pagination.PaginationService[models.User] {
+	return pagination.NewPaginationService[models.User]().WithCursorSecret([]byte(cfg.Pagination.CursorSecret))
 }
 
-func provideBankPaginationService() *pagination.PaginationService[models.Bank] {
-	return pagination.NewPaginationService[models.Bank]()
+func provideBankPaginationService(cfg *config.AppConfig) *pagination.PaginationService[models.Bank] {
+	return pagination.NewPaginationService[models.Bank]().WithCursorSecret([]byte(cfg.Pagination.CursorSecret))
+}
+
+func provideRolePaginationService(cfg *config.AppConfig) *pagination.PaginationService[models.Role] {
+	return pagination.NewPaginationService[models.Role]().WithCursorSecret([]byte(cfg.Pagination.CursorSecret))
 }
 
 var connectionSet = wire.NewSet(
@@ -168,11 +273,17 @@ var connectionSet = wire.NewSet(
 	provideConnectDTO,
 )
 
-var repositorySet = wire.NewSet(user.NewUserReaderRepo, user.NewUserWriterRepo, user.NewUserPaginatorRepo, user.NewUserRepository, bank.NewBankReaderRepo, bank.NewBankWriterRepo, bank.NewBankCrudRepository, bank.NewBankPaginationRepo, menu.NewMenuReaderRepository, menu.NewMenuWriterRepository, refreshtoken.NewRefreshTokenReaderRepo, refreshtoken.NewRefreshTokenWriterRepo, refreshtoken.NewRefreshTokenRepository)
+var repositorySet = wire.NewSet(user.NewUserReaderRepo, user.NewUserWriterRepo, user.NewUserPaginatorRepo, user.NewUserRepository, bank.NewBankReaderRepo, bank.NewBankWriterRepo, bank.NewBankCrudRepository, bank.NewBankPaginationRepo, menu.NewMenuReaderRepository, menu.NewMenuWriterRepository, refreshtoken.NewRefreshTokenReaderRepo, refreshtoken.NewRefreshTokenWriterRepo, refreshtoken.NewRefreshTokenRepository, usertoken.NewUserTokenReaderRepo, usertoken.NewUserTokenWriterRepo, usertoken.NewUserTokenRepository, twofactor.NewTwoFactorReaderRepo, twofactor.NewTwoFactorWriterRepo, twofactor.NewTwoFactorRepository, apikey.NewAPIKeyReaderRepo, apikey.NewAPIKeyWriterRepo, apikey.NewAPIKeyRepository, savedview.NewSavedViewReaderRepo, savedview.NewSavedViewWriterRepo, permission.NewPermissionReaderRepo, role.NewRoleReaderRepo, role.NewRoleWriterRepo, role.NewRolePaginationRepo)
+
+var serviceSet = wire.NewSet(auth.NewKeyRing, provideTokenService, auth.NewAuthService, auth.NewTokenDenylist, auth.NewTwoFactorService, auth.NewTwoFactorChallenges, auth.NewLoginGuard, auth.NewAPIKeyService, auth.NewImpersonationService, provideDiscordAdapter,
+	provideSessionRevoker,
+
+	provideEmailSender,
+	provideTemplateSender, account.NewAccountService, provideUserInviter,
 
-var serviceSet = wire.NewSet(
-	provideTokenService, auth.NewAuthService, provideUserPaginationService,
-	provideBankPaginationService, services.NewTransactionManager, services.NewDatabaseService, user2.NewUserReaderService, user2.NewUserWriterService, bank2.NewBankReaderService, bank2.NewBankWriterService, bank2.NewBankPaginationService, bank2.NewDeactivationService, menu2.NewMenuReaderService, menu2.NewMenuWriterService,
+	provideUserPaginationService,
+	provideBankPaginationService,
+	provideRolePaginationService, services.NewTransactionManager, services.NewDatabaseService, user2.NewUserReaderService, user2.NewUserWriterService, user2.NewUserImportService, bank2.NewBankReaderService, bank2.NewBankWriterService, bank2.NewBankPaginationService, bank2.NewDeactivationService, menu2.NewMenuCache, menu2.NewMenuReaderService, menu2.NewMenuWriterService, savedview2.NewSavedViewService, permission2.NewPermissionService, role2.NewRoleReaderService, role2.NewRoleWriterService, role2.NewRolePaginationService,
 )
 
-var handlerSet = wire.NewSet(handlers.NewAuthHandler, handlers.NewUserHandler, handlers.NewBankHandler, handlers.NewDatabaseHandler, handlers.NewMenuHandler)
+var handlerSet = wire.NewSet(handlers.NewAuthHandler, handlers.NewAccountHandler, handlers.NewUserHandler, handlers.NewBankHandler, handlers.NewDatabaseHandler, handlers.NewMenuHandler, handlers.NewSavedViewHandler, handlers.NewRoleHandler, handlers.NewAPIKeyHandler)
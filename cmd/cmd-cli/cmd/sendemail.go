@@ -41,7 +41,7 @@ están funcionando correctamente.`,
 			emailSvc = email.NewLogSender(appConfig.EmailConfig)
 		}
 
-		templateSvc, err := email.NewTemplateSender(emailSvc, "internal/templates")
+		templateSvc, err := email.NewTemplateSender(emailSvc, email.DefaultTemplatesDir)
 		if err != nil {
 			return fmt.Errorf("❌ no se pudieron cargar las plantillas de email: %w", err)
 		}
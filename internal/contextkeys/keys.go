@@ -1,6 +1,9 @@
 package contextkeys
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Definimos un tipo local para la clave. Esto previene colisiones
 // con otras bibliotecas que puedan usar el mismo string.
@@ -22,3 +25,99 @@ func GetUserID(ctx context.Context) (string, bool) {
 	userID, ok := ctx.Value(UserIDKey).(string)
 	return userID, ok
 }
+
+// PermissionsKey guarda los permisos ya resueltos del usuario durante la solicitud,
+// para que varios RequirePermission no consulten la base más de una vez.
+const PermissionsKey contextKey = "permissions"
+
+// SetPermissions guarda en el contexto el conjunto de permisos del usuario.
+func SetPermissions(ctx context.Context, permissions map[string]bool) context.Context {
+	return context.WithValue(ctx, PermissionsKey, permissions)
+}
+
+// GetPermissions devuelve los permisos guardados por SetPermissions.
+func GetPermissions(ctx context.Context) (map[string]bool, bool) {
+	permissions, ok := ctx.Value(PermissionsKey).(map[string]bool)
+	return permissions, ok
+}
+
+// MenuPathsKey guarda los to_path de los menús efectivos del usuario durante la solicitud,
+// para que varios RequireMenu no armen el menú más de una vez.
+const MenuPathsKey contextKey = "menuPaths"
+
+// SetMenuPaths guarda en el contexto el conjunto de to_path de los menús del usuario.
+func SetMenuPaths(ctx context.Context, paths map[string]bool) context.Context {
+	return context.WithValue(ctx, MenuPathsKey, paths)
+}
+
+// GetMenuPaths devuelve los to_path guardados por SetMenuPaths.
+func GetMenuPaths(ctx context.Context) (map[string]bool, bool) {
+	paths, ok := ctx.Value(MenuPathsKey).(map[string]bool)
+	return paths, ok
+}
+
+// SessionKey guarda la sesión del access token de la solicitud (claims sid y jti).
+const SessionKey contextKey = "session"
+
+// Session identifica el access token con el que se autenticó la solicitud.
+type Session struct {
+	ID        string    // Claim sid: se conserva al refrescar los tokens
+	TokenID   string    // Claim jti: único por access token
+	ExpiresAt time.Time // Claim exp
+}
+
+// SetSession enriquece un contexto con la sesión del token.
+func SetSession(ctx context.Context, session Session) context.Context {
+	return context.WithValue(ctx, SessionKey, session)
+}
+
+// GetSession devuelve la sesión guardada por SetSession.
+func GetSession(ctx context.Context) (Session, bool) {
+	session, ok := ctx.Value(SessionKey).(Session)
+	return session, ok
+}
+
+// ClientKey guarda el cliente máquina a máquina autenticado con una API key.
+const ClientKey contextKey = "client"
+
+// Client identifica la API key con la que se autenticó la solicitud. El usuario
+// (SetUserID) es el dueño de la clave.
+type Client struct {
+	Code     string   // X-Client-Code verificado contra la clave
+	APIKeyID uint64   // ID de la clave en api_keys
+	Scopes   []string // Permisos a los que se limita la clave
+}
+
+// SetClient enriquece un contexto con el cliente de la API key.
+func SetClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, ClientKey, client)
+}
+
+// GetClient devuelve el cliente guardado por SetClient.
+func GetClient(ctx context.Context) (Client, bool) {
+	client, ok := ctx.Value(ClientKey).(Client)
+	return client, ok
+}
+
+// ActorIDKey guarda el usuario real de una solicitud con token de suplantación. Durante
+// la suplantación UserIDKey es el usuario suplantado (el sujeto: sus permisos y su menú)
+// y ActorIDKey quien lo suplanta.
+const ActorIDKey contextKey = "actorID"
+
+// SetActorID marca la solicitud como suplantada por actorID.
+func SetActorID(ctx context.Context, actorID string) context.Context {
+	return context.WithValue(ctx, ActorIDKey, actorID)
+}
+
+// GetActorID devuelve el usuario real guardado por SetActorID; false si la solicitud no
+// es una suplantación.
+func GetActorID(ctx context.Context) (string, bool) {
+	actorID, ok := ctx.Value(ActorIDKey).(string)
+	return actorID, ok
+}
+
+// GetSubjectID devuelve el usuario en nombre del que se actúa: el suplantado durante una
+// suplantación y, si no, el autenticado. Es el mismo valor que GetUserID.
+func GetSubjectID(ctx context.Context) (string, bool) {
+	return GetUserID(ctx)
+}
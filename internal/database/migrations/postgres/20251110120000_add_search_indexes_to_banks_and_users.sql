@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- La expresión debe coincidir con la que arma pagination.searchVector (tags `search` del modelo)
+-- para que Postgres use el índice en las búsquedas de texto.
+CREATE INDEX idx_banks_search_gin ON banks USING GIN ((
+    setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(entity_code, '')), 'B')
+));
+CREATE INDEX idx_users_search_gin ON users USING GIN ((
+    setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(email, '')), 'B')
+));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_search_gin;
+DROP INDEX IF EXISTS idx_banks_search_gin;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE saved_views (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id INT REFERENCES roles(id) ON DELETE SET NULL,
+    resource VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    request JSONB NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now(),
+    deleted_at TIMESTAMP
+);
+
+-- Nombre único por usuario y recurso
+CREATE UNIQUE INDEX idx_saved_views_user_resource_name ON saved_views (user_id, resource, lower(name)) WHERE deleted_at IS NULL;
+
+-- Una sola vista por defecto por usuario y recurso
+CREATE UNIQUE INDEX idx_saved_views_default ON saved_views (user_id, resource) WHERE is_default AND deleted_at IS NULL;
+
+CREATE INDEX idx_saved_views_role_id ON saved_views (role_id);
+CREATE INDEX idx_saved_views_deleted_at ON saved_views (deleted_at);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_saved_views_deleted_at;
+DROP INDEX IF EXISTS idx_saved_views_role_id;
+DROP INDEX IF EXISTS idx_saved_views_default;
+DROP INDEX IF EXISTS idx_saved_views_user_resource_name;
+DROP TABLE IF EXISTS saved_views;
+-- +goose StatementEnd
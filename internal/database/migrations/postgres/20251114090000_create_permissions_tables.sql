@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE permissions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) UNIQUE NOT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now(),
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_permissions_deleted_at ON permissions (deleted_at);
+
+CREATE TABLE role_permission (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE INDEX idx_role_permission_permission_id ON role_permission (permission_id);
+
+-- Permisos usados por las rutas protegidas (middleware.RequirePermission)
+INSERT INTO permissions (name, description) VALUES
+    ('banks.read', 'Listar y consultar bancos'),
+    ('banks.create', 'Crear bancos'),
+    ('banks.update', 'Modificar bancos'),
+    ('banks.delete', 'Borrado lógico de bancos'),
+    ('banks.force_delete', 'Borrado físico de bancos'),
+    ('banks.export', 'Exportar bancos'),
+    ('users.read', 'Listar y consultar usuarios'),
+    ('users.create', 'Crear usuarios'),
+    ('users.update', 'Modificar usuarios'),
+    ('users.delete', 'Borrado lógico de usuarios'),
+    ('users.force_delete', 'Borrado físico de usuarios'),
+    ('users.export', 'Exportar usuarios'),
+    ('menus.read', 'Ver el menú propio'),
+    ('menus.assign', 'Asignar y quitar menús a usuarios')
+ON CONFLICT (name) DO NOTHING;
+
+-- El rol admin (el seeder lo crea como "Admin") recibe todos los permisos para no perder
+-- el acceso al activar el middleware. Si todavía no existe, se crea.
+INSERT INTO roles (name)
+SELECT 'Admin' WHERE NOT EXISTS (SELECT 1 FROM roles WHERE LOWER(name) = 'admin');
+INSERT INTO role_permission (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE LOWER(r.name) = 'admin'
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_role_permission_permission_id;
+DROP TABLE IF EXISTS role_permission;
+DROP INDEX IF EXISTS idx_permissions_deleted_at;
+DROP TABLE IF EXISTS permissions;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Permisos de la administración de roles (/roles)
+INSERT INTO permissions (name, description) VALUES
+    ('roles.read', 'Listar y consultar roles y permisos'),
+    ('roles.create', 'Crear roles'),
+    ('roles.update', 'Renombrar, activar/desactivar roles y cambiar sus permisos'),
+    ('roles.delete', 'Borrado lógico de roles'),
+    ('roles.assign', 'Asignar y quitar roles a usuarios')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permission (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE LOWER(r.name) = 'admin' AND p.name LIKE 'roles.%'
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name LIKE 'roles.%';
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Menús que otorga cada rol. El menú efectivo de un usuario es la unión de los menús
+-- de sus roles activos más las excepciones de menu_user (is_active = TRUE otorga,
+-- is_active = FALSE deniega).
+CREATE TABLE menu_role (
+    menu_id INT NOT NULL REFERENCES menus(id) ON DELETE CASCADE,
+    role_id INT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (menu_id, role_id)
+);
+
+CREATE INDEX idx_menu_role_role_id ON menu_role (role_id);
+
+-- Consultas del menú efectivo por usuario
+CREATE INDEX idx_menu_user_user_id ON menu_user (user_id);
+
+UPDATE permissions SET description = 'Asignar menús a roles y excepciones a usuarios' WHERE name = 'menus.assign';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE permissions SET description = 'Asignar y quitar menús a usuarios' WHERE name = 'menus.assign';
+DROP INDEX IF EXISTS idx_menu_user_user_id;
+DROP INDEX IF EXISTS idx_menu_role_role_id;
+DROP TABLE IF EXISTS menu_role;
+-- +goose StatementEnd
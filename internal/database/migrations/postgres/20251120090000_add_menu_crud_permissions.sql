@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Permisos de la administración del árbol de menús
+INSERT INTO permissions (name, description) VALUES
+    ('menus.create', 'Crear ítems de menú'),
+    ('menus.update', 'Editar, mover y reordenar ítems de menú'),
+    ('menus.delete', 'Borrado lógico y restauración de ítems de menú'),
+    ('menus.export', 'Exportar el árbol de menús'),
+    ('menus.import', 'Importar el árbol de menús')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permission (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE LOWER(r.name) = 'admin'
+  AND p.name IN ('menus.create', 'menus.update', 'menus.delete', 'menus.export', 'menus.import')
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name IN ('menus.create', 'menus.update', 'menus.delete', 'menus.export', 'menus.import');
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Una fila por token emitido; las rotaciones de una misma sesión (dispositivo) comparten session_id.
+ALTER TABLE refresh_tokens
+    ADD COLUMN session_id VARCHAR(64),
+    ADD COLUMN user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    ADD COLUMN last_used_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN rotated_at TIMESTAMPTZ,
+    ADD COLUMN revoked_at TIMESTAMPTZ;
+
+-- Los tokens existentes quedan cada uno en su propia sesión
+UPDATE refresh_tokens SET session_id = 'legacy-' || id WHERE session_id IS NULL;
+ALTER TABLE refresh_tokens ALTER COLUMN session_id SET NOT NULL;
+
+CREATE INDEX idx_refresh_tokens_session_id ON refresh_tokens(session_id);
+-- Sesiones activas de un usuario (GET /auth/sessions)
+CREATE INDEX idx_refresh_tokens_user_active ON refresh_tokens(user_id)
+    WHERE rotated_at IS NULL AND revoked_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_refresh_tokens_user_active;
+DROP INDEX IF EXISTS idx_refresh_tokens_session_id;
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS revoked_at,
+    DROP COLUMN IF EXISTS rotated_at,
+    DROP COLUMN IF EXISTS last_used_at,
+    DROP COLUMN IF EXISTS ip_address,
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS session_id;
+-- +goose StatementEnd
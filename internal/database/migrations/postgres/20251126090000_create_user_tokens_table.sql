@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tokens de un solo uso enviados por email (recuperar contraseña, verificar email).
+-- Solo se guarda el SHA-256 del token; el valor en claro viaja únicamente en el enlace.
+CREATE TABLE user_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    sent_to VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_tokens_user_purpose ON user_tokens(user_id, purpose);
+
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+-- Las cuentas existentes se consideran verificadas para no bloquear su login.
+UPDATE users SET email_verified_at = created_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
+DROP TABLE IF EXISTS user_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Segundo factor TOTP por usuario. El secreto se guarda cifrado (AES-GCM con AUTH_TWO_FACTOR_KEY)
+-- porque hay que leerlo para validar cada código; confirmed_at es NULL mientras la
+-- inscripción no se confirma con un primer código.
+CREATE TABLE user_two_factor (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret_encrypted TEXT NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Códigos de recuperación de un solo uso (solo su SHA-256).
+CREATE TABLE user_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_two_factor;
+-- +goose StatementEnd
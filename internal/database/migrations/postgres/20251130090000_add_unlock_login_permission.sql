@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO permissions (name, description) VALUES
+    ('users.unlock', 'Desbloquear la cuenta de un usuario bloqueada por intentos fallidos de login')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permission (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE LOWER(r.name) = 'admin' AND p.name = 'users.unlock'
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'users.unlock';
+-- +goose StatementEnd
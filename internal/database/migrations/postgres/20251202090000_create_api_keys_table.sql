@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- API keys de clientes máquina a máquina. La clave solo se muestra al crearla: acá queda su
+-- SHA-256 y un prefijo para reconocerla en el listado. client_code es el X-Client-Code con
+-- el que el cliente debe presentarla (y la identidad del rate limit); scopes limita los
+-- permisos del dueño que puede usar.
+CREATE TABLE api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    client_code VARCHAR(64) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes JSONB NOT NULL DEFAULT '[]',
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
+CREATE INDEX idx_api_keys_client_code ON api_keys(client_code) WHERE revoked_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
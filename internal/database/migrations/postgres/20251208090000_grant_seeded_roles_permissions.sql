@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Las migraciones de permisos aplicadas antes de comparar el nombre sin distinguir
+-- mayúsculas dejaron sin permisos al rol "Admin" del seeder: recibe todos.
+INSERT INTO role_permission (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE LOWER(r.name) = 'admin' AND p.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+-- Permisos base de los demás roles sembrados: lectura (y alta de bancos). Modificar, borrar
+-- y exportar queda para Admin (ver seeders.RoleBaselinePermissions).
+INSERT INTO role_permission (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE LOWER(r.name) IN ('coordinador', 'supervisor', 'operador')
+  AND p.name IN ('menus.read', 'banks.read', 'banks.create', 'users.read')
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM role_permission rp
+USING roles r, permissions p
+WHERE rp.role_id = r.id AND rp.permission_id = p.id
+  AND LOWER(r.name) IN ('coordinador', 'supervisor', 'operador')
+  AND p.name IN ('menus.read', 'banks.read', 'banks.create', 'users.read');
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Las bases creadas antes de menu_role tienen los menús de cada rol copiados en menu_user.
+-- Se carga menu_role con las plantillas de los roles sembrados (seeders.MenuTemplates),
+-- solo para los roles que todavía no tienen menús: los ya administrados por la API no se tocan.
+INSERT INTO menu_role (menu_id, role_id)
+SELECT t.menu_id, r.id
+FROM (VALUES
+    ('admin', 1), ('admin', 2), ('admin', 3), ('admin', 4), ('admin', 5),
+    ('admin', 6), ('admin', 7), ('admin', 8), ('admin', 9), ('admin', 10),
+    ('admin', 11), ('admin', 12), ('admin', 13), ('admin', 14), ('admin', 15),
+    ('coordinador', 1), ('coordinador', 3), ('coordinador', 4), ('coordinador', 6), ('coordinador', 14),
+    ('supervisor', 1), ('supervisor', 3), ('supervisor', 14), ('supervisor', 15),
+    ('operador', 1), ('operador', 15)
+) AS t (role_name, menu_id)
+JOIN roles r ON LOWER(r.name) = t.role_name AND r.deleted_at IS NULL
+JOIN menus m ON m.id = t.menu_id
+WHERE NOT EXISTS (SELECT 1 FROM menu_role mr WHERE mr.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+-- Las copias por usuario que ya otorga alguno de sus roles activos dejan de ser excepciones.
+-- Las denegaciones (is_active = FALSE) y los otorgamientos que ningún rol cubre se conservan.
+DELETE FROM menu_user mu
+WHERE mu.is_active = TRUE
+  AND EXISTS (
+    SELECT 1
+    FROM role_user ru
+    JOIN roles r ON r.id = ru.role_id AND r.is_active = TRUE AND r.deleted_at IS NULL
+    JOIN menu_role mr ON mr.role_id = ru.role_id
+    WHERE ru.user_id = mu.user_id AND mr.menu_id = mu.menu_id
+  );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Migración de datos: no se restauran las copias de menu_user (el menú efectivo es el mismo).
+SELECT 1;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- La primera versión de 20251208090000 dio a Coordinador, Supervisor y Operador permisos de
+-- modificar, borrar y exportar bancos y usuarios. Se les quitan: por defecto solo leen (y
+-- dan de alta bancos); el resto queda para Admin (ver seeders.RoleBaselinePermissions).
+DELETE FROM role_permission rp
+USING roles r, permissions p
+WHERE rp.role_id = r.id AND rp.permission_id = p.id
+  AND LOWER(r.name) IN ('coordinador', 'supervisor', 'operador')
+  AND p.name IN (
+    'banks.update', 'banks.delete', 'banks.force_delete', 'banks.export',
+    'users.create', 'users.update', 'users.delete', 'users.force_delete', 'users.export'
+  );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 1;
+-- +goose StatementEnd
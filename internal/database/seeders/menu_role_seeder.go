@@ -0,0 +1,147 @@
+package seeders
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MenuRole-specific constants
+const (
+	menuRoleTableName = "menu_role"
+	menuUserTableName = "menu_user" // Singular, según tu modelo
+)
+
+// MenuTemplates defines which menus each role has access to.
+// They are only the initial data of menu_role: once seeded, menus are managed per role
+// through the API (/menus/:id/roles, /menus/roles/bulk).
+var MenuTemplates = map[string][]uint{
+	"Admin": {
+		// Admin tiene acceso a TODO
+		1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+	},
+	"Coordinador": {
+		// Coordinador: Dashboard, Mensajes, Segmentos, Tablas (sin hijos), Mis Archivos
+		1, 3, 4, 6, 14,
+	},
+	"Supervisor": {
+		// Supervisor: Dashboard, Mensajes, Mis Archivos, Notificaciones
+		1, 3, 14, 15,
+	},
+	"Operador": {
+		// Operador: Solo Dashboard y Notificaciones
+		1, 15,
+	},
+}
+
+// MenuRoleSeeder seeds the menu_role relationship table from MenuTemplates.
+// Users get their menus through their roles, so menu_user (per-user overrides)
+// starts empty.
+func MenuRoleSeeder(pool *pgxpool.Pool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultSeederTimeout)
+	defer cancel()
+
+	logger := slog.Default().With("seeder", "menu_role")
+	logger.Info("iniciando seeder de relación menu_role")
+
+	roleIDs, err := getRoleIDsByName(ctx, pool)
+	if err != nil {
+		return fmt.Errorf("getRoleIDsByName: %w", err)
+	}
+
+	now := time.Now()
+	menuRoles := make([]*MenuRole, 0)
+	for roleName, menuIDs := range MenuTemplates {
+		roleID, exists := roleIDs[roleName]
+		if !exists {
+			logger.Warn("no existe el rol de la plantilla", "role", roleName)
+			continue
+		}
+		for _, menuID := range menuIDs {
+			menuRoles = append(menuRoles, &MenuRole{
+				MenuID:    menuID,
+				RoleID:    roleID,
+				CreatedAt: now,
+			})
+		}
+		logger.Debug("plantilla de menús encontrada", "role", roleName, "menu_count", len(menuIDs))
+	}
+
+	if err := seedMenuRoles(ctx, pool, menuRoles, logger); err != nil {
+		return fmt.Errorf("seedMenuRoles: %w", err)
+	}
+
+	logger.Info("seeder completado exitosamente", "total_relaciones", len(menuRoles))
+	return nil
+}
+
+// MenuRole represents a menu-role relationship for seeding.
+type MenuRole struct {
+	MenuID    uint
+	RoleID    uint64
+	CreatedAt time.Time
+}
+
+// getRoleIDsByName returns the ID of every role, keyed by name.
+func getRoleIDsByName(ctx context.Context, pool *pgxpool.Pool) (map[string]uint64, error) {
+	rows, err := pool.Query(ctx, `SELECT id, name FROM roles WHERE deleted_at IS NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("query roles: %w", err)
+	}
+	defer rows.Close()
+
+	roleIDs := make(map[string]uint64)
+	for rows.Next() {
+		var id uint64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("scan role: %w", err)
+		}
+		roleIDs[name] = id
+	}
+	return roleIDs, rows.Err()
+}
+
+// seedMenuRoles executes the database seeding operation within a transaction.
+// menu_user is emptied as well: the old per-user copies of the templates are no longer needed.
+func seedMenuRoles(ctx context.Context, pool *pgxpool.Pool, menuRoles []*MenuRole, logger *slog.Logger) error {
+	return executeInTransaction(ctx, pool, func(ctx context.Context, tx pgx.Tx) error {
+		for _, table := range []string{menuRoleTableName, menuUserTableName} {
+			if err := truncateTable(ctx, tx, table); err != nil {
+				return fmt.Errorf("truncate: %w", err)
+			}
+			logger.Debug("tabla truncada", "table", table)
+		}
+
+		rows := menuRolesToCopyRows(menuRoles)
+		count, err := tx.CopyFrom(
+			ctx,
+			pgx.Identifier{menuRoleTableName},
+			[]string{"menu_id", "role_id", "created_at"},
+			pgx.CopyFromRows(rows),
+		)
+		if err != nil {
+			return fmt.Errorf("CopyFrom: %w", err)
+		}
+
+		logger.Debug("relaciones insertadas vía COPY", "count", count)
+		return nil
+	})
+}
+
+// menuRolesToCopyRows converts MenuRole structs to the format required by CopyFrom.
+func menuRolesToCopyRows(menuRoles []*MenuRole) [][]any {
+	rows := make([][]any, 0, len(menuRoles))
+	for _, mr := range menuRoles {
+		rows = append(rows, []any{
+			mr.MenuID,
+			mr.RoleID,
+			mr.CreatedAt,
+		})
+	}
+	return rows
+}
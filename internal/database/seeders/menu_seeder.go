@@ -7,6 +7,8 @@ import (
 	"log/slog"
 	"os"
 
+	"go-fiber-core/internal/dtos"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -52,14 +54,8 @@ func MenuSeeder(pool *pgxpool.Pool) error {
 
 // MenuJSON represents the JSON structure for menu items.
 // IDs are not needed - the structure is inferred from the children array.
-type MenuJSON struct {
-	ItemType   string     `json:"item_type"`
-	ItemName   string     `json:"item_name"`
-	ToPath     *string    `json:"to_path,omitempty"`
-	Icon       *string    `json:"icon,omitempty"`
-	OrderIndex int        `json:"order_index"`
-	Children   []MenuJSON `json:"children,omitempty"`
-}
+// It is the same format the API exports and imports (GET /menus/export, POST /menus/import).
+type MenuJSON = dtos.MenuTreeItem
 
 // Menu represents a flattened menu item for database insertion.
 type Menu struct {
@@ -127,7 +123,7 @@ func jsonToMenu(item *MenuJSON, parentID *uint) *Menu {
 		Icon:       item.Icon,
 		ParentID:   parentID,
 		OrderIndex: item.OrderIndex,
-		IsActive:   true, // All menus are active by default
+		IsActive:   item.Active(), // All menus are active by default
 	}
 
 	return menu
@@ -0,0 +1,83 @@
+package seeders
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RolePermission-specific constants
+const (
+	rolePermissionTableName = "role_permission"
+	adminRoleName           = "admin" // compared case-insensitively
+)
+
+// baselinePermissions is the default access of the non-admin seeded roles: read (and
+// create banks). Updating, deleting and exporting stay with the admin role.
+var baselinePermissions = []string{
+	"menus.read",
+	"banks.read", "banks.create",
+	"users.read",
+}
+
+// RoleBaselinePermissions defines the permissions of the other seeded roles. Like
+// MenuTemplates, they are only initial data: afterwards they are managed through
+// /roles/:id/permissions. Keep in sync with migrations 20251208090000 and 20251209090000.
+var RoleBaselinePermissions = map[string][]string{
+	"Coordinador": baselinePermissions,
+	"Supervisor":  baselinePermissions,
+	"Operador":    baselinePermissions,
+}
+
+// RolePermissionSeeder grants every permission to the admin role and
+// RoleBaselinePermissions to the other roles.
+// The roles seeder truncates roles with CASCADE, which also empties role_permission,
+// so this must run after it. Permissions themselves are created by the migrations.
+func RolePermissionSeeder(pool *pgxpool.Pool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultSeederTimeout)
+	defer cancel()
+
+	logger := slog.Default().With("seeder", "role_permission")
+	logger.Info("iniciando seeder de relación role_permission")
+
+	var count int64
+	err := executeInTransaction(ctx, pool, func(ctx context.Context, tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO role_permission (role_id, permission_id)
+			SELECT r.id, p.id
+			FROM roles r
+			CROSS JOIN permissions p
+			WHERE LOWER(r.name) = $1 AND p.deleted_at IS NULL
+			ON CONFLICT DO NOTHING
+		`, adminRoleName)
+		if err != nil {
+			return fmt.Errorf("insert %s: %w", rolePermissionTableName, err)
+		}
+		count = tag.RowsAffected()
+
+		for roleName, permissions := range RoleBaselinePermissions {
+			tag, err := tx.Exec(ctx, `
+				INSERT INTO role_permission (role_id, permission_id)
+				SELECT r.id, p.id
+				FROM roles r
+				CROSS JOIN permissions p
+				WHERE r.name = $1 AND p.name = ANY($2) AND p.deleted_at IS NULL
+				ON CONFLICT DO NOTHING
+			`, roleName, permissions)
+			if err != nil {
+				return fmt.Errorf("insert %s (%s): %w", rolePermissionTableName, roleName, err)
+			}
+			count += tag.RowsAffected()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("seeder completado exitosamente", "permisos_asignados", count)
+	return nil
+}
@@ -195,19 +195,16 @@ func registerSeeders(service *SeederService, dbPool interface{}, configPath stri
 		return RoleUserSeeder(pool)
 	})
 
-	// Assign menus to users based on their role templates
-	// User 1 has role "Admin", so will get all 15 menus
-	// The seeder automatically:
-	// 1. Queries the user's role from role_user table
-	// 2. Looks up the menu template for that role
-	// 3. Inserts all menu permissions for the user
-	service.AddSeeder("menu_user", func() error {
-		return MenuUserSeeder(pool)
+	// Grant every permission to the Admin role and the baseline to the others (role_permission is emptied
+	// by the CASCADE when the roles seeder truncates roles)
+	service.AddSeeder("role_permission", func() error {
+		return RolePermissionSeeder(pool)
 	})
 
-	// Example: Seed menus for multiple users at once
-	// Uncomment this if you created multiple users above
-	// service.AddSeeder("menu_user_multiple", func() error {
-	//     return MenuUserSeederForMultipleUsers(pool, []uint{1, 2, 3, 4})
-	// })
+	// Assign menus to roles based on MenuTemplates
+	// User 1 has role "Admin", so will see all 15 menus through it.
+	// menu_user only holds per-user overrides (grants/denials) and starts empty.
+	service.AddSeeder("menu_role", func() error {
+		return MenuRoleSeeder(pool)
+	})
 }
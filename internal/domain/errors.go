@@ -7,6 +7,15 @@ var (
 	ErrInvalidArgument = errors.New("argumento inválido")
 	ErrInternal        = errors.New("ha ocurrido un error interno")
 	ErrAuthentication  = errors.New("credenciales inválidas")
+	// ErrEmailNotVerified se devuelve en el login (con la contraseña correcta) si la cuenta
+	// debe verificar su email antes de entrar.
+	ErrEmailNotVerified = errors.New("el email de la cuenta no fue verificado")
+	// ErrAccountLocked se devuelve en el login tras demasiados intentos fallidos (por email
+	// o por IP). No revela si la cuenta existe ni si la contraseña era correcta.
+	ErrAccountLocked = errors.New("demasiados intentos fallidos; intentá de nuevo más tarde")
+	// ErrForbidden se devuelve cuando el usuario está autenticado pero la acción excede lo
+	// que puede hacer (ej: suplantar a alguien con permisos que no tiene).
+	ErrForbidden = errors.New("no tiene permiso para realizar esta acción")
 
 	// ErrCritical se usa para errores que deben detener inmediatamente la ejecución de la cadena.
 	// Por ejemplo, una falla al procesar una transacción financiera.
@@ -19,3 +28,22 @@ var (
 
 	// ... y otros errores de negocio que necesites
 )
+
+// ValidationError agrupa errores de validación por campo.
+// Envuelve a ErrInvalidArgument, por lo que errors.Is(err, ErrInvalidArgument) sigue funcionando.
+type ValidationError struct {
+	Fields map[string][]string
+}
+
+// NewValidationError crea un ValidationError a partir de los mensajes por campo.
+func NewValidationError(fields map[string][]string) *ValidationError {
+	return &ValidationError{Fields: fields}
+}
+
+func (e *ValidationError) Error() string {
+	return ErrInvalidArgument.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidArgument
+}
@@ -15,11 +15,13 @@ type AppConfig struct {
 	App                 App                 `mapstructure:"app"`
 	Server              Server              `mapstructure:"server"`
 	JWTConfig           JWTConfig           `mapstructure:"jwt"`
+	Auth                AuthConfig          `mapstructure:"auth"`
 	MultiDatabaseConfig MultiDatabaseConfig `mapstructure:"database"`
 	Redis               Redis               `mapstructure:"redis"`
 	EmailConfig         EmailConfig         `mapstructure:"email_config"`
 	ApiBackoffice       ApiConfig           `mapstructure:"apis.backoffice"`
 	ApiDiscord          ApiConfig           `mapstructure:"apis.discord"`
+	Pagination          Pagination          `mapstructure:"pagination"`
 }
 
 type MultiDatabaseConfig struct {
@@ -74,6 +76,39 @@ type JWTConfig struct {
 	JwtRefreshSecret    string        `mapstructure:"jwt_refresh_secret"`
 	JwtAccessTtlMinutes time.Duration `mapstructure:"jwt_access_ttl_minutes"`
 	JwtRefreshTtlDays   time.Duration `mapstructure:"jwt_refresh_ttl_days"`
+	// JwtAlgorithm es HS256 (por defecto, usa los secretos), RS256 o EdDSA (usan JwtKeys).
+	JwtAlgorithm string `mapstructure:"jwt_algorithm"`
+	// JwtKeys lista las claves PEM como "kid=ruta[@fecha RFC3339]" separadas por coma.
+	JwtKeys string `mapstructure:"jwt_keys"`
+}
+
+// AuthConfig configura la recuperación de contraseña, la verificación de email, el segundo
+// factor, la protección del login contra fuerza bruta y la suplantación de usuarios.
+type AuthConfig struct {
+	// RequireEmailVerification rechaza el login de las cuentas sin email verificado.
+	RequireEmailVerification bool `mapstructure:"require_email_verification"`
+	// FrontendUrl es la base de los enlaces enviados por email (ej: https://app.ejemplo.com).
+	FrontendUrl               string `mapstructure:"frontend_url"`
+	PasswordResetTtlMinutes   int    `mapstructure:"password_reset_ttl_minutes"`
+	EmailVerificationTtlHours int    `mapstructure:"email_verification_ttl_hours"`
+	// TwoFactorIssuer es el nombre que muestran las apps TOTP (por defecto app.app_name).
+	TwoFactorIssuer string `mapstructure:"two_factor_issuer"`
+	// TwoFactorKey cifra los secretos TOTP en la base. Sin clave no se puede activar 2FA.
+	TwoFactorKey string `mapstructure:"two_factor_key"`
+	// TwoFactorRequiredRoles son los nombres de rol (separados por coma) que exigen 2FA.
+	TwoFactorRequiredRoles string `mapstructure:"two_factor_required_roles"`
+	// LoginMaxAttempts son los intentos fallidos por email que bloquean la cuenta.
+	LoginMaxAttempts int `mapstructure:"login_max_attempts"`
+	// LoginIpMaxAttempts son los intentos fallidos desde una IP (con cualquier email) que la bloquean.
+	LoginIpMaxAttempts        int `mapstructure:"login_ip_max_attempts"`
+	LoginAttemptWindowMinutes int `mapstructure:"login_attempt_window_minutes"`
+	LoginLockoutMinutes       int `mapstructure:"login_lockout_minutes"`
+	// ImpersonationTtlMinutes es la vida del access token de suplantación (sin refresh token).
+	ImpersonationTtlMinutes int `mapstructure:"impersonation_ttl_minutes"`
+}
+
+type Pagination struct {
+	CursorSecret string `mapstructure:"cursor_secret"`
 }
 
 type Redis struct {
@@ -0,0 +1,21 @@
+package dtos
+
+// MenuTreeItem es un ítem del árbol de menús en el formato JSON anidado de
+// seeders/files/menus.json. Lo usan el seeder, la exportación y la importación.
+// La jerarquía sale del arreglo children. El ID es opcional: la exportación lo incluye
+// para que al importar un ítem movido de padre conserve su ID y sus asignaciones.
+type MenuTreeItem struct {
+	ID         *uint          `json:"id,omitempty"`
+	ItemType   string         `json:"item_type"`
+	ItemName   string         `json:"item_name"`
+	ToPath     *string        `json:"to_path,omitempty"`
+	Icon       *string        `json:"icon,omitempty"`
+	OrderIndex int            `json:"order_index"`
+	IsActive   *bool          `json:"is_active,omitempty"` // Sin valor = activo
+	Children   []MenuTreeItem `json:"children,omitempty"`
+}
+
+// Active indica si el ítem está activo (por defecto, sí).
+func (m MenuTreeItem) Active() bool {
+	return m.IsActive == nil || *m.IsActive
+}
@@ -1,5 +1,10 @@
 package dtos
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
 // type PaginationRequest struct {
 // 	SortBy          []string `json:"sortBy"`
 // 	SortDesc        []bool   `json:"sortDesc"`
@@ -18,6 +23,64 @@ type PaginationRequest struct {
 	RowsPerPage     int      `json:"rowsPerPage" mapstructure:"rowsPerPage"`
 	Page            int      `json:"page" mapstructure:"page"`
 	OptimizeWithKey string   `json:"optimize_with_key,omitempty" mapstructure:"optimize_with_key,omitempty"`
+
+	// Filters es el formato con operadores explícitos. Se combina (AND) con FilterBy/FilterValues.
+	Filters *FilterGroup `json:"filters,omitempty" mapstructure:"filters,omitempty"`
+
+	// Mode "cursor" activa la paginación por keyset: se ignora Page, no se ejecuta el COUNT
+	// y la respuesta devuelve next_cursor/prev_cursor. Enviar un Cursor implica el modo cursor.
+	Mode   string `json:"mode,omitempty" mapstructure:"mode,omitempty"`
+	Cursor string `json:"cursor,omitempty" mapstructure:"cursor,omitempty"`
+
+	// Aggregates se calculan sobre la consulta filtrada (sin paginar) y se devuelven en Extras.
+	Aggregates []Aggregate `json:"aggregates,omitempty" mapstructure:"aggregates,omitempty"`
+
+	// Fields limita las columnas devueltas (sparse fieldsets); la clave primaria siempre se incluye.
+	// Include precarga relaciones declaradas en el modelo con el tag `include` (ej: "roles").
+	Fields  []string `json:"fields,omitempty" mapstructure:"fields,omitempty"`
+	Include []string `json:"include,omitempty" mapstructure:"include,omitempty"`
+
+	// Search busca el texto en las columnas declaradas con el tag `search` del modelo.
+	// Sin SortBy, los resultados se ordenan por relevancia (Postgres). Highlight devuelve en
+	// Extras["highlights"] los fragmentos con coincidencias marcadas con <mark>, por ID.
+	Search    string `json:"search,omitempty" mapstructure:"search,omitempty"`
+	Highlight bool   `json:"highlight,omitempty" mapstructure:"highlight,omitempty"`
+
+	// ViewID aplica una vista guardada (saved_views) como base; los filtros enviados se suman
+	// a los de la vista y el resto de los parámetros enviados la reemplazan.
+	ViewID uint64 `json:"view_id,omitempty" mapstructure:"view_id,omitempty"`
+}
+
+// Aggregate pide un valor calculado sobre una columna de la lista blanca del modelo.
+// Op: count, count_distinct, sum, avg, min, max, group_count.
+// As es la clave en Extras (por defecto "<op>_<field>", o "count" para count sin campo).
+// Limit solo aplica a group_count: cantidad máxima de grupos devueltos (los más numerosos).
+//
+//	{"op": "sum", "field": "amount", "as": "total"}
+//	{"op": "group_count", "field": "enabled", "limit": 10}
+type Aggregate struct {
+	Op    string `json:"op" mapstructure:"op"`
+	Field string `json:"field,omitempty" mapstructure:"field,omitempty"`
+	As    string `json:"as,omitempty" mapstructure:"as,omitempty"`
+	Limit int    `json:"limit,omitempty" mapstructure:"limit,omitempty"`
+}
+
+// FilterGroup combina condiciones y subgrupos con "and" (por defecto) u "or".
+//
+//	{"logic": "or", "conditions": [{"field": "name", "op": "contains", "value": "nación"}],
+//	 "groups": [{"conditions": [{"field": "enabled", "op": "eq", "value": true}]}]}
+type FilterGroup struct {
+	Logic      string            `json:"logic,omitempty" mapstructure:"logic,omitempty"`
+	Conditions []FilterCondition `json:"conditions,omitempty" mapstructure:"conditions,omitempty"`
+	Groups     []FilterGroup     `json:"groups,omitempty" mapstructure:"groups,omitempty"`
+}
+
+// FilterCondition es una condición sobre una columna de la lista blanca del modelo.
+// Op: eq, ne, gt, gte, lt, lte, between, in, not_in, is_null, not_null, starts_with, contains.
+type FilterCondition struct {
+	Field string `json:"field" mapstructure:"field"`
+	Op    string `json:"op" mapstructure:"op"`
+	Value any    `json:"value,omitempty" mapstructure:"value,omitempty"`
 }
 
 type PaginationResponse[T any] struct {
@@ -27,4 +90,116 @@ type PaginationResponse[T any] struct {
 	Page        int            `json:"page" mapstructure:"page"`
 	RowsPerPage int            `json:"rowsPerPage" mapstructure:"rowsPerPage"`
 	Extras      map[string]any `json:"extras" mapstructure:"extras"`
+
+	// Solo en modo cursor. Vacíos cuando no hay más páginas en esa dirección.
+	NextCursor string `json:"next_cursor,omitempty" mapstructure:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty" mapstructure:"prev_cursor,omitempty"`
+
+	// Fields son las claves JSON que se devuelven de cada fila (vacío = todas). No se serializa.
+	Fields []string `json:"-" mapstructure:"-"`
+}
+
+// MarshalJSON serializa la respuesta aplicando Fields a cada fila de Data.
+func (r PaginationResponse[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Data        Sparse         `json:"data"`
+		TotalRows   int64          `json:"totalRows"`
+		TotalPages  int            `json:"totalPages"`
+		Page        int            `json:"page"`
+		RowsPerPage int            `json:"rowsPerPage"`
+		Extras      map[string]any `json:"extras"`
+		NextCursor  string         `json:"next_cursor,omitempty"`
+		PrevCursor  string         `json:"prev_cursor,omitempty"`
+	}{
+		Data:        Sparse{Value: r.Data, Fields: r.Fields},
+		TotalRows:   r.TotalRows,
+		TotalPages:  r.TotalPages,
+		Page:        r.Page,
+		RowsPerPage: r.RowsPerPage,
+		Extras:      r.Extras,
+		NextCursor:  r.NextCursor,
+		PrevCursor:  r.PrevCursor,
+	})
+}
+
+// Sparse serializa Value (un objeto o una lista de objetos) conservando solo las claves
+// JSON de Fields, en ese orden. Con Fields vacío se serializa completo.
+type Sparse struct {
+	Value  any
+	Fields []string
+}
+
+func (s Sparse) MarshalJSON() ([]byte, error) {
+	raw, err := json.Marshal(s.Value)
+	if err != nil || len(s.Fields) == 0 {
+		return raw, err
+	}
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return s.project(trimmed)
+	}
+
+	var rows []json.RawMessage
+	if err := json.Unmarshal(trimmed, &rows); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		projected, err := s.project(row)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(projected)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// project conserva las claves de Fields de un objeto JSON. Cualquier otro valor se devuelve igual.
+func (s Sparse) project(raw []byte) ([]byte, error) {
+	if len(raw) == 0 || raw[0] != '{' {
+		return raw, nil
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for _, field := range s.Fields {
+		value, ok := object[field]
+		if !ok {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		key, _ := json.Marshal(field)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ExportRequest acepta los mismos filtros que PaginationRequest (se ignoran Page, SortBy y el modo cursor:
+// la exportación recorre la tabla por clave primaria). Columns define qué columnas exportar y con qué títulos;
+// si se omite, se exportan todas las columnas visibles del modelo.
+type ExportRequest struct {
+	PaginationRequest
+	Format  string         `json:"format" mapstructure:"format"` // csv (por defecto) | xlsx
+	Columns []ExportColumn `json:"columns,omitempty" mapstructure:"columns,omitempty"`
+}
+
+// ExportColumn es una columna del archivo exportado.
+type ExportColumn struct {
+	Field string `json:"field" mapstructure:"field"`
+	Label string `json:"label,omitempty" mapstructure:"label,omitempty"`
 }
@@ -0,0 +1,22 @@
+package requests
+
+// ForgotPasswordRequest pide el enlace para restablecer la contraseña.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest cambia la contraseña con el token recibido por email.
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+// EmailVerificationRequest pide (o vuelve a pedir) el enlace para verificar el email.
+type EmailVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// VerifyEmailRequest confirma el email con el token recibido.
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,12 @@
+package requests
+
+// CreateAPIKeyRequest crea una API key para un cliente máquina a máquina.
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	// ClientCode es el X-Client-Code con el que el cliente presenta la clave.
+	ClientCode string `json:"client_code" validate:"required,max=64"`
+	// Scopes son nombres de permisos del usuario (ej: "banks.read"); la clave no puede usar otros.
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,required,max=100"`
+	// ExpiresInDays es la vigencia de la clave; sin valor no vence.
+	ExpiresInDays *int `json:"expires_in_days" validate:"omitempty,min=1,max=3650"`
+}
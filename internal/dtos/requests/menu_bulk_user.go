@@ -1,6 +1,7 @@
 package requests
 
+// BulkAssignMenuUsersRequest otorga, deniega o quita excepciones de menús a múltiples usuarios.
 type BulkAssignMenuUsersRequest struct {
-	MenuIDs []uint64 `json:"menu_ids"`
-	UserIDs []uint64 `json:"user_ids"`
+	MenuIDs []uint64 `json:"menu_ids" validate:"required,min=1"`
+	UserIDs []uint64 `json:"user_ids" validate:"required,min=1"`
 }
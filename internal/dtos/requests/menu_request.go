@@ -2,9 +2,32 @@ package requests
 
 // CreateMenuRequest define los campos necesarios para crear un menú.
 type CreateMenuRequest struct {
-	ItemType string  `json:"item_type" validate:"required"` // Ej: "link", "submenu"
-	ItemName string  `json:"item_name" validate:"required"` // Nombre visible
-	ToPath   *string `json:"to,omitempty"`                  // Ruta o enlace opcional
-	Icon     *string `json:"icon,omitempty"`                // Icono opcional
-	ParentID *uint   `json:"parent_id,omitempty"`           // ID del padre (si es submenu)
+	ItemType   string  `json:"item_type" validate:"required,oneof=link separator group line"` // link, separator, group o line
+	ItemName   string  `json:"item_name" validate:"required,max=100"`                         // Nombre visible
+	ToPath     *string `json:"to,omitempty" validate:"omitempty,max=255"`                     // Ruta o enlace opcional
+	Icon       *string `json:"icon,omitempty" validate:"omitempty,max=100"`                   // Icono opcional
+	ParentID   *uint64 `json:"parent_id,omitempty"`                                           // ID del padre (si es submenu)
+	OrderIndex *int    `json:"order_index,omitempty"`                                         // Si se omite, va al final de su nivel
+}
+
+// UpdateMenuRequest modifica los datos de un ítem. Para cambiar de padre u orden se usa /menus/move.
+type UpdateMenuRequest struct {
+	ItemType string  `json:"item_type" validate:"required,oneof=link separator group line"`
+	ItemName string  `json:"item_name" validate:"required,max=100"`
+	ToPath   *string `json:"to,omitempty" validate:"omitempty,max=255"`
+	Icon     *string `json:"icon,omitempty" validate:"omitempty,max=100"`
+	IsActive bool    `json:"is_active" validate:"boolean"`
+}
+
+// MoveMenusRequest ubica los ítems bajo parent_id (null = raíz) en el orden recibido.
+// Si un ítem trae children, su nivel también se reordena.
+type MoveMenusRequest struct {
+	ParentID *uint64               `json:"parent_id"`
+	Items    []MenuPositionRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// MenuPositionRequest es un ítem dentro de MoveMenusRequest.
+type MenuPositionRequest struct {
+	ID       uint64                `json:"id" validate:"required"`
+	Children []MenuPositionRequest `json:"children,omitempty" validate:"omitempty,dive"`
 }
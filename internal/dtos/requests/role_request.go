@@ -0,0 +1,22 @@
+package requests
+
+type CreateRoleRequest struct {
+	Name string `json:"name" validate:"required,min=3,max=100"`
+}
+
+// UpdateRoleRequest renombra el rol y lo activa o desactiva.
+type UpdateRoleRequest struct {
+	Name     string `json:"name" validate:"required,min=3,max=100"`
+	IsActive bool   `json:"is_active" validate:"boolean"`
+}
+
+// BulkAssignRoleUsersRequest asigna (o quita) múltiples roles a múltiples usuarios.
+type BulkAssignRoleUsersRequest struct {
+	RoleIDs []uint64 `json:"role_ids" validate:"required,min=1"`
+	UserIDs []uint64 `json:"user_ids" validate:"required,min=1"`
+}
+
+// SetRolePermissionsRequest reemplaza los permisos del rol; una lista vacía los quita todos.
+type SetRolePermissionsRequest struct {
+	PermissionIDs []uint64 `json:"permission_ids" validate:"required"`
+}
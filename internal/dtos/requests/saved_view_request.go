@@ -0,0 +1,13 @@
+package requests
+
+import "go-fiber-core/internal/dtos"
+
+// SavedViewRequest crea o reemplaza una vista guardada.
+// Request es el mismo cuerpo que acepta /paginated (sin page ni cursor).
+type SavedViewRequest struct {
+	Resource  string                 `json:"resource" validate:"required,oneof=banks users"`
+	Name      string                 `json:"name" validate:"required,min=3,max=100"`
+	Request   dtos.PaginationRequest `json:"request"`
+	RoleID    *uint64                `json:"role_id" validate:"omitempty,gt=0"`
+	IsDefault bool                   `json:"is_default"`
+}
@@ -0,0 +1,17 @@
+package requests
+
+// TwoFactorCodeRequest lleva un código TOTP de 6 dígitos o un código de recuperación.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+// TwoFactorChallengeRequest identifica el login pendiente del segundo paso.
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+// TwoFactorVerifyRequest completa el login con el código del segundo factor.
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
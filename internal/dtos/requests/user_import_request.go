@@ -0,0 +1,12 @@
+package requests
+
+// ImportUserRow es una fila del archivo de importación de usuarios (CSV/XLSX).
+type ImportUserRow struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Email string `json:"email" validate:"required,email,max=255"`
+	// Roles son nombres de roles existentes; el usuario ve los menús de esos roles.
+	Roles []string `json:"roles" validate:"dive,required,max=100"`
+	// Password es la contraseña inicial; sin valor se guarda una aleatoria (el usuario elige
+	// la suya con el enlace de la invitación).
+	Password string `json:"password" validate:"omitempty,min=8,max=72"`
+}
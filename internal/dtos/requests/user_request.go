@@ -15,3 +15,17 @@ type UpdateUserRequest struct {
 	// Podrías añadir más campos opcionales aquí si lo necesitas
 	// Password string `json:"password,omitempty" validate:"omitempty,min=8"`
 }
+
+// UpdateMeRequest actualiza el perfil del usuario autenticado; los campos omitidos no cambian.
+// Cambiar el email exige la contraseña actual.
+type UpdateMeRequest struct {
+	Name            *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Email           *string `json:"email" validate:"omitempty,email,max=255"`
+	CurrentPassword string  `json:"current_password" validate:"omitempty,max=72"`
+}
+
+// ChangePasswordRequest cambia la contraseña del usuario autenticado.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required,max=72"`
+	Password        string `json:"password" validate:"required,min=8,max=72"`
+}
@@ -0,0 +1,23 @@
+package responses
+
+import "time"
+
+// APIKeyResponse es una API key del usuario autenticado. La clave en sí no se puede volver
+// a consultar: prefix alcanza para reconocerla.
+type APIKeyResponse struct {
+	ID         uint64     `json:"id"`
+	Name       string     `json:"name"`
+	ClientCode string     `json:"client_code"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreatedResponse incluye la clave completa; se muestra una sola vez.
+type APIKeyCreatedResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,13 @@
+package responses
+
+import "time"
+
+// ImpersonationResponse es el access token para actuar como otro usuario. No trae refresh
+// token: al vencer hay que volver a suplantar.
+type ImpersonationResponse struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	ActorID     uint64    `json:"actor_id"` // Usuario real
+	UserID      uint64    `json:"user_id"`  // Usuario suplantado
+	UserName    string    `json:"user_name"`
+}
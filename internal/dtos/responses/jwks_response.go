@@ -0,0 +1,18 @@
+package responses
+
+// JWKSResponse es el documento de /.well-known/jwks.json (RFC 7517).
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK es una clave pública de firma: RSA (n, e) u OKP Ed25519 (crv, x).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
@@ -8,4 +8,12 @@ type LoginResponse struct {
 	RoleIDs      []uint64           `json:"role_ids"`
 	Roles        []string           `json:"roles"`
 	Menu         []MenuItemResponse `json:"menu"` // menú filtrado según el user
+
+	// Login en dos pasos: si ChallengeToken no está vacío no hay tokens todavía; el
+	// cliente debe enviar el código (o configurar el 2FA si TwoFactorSetupRequired).
+	TwoFactorRequired      bool   `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"`
+	ChallengeToken         string `json:"challenge_token,omitempty"`
+	// RecoveryCodes se devuelve una sola vez, al completar la configuración obligatoria.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
@@ -7,3 +7,19 @@ type MenuItemResponse struct {
 	To       *string            `json:"to,omitempty"`
 	Children []MenuItemResponse `json:"children,omitempty"`
 }
+
+// MenuRouteAccessResponse es el diagnóstico de GET /menus/routes.
+type MenuRouteAccessResponse struct {
+	Menus        []MenuRoutesResponse `json:"menus"`
+	MissingMenus []string             `json:"missing_menus"` // to_path del mapeo sin ítem de menú
+}
+
+// MenuRoutesResponse lista los grupos y rutas de la API que habilita un ítem de menú.
+type MenuRoutesResponse struct {
+	MenuID   uint     `json:"menu_id"`
+	ItemName string   `json:"item_name"`
+	ToPath   string   `json:"to_path"`
+	IsActive bool     `json:"is_active"`
+	Groups   []string `json:"groups"`
+	Routes   []string `json:"routes,omitempty"` // "GET /api/v1/banks/:id"
+}
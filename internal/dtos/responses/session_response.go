@@ -0,0 +1,14 @@
+package responses
+
+import "time"
+
+// SessionResponse es una sesión abierta (dispositivo) del usuario autenticado.
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // Es la sesión del token con el que se consulta
+}
@@ -0,0 +1,20 @@
+package responses
+
+// TwoFactorStatusResponse es el estado del segundo factor del usuario autenticado.
+type TwoFactorStatusResponse struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"` // Uno de sus roles lo exige
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrollResponse tiene el secreto a cargar en la app de autenticación. El
+// otpauth_uri es el contenido del código QR.
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+// RecoveryCodesResponse son los códigos de recuperación; se muestran una sola vez.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
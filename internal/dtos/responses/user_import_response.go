@@ -0,0 +1,34 @@
+package responses
+
+import "time"
+
+// Estados de una importación de usuarios.
+const (
+	UserImportValidated  = "validated"  // Dry-run: solo se validó el archivo
+	UserImportRejected   = "rejected"   // Hay filas con errores: no se creó ningún usuario
+	UserImportProcessing = "processing" // Archivo grande: se está importando en segundo plano
+	UserImportCompleted  = "completed"
+	UserImportFailed     = "failed"
+)
+
+// UserImportResult resume la importación de un archivo de usuarios. En los archivos grandes
+// se devuelve con JobID y estado "processing"; el resultado final se consulta con ese ID.
+type UserImportResult struct {
+	JobID      string               `json:"job_id,omitempty"`
+	Status     string               `json:"status"`
+	DryRun     bool                 `json:"dry_run"`
+	Total      int                  `json:"total"`   // Filas con datos
+	Valid      int                  `json:"valid"`   // Filas sin errores
+	Created    int                  `json:"created"` // Usuarios creados
+	Errors     []UserImportRowError `json:"errors"`
+	Message    string               `json:"message,omitempty"` // Motivo si el job falló
+	FinishedAt *time.Time           `json:"finished_at,omitempty"`
+}
+
+// UserImportRowError son los errores por campo de una fila (Row es el número de fila del
+// archivo, contando el encabezado como la 1).
+type UserImportRowError struct {
+	Row    int                 `json:"row"`
+	Email  string              `json:"email,omitempty"`
+	Errors map[string][]string `json:"errors"`
+}
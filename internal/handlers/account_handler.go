@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/dtos/responses"
+	accountService "go-fiber-core/internal/services/account"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// AccountHandler expone la recuperación de contraseña y la verificación de email.
+// Son rutas públicas: el usuario todavía no puede iniciar sesión.
+type AccountHandler interface {
+	ForgotPassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+	RequestEmailVerification(c *fiber.Ctx) error
+	VerifyEmail(c *fiber.Ctx) error
+}
+
+type accountHandler struct {
+	accounts accountService.AccountService
+}
+
+func NewAccountHandler(accounts accountService.AccountService) AccountHandler {
+	return &accountHandler{accounts: accounts}
+}
+
+func (h *accountHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req requests.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+	if err := h.accounts.ForgotPassword(c.UserContext(), req.Email); err != nil {
+		return err
+	}
+	// Misma respuesta exista o no la cuenta.
+	return responses.Success(c, "Si el email está registrado, te enviamos un enlace para restablecer la contraseña", nil)
+}
+
+func (h *accountHandler) ResetPassword(c *fiber.Ctx) error {
+	var req requests.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+	if err := h.accounts.ResetPassword(c.UserContext(), req.Token, req.Password); err != nil {
+		return err
+	}
+	return responses.Success(c, "Contraseña restablecida exitosamente. Iniciá sesión con la nueva contraseña", nil)
+}
+
+func (h *accountHandler) RequestEmailVerification(c *fiber.Ctx) error {
+	var req requests.EmailVerificationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+	if err := h.accounts.RequestEmailVerification(c.UserContext(), req.Email); err != nil {
+		return err
+	}
+	return responses.Success(c, "Si el email está registrado y sin verificar, te enviamos un enlace para confirmarlo", nil)
+}
+
+func (h *accountHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req requests.VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+	if err := h.accounts.VerifyEmail(c.UserContext(), req.Token); err != nil {
+		return err
+	}
+	return responses.Success(c, "Email verificado exitosamente", nil)
+}
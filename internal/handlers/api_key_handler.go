@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/dtos/responses"
+	authService "go-fiber-core/internal/services/auth"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// APIKeyHandler administra las API keys del usuario autenticado.
+type APIKeyHandler interface {
+	List(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Revoke(c *fiber.Ctx) error
+}
+
+type apiKeyHandler struct {
+	apiKeys authService.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeys authService.APIKeyService) APIKeyHandler {
+	return &apiKeyHandler{apiKeys: apiKeys}
+}
+
+func (h *apiKeyHandler) List(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	keys, err := h.apiKeys.List(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "API keys obtenidas exitosamente", keys)
+}
+
+// Create devuelve la clave completa; después solo se puede ver su prefijo.
+func (h *apiKeyHandler) Create(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+	var req requests.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	key, err := h.apiKeys.Create(ctx, userID, req)
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "API key creada. Guardala ahora: no se vuelve a mostrar", key)
+}
+
+func (h *apiKeyHandler) Revoke(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+	id, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.apiKeys.Revoke(ctx, userID, uint64(id)); err != nil {
+		return err
+	}
+	return responses.Success(c, "API key revocada", nil)
+}
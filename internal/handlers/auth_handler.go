@@ -1,11 +1,11 @@
 package handlers
 
 import (
+	"go-fiber-core/internal/contextkeys"
 	"go-fiber-core/internal/domain"
 	"go-fiber-core/internal/dtos/requests"
 	"go-fiber-core/internal/dtos/responses"
 	authService "go-fiber-core/internal/services/auth"
-	"strconv"
 
 	fiber "github.com/gofiber/fiber/v2"
 )
@@ -14,15 +14,42 @@ type AuthHandler interface {
 	Login(c *fiber.Ctx) error
 	Refresh(c *fiber.Ctx) error
 	Logout(c *fiber.Ctx) error
+	ListSessions(c *fiber.Ctx) error
+	RevokeSession(c *fiber.Ctx) error
+	RevokeOtherSessions(c *fiber.Ctx) error
+	JWKS(c *fiber.Ctx) error
+
+	// Segundo paso del login (público, con el challenge_token)
+	SetupTwoFactor(c *fiber.Ctx) error
+	VerifyTwoFactor(c *fiber.Ctx) error
+	// Administración del 2FA del usuario autenticado
+	TwoFactorStatus(c *fiber.Ctx) error
+	EnrollTwoFactor(c *fiber.Ctx) error
+	ConfirmTwoFactor(c *fiber.Ctx) error
+	RegenerateRecoveryCodes(c *fiber.Ctx) error
+	DisableTwoFactor(c *fiber.Ctx) error
+	// Suplantación de usuarios (soporte)
+	Impersonate(c *fiber.Ctx) error
 }
 
 type authHandler struct {
-	authService authService.AuthService
+	authService   authService.AuthService
+	twoFactor     authService.TwoFactorService
+	impersonation authService.ImpersonationService
+	keys          authService.KeyRing
 }
 
-func NewAuthHandler(authService authService.AuthService) AuthHandler {
+func NewAuthHandler(
+	authService authService.AuthService,
+	twoFactor authService.TwoFactorService,
+	impersonation authService.ImpersonationService,
+	keys authService.KeyRing,
+) AuthHandler {
 	return &authHandler{
-		authService: authService,
+		authService:   authService,
+		twoFactor:     twoFactor,
+		impersonation: impersonation,
+		keys:          keys,
 	}
 }
 
@@ -39,11 +66,29 @@ func (h *authHandler) Login(c *fiber.Ctx) error {
 
 	// fmt.Printf("Intentando login para usuario: %s\n", req.Email)
 
-	resp, err := h.authService.Login(c.Context(), req)
+	resp, err := h.authService.Login(c.Context(), req, clientInfo(c))
 	if err != nil {
 		return err
 	}
 
+	if resp.ChallengeToken != "" {
+		message := "Ingresá el código de tu app de autenticación"
+		if resp.TwoFactorSetupRequired {
+			message = "Tu rol exige configurar el segundo factor"
+		}
+		return responses.Success(c, message, fiber.Map{
+			"two_factor_required":       resp.TwoFactorRequired,
+			"two_factor_setup_required": resp.TwoFactorSetupRequired,
+			"challenge_token":           resp.ChallengeToken,
+			"user_name":                 resp.UserName,
+		})
+	}
+
+	return responses.Success(c, "Inicio de sesión exitoso", loginData(resp))
+}
+
+// loginData arma la respuesta de una sesión abierta.
+func loginData(resp *responses.LoginResponse) fiber.Map {
 	data := fiber.Map{
 		"access_token":  resp.Token,
 		"refresh_token": resp.RefreshToken,
@@ -52,8 +97,10 @@ func (h *authHandler) Login(c *fiber.Ctx) error {
 		"roles":         resp.Roles,
 		"menu":          resp.Menu,
 	}
-
-	return responses.Success(c, "Inicio de sesión exitoso", data)
+	if len(resp.RecoveryCodes) > 0 {
+		data["recovery_codes"] = resp.RecoveryCodes
+	}
+	return data
 }
 
 func (h *authHandler) Refresh(c *fiber.Ctx) error {
@@ -66,7 +113,7 @@ func (h *authHandler) Refresh(c *fiber.Ctx) error {
 		return domain.ErrInvalidArgument
 	}
 
-	newAccessToken, newRefreshToken, err := h.authService.Refresh(c.Context(), req.RefreshToken)
+	newAccessToken, newRefreshToken, err := h.authService.Refresh(c.Context(), req.RefreshToken, clientInfo(c))
 	if err != nil {
 		return err
 	}
@@ -79,21 +126,230 @@ func (h *authHandler) Refresh(c *fiber.Ctx) error {
 }
 
 func (h *authHandler) Logout(c *fiber.Ctx) error {
-	userIDStr, ok := c.Locals("userID").(string)
-	if !ok || userIDStr == "" {
-		// Este es un error de autorización que el middleware de errores
-		// puede traducir a un 401 si lo configuramos.
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+	session, ok := contextkeys.GetSession(ctx)
+	if !ok {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", domain.ErrAuthentication)
+	}
+
+	if err := h.authService.Logout(ctx, userID, session); err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Cierre de sesión exitoso", nil)
+}
+
+func (h *authHandler) ListSessions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+	session, _ := contextkeys.GetSession(ctx)
+
+	sessions, err := h.authService.ListSessions(ctx, userID, session.ID)
+	if err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Sesiones obtenidas exitosamente", sessions)
+}
+
+func (h *authHandler) RevokeSession(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+	sessionID := c.Params("id")
+	if sessionID == "" {
 		return domain.ErrInvalidArgument
 	}
 
-	userID, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err := h.authService.RevokeSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Sesión cerrada exitosamente", nil)
+}
+
+func (h *authHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
 	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+	session, ok := contextkeys.GetSession(ctx)
+	if !ok {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", domain.ErrAuthentication)
+	}
+
+	if err := h.authService.RevokeOtherSessions(ctx, userID, session.ID); err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Se cerraron las demás sesiones", nil)
+}
+
+func (h *authHandler) SetupTwoFactor(c *fiber.Ctx) error {
+	var req requests.TwoFactorChallengeRequest
+	if err := c.BodyParser(&req); err != nil {
 		return domain.ErrInvalidArgument
 	}
 
-	if err := h.authService.Logout(c.Context(), userID); err != nil {
+	enrollment, err := h.authService.SetupTwoFactor(c.Context(), req.ChallengeToken)
+	if err != nil {
 		return err
 	}
 
-	return responses.Success(c, "Cierre de sesión exitoso", nil)
+	return responses.Success(c, "Cargá el secreto en tu app de autenticación y enviá el primer código", enrollment)
+}
+
+func (h *authHandler) VerifyTwoFactor(c *fiber.Ctx) error {
+	var req requests.TwoFactorVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	resp, err := h.authService.VerifyTwoFactor(c.Context(), req.ChallengeToken, req.Code, clientInfo(c))
+	if err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Inicio de sesión exitoso", loginData(resp))
+}
+
+func (h *authHandler) TwoFactorStatus(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	status, err := h.twoFactor.Status(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Estado del segundo factor obtenido exitosamente", status)
+}
+
+func (h *authHandler) EnrollTwoFactor(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	enrollment, err := h.twoFactor.Enroll(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Cargá el secreto en tu app de autenticación y confirmá con el primer código", enrollment)
+}
+
+func (h *authHandler) ConfirmTwoFactor(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+	var req requests.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	codes, err := h.twoFactor.Confirm(ctx, userID, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Segundo factor activado. Guardá los códigos de recuperación", responses.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *authHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+	var req requests.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	codes, err := h.twoFactor.RegenerateRecoveryCodes(ctx, userID, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Códigos de recuperación generados. Los anteriores ya no sirven", responses.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *authHandler) DisableTwoFactor(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+	var req requests.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	if err := h.twoFactor.Disable(ctx, userID, req.Code); err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Segundo factor desactivado", nil)
+}
+
+// Impersonate emite un access token para actuar como el usuario :id.
+func (h *authHandler) Impersonate(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	actorID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+	subjectID, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.impersonation.Impersonate(ctx, actorID, uint64(subjectID))
+	if err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Suplantación iniciada. Las acciones quedan registradas a tu nombre", resp)
+}
+
+// JWKS publica las claves públicas de firma para que otros servicios verifiquen nuestros
+// tokens. Va sin el envoltorio de responses.Success: es el formato estándar de JWKS.
+func (h *authHandler) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.keys.JWKS())
+}
+
+// clientInfo toma del request los datos del dispositivo que se guardan con la sesión.
+func clientInfo(c *fiber.Ctx) authService.ClientInfo {
+	return authService.ClientInfo{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	}
 }
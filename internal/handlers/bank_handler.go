@@ -7,6 +7,9 @@ import (
 	"go-fiber-core/internal/dtos/responses"
 	"go-fiber-core/internal/models"
 	bankService "go-fiber-core/internal/services/bank"
+	"go-fiber-core/internal/services/export"
+	"go-fiber-core/internal/services/pagination"
+	savedViewService "go-fiber-core/internal/services/savedview"
 	"log" // <-- AÑADIDO: Para logging de ejemplo
 
 	fiber "github.com/gofiber/fiber/v2"
@@ -21,6 +24,8 @@ type BankHandler interface {
 	SoftDelete(c *fiber.Ctx) error
 	HardDelete(c *fiber.Ctx) error
 	GetAllPaginated(c *fiber.Ctx) error
+	GetAllPaginatedQuery(c *fiber.Ctx) error
+	Export(c *fiber.Ctx) error
 }
 
 // Handler concreto
@@ -28,6 +33,7 @@ type bankHandler struct {
 	writer    bankService.BankWriterService
 	reader    bankService.BankReaderService
 	paginator bankService.BankPaginationService
+	views     savedViewService.SavedViewService
 }
 
 // Constructor
@@ -35,11 +41,13 @@ func NewBankHandler(
 	writer bankService.BankWriterService,
 	reader bankService.BankReaderService,
 	paginator bankService.BankPaginationService,
+	views savedViewService.SavedViewService,
 ) BankHandler {
 	return &bankHandler{
 		writer:    writer,
 		reader:    reader,
 		paginator: paginator,
+		views:     views,
 	}
 }
 
@@ -107,7 +115,7 @@ func (h *bankHandler) GetByID(c *fiber.Ctx) error {
 		return err
 	}
 
-	bank, err := h.reader.GetByID(ctx, uint(id))
+	bank, err := h.reader.GetByIDSparse(ctx, uint(id), queryList(c, "fields"), queryList(c, "include"))
 	if err != nil {
 		return err
 	}
@@ -211,9 +219,70 @@ func (h *bankHandler) GetAllPaginated(c *fiber.Ctx) error {
 		return domain.ErrInvalidArgument
 	}
 
+	// Vista guardada (view_id) + filtros enviados
+	req, err = h.views.Apply(ctx, userID, savedViewService.ResourceBanks, req)
+	if err != nil {
+		return err
+	}
+
 	response, err := h.paginator.GetAllPaginated(ctx, req)
 	if err != nil {
 		return err
 	}
 	return responses.Success(c, "Bancos paginados obtenidos exitosamente", response)
 }
+
+// GetAllPaginatedQuery es la variante GET de GetAllPaginated: lee la paginación de la query string
+// (ej: ?sort=-name&filter[name][contains]=galicia&page=2&per_page=50) y agrega el header Link.
+func (h *bankHandler) GetAllPaginatedQuery(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	values, err := queryValues(c)
+	if err != nil {
+		return err
+	}
+	req, err := pagination.ParseQuery[models.Bank](values)
+	if err != nil {
+		return err
+	}
+	if req, err = h.views.Apply(ctx, userID, savedViewService.ResourceBanks, req); err != nil {
+		return err
+	}
+
+	response, err := h.paginator.GetAllPaginated(ctx, req)
+	if err != nil {
+		return err
+	}
+	setPaginationLinks(c, values, response)
+	return responses.Success(c, "Bancos paginados obtenidos exitosamente", response)
+}
+
+// Export descarga los bancos filtrados como CSV o XLSX.
+func (h *bankHandler) Export(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	var req dtos.ExportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+	if req.PaginationRequest, err = h.views.Apply(ctx, userID, savedViewService.ResourceBanks, req.PaginationRequest); err != nil {
+		return err
+	}
+
+	stream, err := h.paginator.Export(ctx, req)
+	if err != nil {
+		return err
+	}
+	log.Printf("Usuario %d exporta %s", userID, export.FileName("bancos", req.Format))
+	return sendExport(c, "bancos", req.Format, stream)
+}
@@ -1,10 +1,18 @@
 package handlers
 
 import (
+	"bufio"
 	"context"
+	"fmt"
 	"go-fiber-core/internal/contextkeys"
 	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos"
+	"go-fiber-core/internal/services/export"
+	"go-fiber-core/internal/services/pagination"
+	"log"
+	"net/url"
 	"strconv"
+	"strings"
 
 	fiber "github.com/gofiber/fiber/v2"
 )
@@ -50,3 +58,74 @@ func getUintID(c *fiber.Ctx) (uint, error) {
 	}
 	return uint(id), nil
 }
+
+// queryList lee un parámetro de query separado por comas (ej: ?fields=id,name).
+func queryList(c *fiber.Ctx, key string) []string {
+	value := c.Query(key)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// queryValues devuelve la query string completa, incluyendo claves repetidas
+// y con corchetes (ej: filter[name][contains]).
+func queryValues(c *fiber.Ctx) (url.Values, error) {
+	values, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return nil, domain.ErrInvalidArgument
+	}
+	return values, nil
+}
+
+// setPaginationLinks agrega el header Link (RFC 8288) con las páginas vecinas,
+// conservando el resto de la query string. En modo cursor usa next_cursor/prev_cursor.
+func setPaginationLinks[T any](c *fiber.Ctx, values url.Values, resp *dtos.PaginationResponse[T]) {
+	link := func(key, value, rel string) string {
+		query := url.Values{}
+		for k, v := range values {
+			query[k] = v
+		}
+		query.Set(key, value)
+		return fmt.Sprintf(`<%s%s?%s>; rel="%s"`, c.BaseURL(), c.Path(), query.Encode(), rel)
+	}
+
+	var links []string
+	if resp.NextCursor != "" {
+		links = append(links, link(pagination.QueryCursor, resp.NextCursor, "next"))
+	}
+	if resp.PrevCursor != "" {
+		links = append(links, link(pagination.QueryCursor, resp.PrevCursor, "prev"))
+	}
+	if resp.TotalPages > 0 && resp.RowsPerPage > 0 {
+		links = append(links, link(pagination.QueryPage, "1", "first"))
+		if resp.Page > 1 {
+			links = append(links, link(pagination.QueryPage, strconv.Itoa(resp.Page-1), "prev"))
+		}
+		if resp.Page < resp.TotalPages {
+			links = append(links, link(pagination.QueryPage, strconv.Itoa(resp.Page+1), "next"))
+		}
+		links = append(links, link(pagination.QueryPage, strconv.Itoa(resp.TotalPages), "last"))
+	}
+	if len(links) > 0 {
+		c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	}
+}
+
+// --- HELPERS DE EXPORTACIÓN ---
+
+// sendExport escribe el archivo en streaming. Una vez enviados los headers ya no se puede
+// cambiar el status, por eso los errores durante la escritura solo se registran.
+func sendExport(c *fiber.Ctx, fileName, format string, stream export.StreamFunc) error {
+	c.Set(fiber.HeaderContentType, export.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, export.FileName(fileName, format)))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := stream(w); err != nil {
+			log.Printf("Error al exportar %s: %v", fileName, err)
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("Error al enviar la exportación %s: %v", fileName, err)
+		}
+	})
+	return nil
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"encoding/json"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos"
 	"go-fiber-core/internal/dtos/requests"
 	"go-fiber-core/internal/dtos/responses"
+	"go-fiber-core/internal/menuaccess"
 	"log"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,8 +19,22 @@ import (
 // ─────────────────────────────────────────────
 type MenuHandler interface {
 	AddBulkUsers(c *fiber.Ctx) error
+	DenyBulkUsers(c *fiber.Ctx) error
 	BulkRemoveUsers(c *fiber.Ctx) error
 	GetMenuByUser(c *fiber.Ctx) error
+	GetRoles(c *fiber.Ctx) error
+	AddRoles(c *fiber.Ctx) error
+	RemoveRoles(c *fiber.Ctx) error
+	BulkAddRoles(c *fiber.Ctx) error
+	BulkRemoveRoles(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	SoftDelete(c *fiber.Ctx) error
+	Restore(c *fiber.Ctx) error
+	Move(c *fiber.Ctx) error
+	Export(c *fiber.Ctx) error
+	Import(c *fiber.Ctx) error
+	RouteAccess(c *fiber.Ctx) error
 }
 
 // ─────────────────────────────────────────────
@@ -66,6 +84,32 @@ func (h *menuHandler) AddBulkUsers(c *fiber.Ctx) error {
 	return responses.Success(c, "Usuarios asignados correctamente a los menús", nil)
 }
 
+// ─────────────────────────────────────────────
+// DENY USERS → DenyBulkUsers
+// ─────────────────────────────────────────────
+func (h *menuHandler) DenyBulkUsers(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	log.Printf("Usuario %d está denegando menús a usuarios", userID)
+
+	var req requests.BulkAssignMenuUsersRequest // mismo DTO
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, fiber.StatusBadRequest, "Error al parsear solicitud", err)
+	}
+
+	if err := h.writer.DenyBulkUsers(ctx, req.MenuIDs, req.UserIDs); err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Menús denegados correctamente a los usuarios", nil)
+}
+
 // ─────────────────────────────────────────────
 // REMOVE USERS → BulkRemoveUsers
 // ─────────────────────────────────────────────
@@ -113,3 +157,383 @@ func (h *menuHandler) GetMenuByUser(c *fiber.Ctx) error {
 
 	return responses.Success(c, "Menú obtenido correctamente", tree)
 }
+
+// ─────────────────────────────────────────────
+// ROLES DE UN MENÚ → GetRoles
+// ─────────────────────────────────────────────
+func (h *menuHandler) GetRoles(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	if _, err := getUserIDUint64FromCtx(ctx); err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	menuID, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	roles, err := h.reader.GetRolesByMenu(ctx, uint64(menuID))
+	if err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Roles del menú obtenidos correctamente", roles)
+}
+
+// ─────────────────────────────────────────────
+// ASSIGN ROLES → AddRoles (un menú)
+// ─────────────────────────────────────────────
+func (h *menuHandler) AddRoles(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	menuID, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	var req requests.AddRolesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, fiber.StatusBadRequest, "Error al parsear solicitud", err)
+	}
+
+	log.Printf("Usuario %d está asignando los roles %v al menú %d", userID, req.RoleIDs, menuID)
+
+	if err := h.writer.AddRoles(ctx, uint64(menuID), req.RoleIDs); err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Roles asignados correctamente al menú", nil)
+}
+
+// ─────────────────────────────────────────────
+// REMOVE ROLES → RemoveRoles (un menú)
+// ─────────────────────────────────────────────
+func (h *menuHandler) RemoveRoles(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	menuID, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	var req requests.AddRolesRequest // mismo DTO
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, fiber.StatusBadRequest, "Error al parsear solicitud", err)
+	}
+
+	log.Printf("Usuario %d está quitando los roles %v del menú %d", userID, req.RoleIDs, menuID)
+
+	if err := h.writer.RemoveRoles(ctx, uint64(menuID), req.RoleIDs); err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Roles removidos correctamente del menú", nil)
+}
+
+// ─────────────────────────────────────────────
+// ASSIGN ROLES → BulkAddRoles (varios menús)
+// ─────────────────────────────────────────────
+func (h *menuHandler) BulkAddRoles(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	log.Printf("Usuario %d está asignando roles a menús", userID)
+
+	var req requests.BulkAddRolesToMenusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, fiber.StatusBadRequest, "Error al parsear solicitud", err)
+	}
+
+	if err := h.writer.BulkAddRoles(ctx, req.MenuIDs, req.RoleIDs); err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Roles asignados correctamente a los menús", nil)
+}
+
+// ─────────────────────────────────────────────
+// REMOVE ROLES → BulkRemoveRoles (varios menús)
+// ─────────────────────────────────────────────
+func (h *menuHandler) BulkRemoveRoles(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	log.Printf("Usuario %d está removiendo roles de menús", userID)
+
+	var req requests.BulkAddRolesToMenusRequest // mismo DTO
+	if err := c.BodyParser(&req); err != nil {
+		return responses.Error(c, fiber.StatusBadRequest, "Error al parsear solicitud", err)
+	}
+
+	if err := h.writer.BulkRemoveRoles(ctx, req.MenuIDs, req.RoleIDs); err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Roles removidos correctamente de los menús", nil)
+}
+
+// ─────────────────────────────────────────────
+// CRUD DE ÍTEMS → Create
+// ─────────────────────────────────────────────
+func (h *menuHandler) Create(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	var req requests.CreateMenuRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	item, err := h.writer.Create(ctx, menuService.CreateMenuDTO{
+		ItemType:   req.ItemType,
+		ItemName:   req.ItemName,
+		ToPath:     req.ToPath,
+		Icon:       req.Icon,
+		ParentID:   req.ParentID,
+		OrderIndex: req.OrderIndex,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Usuario %d creó el menú %d (%s)", userID, item.ID, item.ItemName)
+	return responses.Success(c, "Menú creado exitosamente", item)
+}
+
+// ─────────────────────────────────────────────
+// CRUD DE ÍTEMS → Update
+// ─────────────────────────────────────────────
+func (h *menuHandler) Update(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	menuID, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	var req requests.UpdateMenuRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	item, err := h.writer.Update(ctx, uint64(menuID), menuService.UpdateMenuDTO{
+		ItemType: req.ItemType,
+		ItemName: req.ItemName,
+		ToPath:   req.ToPath,
+		Icon:     req.Icon,
+		IsActive: req.IsActive,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Usuario %d actualizó el menú %d", userID, menuID)
+	return responses.Success(c, "Menú actualizado exitosamente", item)
+}
+
+// ─────────────────────────────────────────────
+// CRUD DE ÍTEMS → SoftDelete (incluye sus hijos)
+// ─────────────────────────────────────────────
+func (h *menuHandler) SoftDelete(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	menuID, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.writer.SoftDelete(ctx, uint64(menuID)); err != nil {
+		return err
+	}
+
+	log.Printf("Usuario %d borró el menú %d y sus hijos", userID, menuID)
+	return responses.Success(c, "Menú eliminado exitosamente", nil)
+}
+
+// ─────────────────────────────────────────────
+// CRUD DE ÍTEMS → Restore
+// ─────────────────────────────────────────────
+func (h *menuHandler) Restore(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	menuID, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	item, err := h.writer.Restore(ctx, uint64(menuID))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Usuario %d restauró el menú %d", userID, menuID)
+	return responses.Success(c, "Menú restaurado exitosamente", item)
+}
+
+// ─────────────────────────────────────────────
+// MOVER / REORDENAR → Move
+// ─────────────────────────────────────────────
+func (h *menuHandler) Move(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	var req requests.MoveMenusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	if err := h.writer.Move(ctx, req.ParentID, toMenuNodes(req.Items)); err != nil {
+		return err
+	}
+
+	log.Printf("Usuario %d reordenó %d menús bajo el padre %v", userID, len(req.Items), req.ParentID)
+	return responses.Success(c, "Menús reordenados correctamente", nil)
+}
+
+func toMenuNodes(items []requests.MenuPositionRequest) []menuService.MenuNode {
+	nodes := make([]menuService.MenuNode, len(items))
+	for i, item := range items {
+		nodes[i] = menuService.MenuNode{ID: item.ID, Children: toMenuNodes(item.Children)}
+	}
+	return nodes
+}
+
+// ─────────────────────────────────────────────
+// EXPORTAR / IMPORTAR ÁRBOL (formato de menus.json)
+// ─────────────────────────────────────────────
+func (h *menuHandler) Export(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	if _, err := getUserIDUint64FromCtx(ctx); err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	tree, err := h.reader.Export(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="menus.json"`)
+	return c.Send(body)
+}
+
+// Import recibe el mismo arreglo que devuelve Export y reemplaza el árbol: actualiza los
+// ítems existentes (por ID, padre y nombre o ruta), crea los nuevos y borra los que no vinieron.
+func (h *menuHandler) Import(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	var items []dtos.MenuTreeItem
+	if err := json.Unmarshal(c.Body(), &items); err != nil {
+		return responses.Error(c, fiber.StatusBadRequest, "El archivo debe ser un arreglo JSON de menús", err.Error())
+	}
+
+	result, err := h.writer.Import(ctx, items)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Usuario %d importó menús: %d creados, %d actualizados, %d borrados", userID, result.Created, result.Updated, result.Deleted)
+	return responses.Success(c, "Menús importados correctamente", result)
+}
+
+// ─────────────────────────────────────────────
+// DIAGNÓSTICO → RouteAccess (qué rutas habilita cada menú)
+// ─────────────────────────────────────────────
+func (h *menuHandler) RouteAccess(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Validar sesión
+	if _, err := getUserIDUint64FromCtx(ctx); err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	access, err := h.reader.RouteAccess(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Las rutas concretas salen de las registradas en la app, así el diagnóstico
+	// refleja lo que realmente protege cada grupo.
+	routesByGroup := make(map[string][]string)
+	for _, route := range c.App().GetRoutes(true) {
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		for _, group := range menuaccess.Routes {
+			if group.Matches(route.Path) {
+				routesByGroup[group.Group] = append(routesByGroup[group.Group], route.Method+" "+route.Path)
+			}
+		}
+	}
+	for i, menu := range access.Menus {
+		for _, group := range menu.Groups {
+			access.Menus[i].Routes = append(access.Menus[i].Routes, routesByGroup[group]...)
+		}
+	}
+
+	return responses.Success(c, "Rutas habilitadas por cada menú", access)
+}
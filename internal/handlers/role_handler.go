@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos"
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/dtos/responses"
+	"go-fiber-core/internal/models"
+	"go-fiber-core/internal/services/pagination"
+	permissionService "go-fiber-core/internal/services/permission"
+	roleService "go-fiber-core/internal/services/role"
+	"log"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+type RoleHandler interface {
+	Create(c *fiber.Ctx) error
+	GetAll(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	SoftDelete(c *fiber.Ctx) error
+	GetAllPaginated(c *fiber.Ctx) error
+	GetAllPaginatedQuery(c *fiber.Ctx) error
+	AssignUsers(c *fiber.Ctx) error
+	RevokeUsers(c *fiber.Ctx) error
+	SetPermissions(c *fiber.Ctx) error
+	ListPermissions(c *fiber.Ctx) error
+}
+
+type roleHandler struct {
+	writer      roleService.RoleWriterService
+	reader      roleService.RoleReaderService
+	paginator   roleService.RolePaginationService
+	permissions permissionService.PermissionService
+}
+
+func NewRoleHandler(
+	writer roleService.RoleWriterService,
+	reader roleService.RoleReaderService,
+	paginator roleService.RolePaginationService,
+	permissions permissionService.PermissionService,
+) RoleHandler {
+	return &roleHandler{
+		writer:      writer,
+		reader:      reader,
+		paginator:   paginator,
+		permissions: permissions,
+	}
+}
+
+// --- Métodos ---
+
+func (h *roleHandler) Create(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	var req requests.CreateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	role, err := h.writer.Create(ctx, req.Name)
+	if err != nil {
+		return err
+	}
+	log.Printf("Usuario %d creó el rol %d (%s)", userID, role.ID, role.Name)
+	return responses.Success(c, "Rol creado exitosamente", role)
+}
+
+func (h *roleHandler) GetAll(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	if _, err := getUserIDUint64FromCtx(ctx); err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	roles, err := h.reader.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "Roles obtenidos exitosamente", roles)
+}
+
+// GetByID devuelve el rol con sus permisos.
+func (h *roleHandler) GetByID(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	if _, err := getUserIDUint64FromCtx(ctx); err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	id, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	role, err := h.reader.GetByID(ctx, uint64(id))
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "Rol obtenido exitosamente", role)
+}
+
+func (h *roleHandler) Update(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	id, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Usuario %d está actualizando el rol %d", userID, id)
+
+	var req requests.UpdateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	role, err := h.writer.Update(ctx, uint64(id), roleService.UpdateRoleDTO{
+		Name:     req.Name,
+		IsActive: req.IsActive,
+	})
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "Rol actualizado exitosamente", role)
+}
+
+func (h *roleHandler) SoftDelete(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	id, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Usuario %d está borrando lógicamente el rol %d", userID, id)
+
+	if err := h.writer.SoftDelete(ctx, uint64(id)); err != nil {
+		return err
+	}
+	return responses.Success(c, "Rol borrado lógicamente", nil)
+}
+
+func (h *roleHandler) GetAllPaginated(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	if _, err := getUserIDUint64FromCtx(ctx); err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	var req dtos.PaginationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	response, err := h.paginator.GetAllPaginated(ctx, req)
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "Roles paginados obtenidos exitosamente", response)
+}
+
+// GetAllPaginatedQuery es la variante GET de GetAllPaginated (ej: ?filter[is_active]=true&sort=name).
+func (h *roleHandler) GetAllPaginatedQuery(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	if _, err := getUserIDUint64FromCtx(ctx); err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	values, err := queryValues(c)
+	if err != nil {
+		return err
+	}
+	req, err := pagination.ParseQuery[models.Role](values)
+	if err != nil {
+		return err
+	}
+
+	response, err := h.paginator.GetAllPaginated(ctx, req)
+	if err != nil {
+		return err
+	}
+	setPaginationLinks(c, values, response)
+	return responses.Success(c, "Roles paginados obtenidos exitosamente", response)
+}
+
+// AssignUsers asigna cada rol de role_ids a cada usuario de user_ids.
+func (h *roleHandler) AssignUsers(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	var req requests.BulkAssignRoleUsersRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	log.Printf("Usuario %d está asignando los roles %v a los usuarios %v", userID, req.RoleIDs, req.UserIDs)
+
+	if err := h.writer.AssignUsers(ctx, userID, req.RoleIDs, req.UserIDs); err != nil {
+		return err
+	}
+	return responses.Success(c, "Roles asignados correctamente a los usuarios", nil)
+}
+
+// RevokeUsers quita cada rol de role_ids a cada usuario de user_ids.
+func (h *roleHandler) RevokeUsers(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	var req requests.BulkAssignRoleUsersRequest // mismo DTO
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	log.Printf("Usuario %d está quitando los roles %v a los usuarios %v", userID, req.RoleIDs, req.UserIDs)
+
+	if err := h.writer.RevokeUsers(ctx, req.RoleIDs, req.UserIDs); err != nil {
+		return err
+	}
+	return responses.Success(c, "Roles removidos correctamente de los usuarios", nil)
+}
+
+// SetPermissions reemplaza los permisos del rol.
+func (h *roleHandler) SetPermissions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	id, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	var req requests.SetRolePermissionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	log.Printf("Usuario %d está cambiando los permisos del rol %d: %v", userID, id, req.PermissionIDs)
+
+	role, err := h.writer.SetPermissions(ctx, userID, uint64(id), req.PermissionIDs)
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "Permisos del rol actualizados", role)
+}
+
+// ListPermissions devuelve el catálogo de permisos asignables.
+func (h *roleHandler) ListPermissions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	if _, err := getUserIDUint64FromCtx(ctx); err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	permissions, err := h.permissions.List(ctx)
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "Permisos obtenidos exitosamente", permissions)
+}
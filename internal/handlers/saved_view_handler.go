@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/dtos/responses"
+	savedViewService "go-fiber-core/internal/services/savedview"
+	"log"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+type SavedViewHandler interface {
+	List(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	SetDefault(c *fiber.Ctx) error
+}
+
+type savedViewHandler struct {
+	views savedViewService.SavedViewService
+}
+
+func NewSavedViewHandler(views savedViewService.SavedViewService) SavedViewHandler {
+	return &savedViewHandler{views: views}
+}
+
+// List devuelve las vistas propias y las compartidas con los roles del usuario (?resource=banks).
+func (h *savedViewHandler) List(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	views, err := h.views.List(ctx, userID, c.Query("resource"))
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "Vistas obtenidas exitosamente", views)
+}
+
+func (h *savedViewHandler) GetByID(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	id, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	view, err := h.views.GetByID(ctx, userID, uint64(id))
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "Vista obtenida exitosamente", view)
+}
+
+func (h *savedViewHandler) Create(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	var req requests.SavedViewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	view, err := h.views.Create(ctx, userID, toSaveViewDTO(req))
+	if err != nil {
+		return err
+	}
+	log.Printf("Usuario %d creó la vista %d (%s)", userID, view.ID, view.Resource)
+	return responses.Success(c, "Vista creada exitosamente", view)
+}
+
+func (h *savedViewHandler) Update(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	id, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	var req requests.SavedViewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	view, err := h.views.Update(ctx, userID, uint64(id), toSaveViewDTO(req))
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "Vista actualizada exitosamente", view)
+}
+
+func (h *savedViewHandler) Delete(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	id, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.views.Delete(ctx, userID, uint64(id)); err != nil {
+		return err
+	}
+	return responses.Success(c, "Vista eliminada exitosamente", nil)
+}
+
+// SetDefault marca la vista como la vista por defecto del usuario para su recurso.
+func (h *savedViewHandler) SetDefault(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	id, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	view, err := h.views.SetDefault(ctx, userID, uint64(id))
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "Vista por defecto actualizada", view)
+}
+
+func toSaveViewDTO(req requests.SavedViewRequest) savedViewService.SaveViewDTO {
+	return savedViewService.SaveViewDTO{
+		Resource:  req.Resource,
+		Name:      req.Name,
+		Request:   req.Request,
+		RoleID:    req.RoleID,
+		IsDefault: req.IsDefault,
+	}
+}
@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"go-fiber-core/internal/contextkeys"
 	"go-fiber-core/internal/domain"
 	"go-fiber-core/internal/dtos"
 	"go-fiber-core/internal/dtos/requests"
 	"go-fiber-core/internal/dtos/responses"
 	"go-fiber-core/internal/models"
+	"go-fiber-core/internal/services/pagination"
+	savedViewService "go-fiber-core/internal/services/savedview"
 	userService "go-fiber-core/internal/services/user"
+	"io"
 	"log"
 	"strconv"
 
@@ -25,18 +29,33 @@ type UserHandler interface {
 	SoftDelete(c *fiber.Ctx) error
 	HardDelete(c *fiber.Ctx) error
 	GetAllPaginatedUsers(c *fiber.Ctx) error
+	GetAllPaginatedUsersQuery(c *fiber.Ctx) error
+	ExportUsers(c *fiber.Ctx) error
+	ImportUsers(c *fiber.Ctx) error
+	ImportUsersStatus(c *fiber.Ctx) error
+	RevokeSessions(c *fiber.Ctx) error
+	UnlockLogin(c *fiber.Ctx) error
+
+	// Cuenta del usuario autenticado (/me)
+	GetMe(c *fiber.Ctx) error
+	UpdateMe(c *fiber.Ctx) error
+	ChangeMyPassword(c *fiber.Ctx) error
 }
 
 type userHandler struct {
 	userWriter userService.UserWriterService
 	userReader userService.UserReaderService
+	views      savedViewService.SavedViewService
+	imports    userService.UserImportService
 	// userDeactivation userService.DeactivationService
 }
 
-func NewUserHandler(writer userService.UserWriterService, reader userService.UserReaderService) UserHandler {
+func NewUserHandler(writer userService.UserWriterService, reader userService.UserReaderService, views savedViewService.SavedViewService, imports userService.UserImportService) UserHandler {
 	return &userHandler{
 		userWriter: writer,
 		userReader: reader,
+		views:      views,
+		imports:    imports,
 	}
 }
 
@@ -103,7 +122,7 @@ func (h *userHandler) GetUserByID(c *fiber.Ctx) error {
 
 	log.Printf("Usuario %d está solicitando el usuario %d", requestingUserID, id)
 
-	user, err := h.userReader.GetByID(ctx, id)
+	user, err := h.userReader.GetByIDSparse(ctx, id, queryList(c, "fields"), queryList(c, "include"))
 	if err != nil {
 		return err
 	}
@@ -128,10 +147,8 @@ func (h *userHandler) UpdateUser(c *fiber.Ctx) error {
 
 	log.Printf("Usuario %d está intentando actualizar al usuario %d", requestingUserID, id)
 
-	// LÓGICA DE NEGOCIO: Aquí podrías verificar permisos
-	// ej: if requestingUserID != id && !esAdmin(requestingUserID) {
-	// 	   return responses.Error(c, fiber.StatusForbidden, "No tiene permisos", nil)
-	// }
+	// El permiso users.update se verifica en la ruta (middleware.RequirePermission) y el
+	// servicio rechaza editar a un usuario con más permisos que el actor.
 
 	var req requests.UpdateUserRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -143,7 +160,7 @@ func (h *userHandler) UpdateUser(c *fiber.Ctx) error {
 		Email: &req.Email,
 	}
 
-	updatedUser, err := h.userWriter.Update(ctx, id, updateDTO)
+	updatedUser, err := h.userWriter.UpdateAsActor(ctx, requestingUserID, id, updateDTO)
 	if err != nil {
 		return err
 	}
@@ -201,7 +218,7 @@ func (h *userHandler) GetAllPaginatedUsers(c *fiber.Ctx) error {
 	ctx := c.UserContext()
 
 	// AÑADIDO: Obtener el ID de usuario del contexto
-	_, err := getUserIDUint64FromCtx(ctx)
+	userID, err := getUserIDUint64FromCtx(ctx)
 	if err != nil {
 		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
 	}
@@ -212,6 +229,12 @@ func (h *userHandler) GetAllPaginatedUsers(c *fiber.Ctx) error {
 		return domain.ErrInvalidArgument
 	}
 
+	// Vista guardada (view_id) + filtros enviados
+	req, err = h.views.Apply(ctx, userID, savedViewService.ResourceUsers, req)
+	if err != nil {
+		return err
+	}
+
 	response, err := h.userReader.GetAllPaginated(ctx, req)
 	if err != nil {
 		return err
@@ -220,6 +243,118 @@ func (h *userHandler) GetAllPaginatedUsers(c *fiber.Ctx) error {
 	return responses.Success(c, "Usuarios paginados obtenidos exitosamente", response)
 }
 
+// GetAllPaginatedUsersQuery es la variante GET de GetAllPaginatedUsers: lee la paginación
+// de la query string y agrega el header Link con las páginas vecinas.
+func (h *userHandler) GetAllPaginatedUsersQuery(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	values, err := queryValues(c)
+	if err != nil {
+		return err
+	}
+	req, err := pagination.ParseQuery[models.User](values)
+	if err != nil {
+		return err
+	}
+	if req, err = h.views.Apply(ctx, userID, savedViewService.ResourceUsers, req); err != nil {
+		return err
+	}
+
+	response, err := h.userReader.GetAllPaginated(ctx, req)
+	if err != nil {
+		return err
+	}
+	setPaginationLinks(c, values, response)
+	return responses.Success(c, "Usuarios paginados obtenidos exitosamente", response)
+}
+
+// ExportUsers descarga los usuarios filtrados como CSV o XLSX.
+func (h *userHandler) ExportUsers(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	var req dtos.ExportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+	if req.PaginationRequest, err = h.views.Apply(ctx, userID, savedViewService.ResourceUsers, req.PaginationRequest); err != nil {
+		return err
+	}
+
+	stream, err := h.userReader.Export(ctx, req)
+	if err != nil {
+		return err
+	}
+	return sendExport(c, "usuarios", req.Format, stream)
+}
+
+// ImportUsers crea usuarios desde un CSV/XLSX (campo "file" del formulario). Con
+// ?dry_run=true solo valida y devuelve los errores por fila; si hay errores no se crea
+// ningún usuario. Los archivos grandes se importan en segundo plano (202 con job_id).
+func (h *userHandler) ImportUsers(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return domain.NewValidationError(map[string][]string{"file": {"Debe adjuntar el archivo a importar."}})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.imports.Import(ctx, userID, fileHeader.Filename, content, c.QueryBool("dry_run"))
+	if err != nil {
+		return err
+	}
+
+	switch result.Status {
+	case responses.UserImportValidated:
+		return responses.Success(c, "Archivo validado; no se creó ningún usuario", result)
+	case responses.UserImportRejected:
+		return responses.Error(c, fiber.StatusUnprocessableEntity, "El archivo tiene errores; no se creó ningún usuario", result)
+	case responses.UserImportProcessing:
+		c.Status(fiber.StatusAccepted)
+		return responses.Success(c, "La importación continúa en segundo plano", result)
+	}
+	return responses.Success(c, "Usuarios importados correctamente", result)
+}
+
+// ImportUsersStatus devuelve el estado de una importación en segundo plano.
+func (h *userHandler) ImportUsersStatus(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	result, err := h.imports.Status(ctx, userID, c.Params("job_id"))
+	if err != nil {
+		return err
+	}
+	return responses.Success(c, "Estado de la importación", result)
+}
+
 // func (h *userHandler) CreateUserWithRelations(c *fiber.Ctx) error {
 // 	ctx := c.UserContext()
 // 	var req requests.CreateUserWithRelationsRequest
@@ -385,3 +520,111 @@ func (h *userHandler) GetAllPaginatedUsers(c *fiber.Ctx) error {
 
 // 	return responses.Success(c, "Usuario creado con productos y roles nuevos exitosamente", user)
 // }
+
+// RevokeSessions cierra todas las sesiones de un usuario ("logout everywhere"): borra sus
+// refresh tokens y deniega sus access tokens vigentes.
+func (h *userHandler) RevokeSessions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	id, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.userWriter.RevokeSessions(ctx, uint64(id)); err != nil {
+		return err
+	}
+
+	log.Printf("Usuario %d cerró todas las sesiones del usuario %d", userID, id)
+	return responses.Success(c, "Sesiones del usuario cerradas correctamente", nil)
+}
+
+// UnlockLogin levanta el bloqueo de la cuenta por intentos fallidos de login.
+func (h *userHandler) UnlockLogin(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	id, err := getUintID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.userWriter.UnlockLogin(ctx, uint64(id)); err != nil {
+		return err
+	}
+
+	log.Printf("Usuario %d desbloqueó el login del usuario %d", userID, id)
+	return responses.Success(c, "Cuenta desbloqueada correctamente", nil)
+}
+
+// GetMe devuelve el usuario autenticado (admite ?fields= e ?include= como GET /users/:id).
+func (h *userHandler) GetMe(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+
+	user, err := h.userReader.GetByIDSparse(ctx, userID, queryList(c, "fields"), queryList(c, "include"))
+	if err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Usuario obtenido exitosamente", user)
+}
+
+// UpdateMe cambia el nombre y/o el email del propio usuario. Cambiar el email exige la
+// contraseña actual y lo deja sin verificar.
+func (h *userHandler) UpdateMe(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+	var req requests.UpdateMeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	updatedUser, err := h.userWriter.UpdateProfile(ctx, userID, req.Name, req.Email, req.CurrentPassword)
+	if err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Perfil actualizado exitosamente", updatedUser)
+}
+
+// ChangeMyPassword cambia la contraseña del propio usuario y cierra sus demás sesiones.
+func (h *userHandler) ChangeMyPassword(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, err := getUserIDUint64FromCtx(ctx)
+	if err != nil {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", err)
+	}
+	session, ok := contextkeys.GetSession(ctx)
+	if !ok {
+		return responses.Error(c, fiber.StatusUnauthorized, "Error de autenticación", domain.ErrAuthentication)
+	}
+	var req requests.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return domain.ErrInvalidArgument
+	}
+
+	if err := h.userWriter.ChangePassword(ctx, userID, req.CurrentPassword, req.Password, session.ID); err != nil {
+		return err
+	}
+
+	return responses.Success(c, "Contraseña actualizada. Se cerraron las demás sesiones", nil)
+}
@@ -0,0 +1,36 @@
+// Package menuaccess vincula los ítems de menú del frontend con los grupos de rutas de
+// la API, para que lo que el usuario ve en el menú y lo que puede llamar no se separen.
+package menuaccess
+
+import "strings"
+
+// BasePath es el prefijo de la API (igual que en server.RegisterRoutes).
+const BasePath = "/api/v1"
+
+// Route declara qué ítems de menú habilitan un grupo de rutas de la API.
+type Route struct {
+	Group string   // Prefijo del grupo bajo BasePath (ej: "/banks")
+	Menus []string // to_path de los ítems de menú; basta con tener uno
+}
+
+// Routes es el mapeo declarativo menú → grupo de rutas. Los grupos que no aparecen aquí
+// solo se controlan con permisos (middleware.RequirePermission).
+var Routes = []Route{
+	{Group: "/banks", Menus: []string{"/banks-v1", "/banks-v2", "/banks-v3", "/banks-v4", "/banks-v5"}},
+}
+
+// MenusFor devuelve los to_path que habilitan el grupo, o nil si el grupo no está mapeado.
+func MenusFor(group string) []string {
+	for _, route := range Routes {
+		if route.Group == group {
+			return route.Menus
+		}
+	}
+	return nil
+}
+
+// Matches indica si la ruta registrada en Fiber (ej: "/api/v1/banks/:id") pertenece al grupo.
+func (r Route) Matches(path string) bool {
+	prefix := BasePath + r.Group
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
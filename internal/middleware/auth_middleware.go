@@ -1,16 +1,44 @@
 package middleware
 
 import (
+	"errors"
 	"go-fiber-core/internal/contextkeys" // <-- Importa tu nuevo paquete
+	"go-fiber-core/internal/domain"
 	"go-fiber-core/internal/services/auth"
+	"log"
+	"strconv"
 	"strings"
 
 	fiber "github.com/gofiber/fiber/v2"
 	jwt "github.com/golang-jwt/jwt/v5"
+	redis "github.com/redis/go-redis/v9"
 )
 
-func AuthMiddleware(tokenService auth.TokenService) fiber.Handler {
+// Cabeceras con las que se presenta un cliente máquina a máquina.
+const (
+	APIKeyHeader     = "X-API-Key"
+	ClientCodeHeader = "X-Client-Code"
+)
+
+// AuthMiddleware acepta un access token (Authorization: Bearer <jwt>) o una API key
+// (X-API-Key junto a su X-Client-Code). En ambos casos deja el usuario en contextkeys;
+// con API key además el cliente (contextkeys.Client) y sin sesión.
+//
+// Los access tokens revocados (logout, cambio de contraseña, usuario desactivado) se
+// rechazan consultando la denylist.
+func AuthMiddleware(tokenService auth.TokenService, denylist auth.TokenDenylist, apiKeys auth.APIKeyService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// APIKeyMiddleware ya la verificó antes del rate limit.
+		if _, ok := contextkeys.GetClient(c.UserContext()); ok {
+			return c.Next()
+		}
+		if c.Get(APIKeyHeader) != "" {
+			if ok, err := authenticateAPIKey(c, apiKeys); !ok {
+				return err
+			}
+			return c.Next()
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "falta la cabecera de autorización"})
@@ -33,11 +61,29 @@ func AuthMiddleware(tokenService auth.TokenService) fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "formato de claims de token inválido"})
 		}
 
+		// Con RS256/EdDSA el refresh token se firma con la misma clave: solo el typ los distingue.
+		if claims["typ"] != "access" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "tipo de token inválido"})
+		}
+
 		userID, ok := claims["sub"].(string)
 		if !ok {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "claim de ID de usuario inválida"})
 		}
 
+		session, ok := auth.SessionFromClaims(claims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "claim de sesión inválida"})
+		}
+		revoked, err := denylist.IsRevoked(c.UserContext(), session)
+		if err != nil {
+			log.Printf("Error al verificar la sesión: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "no se pudo verificar la sesión"})
+		}
+		if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "la sesión fue cerrada"})
+		}
+
 		// --- CAMBIO CLAVE ---
 		// En lugar de: c.Locals("userID", userID)
 		// Usamos el context.Context estándar de Go.
@@ -46,12 +92,157 @@ func AuthMiddleware(tokenService auth.TokenService) fiber.Handler {
 		ctx := c.UserContext()
 
 		// 2. Creamos un nuevo contexto enriquecido usando nuestro helper
-		newCtx := contextkeys.SetUserID(ctx, userID)
+		newCtx := contextkeys.SetSession(contextkeys.SetUserID(ctx, userID), session)
+
+		actorID, impersonating := auth.ActorFromClaims(claims)
+		if impersonating {
+			if !allowedDuringImpersonation(c) {
+				log.Printf("SUPLANTACIÓN: actor=%s usuario=%s %s %s bloqueada", actorID, userID, c.Method(), c.Path())
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "acción no permitida durante la suplantación"})
+			}
+			newCtx = contextkeys.SetActorID(newCtx, actorID)
+		}
 
 		// 3. Establecemos el nuevo contexto para esta solicitud
 		c.SetUserContext(newCtx)
 		// --- FIN DEL CAMBIO ---
 
+		if !impersonating {
+			return c.Next()
+		}
+		// Cada solicitud suplantada queda registrada con el usuario real.
+		err = c.Next()
+		log.Printf("SUPLANTACIÓN: actor=%s usuario=%s %s %s -> %d", actorID, userID, c.Method(), c.Path(), c.Response().StatusCode())
+		return err
+	}
+}
+
+// DenyImpersonation rechaza las solicitudes suplantadas. AuthMiddleware ya bloquea toda
+// escritura; se deja en las rutas sensibles (ej: contraseña o estado de otro usuario) por
+// si un POST de ellas entrara por error en impersonationAllowedPosts.
+func DenyImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := contextkeys.GetActorID(c.UserContext()); ok {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "acción no permitida durante la suplantación"})
+		}
+		return c.Next()
+	}
+}
+
+// impersonationAllowedPosts son los POST que se permiten durante la suplantación, además
+// de GET y HEAD: las consultas (los filtros van en el cuerpo) y el logout que la termina.
+var impersonationAllowedPosts = map[string]bool{
+	"/api/v1/auth/logout":     true,
+	"/api/v1/banks/paginated": true,
+	"/api/v1/banks/export":    true,
+	"/api/v1/users/paginated": true,
+	"/api/v1/users/export":    true,
+	"/api/v1/roles/paginated": true,
+}
+
+// allowedDuringImpersonation indica si la solicitud se permite con un token de
+// suplantación: solo lecturas. Cualquier otra escritura (incluidas las rutas nuevas) queda
+// bloqueada sin depender de marcar cada ruta.
+func allowedDuringImpersonation(c *fiber.Ctx) bool {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return true
+	case fiber.MethodPost:
+		return impersonationAllowedPosts[strings.TrimSuffix(c.Path(), "/")]
+	}
+	return false
+}
+
+// APIKeyMiddleware verifica la API key, si la solicitud trae una, antes de las rutas: así
+// RateLimitMiddleware cuenta por el cliente verificado y no por un X-Client-Code cualquiera.
+// Las solicitudes sin API key siguen sin cambios.
+//
+// Cada clave inválida cuenta contra el contador de la IP (el mismo de RateLimitMiddleware)
+// y, con ese contador agotado, no se verifican más claves: probarlas no escapa al límite.
+func APIKeyMiddleware(apiKeys auth.APIKeyService, redisClient *redis.Client, config RateLimitConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get(APIKeyHeader) == "" {
+			return c.Next()
+		}
+
+		ipKey := ipRateLimitKey(c)
+		used, err := rateLimitUsed(c.Context(), redisClient, ipKey)
+		if err != nil {
+			log.Printf("Error al leer el rate limit de la IP: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
+		}
+		if used >= config.Limit {
+			return rateLimitExceeded(c)
+		}
+
+		if err := verifyAPIKey(c, apiKeys); err != nil {
+			if errors.Is(err, domain.ErrAuthentication) {
+				if _, err := incrRateLimit(c.Context(), redisClient, ipKey, config.Window); err != nil {
+					log.Printf("Error al contar la API key inválida en Redis: %v", err)
+				}
+			}
+			return apiKeyError(c, err)
+		}
+		return c.Next()
+	}
+}
+
+// RequireSession rechaza las solicitudes autenticadas con API key o con un token de
+// suplantación: exige la sesión propia del usuario. Se usa en las rutas que administran
+// credenciales (API keys, 2FA, contraseña, suplantar): una clave no puede crear otras con
+// más permisos, y quien suplanta no puede cambiar las credenciales del suplantado.
+func RequireSession() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		if _, ok := contextkeys.GetClient(ctx); ok {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "esta acción requiere iniciar sesión"})
+		}
+		if _, ok := contextkeys.GetActorID(ctx); ok {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "acción no permitida durante la suplantación"})
+		}
+		return c.Next()
+	}
+}
+
+// RequireTokenSession rechaza las solicitudes autenticadas con API key: exige un access
+// token, propio o de suplantación. Lo usa POST /auth/logout, que con un token de
+// suplantación termina la suplantación (cierra solo esa sesión).
+func RequireTokenSession() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := contextkeys.GetClient(c.UserContext()); ok {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "esta acción requiere iniciar sesión"})
+		}
 		return c.Next()
 	}
 }
+
+// authenticateAPIKey valida la API key y deja su dueño y el cliente en el contexto. Si no
+// es válida escribe la respuesta de error y devuelve false (con el error de escribirla).
+func authenticateAPIKey(c *fiber.Ctx, apiKeys auth.APIKeyService) (bool, error) {
+	if err := verifyAPIKey(c, apiKeys); err != nil {
+		return false, apiKeyError(c, err)
+	}
+	return true, nil
+}
+
+// verifyAPIKey valida la API key y deja su dueño y el cliente en el contexto.
+func verifyAPIKey(c *fiber.Ctx, apiKeys auth.APIKeyService) error {
+	ctx := c.UserContext()
+	key, err := apiKeys.Authenticate(ctx, c.Get(APIKeyHeader), c.Get(ClientCodeHeader))
+	if err != nil {
+		return err
+	}
+
+	client := contextkeys.Client{Code: key.ClientCode, APIKeyID: key.ID, Scopes: key.Scopes}
+	c.SetUserContext(contextkeys.SetClient(contextkeys.SetUserID(ctx, strconv.FormatUint(key.UserID, 10)), client))
+	return nil
+}
+
+// apiKeyError escribe la respuesta de una API key rechazada.
+func apiKeyError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, domain.ErrAuthentication) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "API key inválida, vencida o de otro cliente"})
+	}
+	log.Printf("Error al verificar la API key: %v", err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "no se pudo verificar la API key"})
+}
@@ -8,6 +8,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"go-fiber-core/internal/contextkeys"
+	"go-fiber-core/internal/models"
+
 	fiber "github.com/gofiber/fiber/v2"
 	jwt "github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
@@ -22,17 +25,16 @@ func TestAuthMiddleware_Success(t *testing.T) {
 
 	mockService := &mockTokenService{ // <- Go encontrará la definición en el otro archivo
 		tokenToReturn: &jwt.Token{
-			Valid: true,
-			Claims: jwt.MapClaims{
-				"sub": expectedUserID,
-			},
+			Valid:  true,
+			Claims: sessionClaims(expectedUserID, "sid-1"),
 		},
 		errorToReturn: nil,
 	}
 
-	app.Use(AuthMiddleware(mockService))
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{}, &mockAPIKeyService{}))
 	app.Get("/protected", func(c *fiber.Ctx) error {
-		userID := c.Locals("userID")
+		// El middleware deja el usuario en el context.Context de la solicitud.
+		userID, _ := contextkeys.GetUserID(c.UserContext())
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"userID": userID})
 	})
 
@@ -49,7 +51,7 @@ func TestAuthMiddleware_MissingHeader(t *testing.T) {
 	app := fiber.New()
 	mockService := &mockTokenService{}
 
-	app.Use(AuthMiddleware(mockService))
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{}, &mockAPIKeyService{}))
 	app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
 
 	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
@@ -64,7 +66,7 @@ func TestAuthMiddleware_InvalidTokenFormat(t *testing.T) {
 	app := fiber.New()
 	mockService := &mockTokenService{}
 
-	app.Use(AuthMiddleware(mockService))
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{}, &mockAPIKeyService{}))
 	app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
 
 	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
@@ -83,7 +85,7 @@ func TestAuthMiddleware_TokenServiceValidationError(t *testing.T) {
 		errorToReturn: errors.New("error de validación"),
 	}
 
-	app.Use(AuthMiddleware(mockService))
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{}, &mockAPIKeyService{}))
 	app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
 
 	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
@@ -102,7 +104,7 @@ func TestAuthMiddleware_TokenNotValid(t *testing.T) {
 		errorToReturn: nil,
 	}
 
-	app.Use(AuthMiddleware(mockService))
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{}, &mockAPIKeyService{}))
 	app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
 
 	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
@@ -123,7 +125,7 @@ func TestAuthMiddleware_InvalidClaimsFormat(t *testing.T) {
 		},
 	}
 
-	app.Use(AuthMiddleware(mockService))
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{}, &mockAPIKeyService{}))
 	app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
 
 	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
@@ -135,18 +137,39 @@ func TestAuthMiddleware_InvalidClaimsFormat(t *testing.T) {
 	assert.Contains(t, string(body), "formato de claims de token inválido")
 }
 
+func TestAuthMiddleware_RefreshTokenRejected(t *testing.T) {
+	app := fiber.New()
+	claims := sessionClaims("7", "sid-1")
+	claims["typ"] = "refresh"
+	mockService := &mockTokenService{
+		tokenToReturn: &jwt.Token{Valid: true, Claims: claims},
+	}
+
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{}, &mockAPIKeyService{}))
+	app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer un-refresh-token")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "tipo de token inválido")
+}
+
 func TestAuthMiddleware_MissingSubClaim(t *testing.T) {
 	app := fiber.New()
 	mockService := &mockTokenService{
 		tokenToReturn: &jwt.Token{
 			Valid: true,
 			Claims: jwt.MapClaims{
+				"typ":  "access",
 				"role": "admin",
 			},
 		},
 	}
 
-	app.Use(AuthMiddleware(mockService))
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{}, &mockAPIKeyService{}))
 	app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
 
 	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
@@ -164,12 +187,13 @@ func TestAuthMiddleware_SubClaimNotAString(t *testing.T) {
 		tokenToReturn: &jwt.Token{
 			Valid: true,
 			Claims: jwt.MapClaims{
+				"typ": "access",
 				"sub": 12345,
 			},
 		},
 	}
 
-	app.Use(AuthMiddleware(mockService))
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{}, &mockAPIKeyService{}))
 	app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
 
 	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
@@ -180,3 +204,223 @@ func TestAuthMiddleware_SubClaimNotAString(t *testing.T) {
 	body, _ := io.ReadAll(resp.Body)
 	assert.Contains(t, string(body), "claim de ID de usuario inválida")
 }
+
+func TestAuthMiddleware_MissingSessionClaims(t *testing.T) {
+	app := fiber.New()
+	mockService := &mockTokenService{
+		tokenToReturn: &jwt.Token{
+			Valid:  true,
+			Claims: jwt.MapClaims{"typ": "access", "sub": "7"},
+		},
+	}
+
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{}, &mockAPIKeyService{}))
+	app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer token-sin-sesion")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "claim de sesión inválida")
+}
+
+func TestAuthMiddleware_RevokedSession(t *testing.T) {
+	app := fiber.New()
+	mockService := &mockTokenService{
+		tokenToReturn: &jwt.Token{Valid: true, Claims: sessionClaims("7", "sid-cerrada")},
+	}
+	denylist := &mockTokenDenylist{revoked: map[string]bool{"sid-cerrada": true}}
+
+	app.Use(AuthMiddleware(mockService, denylist, &mockAPIKeyService{}))
+	app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer token-revocado")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "la sesión fue cerrada")
+}
+
+func TestAuthMiddleware_DenylistError(t *testing.T) {
+	app := fiber.New()
+	mockService := &mockTokenService{
+		tokenToReturn: &jwt.Token{Valid: true, Claims: sessionClaims("7", "sid-1")},
+	}
+
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{err: errors.New("redis caído")}, &mockAPIKeyService{}))
+	app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer un-token-valido")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+}
+
+// apiKeyService conoce una sola clave, del usuario 7 para el cliente erp-sync.
+func apiKeyService() *mockAPIKeyService {
+	return &mockAPIKeyService{keys: map[string]*models.APIKey{
+		"fck_valida": {ID: 3, UserID: 7, ClientCode: "erp-sync", Scopes: []string{"banks.read"}},
+	}}
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	app := fiber.New()
+	app.Use(AuthMiddleware(&mockTokenService{}, &mockTokenDenylist{}, apiKeyService()))
+	app.Get("/protected", func(c *fiber.Ctx) error {
+		userID, _ := contextkeys.GetUserID(c.UserContext())
+		client, _ := contextkeys.GetClient(c.UserContext())
+		_, hasSession := contextkeys.GetSession(c.UserContext())
+		return c.JSON(fiber.Map{"userID": userID, "client": client.Code, "keyID": client.APIKeyID, "session": hasSession})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set(APIKeyHeader, "fck_valida")
+	req.Header.Set(ClientCodeHeader, "erp-sync")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"userID": "7", "client": "erp-sync", "keyID": 3, "session": false}`, string(body))
+}
+
+func TestAuthMiddleware_APIKeyRejected(t *testing.T) {
+	cases := map[string]struct{ key, clientCode string }{
+		"clave desconocida": {"fck_otra", "erp-sync"},
+		"otro cliente":      {"fck_valida", "otro-cliente"},
+		"sin cliente":       {"fck_valida", ""},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(AuthMiddleware(&mockTokenService{}, &mockTokenDenylist{}, apiKeyService()))
+			app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
+
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set(APIKeyHeader, tc.key)
+			req.Header.Set(ClientCodeHeader, tc.clientCode)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		})
+	}
+}
+
+func TestAuthMiddleware_APIKeyServiceError(t *testing.T) {
+	app := fiber.New()
+	app.Use(AuthMiddleware(&mockTokenService{}, &mockTokenDenylist{}, &mockAPIKeyService{err: errors.New("base caída")}))
+	app.Get("/protected", func(c *fiber.Ctx) error { return c.SendStatus(200) })
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set(APIKeyHeader, "fck_valida")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestAPIKeyMiddleware_IgnoresRequestsWithoutKey(t *testing.T) {
+	app := fiber.New()
+	app.Use(APIKeyMiddleware(apiKeyService(), nil, RateLimitConfig{}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		_, isClient := contextkeys.GetClient(c.UserContext())
+		return c.JSON(fiber.Map{"client": isClient})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(ClientCodeHeader, "erp-sync")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"client": false}`, string(body))
+}
+
+func TestRequireSession_RejectsAPIKey(t *testing.T) {
+	app := fiber.New()
+	app.Use(AuthMiddleware(&mockTokenService{}, &mockTokenDenylist{}, apiKeyService()))
+	app.Post("/auth/api-keys", RequireSession(), func(c *fiber.Ctx) error { return c.SendStatus(200) })
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/api-keys", nil)
+	req.Header.Set(APIKeyHeader, "fck_valida")
+	req.Header.Set(ClientCodeHeader, "erp-sync")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
+
+// impersonationClaims son las claims de un token del usuario 2 suplantado por el 1.
+func impersonationClaims() jwt.MapClaims {
+	claims := sessionClaims("2", "imp-1")
+	claims["act"] = map[string]any{"sub": "1"}
+	return claims
+}
+
+func TestAuthMiddleware_Impersonation(t *testing.T) {
+	app := fiber.New()
+	mockService := &mockTokenService{tokenToReturn: &jwt.Token{Valid: true, Claims: impersonationClaims()}}
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{}, &mockAPIKeyService{}))
+	app.Get("/menus/my", func(c *fiber.Ctx) error {
+		subjectID, _ := contextkeys.GetSubjectID(c.UserContext())
+		actorID, _ := contextkeys.GetActorID(c.UserContext())
+		return c.JSON(fiber.Map{"subject": subjectID, "actor": actorID})
+	})
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(200) }
+	app.Delete("/banks/:id", ok)
+	app.Post("/auth/api-keys", RequireSession(), ok)
+	app.Put("/users/:id", DenyImpersonation(), ok)
+	app.Post("/api/v1/banks", ok)
+	app.Patch("/api/v1/me", ok)
+	app.Post("/api/v1/banks/paginated", ok)
+	app.Post("/api/v1/auth/logout", RequireTokenSession(), ok)
+
+	req := httptest.NewRequest(http.MethodGet, "/menus/my", nil)
+	req.Header.Set("Authorization", "Bearer token-suplantado")
+	resp, _ := app.Test(req)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"subject": "2", "actor": "1"}`, string(body))
+
+	// Los POST de consulta de la lista y el logout que termina la suplantación se permiten.
+	for _, path := range []string{"/api/v1/banks/paginated", "/api/v1/auth/logout"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "Bearer token-suplantado")
+		resp, _ := app.Test(req)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode, path)
+	}
+
+	// Cualquier otra escritura queda bloqueada, aunque la ruta no use DenyImpersonation.
+	for _, blocked := range []struct{ method, path string }{
+		{http.MethodDelete, "/banks/1"},
+		{http.MethodPost, "/auth/api-keys"},
+		{http.MethodPut, "/users/3"},
+		{http.MethodPost, "/api/v1/banks"},
+		{http.MethodPatch, "/api/v1/me"},
+	} {
+		req := httptest.NewRequest(blocked.method, blocked.path, nil)
+		req.Header.Set("Authorization", "Bearer token-suplantado")
+		resp, _ := app.Test(req)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode, blocked.path)
+	}
+}
+
+func TestAuthMiddleware_RegularTokenHasNoActor(t *testing.T) {
+	app := fiber.New()
+	mockService := &mockTokenService{tokenToReturn: &jwt.Token{Valid: true, Claims: sessionClaims("2", "sid-1")}}
+	app.Use(AuthMiddleware(mockService, &mockTokenDenylist{}, &mockAPIKeyService{}))
+	app.Delete("/banks/:id", func(c *fiber.Ctx) error {
+		_, impersonating := contextkeys.GetActorID(c.UserContext())
+		return c.JSON(fiber.Map{"impersonating": impersonating})
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/banks/1", nil)
+	req.Header.Set("Authorization", "Bearer un-token-valido")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"impersonating": false}`, string(body))
+}
@@ -10,8 +10,14 @@ import (
 )
 
 func ErrorHandler(c *fiber.Ctx, err error) error {
+	var validationErr *domain.ValidationError
+	var fiberErr *fiber.Error
+
 	// Revisa el tipo de error y decide qué respuesta enviar.
 	switch {
+	case errors.As(err, &validationErr):
+		return responses.ValidationError(c, validationErr.Fields)
+
 	case errors.Is(err, domain.ErrNotFound):
 		return responses.Error(c, fiber.StatusNotFound, err.Error())
 
@@ -21,6 +27,16 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 	case errors.Is(err, domain.ErrAuthentication):
 		return responses.Error(c, fiber.StatusUnauthorized, err.Error())
 
+	case errors.Is(err, domain.ErrEmailNotVerified), errors.Is(err, domain.ErrForbidden):
+		return responses.Error(c, fiber.StatusForbidden, err.Error())
+
+	case errors.Is(err, domain.ErrAccountLocked):
+		return responses.Error(c, fiber.StatusTooManyRequests, err.Error())
+
+	// Errores propios de Fiber (ruta inexistente, método no permitido, etc.)
+	case errors.As(err, &fiberErr):
+		return responses.Error(c, fiberErr.Code, fiberErr.Message)
+
 	// Y así para otros errores personalizados...
 
 	default:
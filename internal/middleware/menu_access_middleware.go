@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"go-fiber-core/internal/contextkeys"
+	"log"
+	"strconv"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// MenuAccessResolver obtiene los to_path de los menús efectivos de un usuario
+// (menús de sus roles más las excepciones de menu_user).
+type MenuAccessResolver interface {
+	GetMenuPaths(ctx context.Context, userID uint64) ([]string, error)
+}
+
+var (
+	menuAccessResolver MenuAccessResolver
+
+	errMenuAccessNotConfigured = errors.New("middleware: falta llamar a SetupMenuAccess")
+)
+
+// SetupMenuAccess configura el resolver que usa RequireMenu.
+// Debe llamarse una vez al registrar las rutas (igual que SetupPermissions).
+func SetupMenuAccess(resolver MenuAccessResolver) {
+	menuAccessResolver = resolver
+}
+
+// RequireMenu exige que el usuario autenticado tenga al menos uno de los ítems de menú
+// indicados (por su to_path). Se usa sobre un grupo de rutas, con el mapeo de menuaccess:
+//
+//	bankGroup := router.Group("/banks", middleware.RequireMenu(menuaccess.MenusFor("/banks")...))
+//
+// Los menús se resuelven una sola vez por solicitud y quedan en el contexto.
+func RequireMenu(menus ...string) fiber.Handler {
+	if len(menus) == 0 {
+		panic("middleware: RequireMenu necesita al menos un menú")
+	}
+	return func(c *fiber.Ctx) error {
+		granted, err := resolveMenuPaths(c)
+		if err != nil {
+			log.Printf("Error al resolver menús: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "no se pudieron verificar los menús"})
+		}
+		if granted == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "usuario no autenticado"})
+		}
+		for _, menu := range menus {
+			if granted[menu] {
+				return c.Next()
+			}
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "no tiene acceso a esta sección"})
+	}
+}
+
+// resolveMenuPaths devuelve los to_path del usuario, consultando al resolver solo la
+// primera vez en la solicitud. Devuelve nil si no hay un usuario autenticado.
+func resolveMenuPaths(c *fiber.Ctx) (map[string]bool, error) {
+	ctx := c.UserContext()
+	if granted, ok := contextkeys.GetMenuPaths(ctx); ok {
+		return granted, nil
+	}
+
+	userIDStr, ok := contextkeys.GetUserID(ctx)
+	if !ok {
+		return nil, nil
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	if menuAccessResolver == nil {
+		return nil, errMenuAccessNotConfigured
+	}
+
+	paths, err := menuAccessResolver.GetMenuPaths(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	granted := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		granted[path] = true
+	}
+	c.SetUserContext(contextkeys.SetMenuPaths(ctx, granted))
+	return granted, nil
+}
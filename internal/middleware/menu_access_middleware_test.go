@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-fiber-core/internal/contextkeys"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockMenuAccessResolver devuelve menús fijos y cuenta las consultas.
+type mockMenuAccessResolver struct {
+	paths []string
+	err   error
+	calls int
+}
+
+func (m *mockMenuAccessResolver) GetMenuPaths(ctx context.Context, userID uint64) ([]string, error) {
+	m.calls++
+	return m.paths, m.err
+}
+
+// newMenuAccessApp arma una app con un usuario autenticado (si userID no es vacío)
+// y el grupo /banks protegido por los middlewares indicados.
+func newMenuAccessApp(resolver MenuAccessResolver, userID string, handlers ...fiber.Handler) *fiber.App {
+	SetupMenuAccess(resolver)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if userID != "" {
+			c.SetUserContext(contextkeys.SetUserID(c.UserContext(), userID))
+		}
+		return c.Next()
+	})
+	group := app.Group("/banks", handlers...)
+	group.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	return app
+}
+
+func TestRequireMenu_Allowed(t *testing.T) {
+	resolver := &mockMenuAccessResolver{paths: []string{"/", "/banks-v2"}}
+	app := newMenuAccessApp(resolver, "7", RequireMenu("/banks-v1", "/banks-v2"))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/banks", nil))
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestRequireMenu_Forbidden(t *testing.T) {
+	resolver := &mockMenuAccessResolver{paths: []string{"/", "/messages"}}
+	app := newMenuAccessApp(resolver, "7", RequireMenu("/banks-v1", "/banks-v2"))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/banks", nil))
+
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
+
+func TestRequireMenu_Unauthenticated(t *testing.T) {
+	resolver := &mockMenuAccessResolver{paths: []string{"/banks-v1"}}
+	app := newMenuAccessApp(resolver, "", RequireMenu("/banks-v1"))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/banks", nil))
+
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, 0, resolver.calls)
+}
+
+func TestRequireMenu_ResolvesOncePerRequest(t *testing.T) {
+	resolver := &mockMenuAccessResolver{paths: []string{"/banks-v1", "/banks-v3"}}
+	app := newMenuAccessApp(resolver, "7", RequireMenu("/banks-v1"), RequireMenu("/banks-v3"))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/banks", nil))
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, resolver.calls)
+}
+
+func TestRequireMenu_ResolverError(t *testing.T) {
+	resolver := &mockMenuAccessResolver{err: errors.New("redis caído")}
+	app := newMenuAccessApp(resolver, "7", RequireMenu("/banks-v1"))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/banks", nil))
+
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestRequireMenu_PanicsWithoutMenus(t *testing.T) {
+	assert.Panics(t, func() { RequireMenu() })
+}
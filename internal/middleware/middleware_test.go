@@ -1,6 +1,14 @@
 package middleware
 
 import (
+	"context"
+	"go-fiber-core/internal/contextkeys"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/dtos/responses"
+	"go-fiber-core/internal/models"
+	"time"
+
 	jwt "github.com/golang-jwt/jwt/v5" // ✅ Esta línea soluciona el error
 )
 
@@ -10,7 +18,8 @@ import (
 // Esta debe coincidir con la interfaz real de tu paquete 'services'.
 type TokenService interface {
 	ValidateToken(tokenString string) (*jwt.Token, error)
-	GenerateTokens(userID string) (string, string, error)
+	GenerateTokens(userID, sessionID string) (string, string, error)
+	GenerateImpersonationToken(actorID, subjectID, sessionID string, ttl time.Duration) (string, error)
 }
 
 // Definimos el mock que implementa la interfaz TokenService.
@@ -24,6 +33,72 @@ func (m *mockTokenService) ValidateToken(tokenString string) (*jwt.Token, error)
 	return m.tokenToReturn, m.errorToReturn
 }
 
-func (m *mockTokenService) GenerateTokens(userID string) (string, string, error) {
+func (m *mockTokenService) GenerateTokens(userID, sessionID string) (string, string, error) {
 	return "access_token_mock", "refresh_token_mock", nil
 }
+
+func (m *mockTokenService) GenerateImpersonationToken(actorID, subjectID, sessionID string, ttl time.Duration) (string, error) {
+	return "impersonation_token_mock", nil
+}
+
+// mockTokenDenylist revoca las sesiones indicadas en revoked (por sid).
+type mockTokenDenylist struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (m *mockTokenDenylist) TrackSession(ctx context.Context, userID uint64, sessionID string) error {
+	return nil
+}
+
+func (m *mockTokenDenylist) RevokeSession(ctx context.Context, userID uint64, session contextkeys.Session) error {
+	return nil
+}
+
+func (m *mockTokenDenylist) RevokeUser(ctx context.Context, userID uint64) error {
+	return nil
+}
+
+func (m *mockTokenDenylist) IsRevoked(ctx context.Context, session contextkeys.Session) (bool, error) {
+	return m.revoked[session.ID], m.err
+}
+
+// sessionClaims devuelve claims válidas de un access token de la sesión sid.
+func sessionClaims(sub any, sid string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": sub,
+		"typ": "access",
+		"sid": sid,
+		"jti": "jti-" + sid,
+		"exp": float64(time.Now().Add(time.Hour).Unix()),
+	}
+}
+
+// mockAPIKeyService acepta solo la clave keys[key] presentada con su client_code.
+type mockAPIKeyService struct {
+	keys map[string]*models.APIKey
+	err  error
+}
+
+func (m *mockAPIKeyService) Create(ctx context.Context, userID uint64, req requests.CreateAPIKeyRequest) (*responses.APIKeyCreatedResponse, error) {
+	return nil, nil
+}
+
+func (m *mockAPIKeyService) List(ctx context.Context, userID uint64) ([]responses.APIKeyResponse, error) {
+	return nil, nil
+}
+
+func (m *mockAPIKeyService) Revoke(ctx context.Context, userID, id uint64) error {
+	return nil
+}
+
+func (m *mockAPIKeyService) Authenticate(ctx context.Context, key, clientCode string) (*models.APIKey, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	apiKey, ok := m.keys[key]
+	if !ok || apiKey.ClientCode != clientCode {
+		return nil, domain.ErrAuthentication
+	}
+	return apiKey, nil
+}
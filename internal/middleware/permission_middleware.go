@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"go-fiber-core/internal/contextkeys"
+	"log"
+	"slices"
+	"strconv"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// PermissionResolver obtiene los permisos de un usuario a partir de sus roles.
+type PermissionResolver interface {
+	GetUserPermissions(ctx context.Context, userID uint64) ([]string, error)
+}
+
+var (
+	permissionResolver PermissionResolver
+
+	errPermissionsNotConfigured = errors.New("middleware: falta llamar a SetupPermissions")
+)
+
+// SetupPermissions configura el resolver que usa RequirePermission.
+// Debe llamarse una vez al registrar las rutas (igual que utils.SetupValidator).
+func SetupPermissions(resolver PermissionResolver) {
+	permissionResolver = resolver
+}
+
+// RequirePermission exige que el usuario autenticado tenga todos los permisos indicados.
+// Se usa después de AuthMiddleware:
+//
+//	bankGroup.Delete("/:id", middleware.RequirePermission("banks.delete"), bankHandler.SoftDelete)
+//
+// Los permisos se resuelven una sola vez por solicitud y quedan en el contexto. Con API key
+// solo cuentan los permisos del dueño que además están en los scopes de la clave.
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		granted, err := resolvePermissions(c)
+		if err != nil {
+			log.Printf("Error al resolver permisos: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "no se pudieron verificar los permisos"})
+		}
+		if granted == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "usuario no autenticado"})
+		}
+		for _, permission := range permissions {
+			if !granted[permission] {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "no tiene permiso para realizar esta acción"})
+			}
+		}
+		return c.Next()
+	}
+}
+
+// resolvePermissions devuelve los permisos del usuario, consultando al resolver solo la
+// primera vez en la solicitud. Devuelve nil si no hay un usuario autenticado.
+func resolvePermissions(c *fiber.Ctx) (map[string]bool, error) {
+	ctx := c.UserContext()
+	if granted, ok := contextkeys.GetPermissions(ctx); ok {
+		return granted, nil
+	}
+
+	userIDStr, ok := contextkeys.GetUserID(ctx)
+	if !ok {
+		return nil, nil
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	if permissionResolver == nil {
+		return nil, errPermissionsNotConfigured
+	}
+
+	names, err := permissionResolver.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	client, isClient := contextkeys.GetClient(ctx)
+	granted := make(map[string]bool, len(names))
+	for _, name := range names {
+		if isClient && !slices.Contains(client.Scopes, name) {
+			continue
+		}
+		granted[name] = true
+	}
+	c.SetUserContext(contextkeys.SetPermissions(ctx, granted))
+	return granted, nil
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-fiber-core/internal/contextkeys"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockPermissionResolver devuelve permisos fijos y cuenta las consultas.
+type mockPermissionResolver struct {
+	permissions []string
+	err         error
+	calls       int
+}
+
+func (m *mockPermissionResolver) GetUserPermissions(ctx context.Context, userID uint64) ([]string, error) {
+	m.calls++
+	return m.permissions, m.err
+}
+
+// newPermissionApp arma una app con un usuario autenticado (si userID no es vacío)
+// y la ruta protegida por los middlewares indicados.
+func newPermissionApp(resolver PermissionResolver, userID string, handlers ...fiber.Handler) *fiber.App {
+	SetupPermissions(resolver)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if userID != "" {
+			c.SetUserContext(contextkeys.SetUserID(c.UserContext(), userID))
+		}
+		return c.Next()
+	})
+	handlers = append(handlers, func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	app.Delete("/banks/:id", handlers...)
+	return app
+}
+
+func TestRequirePermission_Allowed(t *testing.T) {
+	resolver := &mockPermissionResolver{permissions: []string{"banks.read", "banks.delete"}}
+	app := newPermissionApp(resolver, "7", RequirePermission("banks.delete"))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodDelete, "/banks/1", nil))
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestRequirePermission_Forbidden(t *testing.T) {
+	resolver := &mockPermissionResolver{permissions: []string{"banks.read"}}
+	app := newPermissionApp(resolver, "7", RequirePermission("banks.delete"))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodDelete, "/banks/1", nil))
+
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
+
+func TestRequirePermission_Unauthenticated(t *testing.T) {
+	resolver := &mockPermissionResolver{permissions: []string{"banks.delete"}}
+	app := newPermissionApp(resolver, "", RequirePermission("banks.delete"))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodDelete, "/banks/1", nil))
+
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, 0, resolver.calls)
+}
+
+func TestRequirePermission_ResolvesOncePerRequest(t *testing.T) {
+	resolver := &mockPermissionResolver{permissions: []string{"banks.read", "banks.delete"}}
+	app := newPermissionApp(resolver, "7", RequirePermission("banks.read"), RequirePermission("banks.delete"))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodDelete, "/banks/1", nil))
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, resolver.calls)
+}
+
+func TestRequirePermission_ResolverError(t *testing.T) {
+	resolver := &mockPermissionResolver{err: errors.New("db caída")}
+	app := newPermissionApp(resolver, "7", RequirePermission("banks.delete"))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodDelete, "/banks/1", nil))
+
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestRequirePermission_APIKeyLimitedToScopes(t *testing.T) {
+	resolver := &mockPermissionResolver{permissions: []string{"banks.read", "banks.delete"}}
+	SetupPermissions(resolver)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		ctx := contextkeys.SetUserID(c.UserContext(), "7")
+		c.SetUserContext(contextkeys.SetClient(ctx, contextkeys.Client{Code: "erp-sync", Scopes: []string{"banks.read"}}))
+		return c.Next()
+	})
+	app.Get("/banks", RequirePermission("banks.read"), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	app.Delete("/banks/:id", RequirePermission("banks.delete"), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/banks", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// El dueño tiene banks.delete, pero la clave no.
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, "/banks/1", nil))
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
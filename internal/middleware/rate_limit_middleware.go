@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"go-fiber-core/internal/contextkeys"
 	"log"
 	"time"
 
@@ -16,50 +19,70 @@ type RateLimitConfig struct {
 	Window time.Duration
 }
 
-// RateLimitMiddleware aplica un rate limit por IP o cabecera de forma atómica.
+// RateLimitMiddleware aplica un rate limit por cliente o IP de forma atómica.
+//
+// Se usa después de APIKeyMiddleware: solo un cliente verificado por su API key tiene un
+// contador propio. Un X-Client-Code sin clave no cuenta, para que no se pueda inventar uno
+// y estrenar un contador en cada solicitud. Las API keys inválidas las carga
+// APIKeyMiddleware al contador de la IP.
 func RateLimitMiddleware(redisClient *redis.Client, config RateLimitConfig) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// 1. Usa un identificador fiable: cabecera para usuarios autenticados o IP para anónimos.
-		clientIdentifier := c.Get("X-Client-Code") // Ideal para una API Key
-		if clientIdentifier == "" {
-			clientIdentifier = c.IP()
+		// 1. Usa un identificador fiable: el cliente verificado o la IP para el resto.
+		key := ipRateLimitKey(c)
+		if client, ok := contextkeys.GetClient(c.UserContext()); ok {
+			key = rateLimitKeyPrefix + "client:" + client.Code
 		}
 
-		key := rateLimitKeyPrefix + clientIdentifier
-
-		// 2. Utiliza el contexto de la petición de Fiber.
-		ctx := c.Context()
-
-		// 3. Usa una pipeline de Redis para ejecutar comandos de forma atómica.
-		var countCmd *redis.IntCmd
-		_, err := redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
-			// Incrementa el contador. Si la clave no existe, la crea con valor 1.
-			countCmd = pipe.Incr(ctx, key)
-			// Establece la expiración en cada petición para implementar una ventana deslizante.
-			pipe.Expire(ctx, key, config.Window)
-			return nil
-		})
-
+		// 2. Cuenta la solicitud en Redis.
+		count, err := incrRateLimit(c.Context(), redisClient, key, config.Window)
 		if err != nil {
-			log.Printf("Error al ejecutar la pipeline de Redis: %v", err)
+			log.Printf("Error al contar la solicitud en Redis: %v", err)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
 		}
 
-		// Obtenemos el valor actual del contador
-		count, err := countCmd.Result()
-		if err != nil {
-			log.Printf("Error al obtener el resultado del contador: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
-		}
-
-		// 4. Comprueba si se ha excedido el límite.
+		// 3. Comprueba si se ha excedido el límite.
 		if count > config.Limit {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error": "Rate limit exceeded",
-			})
+			return rateLimitExceeded(c)
 		}
 
 		// Si todo está bien, pasa a la siguiente ruta.
 		return c.Next()
 	}
 }
+
+// ipRateLimitKey es el contador de la IP de la solicitud.
+func ipRateLimitKey(c *fiber.Ctx) string {
+	return rateLimitKeyPrefix + "ip:" + c.IP()
+}
+
+// incrRateLimit suma una solicitud al contador y devuelve el total de la ventana.
+func incrRateLimit(ctx context.Context, redisClient *redis.Client, key string, window time.Duration) (int64, error) {
+	// Pipeline para ejecutar los comandos de forma atómica.
+	var countCmd *redis.IntCmd
+	_, err := redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		// Incrementa el contador. Si la clave no existe, la crea con valor 1.
+		countCmd = pipe.Incr(ctx, key)
+		// Establece la expiración en cada petición para implementar una ventana deslizante.
+		pipe.Expire(ctx, key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return countCmd.Result()
+}
+
+// rateLimitUsed devuelve lo que ya consumió el contador en la ventana, sin sumar.
+func rateLimitUsed(ctx context.Context, redisClient *redis.Client, key string) (int64, error) {
+	count, err := redisClient.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return count, err
+}
+
+func rateLimitExceeded(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error": "Rate limit exceeded",
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"go-fiber-core/internal/models"
+
 	// ✅ CORRECCIÓN: Se añaden nombres explícitos para evitar la colisión de 'v2'.
 	miniredis "github.com/alicebob/miniredis/v2"
 	fiber "github.com/gofiber/fiber/v2"
@@ -116,8 +118,8 @@ func TestRateLimitMiddleware_DifferentIPsHaveDifferentCounters(t *testing.T) {
 	assert.Equal(t, fiber.StatusTooManyRequests, resp3.StatusCode)
 }
 
-// Test 4: Usa la cabecera X-Client-Code como identificador prioritario.
-func TestRateLimitMiddleware_UsesHeaderAsIdentifier(t *testing.T) {
+// Test 4: Un cliente verificado por su API key tiene su propio contador.
+func TestRateLimitMiddleware_UsesVerifiedClientAsIdentifier(t *testing.T) {
 	app, redisClient, s := setupTest(t)
 	defer s.Close()
 
@@ -126,25 +128,95 @@ func TestRateLimitMiddleware_UsesHeaderAsIdentifier(t *testing.T) {
 		Window: 1 * time.Minute,
 	}
 
+	app.Use(APIKeyMiddleware(&mockAPIKeyService{keys: map[string]*models.APIKey{
+		"fck_a": {ID: 1, UserID: 7, ClientCode: "client-A"},
+		"fck_b": {ID: 2, UserID: 8, ClientCode: "client-B"},
+	}}, redisClient, config))
 	app.Use(RateLimitMiddleware(redisClient, config))
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendStatus(fiber.StatusOK)
 	})
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.Header.Set("X-Client-Code", "client-A")
+	req.Header.Set(APIKeyHeader, "fck_a")
+	req.Header.Set(ClientCodeHeader, "client-A")
 	resp, _ := app.Test(req)
 	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
 
 	resp2, _ := app.Test(req)
 	assert.Equal(t, fiber.StatusTooManyRequests, resp2.StatusCode)
 
+	// Misma IP, otro cliente verificado: contador aparte.
 	req3 := httptest.NewRequest(http.MethodGet, "/", nil)
-	req3.Header.Set("X-Client-Code", "client-B")
+	req3.Header.Set(APIKeyHeader, "fck_b")
+	req3.Header.Set(ClientCodeHeader, "client-B")
 	resp3, _ := app.Test(req3)
 	assert.Equal(t, fiber.StatusOK, resp3.StatusCode)
 }
 
+// Test 4b: Un X-Client-Code sin API key no estrena contador: cuenta la IP.
+func TestRateLimitMiddleware_IgnoresUnverifiedClientCode(t *testing.T) {
+	app, redisClient, s := setupTest(t)
+	defer s.Close()
+
+	config := RateLimitConfig{
+		Limit:  1,
+		Window: 1 * time.Minute,
+	}
+
+	app.Use(APIKeyMiddleware(&mockAPIKeyService{}, redisClient, config))
+	app.Use(RateLimitMiddleware(redisClient, config))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(ClientCodeHeader, "client-A")
+	resp, _ := app.Test(req)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	req2 := httptest.NewRequest(http.MethodGet, "/", nil)
+	req2.Header.Set(ClientCodeHeader, "client-inventado")
+	resp2, _ := app.Test(req2)
+	assert.Equal(t, fiber.StatusTooManyRequests, resp2.StatusCode)
+}
+
+// Test 4c: Las API keys inválidas cuentan contra la IP; agotado el límite no se verifican más.
+func TestRateLimitMiddleware_CountsInvalidAPIKeysAgainstIP(t *testing.T) {
+	app, redisClient, s := setupTest(t)
+	defer s.Close()
+
+	config := RateLimitConfig{
+		Limit:  2,
+		Window: 1 * time.Minute,
+	}
+
+	app.Use(APIKeyMiddleware(&mockAPIKeyService{keys: map[string]*models.APIKey{
+		"fck_a": {ID: 1, UserID: 7, ClientCode: "client-A"},
+	}}, redisClient, config))
+	app.Use(RateLimitMiddleware(redisClient, config))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	invalid := httptest.NewRequest(http.MethodGet, "/", nil)
+	invalid.Header.Set(APIKeyHeader, "fck_adivinada")
+	invalid.Header.Set(ClientCodeHeader, "client-A")
+	for range 2 {
+		resp, _ := app.Test(invalid)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	}
+	resp, _ := app.Test(invalid)
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+
+	// Desde esa IP tampoco se verifica una clave válida hasta que venza la ventana.
+	valid := httptest.NewRequest(http.MethodGet, "/", nil)
+	valid.Header.Set(APIKeyHeader, "fck_a")
+	valid.Header.Set(ClientCodeHeader, "client-A")
+	resp, _ = app.Test(valid)
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+}
+
 // Test 5: El contador se reinicia después de que la ventana de tiempo expira.
 func TestRateLimitMiddleware_CounterResetsAfterWindow(t *testing.T) {
 	app, redisClient, s := setupTest(t)
@@ -190,8 +262,9 @@ func TestRateLimitMiddleware_HandlesRedisError(t *testing.T) {
 		return c.SendStatus(fiber.StatusOK)
 	})
 
+	// Sin límite de tiempo: el cliente de Redis reintenta la conexión antes de fallar.
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	resp, err := app.Test(req)
+	resp, err := app.Test(req, -1)
 
 	assert.NoError(t, err)
 	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
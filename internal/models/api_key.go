@@ -0,0 +1,72 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// APIKey es una clave de un cliente máquina a máquina. Actúa en nombre de su dueño (UserID),
+// pero solo con los permisos listados en Scopes, y solo presentada junto al X-Client-Code
+// ClientCode. De la clave se guarda el hash; Prefix es su comienzo, para reconocerla.
+type APIKey struct {
+	ID         uint64       `gorm:"primaryKey;autoIncrement"`
+	UserID     uint64       `gorm:"not null;index"`
+	Name       string       `gorm:"type:varchar(100);not null"`
+	ClientCode string       `gorm:"type:varchar(64);not null"`
+	Prefix     string       `gorm:"type:varchar(16);not null"`
+	KeyHash    string       `gorm:"type:varchar(64);unique;not null"`
+	Scopes     APIKeyScopes `gorm:"type:jsonb;not null"`
+	ExpiresAt  *time.Time   // nil: no vence
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+
+	CreatedAt time.Time
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Active indica si la clave se puede usar en el momento now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(now))
+}
+
+// APIKeyScopes son los nombres de permisos de la clave, serializados como JSON.
+type APIKeyScopes []string
+
+// Value implementa driver.Valuer.
+func (s APIKeyScopes) Value() (driver.Value, error) {
+	if s == nil {
+		s = APIKeyScopes{}
+	}
+	b, err := json.Marshal([]string(s))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implementa sql.Scanner.
+func (s *APIKeyScopes) Scan(value any) error {
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	case nil:
+		*s = APIKeyScopes{}
+		return nil
+	default:
+		return fmt.Errorf("APIKeyScopes: tipo no soportado %T", value)
+	}
+	var scopes []string
+	if err := json.Unmarshal(raw, &scopes); err != nil {
+		return err
+	}
+	*s = scopes
+	return nil
+}
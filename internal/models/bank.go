@@ -11,11 +11,11 @@ func (Bank) TableName() string {
 }
 
 type Bank struct {
-	ID         uint64         `gorm:"primaryKey" json:"id"`
-	Name       string         `gorm:"size:255;not null" json:"name" validate:"required,min=3,max=255"`
-	EntityCode string         `gorm:"size:50;not null;unique" json:"entity_code" validate:"required,alphanum,max=50"`
-	Enabled    bool           `gorm:"default:true" json:"enabled"`
+	ID         uint64         `gorm:"primaryKey" json:"id" filter:"number" sort:"true"`
+	Name       string         `gorm:"size:255;not null" json:"name" validate:"required,min=3,max=255" filter:"string,fuzzy" sort:"true" search:"A"`
+	EntityCode string         `gorm:"size:50;not null;unique" json:"entity_code" validate:"required,alphanum,max=50" filter:"string" sort:"true" search:"B"`
+	Enabled    bool           `gorm:"default:true" json:"enabled" filter:"bool" sort:"true"`
 	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-" filter:"date"`
-	CreatedAt  time.Time      `gorm:"index" json:"created_at" filter:"date"`
-	UpdatedAt  time.Time      `json:"updated_at" filter:"date"`
+	CreatedAt  time.Time      `gorm:"index" json:"created_at" filter:"date" sort:"true"`
+	UpdatedAt  time.Time      `json:"updated_at" filter:"date" sort:"true"`
 }
@@ -19,6 +19,9 @@ type Menu struct {
 	// Relación con usuarios (many-to-many a través de menu_user)
 	Users []User `json:"users,omitempty" gorm:"many2many:menu_user;joinForeignKey:MenuID;joinReferences:UserID"`
 
+	// Relación con roles (many-to-many a través de menu_role)
+	Roles []Role `json:"roles,omitempty" gorm:"many2many:menu_role;joinForeignKey:MenuID;joinReferences:RoleID"`
+
 	// Relación jerárquica (padres e hijos)
 	Children []Menu `json:"children,omitempty" gorm:"foreignKey:ParentID"`
 
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// MenuRole asigna un menú a un rol; lo ven todos los usuarios con el rol activo.
+type MenuRole struct {
+	MenuID    uint      `json:"menu_id" gorm:"primaryKey;column:menu_id"`
+	RoleID    uint64    `json:"role_id" gorm:"primaryKey;column:role_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (MenuRole) TableName() string {
+	return "menu_role"
+}
@@ -6,6 +6,8 @@ import (
 	"gorm.io/gorm"
 )
 
+// MenuUser es una excepción por usuario sobre los menús de sus roles:
+// IsActive = true otorga el menú y IsActive = false lo deniega.
 type MenuUser struct {
 	MenuID uint `json:"menu_id" gorm:"primaryKey;column:menu_id"`
 	UserID uint `json:"user_id" gorm:"primaryKey;column:user_id"`
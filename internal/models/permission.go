@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Permission es una acción autorizable (ej: "banks.delete"), asignada a roles vía role_permission.
+type Permission struct {
+	ID          uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string `gorm:"type:varchar(100);unique;not null" json:"name"`
+	Description string `gorm:"type:varchar(255)" json:"description"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+type RolePermission struct {
+	RoleID       uint64 `gorm:"primaryKey"`
+	PermissionID uint64 `gorm:"primaryKey"`
+}
+
+func (RolePermission) TableName() string {
+	return "role_permission"
+}
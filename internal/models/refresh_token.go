@@ -5,11 +5,21 @@ import (
 )
 
 // RefreshToken almacena los tokens de refresco para poder invalidarlos.
+//
+// Cada sesión (dispositivo) es una familia de tokens con el mismo SessionID (claim sid):
+// al refrescar, el token usado se marca como rotado y se crea uno nuevo en la familia.
+// Presentar otra vez un token rotado es un reuso y revoca la familia completa.
 type RefreshToken struct {
-	ID        uint      `gorm:"primarykey"`
-	UserID    uint64    `gorm:"not null;index"` // Coincide con el tipo de ID del usuario
-	Token     string    `gorm:"type:varchar(512);unique;not null"`
-	ExpiresAt time.Time `gorm:"not null"`
+	ID         uint       `gorm:"primarykey"`
+	UserID     uint64     `gorm:"not null;index"` // Coincide con el tipo de ID del usuario
+	SessionID  string     `gorm:"type:varchar(64);not null;index"`
+	Token      string     `gorm:"type:varchar(512);unique;not null"`
+	UserAgent  string     `gorm:"type:varchar(255)"`
+	IPAddress  string     `gorm:"type:varchar(45)"`
+	ExpiresAt  time.Time  `gorm:"not null"`
+	LastUsedAt time.Time  `gorm:"not null"`
+	RotatedAt  *time.Time // Se cambió por uno nuevo de la familia
+	RevokedAt  *time.Time // La sesión se cerró (logout, revocación o reuso)
 
 	User      User `gorm:"foreignKey:UserID"`
 	CreatedAt time.Time
@@ -7,15 +7,18 @@ import (
 )
 
 type Role struct {
-	ID       uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name     string `gorm:"type:varchar(100);unique;not null" json:"name"`
-	IsActive bool   `gorm:"not null;default:true" json:"is_active"`
+	ID       uint64 `gorm:"primaryKey;autoIncrement" json:"id" filter:"number" sort:"true"`
+	Name     string `gorm:"type:varchar(100);unique;not null" json:"name" filter:"string,fuzzy" sort:"true" search:"A"`
+	IsActive bool   `gorm:"not null;default:true" json:"is_active" filter:"bool" sort:"true"`
 
 	// Relación con usuarios (many-to-many a través de role_user)
 	Users []User `gorm:"many2many:role_user;joinForeignKey:RoleID;joinReferences:UserID" json:"users,omitempty"`
 
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	// Relación con permisos (many-to-many a través de role_permission)
+	Permissions []Permission `gorm:"many2many:role_permission;joinForeignKey:RoleID;joinReferences:PermissionID" json:"permissions,omitempty" include:"permissions"`
+
+	CreatedAt time.Time      `json:"created_at" filter:"date" sort:"true"`
+	UpdatedAt time.Time      `json:"updated_at" filter:"date" sort:"true"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"go-fiber-core/internal/dtos"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SavedView es un preset de filtros, orden y columnas de un listado paginado.
+// Pertenece a un usuario y puede compartirse con un rol; cada usuario puede marcar
+// una vista por defecto por recurso.
+type SavedView struct {
+	ID        uint64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint64      `gorm:"not null;index" json:"user_id"`
+	RoleID    *uint64     `gorm:"index" json:"role_id"` // Rol con el que se comparte (opcional)
+	Resource  string      `gorm:"type:varchar(50);not null" json:"resource"`
+	Name      string      `gorm:"type:varchar(100);not null" json:"name"`
+	Request   ViewRequest `gorm:"type:jsonb;not null" json:"request"`
+	IsDefault bool        `gorm:"not null;default:false" json:"is_default"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (SavedView) TableName() string {
+	return "saved_views"
+}
+
+// ViewRequest es el PaginationRequest guardado en la vista, serializado como JSON.
+type ViewRequest dtos.PaginationRequest
+
+// Value implementa driver.Valuer.
+func (r ViewRequest) Value() (driver.Value, error) {
+	b, err := json.Marshal(dtos.PaginationRequest(r))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implementa sql.Scanner.
+func (r *ViewRequest) Scan(value any) error {
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	case nil:
+		*r = ViewRequest{}
+		return nil
+	default:
+		return fmt.Errorf("ViewRequest: tipo no soportado %T", value)
+	}
+	var req dtos.PaginationRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return err
+	}
+	*r = ViewRequest(req)
+	return nil
+}
@@ -7,20 +7,24 @@ import (
 )
 
 type User struct {
-	ID       uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name     string `gorm:"type:varchar(100);not null" json:"name"`
-	Email    string `gorm:"type:varchar(255);unique;not null" json:"email"`
+	ID       uint64 `gorm:"primaryKey;autoIncrement" json:"id" filter:"number" sort:"true"`
+	Name     string `gorm:"type:varchar(100);not null" json:"name" filter:"string,fuzzy" sort:"true" search:"A"`
+	Email    string `gorm:"type:varchar(255);unique;not null" json:"email" filter:"string" sort:"true" search:"B"`
 	Password string `gorm:"type:text;not null" json:"-"`
-	IsActive bool   `gorm:"not null;default:true" json:"is_active"`
+	IsActive bool   `gorm:"not null;default:true" json:"is_active" filter:"bool" sort:"true"`
+
+	// EmailVerifiedAt es nil mientras el usuario no confirme su email.
+	EmailVerifiedAt *time.Time `json:"email_verified_at" filter:"date" sort:"true"`
 
 	// Relación con roles (many-to-many a través de role_user)
-	Roles []Role `gorm:"many2many:role_user;joinForeignKey:UserID;joinReferences:RoleID" json:"roles,omitempty"`
+	Roles []Role `gorm:"many2many:role_user;joinForeignKey:UserID;joinReferences:RoleID" json:"roles,omitempty" include:"roles"`
 
-	// Relación con menús (many-to-many a través de menu_user)
+	// Relación con las excepciones de menú (many-to-many a través de menu_user). Incluye
+	// denegaciones, por eso no se expone con include: el menú efectivo es GET /menus/my.
 	Menus []Menu `gorm:"many2many:menu_user;joinForeignKey:UserID;joinReferences:MenuID" json:"menus,omitempty"`
 
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	CreatedAt time.Time      `json:"created_at" filter:"date" sort:"true"`
+	UpdatedAt time.Time      `json:"updated_at" filter:"date" sort:"true"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Propósitos de UserToken.
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+)
+
+// UserToken es un token de un solo uso enviado por email. Solo se guarda su hash:
+// quien lee la base no puede usar los enlaces pendientes.
+type UserToken struct {
+	ID        uint64     `gorm:"primaryKey;autoIncrement"`
+	UserID    uint64     `gorm:"not null;index"`
+	Purpose   string     `gorm:"type:varchar(30);not null"`
+	TokenHash string     `gorm:"type:varchar(64);unique;not null"`
+	SentTo    string     `gorm:"type:varchar(255);not null"` // Email al que se envió el enlace
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // También se marca al invalidarlo por un token más nuevo
+
+	User      User `gorm:"foreignKey:UserID"`
+	CreatedAt time.Time
+}
+
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
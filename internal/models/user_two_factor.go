@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// UserTwoFactor es el segundo factor TOTP de un usuario. Está activo cuando ConfirmedAt
+// no es nil; antes de eso es una inscripción pendiente.
+type UserTwoFactor struct {
+	UserID          uint64     `gorm:"primaryKey"`
+	SecretEncrypted string     `gorm:"type:text;not null"`
+	ConfirmedAt     *time.Time // Primer código válido
+	LastUsedStep    int64      `gorm:"not null;default:0"` // Paso TOTP del último código aceptado (evita reusarlo)
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (UserTwoFactor) TableName() string {
+	return "user_two_factor"
+}
+
+// UserRecoveryCode es un código de recuperación de un solo uso (se guarda su hash).
+type UserRecoveryCode struct {
+	ID       uint64 `gorm:"primaryKey;autoIncrement"`
+	UserID   uint64 `gorm:"not null;index"`
+	CodeHash string `gorm:"type:varchar(64);not null"`
+	UsedAt   *time.Time
+
+	CreatedAt time.Time
+}
+
+func (UserRecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
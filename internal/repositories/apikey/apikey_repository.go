@@ -0,0 +1,94 @@
+package apikey
+
+import (
+	"context"
+	"go-fiber-core/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// --- INTERFACES SEGREGADAS POR ROL ---
+
+type APIKeyReader interface {
+	GetByHash(ctx context.Context, db *gorm.DB, keyHash string) (*models.APIKey, error)
+	// ListByUserID devuelve las claves del usuario (también las revocadas), de la más nueva a la más vieja.
+	ListByUserID(ctx context.Context, db *gorm.DB, userID uint64) ([]models.APIKey, error)
+	// ClientCodeOwnedByOther indica si otro usuario tiene una clave sin revocar con ese client_code.
+	ClientCodeOwnedByOther(ctx context.Context, db *gorm.DB, clientCode string, userID uint64) (bool, error)
+}
+type APIKeyWriter interface {
+	Create(ctx context.Context, db *gorm.DB, key *models.APIKey) error
+	// Revoke revoca la clave del usuario. Devuelve false si no existe o ya estaba revocada.
+	Revoke(ctx context.Context, db *gorm.DB, userID, id uint64, at time.Time) (bool, error)
+	// TouchLastUsed registra el uso si el anterior es previo a since (evita escribir en cada request).
+	TouchLastUsed(ctx context.Context, db *gorm.DB, id uint64, at, since time.Time) error
+}
+type APIKeyRepository interface {
+	APIKeyReader
+	APIKeyWriter
+}
+
+// --- STRUCTS Y CONSTRUCTORES GRANULARES ---
+
+type APIKeyReaderRepo struct{}
+
+func NewAPIKeyReaderRepo() APIKeyReader { return &APIKeyReaderRepo{} }
+
+type APIKeyWriterRepo struct{}
+
+func NewAPIKeyWriterRepo() APIKeyWriter { return &APIKeyWriterRepo{} }
+
+// --- STRUCT Y CONSTRUCTOR COMPUESTO ---
+
+type apiKeyRepository struct {
+	APIKeyReader
+	APIKeyWriter
+}
+
+func NewAPIKeyRepository(r APIKeyReader, w APIKeyWriter) APIKeyRepository {
+	return &apiKeyRepository{r, w}
+}
+
+// --- IMPLEMENTACIONES DE MÉTODOS ---
+
+func (r *APIKeyReaderRepo) GetByHash(ctx context.Context, db *gorm.DB, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+func (r *APIKeyReaderRepo) ListByUserID(ctx context.Context, db *gorm.DB, userID uint64) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Find(&keys).Error
+	return keys, err
+}
+func (r *APIKeyReaderRepo) ClientCodeOwnedByOther(ctx context.Context, db *gorm.DB, clientCode string, userID uint64) (bool, error) {
+	var count int64
+	err := db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("client_code = ? AND user_id <> ? AND revoked_at IS NULL", clientCode, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+func (r *APIKeyWriterRepo) Create(ctx context.Context, db *gorm.DB, key *models.APIKey) error {
+	return db.WithContext(ctx).Create(key).Error
+}
+func (r *APIKeyWriterRepo) Revoke(ctx context.Context, db *gorm.DB, userID, id uint64, at time.Time) (bool, error) {
+	result := db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+func (r *APIKeyWriterRepo) TouchLastUsed(ctx context.Context, db *gorm.DB, id uint64, at, since time.Time) error {
+	return db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, since).
+		Update("last_used_at", at).Error
+}
@@ -5,12 +5,14 @@ import (
 	"context"
 	"go-fiber-core/internal/dtos"
 	"go-fiber-core/internal/models"
+	"go-fiber-core/internal/services/pagination"
 
 	"gorm.io/gorm"
 )
 
 type BankReader interface {
 	GetByID(ctx context.Context, db *gorm.DB, id uint) (*models.Bank, error)
+	GetByIDWithModifier(ctx context.Context, db *gorm.DB, id uint, modifier pagination.QueryModifier) (*models.Bank, error)
 	GetAll(ctx context.Context, db *gorm.DB) ([]models.Bank, error)
 	// --- NUEVO MÉTODO AÑADIDO ---
 	// GetByRange obtiene todos los bancos cuyos IDs están dentro del rango especificado.
@@ -26,4 +28,6 @@ type BankWriter interface {
 
 type BankPagination interface {
 	GetAllPaginated(ctx context.Context, db *gorm.DB, req dtos.PaginationRequest) (*dtos.PaginationResponse[models.Bank], error)
+	// GetFilteredBatch devuelve un lote de bancos filtrados con ID mayor a lastProcessedID (para exportaciones).
+	GetFilteredBatch(ctx context.Context, db *gorm.DB, req dtos.PaginationRequest, batchSize int, lastProcessedID uint) ([]models.Bank, error)
 }
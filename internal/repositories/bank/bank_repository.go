@@ -62,6 +62,17 @@ func (r *BankReaderRepo) GetByID(ctx context.Context, db *gorm.DB, id uint) (*mo
 	return &bank, err
 }
 
+// GetByIDWithModifier obtiene un banco por ID aplicando el modifier (ej: fields).
+func (r *BankReaderRepo) GetByIDWithModifier(ctx context.Context, db *gorm.DB, id uint, modifier pagination.QueryModifier) (*models.Bank, error) {
+	var bank models.Bank
+	query := db.WithContext(ctx).Model(&models.Bank{})
+	if modifier != nil {
+		query = modifier(query)
+	}
+	err := query.First(&bank, id).Error
+	return &bank, err
+}
+
 func (r *BankReaderRepo) GetAll(ctx context.Context, db *gorm.DB) ([]models.Bank, error) {
 	var banks []models.Bank
 	err := db.WithContext(ctx).Find(&banks).Error
@@ -80,3 +91,7 @@ func (r *BankReaderRepo) GetByRange(ctx context.Context, db *gorm.DB, startID ui
 func (r *BankPaginationRepo) GetAllPaginated(ctx context.Context, db *gorm.DB, req dtos.PaginationRequest) (*dtos.PaginationResponse[models.Bank], error) {
 	return r.ps.Execute(db.WithContext(ctx), req, nil, nil)
 }
+
+func (r *BankPaginationRepo) GetFilteredBatch(ctx context.Context, db *gorm.DB, req dtos.PaginationRequest, batchSize int, lastProcessedID uint) ([]models.Bank, error) {
+	return r.ps.GetFilteredBatch(db.WithContext(ctx), req, nil, batchSize, lastProcessedID)
+}
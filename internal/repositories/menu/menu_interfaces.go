@@ -3,23 +3,44 @@ package menu
 import (
 	"context"
 	"go-fiber-core/internal/models"
+	"time"
 
 	"gorm.io/gorm"
 )
 
-//	type MenuWriter interface {
-//		Create(ctx context.Context, db *gorm.DB, menu *models.Menu) error
-//		SoftDelete(ctx context.Context, db *gorm.DB, id uint) error
-//		AddRoles(ctx context.Context, db *gorm.DB, menuID uint, roleIDs []uint64) error
-//		RemoveRoles(ctx context.Context, db *gorm.DB, menuID uint, roleIDs []uint64) error
-//		BulkAddRoles(ctx context.Context, db *gorm.DB, menuIDs []uint64, roleIDs []uint64) error
-//		BulkRemoveRoles(ctx context.Context, db *gorm.DB, menuIDs []uint64, roleIDs []uint64) error
-//	}
 type MenuWriter interface {
+	// AddBulkUsers otorga los menús a los usuarios (excepción is_active = true en menu_user).
 	AddBulkUsers(ctx context.Context, db *gorm.DB, menuIDs []uint64, userIDs []uint64) error
+	// DenyBulkUsers deniega los menús a los usuarios aunque sus roles los otorguen.
+	DenyBulkUsers(ctx context.Context, db *gorm.DB, menuIDs []uint64, userIDs []uint64) error
+	// BulkRemoveUsers quita las excepciones: el usuario vuelve a ver lo que otorgan sus roles.
 	BulkRemoveUsers(ctx context.Context, db *gorm.DB, menuIDs []uint64, userIDs []uint64) error
+	BulkAddRoles(ctx context.Context, db *gorm.DB, menuIDs []uint64, roleIDs []uint64) error
+	BulkRemoveRoles(ctx context.Context, db *gorm.DB, menuIDs []uint64, roleIDs []uint64) error
+
+	Create(ctx context.Context, db *gorm.DB, menu *models.Menu) error
+	// Update guarda todos los campos, incluido deleted_at (permite restaurar al importar).
+	Update(ctx context.Context, db *gorm.DB, menu *models.Menu) error
+	// SetDeletedAt borra lógicamente (at != nil) o restaura (at == nil) los menús indicados.
+	SetDeletedAt(ctx context.Context, db *gorm.DB, ids []uint, at *time.Time) error
+	// UpdatePosition cambia solo parent_id y order_index.
+	UpdatePosition(ctx context.Context, db *gorm.DB, id uint, parentID *uint, orderIndex int) error
 }
 
 type MenuReader interface {
+	// GetMenusByUserID devuelve el menú efectivo del usuario (roles + excepciones) y sus padres.
 	GetMenusByUserID(ctx context.Context, db *gorm.DB, userID uint64) ([]models.Menu, error)
+	// GetRolesByMenuID devuelve los roles a los que está asignado el menú.
+	GetRolesByMenuID(ctx context.Context, db *gorm.DB, menuID uint64) ([]models.Role, error)
+	// MissingIDs devuelve los IDs de menús que no existen (o están borrados).
+	MissingIDs(ctx context.Context, db *gorm.DB, ids []uint64) ([]uint64, error)
+
+	GetByID(ctx context.Context, db *gorm.DB, id uint) (*models.Menu, error)
+	// GetDeletedByID devuelve el menú solo si está borrado lógicamente.
+	GetDeletedByID(ctx context.Context, db *gorm.DB, id uint) (*models.Menu, error)
+	// GetAll devuelve la lista plana ordenada por order_index; withDeleted incluye los borrados.
+	GetAll(ctx context.Context, db *gorm.DB, withDeleted bool) ([]models.Menu, error)
+	// GetSiblingByName busca otro ítem con el mismo nombre (sin distinguir mayúsculas) bajo el
+	// mismo padre, incluidos los borrados: el índice único de la tabla también los cuenta.
+	GetSiblingByName(ctx context.Context, db *gorm.DB, parentID *uint, name string, excludeID uint) (*models.Menu, error)
 }
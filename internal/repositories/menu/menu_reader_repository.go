@@ -2,18 +2,13 @@ package menu
 
 import (
 	"context"
-	"errors"
 	"go-fiber-core/internal/dtos/connect" // Necesario para obtener la conexión DB
 	"go-fiber-core/internal/models"
+	"sort"
 
 	"gorm.io/gorm"
 )
 
-// MenuReaderRepository Interface (simplemente usa MenuReader)
-// type MenuReaderRepository interface {
-//     GetMenusByUserID(ctx context.Context, db *gorm.DB, userID uint64) ([]models.Menu, error)
-// }
-
 type menuReaderRepository struct {
 	db *gorm.DB
 }
@@ -24,47 +19,174 @@ func NewMenuReaderRepository(conn *connect.ConnectDTO) MenuReader {
 	return &menuReaderRepository{db: conn.ConnectGormRead}
 }
 
-// GetMenusByUserID implementa la lógica SQL para obtener la lista plana de menús
-// (ítems asignados y sus padres), filtrando por 'is_active' en la tabla pivote.
+// GetMenusByUserID devuelve la lista plana del menú efectivo del usuario:
+// (menús de sus roles activos ∪ menús otorgados en menu_user) − menús denegados en menu_user,
+// más los padres necesarios para armar el árbol. Se calcula en cada consulta, así que
+// refleja al instante los cambios de roles.
 func (r *menuReaderRepository) GetMenusByUserID(ctx context.Context, db *gorm.DB, userID uint64) ([]models.Menu, error) {
 	// Usamos el DB inyectado en la struct, no el pasado como argumento (si se inyecta con ConnectDTO)
 	db = r.db.WithContext(ctx)
 
-	// 1️⃣ SUB-CONSULTA: Obtener IDs de menús DIRECTAMENTE asignados y ACTIVOS
-	userMenuIDs := db.
-		Table("menu_user").
-		Select("menu_id").
-		Where("user_id = ?", userID).
-		Where("is_active = ?", true)
+	// 1️⃣ SUB-CONSULTA: menús de los roles activos del usuario
+	roleMenuIDs := db.
+		Table("menu_role").
+		Select("menu_role.menu_id").
+		Joins("JOIN role_user ON role_user.role_id = menu_role.role_id AND role_user.deleted_at IS NULL").
+		Joins("JOIN roles ON roles.id = role_user.role_id AND roles.is_active = ? AND roles.deleted_at IS NULL", true).
+		Where("role_user.user_id = ?", userID)
 
-	// 2️⃣ SUB-CONSULTA: Obtener IDs de los padres de esos menús activos
-	parentIDs := db.
-		Table("menus").
-		Select("parent_id").
-		Where("id IN (?)", userMenuIDs).
-		Where("parent_id IS NOT NULL")
+	// 2️⃣ SUB-CONSULTAS: excepciones del usuario (otorgados y denegados)
+	grantedIDs := userOverrides(db, userID, true)
+	deniedIDs := userOverrides(db, userID, false)
 
-	// 3️⃣ CONSULTA FINAL: Obtener menús que son: A) Asignados/Activos O B) Padres necesarios
+	// 3️⃣ CONSULTA: menús efectivos
 	var menus []models.Menu
-	err := db.
-		Table("menus").
-		Where("id IN (?) OR id IN (?)", userMenuIDs, parentIDs).
-		Where("menus.is_active = ?", true).
-		Where("menus.deleted_at IS NULL").
-		Order("menus.order_index ASC").
+	err := activeMenus(db).
+		Where("menus.id IN (?) OR menus.id IN (?)", roleMenuIDs, grantedIDs).
+		Where("menus.id NOT IN (?)", deniedIDs).
 		Find(&menus).Error
-
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return []models.Menu{}, nil
-		}
 		return nil, err
 	}
 
-	// Si no se encuentra nada, devolver lista vacía en lugar de un error.
-	if len(menus) == 0 {
-		return []models.Menu{}, nil
+	// 4️⃣ PADRES: se suben los niveles que falten. Un padre inactivo o denegado no se carga
+	// y su rama queda fuera del árbol.
+	loaded := make(map[uint]bool, len(menus))
+	for _, m := range menus {
+		loaded[m.ID] = true
+	}
+	pending := missingParents(menus, loaded)
+	for len(pending) > 0 {
+		var parents []models.Menu
+		err := activeMenus(db).
+			Where("menus.id IN ?", pending).
+			Where("menus.id NOT IN (?)", deniedIDs).
+			Find(&parents).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range parents {
+			loaded[m.ID] = true
+		}
+		menus = append(menus, parents...)
+		pending = missingParents(parents, loaded)
 	}
 
+	sortMenus(menus)
 	return menus, nil
 }
+
+func (r *menuReaderRepository) GetRolesByMenuID(ctx context.Context, db *gorm.DB, menuID uint64) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.conn(db).WithContext(ctx).
+		Joins("JOIN menu_role ON menu_role.role_id = roles.id").
+		Where("menu_role.menu_id = ?", menuID).
+		Order("roles.name").
+		Find(&roles).Error
+	return roles, err
+}
+
+func (r *menuReaderRepository) MissingIDs(ctx context.Context, db *gorm.DB, ids []uint64) ([]uint64, error) {
+	var found []uint64
+	if err := r.conn(db).WithContext(ctx).Model(&models.Menu{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return nil, err
+	}
+	exists := make(map[uint64]bool, len(found))
+	for _, id := range found {
+		exists[id] = true
+	}
+	var missing []uint64
+	for _, id := range ids {
+		if !exists[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+func (r *menuReaderRepository) GetByID(ctx context.Context, db *gorm.DB, id uint) (*models.Menu, error) {
+	var menu models.Menu
+	err := r.conn(db).WithContext(ctx).First(&menu, id).Error
+	return &menu, err
+}
+
+func (r *menuReaderRepository) GetDeletedByID(ctx context.Context, db *gorm.DB, id uint) (*models.Menu, error) {
+	var menu models.Menu
+	err := r.conn(db).WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL").
+		First(&menu, id).Error
+	return &menu, err
+}
+
+func (r *menuReaderRepository) GetAll(ctx context.Context, db *gorm.DB, withDeleted bool) ([]models.Menu, error) {
+	query := r.conn(db).WithContext(ctx)
+	if withDeleted {
+		query = query.Unscoped()
+	}
+	var menus []models.Menu
+	err := query.Order("order_index ASC, id ASC").Find(&menus).Error
+	return menus, err
+}
+
+func (r *menuReaderRepository) GetSiblingByName(ctx context.Context, db *gorm.DB, parentID *uint, name string, excludeID uint) (*models.Menu, error) {
+	query := r.conn(db).WithContext(ctx).
+		Unscoped().
+		Where("LOWER(item_name) = LOWER(?) AND id <> ?", name, excludeID)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	var menu models.Menu
+	err := query.First(&menu).Error
+	return &menu, err
+}
+
+// conn usa la conexión recibida (ej: una transacción) o, si es nil, la inyectada.
+func (r *menuReaderRepository) conn(db *gorm.DB) *gorm.DB {
+	if db != nil {
+		return db
+	}
+	return r.db
+}
+
+// userOverrides devuelve la sub-consulta de menús otorgados (true) o denegados (false) al usuario.
+func userOverrides(db *gorm.DB, userID uint64, isActive bool) *gorm.DB {
+	return db.
+		Table("menu_user").
+		Select("menu_id").
+		Where("user_id = ?", userID).
+		Where("is_active = ?", isActive).
+		Where("deleted_at IS NULL")
+}
+
+func activeMenus(db *gorm.DB) *gorm.DB {
+	return db.
+		Table("menus").
+		Where("menus.is_active = ?", true).
+		Where("menus.deleted_at IS NULL")
+}
+
+// missingParents devuelve los padres de menus que todavía no se cargaron.
+func missingParents(menus []models.Menu, loaded map[uint]bool) []uint {
+	var ids []uint
+	seen := make(map[uint]bool)
+	for _, m := range menus {
+		if m.ParentID != nil && !loaded[*m.ParentID] && !seen[*m.ParentID] {
+			seen[*m.ParentID] = true
+			ids = append(ids, *m.ParentID)
+		}
+	}
+	return ids
+}
+
+// sortMenus ordena por order_index (y por ID para que el orden sea estable).
+func sortMenus(menus []models.Menu) {
+	sort.SliceStable(menus, func(i, j int) bool {
+		if menus[i].OrderIndex != menus[j].OrderIndex {
+			return menus[i].OrderIndex < menus[j].OrderIndex
+		}
+		return menus[i].ID < menus[j].ID
+	})
+}
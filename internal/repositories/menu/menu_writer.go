@@ -3,6 +3,7 @@ package menu
 import (
 	"go-fiber-core/internal/dtos/connect"
 	"go-fiber-core/internal/models"
+	"time"
 
 	"context"
 	"gorm.io/gorm"
@@ -23,7 +24,21 @@ func (r *menuWriterRepository) AddBulkUsers(
 	menuIDs []uint64,
 	userIDs []uint64,
 ) error {
+	return upsertMenuUsers(ctx, db, menuIDs, userIDs, true)
+}
+
+func (r *menuWriterRepository) DenyBulkUsers(
+	ctx context.Context,
+	db *gorm.DB,
+	menuIDs []uint64,
+	userIDs []uint64,
+) error {
+	return upsertMenuUsers(ctx, db, menuIDs, userIDs, false)
+}
 
+// upsertMenuUsers crea o reemplaza las excepciones, reactivando las que se habían quitado
+// (borrado lógico) para no chocar con el índice único (menu_id, user_id).
+func upsertMenuUsers(ctx context.Context, db *gorm.DB, menuIDs, userIDs []uint64, isActive bool) error {
 	var relations []models.MenuUser
 
 	for _, mid := range menuIDs {
@@ -31,13 +46,20 @@ func (r *menuWriterRepository) AddBulkUsers(
 			relations = append(relations, models.MenuUser{
 				MenuID:   uint(mid),
 				UserID:   uint(uid),
-				IsActive: true,
+				IsActive: isActive,
 			})
 		}
 	}
 
 	return db.WithContext(ctx).
-		Clauses(clause.OnConflict{DoNothing: true}). // evita duplicados
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "menu_id"}, {Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"is_active":  isActive,
+				"deleted_at": nil,
+				"updated_at": time.Now(),
+			}),
+		}).
 		Create(&relations).Error
 }
 
@@ -53,3 +75,64 @@ func (r *menuWriterRepository) BulkRemoveUsers(
 		Delete(&models.MenuUser{}).
 		Error
 }
+
+func (r *menuWriterRepository) BulkAddRoles(
+	ctx context.Context,
+	db *gorm.DB,
+	menuIDs []uint64,
+	roleIDs []uint64,
+) error {
+
+	var relations []models.MenuRole
+
+	for _, mid := range menuIDs {
+		for _, rid := range roleIDs {
+			relations = append(relations, models.MenuRole{
+				MenuID: uint(mid),
+				RoleID: rid,
+			})
+		}
+	}
+
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}). // evita duplicados
+		Create(&relations).Error
+}
+
+func (r *menuWriterRepository) BulkRemoveRoles(
+	ctx context.Context,
+	db *gorm.DB,
+	menuIDs []uint64,
+	roleIDs []uint64,
+) error {
+
+	return db.WithContext(ctx).
+		Where("menu_id IN ? AND role_id IN ?", menuIDs, roleIDs).
+		Delete(&models.MenuRole{}).
+		Error
+}
+
+func (r *menuWriterRepository) Create(ctx context.Context, db *gorm.DB, menu *models.Menu) error {
+	return db.WithContext(ctx).Omit(clause.Associations).Create(menu).Error
+}
+
+func (r *menuWriterRepository) Update(ctx context.Context, db *gorm.DB, menu *models.Menu) error {
+	return db.WithContext(ctx).Unscoped().Omit(clause.Associations).Save(menu).Error
+}
+
+func (r *menuWriterRepository) SetDeletedAt(ctx context.Context, db *gorm.DB, ids []uint, at *time.Time) error {
+	return db.WithContext(ctx).
+		Unscoped().
+		Model(&models.Menu{}).
+		Where("id IN ?", ids).
+		UpdateColumns(map[string]any{"deleted_at": at, "updated_at": time.Now()}).
+		Error
+}
+
+func (r *menuWriterRepository) UpdatePosition(ctx context.Context, db *gorm.DB, id uint, parentID *uint, orderIndex int) error {
+	return db.WithContext(ctx).
+		Model(&models.Menu{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]any{"parent_id": parentID, "order_index": orderIndex, "updated_at": time.Now()}).
+		Error
+}
@@ -0,0 +1,78 @@
+package permission
+
+import (
+	"context"
+	"go-fiber-core/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PermissionReader interface {
+	// GetNamesByUserID devuelve los permisos de los roles activos del usuario (sin duplicados).
+	GetNamesByUserID(ctx context.Context, db *gorm.DB, userID uint64) ([]string, error)
+	GetAll(ctx context.Context, db *gorm.DB) ([]models.Permission, error)
+	// GetNamesByRoleIDs devuelve los permisos que otorgan los roles indicados (sin duplicados).
+	GetNamesByRoleIDs(ctx context.Context, db *gorm.DB, roleIDs []uint64) ([]string, error)
+	// GetNamesByIDs devuelve los nombres de los permisos indicados.
+	GetNamesByIDs(ctx context.Context, db *gorm.DB, ids []uint64) ([]string, error)
+	// MissingIDs devuelve los IDs de permisos que no existen (o están borrados).
+	MissingIDs(ctx context.Context, db *gorm.DB, ids []uint64) ([]uint64, error)
+}
+
+type PermissionReaderRepo struct{}
+
+func NewPermissionReaderRepo() PermissionReader { return &PermissionReaderRepo{} }
+
+func (r *PermissionReaderRepo) GetNamesByUserID(ctx context.Context, db *gorm.DB, userID uint64) ([]string, error) {
+	var names []string
+	err := db.WithContext(ctx).
+		Table("permissions").
+		Distinct("permissions.name").
+		Joins("JOIN role_permission ON role_permission.permission_id = permissions.id").
+		Joins("JOIN role_user ON role_user.role_id = role_permission.role_id AND role_user.deleted_at IS NULL").
+		Joins("JOIN roles ON roles.id = role_user.role_id AND roles.is_active AND roles.deleted_at IS NULL").
+		Where("role_user.user_id = ? AND permissions.deleted_at IS NULL", userID).
+		Pluck("permissions.name", &names).Error
+	return names, err
+}
+
+func (r *PermissionReaderRepo) GetAll(ctx context.Context, db *gorm.DB) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := db.WithContext(ctx).Order("name").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *PermissionReaderRepo) GetNamesByRoleIDs(ctx context.Context, db *gorm.DB, roleIDs []uint64) ([]string, error) {
+	var names []string
+	err := db.WithContext(ctx).
+		Table("permissions").
+		Distinct("permissions.name").
+		Joins("JOIN role_permission ON role_permission.permission_id = permissions.id").
+		Where("role_permission.role_id IN ? AND permissions.deleted_at IS NULL", roleIDs).
+		Pluck("permissions.name", &names).Error
+	return names, err
+}
+
+func (r *PermissionReaderRepo) GetNamesByIDs(ctx context.Context, db *gorm.DB, ids []uint64) ([]string, error) {
+	var names []string
+	err := db.WithContext(ctx).Model(&models.Permission{}).Where("id IN ?", ids).Pluck("name", &names).Error
+	return names, err
+}
+
+func (r *PermissionReaderRepo) MissingIDs(ctx context.Context, db *gorm.DB, ids []uint64) ([]uint64, error) {
+	var found []uint64
+	if err := db.WithContext(ctx).Model(&models.Permission{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return nil, err
+	}
+	exists := make(map[uint64]bool, len(found))
+	for _, id := range found {
+		exists[id] = true
+	}
+	var missing []uint64
+	for _, id := range ids {
+		if !exists[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
@@ -3,6 +3,7 @@ package refreshtoken
 import (
 	"context"
 	"go-fiber-core/internal/models"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,10 +12,20 @@ import (
 
 type RefreshTokenReader interface {
 	GetByToken(ctx context.Context, db *gorm.DB, token string) (*models.RefreshToken, error)
+	// GetActiveSessions devuelve el token vigente de cada sesión abierta del usuario
+	// (sin rotar, sin revocar y sin vencer), del uso más reciente al más antiguo.
+	GetActiveSessions(ctx context.Context, db *gorm.DB, userID uint64) ([]models.RefreshToken, error)
 }
 type RefreshTokenWriter interface {
 	Create(ctx context.Context, db *gorm.DB, token *models.RefreshToken) error
 	DeleteByUserID(ctx context.Context, db *gorm.DB, userID uint64) error
+	// MarkRotated marca el token como usado si todavía estaba vigente. Devuelve false si
+	// ya había sido rotado o revocado (otra solicitud lo usó antes: es un reuso).
+	MarkRotated(ctx context.Context, db *gorm.DB, id uint, at time.Time) (bool, error)
+	// RevokeSession revoca todos los tokens de la sesión (familia) y devuelve cuántos
+	// seguían vigentes.
+	RevokeSession(ctx context.Context, db *gorm.DB, userID uint64, sessionID string, at time.Time) (int64, error)
+	RevokeByUserID(ctx context.Context, db *gorm.DB, userID uint64, at time.Time) error
 }
 type RefreshTokenRepository interface {
 	RefreshTokenReader
@@ -58,3 +69,31 @@ func (r *RefreshTokenReaderRepo) GetByToken(ctx context.Context, db *gorm.DB, to
 	}
 	return &refreshToken, nil
 }
+func (r *RefreshTokenReaderRepo) GetActiveSessions(ctx context.Context, db *gorm.DB, userID uint64) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	err := db.WithContext(ctx).
+		Where("user_id = ? AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+func (r *RefreshTokenWriterRepo) MarkRotated(ctx context.Context, db *gorm.DB, id uint, at time.Time) (bool, error) {
+	result := db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", id).
+		Updates(map[string]any{"rotated_at": at, "last_used_at": at})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+func (r *RefreshTokenWriterRepo) RevokeSession(ctx context.Context, db *gorm.DB, userID uint64, sessionID string, at time.Time) (int64, error) {
+	result := db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND session_id = ? AND revoked_at IS NULL", userID, sessionID).
+		Update("revoked_at", at)
+	return result.RowsAffected, result.Error
+}
+func (r *RefreshTokenWriterRepo) RevokeByUserID(ctx context.Context, db *gorm.DB, userID uint64, at time.Time) error {
+	return db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
@@ -0,0 +1,39 @@
+package role
+
+import (
+	"context"
+	"go-fiber-core/internal/dtos"
+	"go-fiber-core/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RoleReader interface {
+	// GetByID devuelve el rol con sus permisos.
+	GetByID(ctx context.Context, db *gorm.DB, id uint64) (*models.Role, error)
+	GetAll(ctx context.Context, db *gorm.DB) ([]models.Role, error)
+	// ExistsByName busca también entre los roles borrados: el nombre es único en la tabla.
+	ExistsByName(ctx context.Context, db *gorm.DB, name string, excludeID uint64) (bool, error)
+	// MissingIDs devuelve los IDs de roles que no existen (o están borrados).
+	MissingIDs(ctx context.Context, db *gorm.DB, ids []uint64) ([]uint64, error)
+	// GetByNames devuelve los roles activos cuyos nombres coinciden sin distinguir mayúsculas,
+	// con sus permisos.
+	GetByNames(ctx context.Context, db *gorm.DB, names []string) ([]models.Role, error)
+	// MissingUserIDs devuelve los IDs de usuarios que no existen (o están borrados).
+	MissingUserIDs(ctx context.Context, db *gorm.DB, ids []uint64) ([]uint64, error)
+}
+
+type RoleWriter interface {
+	Create(ctx context.Context, db *gorm.DB, role *models.Role) error
+	Update(ctx context.Context, db *gorm.DB, role *models.Role) error
+	SoftDelete(ctx context.Context, db *gorm.DB, id uint64) error
+	// AddBulkUsers asigna cada rol a cada usuario; las asignaciones existentes se ignoran.
+	AddBulkUsers(ctx context.Context, db *gorm.DB, roleIDs, userIDs []uint64) error
+	BulkRemoveUsers(ctx context.Context, db *gorm.DB, roleIDs, userIDs []uint64) error
+	// ReplacePermissions deja al rol exactamente con los permisos indicados.
+	ReplacePermissions(ctx context.Context, db *gorm.DB, roleID uint64, permissionIDs []uint64) error
+}
+
+type RolePagination interface {
+	GetAllPaginated(ctx context.Context, db *gorm.DB, req dtos.PaginationRequest) (*dtos.PaginationResponse[models.Role], error)
+}
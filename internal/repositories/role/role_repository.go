@@ -0,0 +1,141 @@
+package role
+
+import (
+	"context"
+	"go-fiber-core/internal/dtos"
+	"go-fiber-core/internal/models"
+	"go-fiber-core/internal/services/pagination"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleReaderRepo struct{}
+
+func NewRoleReaderRepo() RoleReader { return &RoleReaderRepo{} }
+
+type RoleWriterRepo struct{}
+
+func NewRoleWriterRepo() RoleWriter { return &RoleWriterRepo{} }
+
+type RolePaginationRepo struct {
+	ps *pagination.PaginationService[models.Role]
+}
+
+func NewRolePaginationRepo(ps *pagination.PaginationService[models.Role]) RolePagination {
+	return &RolePaginationRepo{ps: ps}
+}
+
+// Reader
+func (r *RoleReaderRepo) GetByID(ctx context.Context, db *gorm.DB, id uint64) (*models.Role, error) {
+	var role models.Role
+	err := db.WithContext(ctx).
+		Preload("Permissions", func(db *gorm.DB) *gorm.DB { return db.Order("permissions.name") }).
+		First(&role, id).Error
+	return &role, err
+}
+
+func (r *RoleReaderRepo) GetAll(ctx context.Context, db *gorm.DB) ([]models.Role, error) {
+	var roles []models.Role
+	err := db.WithContext(ctx).Order("name").Find(&roles).Error
+	return roles, err
+}
+
+func (r *RoleReaderRepo) ExistsByName(ctx context.Context, db *gorm.DB, name string, excludeID uint64) (bool, error) {
+	var count int64
+	err := db.WithContext(ctx).
+		Unscoped().
+		Model(&models.Role{}).
+		Where("LOWER(name) = LOWER(?) AND id <> ?", name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *RoleReaderRepo) MissingIDs(ctx context.Context, db *gorm.DB, ids []uint64) ([]uint64, error) {
+	return missingIDs(ctx, db, &models.Role{}, ids)
+}
+
+func (r *RoleReaderRepo) GetByNames(ctx context.Context, db *gorm.DB, names []string) ([]models.Role, error) {
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+	var roles []models.Role
+	err := db.WithContext(ctx).Preload("Permissions").Where("LOWER(name) IN ?", lowered).Find(&roles).Error
+	return roles, err
+}
+
+func (r *RoleReaderRepo) MissingUserIDs(ctx context.Context, db *gorm.DB, ids []uint64) ([]uint64, error) {
+	return missingIDs(ctx, db, &models.User{}, ids)
+}
+
+// missingIDs compara los IDs pedidos con los que existen en la tabla del modelo.
+func missingIDs(ctx context.Context, db *gorm.DB, model any, ids []uint64) ([]uint64, error) {
+	var found []uint64
+	if err := db.WithContext(ctx).Model(model).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return nil, err
+	}
+	exists := make(map[uint64]bool, len(found))
+	for _, id := range found {
+		exists[id] = true
+	}
+	var missing []uint64
+	for _, id := range ids {
+		if !exists[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// Writer
+func (r *RoleWriterRepo) Create(ctx context.Context, db *gorm.DB, role *models.Role) error {
+	return db.WithContext(ctx).Omit(clause.Associations).Create(role).Error
+}
+
+func (r *RoleWriterRepo) Update(ctx context.Context, db *gorm.DB, role *models.Role) error {
+	return db.WithContext(ctx).Omit(clause.Associations).Save(role).Error
+}
+
+func (r *RoleWriterRepo) SoftDelete(ctx context.Context, db *gorm.DB, id uint64) error {
+	return db.WithContext(ctx).Delete(&models.Role{}, id).Error
+}
+
+func (r *RoleWriterRepo) AddBulkUsers(ctx context.Context, db *gorm.DB, roleIDs, userIDs []uint64) error {
+	relations := make([]models.RoleUser, 0, len(roleIDs)*len(userIDs))
+	for _, rid := range roleIDs {
+		for _, uid := range userIDs {
+			relations = append(relations, models.RoleUser{RoleID: rid, UserID: uid})
+		}
+	}
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}). // evita duplicados
+		Create(&relations).Error
+}
+
+func (r *RoleWriterRepo) BulkRemoveUsers(ctx context.Context, db *gorm.DB, roleIDs, userIDs []uint64) error {
+	return db.WithContext(ctx).
+		Where("role_id IN ? AND user_id IN ?", roleIDs, userIDs).
+		Delete(&models.RoleUser{}).Error
+}
+
+func (r *RoleWriterRepo) ReplacePermissions(ctx context.Context, db *gorm.DB, roleID uint64, permissionIDs []uint64) error {
+	db = db.WithContext(ctx)
+	if err := db.Where("role_id = ?", roleID).Delete(&models.RolePermission{}).Error; err != nil {
+		return err
+	}
+	if len(permissionIDs) == 0 {
+		return nil
+	}
+	relations := make([]models.RolePermission, len(permissionIDs))
+	for i, pid := range permissionIDs {
+		relations[i] = models.RolePermission{RoleID: roleID, PermissionID: pid}
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&relations).Error
+}
+
+// Pagination
+func (r *RolePaginationRepo) GetAllPaginated(ctx context.Context, db *gorm.DB, req dtos.PaginationRequest) (*dtos.PaginationResponse[models.Role], error) {
+	return r.ps.Execute(db.WithContext(ctx), req, nil, nil)
+}
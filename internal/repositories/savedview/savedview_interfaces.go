@@ -0,0 +1,28 @@
+package savedview
+
+import (
+	"context"
+	"go-fiber-core/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type SavedViewReader interface {
+	// GetVisibleByID devuelve la vista si es del usuario o está compartida con alguno de sus roles.
+	GetVisibleByID(ctx context.Context, db *gorm.DB, id, userID uint64) (*models.SavedView, error)
+	// ListVisible devuelve las vistas propias y compartidas del recurso (vacío = todos).
+	ListVisible(ctx context.Context, db *gorm.DB, userID uint64, resource string) ([]models.SavedView, error)
+	// NameTaken indica si el usuario ya tiene otra vista del recurso con ese nombre (sin
+	// distinguir mayúsculas), la misma regla que el índice único de saved_views.
+	NameTaken(ctx context.Context, db *gorm.DB, userID uint64, resource, name string, excludeID uint64) (bool, error)
+	// UserHasRole indica si el usuario tiene el rol vigente (solo se comparte con roles propios).
+	UserHasRole(ctx context.Context, db *gorm.DB, userID, roleID uint64) (bool, error)
+}
+
+type SavedViewWriter interface {
+	Create(ctx context.Context, db *gorm.DB, view *models.SavedView) error
+	Update(ctx context.Context, db *gorm.DB, view *models.SavedView) error
+	SoftDelete(ctx context.Context, db *gorm.DB, id uint64) error
+	// ClearDefault quita la marca de vista por defecto del usuario en el recurso.
+	ClearDefault(ctx context.Context, db *gorm.DB, userID uint64, resource string) error
+}
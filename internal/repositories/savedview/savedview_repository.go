@@ -0,0 +1,94 @@
+package savedview
+
+import (
+	"context"
+	"go-fiber-core/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SavedViewReaderRepo struct{}
+
+func NewSavedViewReaderRepo() SavedViewReader { return &SavedViewReaderRepo{} }
+
+type SavedViewWriterRepo struct{}
+
+func NewSavedViewWriterRepo() SavedViewWriter { return &SavedViewWriterRepo{} }
+
+// userRoles son los roles vigentes del usuario, con el mismo criterio que permisos y menús:
+// la asignación no está borrada y el rol está activo y no borrado.
+func userRoles(db *gorm.DB, userID uint64) *gorm.DB {
+	return db.Table("role_user").
+		Joins("JOIN roles ON roles.id = role_user.role_id AND roles.is_active AND roles.deleted_at IS NULL").
+		Where("role_user.user_id = ? AND role_user.deleted_at IS NULL", userID)
+}
+
+// visibleTo filtra las vistas del usuario y las compartidas con sus roles vigentes.
+func visibleTo(db *gorm.DB, userID uint64) *gorm.DB {
+	return db.Where(
+		"saved_views.user_id = ? OR saved_views.role_id IN (?)",
+		userID, userRoles(db.Session(&gorm.Session{NewDB: true}), userID).Select("role_user.role_id"),
+	)
+}
+
+// Reader
+func (r *SavedViewReaderRepo) GetVisibleByID(ctx context.Context, db *gorm.DB, id, userID uint64) (*models.SavedView, error) {
+	var view models.SavedView
+	err := visibleTo(db.WithContext(ctx), userID).First(&view, id).Error
+	return &view, err
+}
+
+func (r *SavedViewReaderRepo) ListVisible(ctx context.Context, db *gorm.DB, userID uint64, resource string) ([]models.SavedView, error) {
+	var views []models.SavedView
+	query := visibleTo(db.WithContext(ctx), userID)
+	if resource != "" {
+		query = query.Where("saved_views.resource = ?", resource)
+	}
+	// Primero la vista por defecto del usuario, luego las propias y las compartidas por nombre.
+	err := query.
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "(saved_views.is_default AND saved_views.user_id = ?) DESC, saved_views.name ASC",
+			Vars:               []any{userID},
+			WithoutParentheses: true,
+		}}).
+		Find(&views).Error
+	return views, err
+}
+
+func (r *SavedViewReaderRepo) NameTaken(ctx context.Context, db *gorm.DB, userID uint64, resource, name string, excludeID uint64) (bool, error) {
+	var count int64
+	err := db.WithContext(ctx).
+		Model(&models.SavedView{}).
+		Where("user_id = ? AND resource = ? AND LOWER(name) = LOWER(?) AND id <> ?", userID, resource, name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *SavedViewReaderRepo) UserHasRole(ctx context.Context, db *gorm.DB, userID, roleID uint64) (bool, error) {
+	var count int64
+	err := userRoles(db.WithContext(ctx), userID).
+		Where("role_user.role_id = ?", roleID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Writer
+func (r *SavedViewWriterRepo) Create(ctx context.Context, db *gorm.DB, view *models.SavedView) error {
+	return db.WithContext(ctx).Create(view).Error
+}
+
+func (r *SavedViewWriterRepo) Update(ctx context.Context, db *gorm.DB, view *models.SavedView) error {
+	return db.WithContext(ctx).Save(view).Error
+}
+
+func (r *SavedViewWriterRepo) SoftDelete(ctx context.Context, db *gorm.DB, id uint64) error {
+	return db.WithContext(ctx).Delete(&models.SavedView{}, id).Error
+}
+
+func (r *SavedViewWriterRepo) ClearDefault(ctx context.Context, db *gorm.DB, userID uint64, resource string) error {
+	return db.WithContext(ctx).
+		Model(&models.SavedView{}).
+		Where("user_id = ? AND resource = ? AND is_default", userID, resource).
+		Update("is_default", false).Error
+}
@@ -0,0 +1,124 @@
+package twofactor
+
+import (
+	"context"
+	"go-fiber-core/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- INTERFACES SEGREGADAS POR ROL ---
+
+type TwoFactorReader interface {
+	GetByUserID(ctx context.Context, db *gorm.DB, userID uint64) (*models.UserTwoFactor, error)
+	// CountUnusedRecoveryCodes devuelve cuántos códigos de recuperación le quedan al usuario.
+	CountUnusedRecoveryCodes(ctx context.Context, db *gorm.DB, userID uint64) (int64, error)
+}
+type TwoFactorWriter interface {
+	// Save crea o reemplaza el segundo factor del usuario.
+	Save(ctx context.Context, db *gorm.DB, twoFactor *models.UserTwoFactor) error
+	Confirm(ctx context.Context, db *gorm.DB, userID uint64, step int64, at time.Time) error
+	// UseStep registra el paso TOTP aceptado. Devuelve false si ya se usó ese paso o uno
+	// posterior (el mismo código presentado dos veces).
+	UseStep(ctx context.Context, db *gorm.DB, userID uint64, step int64) (bool, error)
+	// Delete quita el segundo factor y los códigos de recuperación.
+	Delete(ctx context.Context, db *gorm.DB, userID uint64) error
+	// ReplaceRecoveryCodes borra los códigos anteriores y guarda los nuevos hashes.
+	ReplaceRecoveryCodes(ctx context.Context, db *gorm.DB, userID uint64, codeHashes []string) error
+	// UseRecoveryCode consume el código si existe y no se usó. Devuelve false si no.
+	UseRecoveryCode(ctx context.Context, db *gorm.DB, userID uint64, codeHash string, at time.Time) (bool, error)
+}
+type TwoFactorRepository interface {
+	TwoFactorReader
+	TwoFactorWriter
+}
+
+// --- STRUCTS Y CONSTRUCTORES GRANULARES ---
+
+type TwoFactorReaderRepo struct{}
+
+func NewTwoFactorReaderRepo() TwoFactorReader { return &TwoFactorReaderRepo{} }
+
+type TwoFactorWriterRepo struct{}
+
+func NewTwoFactorWriterRepo() TwoFactorWriter { return &TwoFactorWriterRepo{} }
+
+// --- STRUCT Y CONSTRUCTOR COMPUESTO ---
+
+type twoFactorRepository struct {
+	TwoFactorReader
+	TwoFactorWriter
+}
+
+func NewTwoFactorRepository(r TwoFactorReader, w TwoFactorWriter) TwoFactorRepository {
+	return &twoFactorRepository{r, w}
+}
+
+// --- IMPLEMENTACIONES DE MÉTODOS ---
+
+func (r *TwoFactorReaderRepo) GetByUserID(ctx context.Context, db *gorm.DB, userID uint64) (*models.UserTwoFactor, error) {
+	var twoFactor models.UserTwoFactor
+	err := db.WithContext(ctx).Where("user_id = ?", userID).First(&twoFactor).Error
+	if err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+func (r *TwoFactorReaderRepo) CountUnusedRecoveryCodes(ctx context.Context, db *gorm.DB, userID uint64) (int64, error) {
+	var count int64
+	err := db.WithContext(ctx).Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+func (r *TwoFactorWriterRepo) Save(ctx context.Context, db *gorm.DB, twoFactor *models.UserTwoFactor) error {
+	return db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret_encrypted", "confirmed_at", "last_used_step", "updated_at"}),
+	}).Create(twoFactor).Error
+}
+func (r *TwoFactorWriterRepo) Confirm(ctx context.Context, db *gorm.DB, userID uint64, step int64, at time.Time) error {
+	return db.WithContext(ctx).Model(&models.UserTwoFactor{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{"confirmed_at": at, "last_used_step": step}).Error
+}
+func (r *TwoFactorWriterRepo) UseStep(ctx context.Context, db *gorm.DB, userID uint64, step int64) (bool, error) {
+	result := db.WithContext(ctx).Model(&models.UserTwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+func (r *TwoFactorWriterRepo) Delete(ctx context.Context, db *gorm.DB, userID uint64) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error
+	})
+}
+func (r *TwoFactorWriterRepo) ReplaceRecoveryCodes(ctx context.Context, db *gorm.DB, userID uint64, codeHashes []string) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.UserRecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, models.UserRecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+func (r *TwoFactorWriterRepo) UseRecoveryCode(ctx context.Context, db *gorm.DB, userID uint64, codeHash string, at time.Time) (bool, error) {
+	result := db.WithContext(ctx).Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
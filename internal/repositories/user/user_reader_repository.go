@@ -7,6 +7,7 @@ import (
 	"go-fiber-core/internal/dtos"
 	"go-fiber-core/internal/models"
 	"go-fiber-core/internal/services/pagination"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -15,10 +16,15 @@ import (
 
 type UserReader interface {
 	GetByID(ctx context.Context, db *gorm.DB, id uint64) (*models.User, error)
+	// GetByIDWithModifier no precarga relaciones: las agrega el modifier (ej: fields/include).
+	GetByIDWithModifier(ctx context.Context, db *gorm.DB, id uint64, modifier pagination.QueryModifier) (*models.User, error)
 	GetByEmail(ctx context.Context, db *gorm.DB, email string) (*models.User, error)
 	GetByEmailWithRolesAndMenus(ctx context.Context, db *gorm.DB, email string) (*models.User, error)
 	GetByEmailWithRoles(ctx context.Context, db *gorm.DB, email string) (*models.User, error)
 	GetAll(ctx context.Context, db *gorm.DB) ([]models.User, error)
+	// ExistingEmails devuelve (en minúsculas) los emails ya registrados, incluidos los de
+	// usuarios borrados: el email es único en toda la tabla.
+	ExistingEmails(ctx context.Context, db *gorm.DB, emails []string) ([]string, error)
 }
 
 type UserWriter interface {
@@ -30,6 +36,7 @@ type UserWriter interface {
 
 type UserPaginator interface {
 	GetAllPaginated(ctx context.Context, db *gorm.DB, req dtos.PaginationRequest) (*dtos.PaginationResponse[models.User], error)
+	GetFilteredBatch(ctx context.Context, db *gorm.DB, req dtos.PaginationRequest, batchSize int, lastProcessedID uint) ([]models.User, error)
 }
 
 // --- INTERFAZ COMPUESTA (Para la API) ---
@@ -104,6 +111,17 @@ func (r *UserReaderRepo) GetByID(ctx context.Context, db *gorm.DB, id uint64) (*
 	return &user, err
 }
 
+// GetByIDWithModifier obtiene un usuario por ID aplicando el modifier (Select/Preload).
+func (r *UserReaderRepo) GetByIDWithModifier(ctx context.Context, db *gorm.DB, id uint64, modifier pagination.QueryModifier) (*models.User, error) {
+	var user models.User
+	query := db.WithContext(ctx).Model(&models.User{})
+	if modifier != nil {
+		query = modifier(query)
+	}
+	err := query.First(&user, id).Error
+	return &user, err
+}
+
 // GetByEmail obtiene un usuario por email con sus roles
 // Este método es usado para autenticación rápida (sin cargar menús)
 func (r *UserReaderRepo) GetByEmail(ctx context.Context, db *gorm.DB, email string) (*models.User, error) {
@@ -149,11 +167,29 @@ func (r *UserReaderRepo) GetAll(ctx context.Context, db *gorm.DB) ([]models.User
 	return users, err
 }
 
+func (r *UserReaderRepo) ExistingEmails(ctx context.Context, db *gorm.DB, emails []string) ([]string, error) {
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+	var found []string
+	err := db.WithContext(ctx).
+		Unscoped().
+		Model(&models.User{}).
+		Where("LOWER(email) IN ?", lowered).
+		Pluck("LOWER(email)", &found).Error
+	return found, err
+}
+
 // Métodos para UserPaginatorRepo
 func (r *UserPaginatorRepo) GetAllPaginated(ctx context.Context, db *gorm.DB, req dtos.PaginationRequest) (*dtos.PaginationResponse[models.User], error) {
 	return r.ps.Execute(db.WithContext(ctx), req, nil, nil)
 }
 
+func (r *UserPaginatorRepo) GetFilteredBatch(ctx context.Context, db *gorm.DB, req dtos.PaginationRequest, batchSize int, lastProcessedID uint) ([]models.User, error) {
+	return r.ps.GetFilteredBatch(db.WithContext(ctx), req, nil, batchSize, lastProcessedID)
+}
+
 func (r *UserReaderRepo) GetByEmailWithRoles(ctx context.Context, db *gorm.DB, email string) (*models.User, error) {
 	var user models.User
 
@@ -0,0 +1,76 @@
+package usertoken
+
+import (
+	"context"
+	"go-fiber-core/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// --- INTERFACES SEGREGADAS POR ROL ---
+
+type UserTokenReader interface {
+	// GetByHash busca el token por su hash y propósito, aunque esté usado o vencido.
+	GetByHash(ctx context.Context, db *gorm.DB, purpose, tokenHash string) (*models.UserToken, error)
+}
+type UserTokenWriter interface {
+	Create(ctx context.Context, db *gorm.DB, token *models.UserToken) error
+	// MarkUsed consume el token si seguía sin usar. Devuelve false si otra solicitud lo usó antes.
+	MarkUsed(ctx context.Context, db *gorm.DB, id uint64, at time.Time) (bool, error)
+	// InvalidateByUser consume los tokens pendientes del usuario para ese propósito.
+	InvalidateByUser(ctx context.Context, db *gorm.DB, userID uint64, purpose string, at time.Time) error
+}
+type UserTokenRepository interface {
+	UserTokenReader
+	UserTokenWriter
+}
+
+// --- STRUCTS Y CONSTRUCTORES GRANULARES ---
+
+type UserTokenReaderRepo struct{}
+
+func NewUserTokenReaderRepo() UserTokenReader { return &UserTokenReaderRepo{} }
+
+type UserTokenWriterRepo struct{}
+
+func NewUserTokenWriterRepo() UserTokenWriter { return &UserTokenWriterRepo{} }
+
+// --- STRUCT Y CONSTRUCTOR COMPUESTO ---
+
+type userTokenRepository struct {
+	UserTokenReader
+	UserTokenWriter
+}
+
+func NewUserTokenRepository(r UserTokenReader, w UserTokenWriter) UserTokenRepository {
+	return &userTokenRepository{r, w}
+}
+
+// --- IMPLEMENTACIONES DE MÉTODOS ---
+
+func (r *UserTokenReaderRepo) GetByHash(ctx context.Context, db *gorm.DB, purpose, tokenHash string) (*models.UserToken, error) {
+	var token models.UserToken
+	err := db.WithContext(ctx).Where("purpose = ? AND token_hash = ?", purpose, tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+func (r *UserTokenWriterRepo) Create(ctx context.Context, db *gorm.DB, token *models.UserToken) error {
+	return db.WithContext(ctx).Create(token).Error
+}
+func (r *UserTokenWriterRepo) MarkUsed(ctx context.Context, db *gorm.DB, id uint64, at time.Time) (bool, error) {
+	result := db.WithContext(ctx).Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+func (r *UserTokenWriterRepo) InvalidateByUser(ctx context.Context, db *gorm.DB, userID uint64, purpose string, at time.Time) error {
+	return db.WithContext(ctx).Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", at).Error
+}
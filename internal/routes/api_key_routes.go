@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/handlers"
+	"go-fiber-core/internal/middleware"
+	"go-fiber-core/internal/utils"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// RegisterAPIKeyRoutes define los endpoints de las API keys del usuario autenticado.
+// Requieren una sesión: con una API key no se administran otras.
+func RegisterAPIKeyRoutes(router fiber.Router, apiKeyHandler handlers.APIKeyHandler) {
+	keys := router.Group("/auth/api-keys", middleware.RequireSession())
+
+	// GET /auth/api-keys - Claves propias, incluidas las revocadas
+	keys.Get("/", apiKeyHandler.List)
+
+	// POST /auth/api-keys - Crear una clave (se muestra una sola vez)
+	keys.Post("/", utils.Validate(new(requests.CreateAPIKeyRequest)), apiKeyHandler.Create)
+
+	// DELETE /auth/api-keys/:id - Revocar una clave propia
+	keys.Delete("/:id", apiKeyHandler.Revoke)
+}
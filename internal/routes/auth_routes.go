@@ -2,15 +2,29 @@
 package routes
 
 import (
+	"go-fiber-core/internal/dtos/requests"
 	"go-fiber-core/internal/handlers"
+	"go-fiber-core/internal/utils"
 
 	fiber "github.com/gofiber/fiber/v2"
 )
 
 // SetupAuthRoutes ahora acepta un fiber.Router y la interfaz del handler.
-func RegisterAuthRoutes(router fiber.Router, authHandler handlers.AuthHandler) {
+func RegisterAuthRoutes(router fiber.Router, authHandler handlers.AuthHandler, accountHandler handlers.AccountHandler) {
 	auth := router.Group("/auth")
 
 	auth.Post("/login", authHandler.Login)
 	auth.Post("/refresh", authHandler.Refresh)
+
+	// Segundo paso del login cuando el usuario tiene 2FA (o su rol lo exige)
+	auth.Post("/2fa/setup", utils.Validate(new(requests.TwoFactorChallengeRequest)), authHandler.SetupTwoFactor)
+	auth.Post("/2fa/verify", utils.Validate(new(requests.TwoFactorVerifyRequest)), authHandler.VerifyTwoFactor)
+
+	// Recuperación de contraseña: envía el enlace y luego lo canjea por la nueva contraseña
+	auth.Post("/password/forgot", utils.Validate(new(requests.ForgotPasswordRequest)), accountHandler.ForgotPassword)
+	auth.Post("/password/reset", utils.Validate(new(requests.ResetPasswordRequest)), accountHandler.ResetPassword)
+
+	// Verificación de email: pedir (o reenviar) el enlace y confirmarlo
+	auth.Post("/email/verification", utils.Validate(new(requests.EmailVerificationRequest)), accountHandler.RequestEmailVerification)
+	auth.Post("/email/verify", utils.Validate(new(requests.VerifyEmailRequest)), accountHandler.VerifyEmail)
 }
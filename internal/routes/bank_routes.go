@@ -4,20 +4,25 @@ package routes
 import (
 	"go-fiber-core/internal/dtos/requests"
 	"go-fiber-core/internal/handlers"
+	"go-fiber-core/internal/menuaccess"
+	"go-fiber-core/internal/middleware"
 	"go-fiber-core/internal/utils"
 
 	fiber "github.com/gofiber/fiber/v2"
 )
 
 // RegisterBankRoutes define todos los endpoints relacionados con el recurso de Bancos.
+// Cada ruta exige el permiso correspondiente (ver tabla permissions) y el grupo exige
+// alguno de los menús de bancos (ver menuaccess.Routes).
 func RegisterBankRoutes(router fiber.Router, bankHandler handlers.BankHandler) {
-	bankGroup := router.Group("/banks")
+	bankGroup := router.Group("/banks", middleware.RequireMenu(menuaccess.MenusFor("/banks")...))
 
 	// --- RUTAS DE ESCRITURA (Comandos) ---
 
 	// POST /banks - Crear un nuevo banco
 	bankGroup.Post(
 		"/", // Usar la raíz del grupo es más estándar para "crear"
+		middleware.RequirePermission("banks.create"),
 		utils.Validate(new(requests.CreateBankRequest)),
 		bankHandler.Create,
 	)
@@ -26,25 +31,32 @@ func RegisterBankRoutes(router fiber.Router, bankHandler handlers.BankHandler) {
 	// CAMBIO: Se añade el middleware para validar el body con UpdateBankRequest.
 	bankGroup.Put(
 		"/:id", // Usar /:id en lugar de /edit/:id es más RESTful
+		middleware.RequirePermission("banks.update"),
 		utils.Validate(new(requests.UpdateBankRequest)),
 		bankHandler.Update,
 	)
 
 	// DELETE /banks/:id - Borrado lógico
-	bankGroup.Delete("/:id", bankHandler.SoftDelete)
+	bankGroup.Delete("/:id", middleware.RequirePermission("banks.delete"), bankHandler.SoftDelete)
 
 	// DELETE /banks/hard/:id - Borrado físico
-	bankGroup.Delete("/hard/:id", bankHandler.HardDelete)
+	bankGroup.Delete("/hard/:id", middleware.RequirePermission("banks.force_delete"), bankHandler.HardDelete)
 
 	// --- RUTAS DE LECTURA (Consultas) ---
 
 	// GET /banks - Obtener todos los bancos
-	bankGroup.Get("/", bankHandler.GetAll)
+	bankGroup.Get("/", middleware.RequirePermission("banks.read"), bankHandler.GetAll)
+
+	// GET /banks/paginated - Bancos paginados con la query string (se registra antes de /:id)
+	bankGroup.Get("/paginated", middleware.RequirePermission("banks.read"), bankHandler.GetAllPaginatedQuery)
 
 	// GET /banks/:id - Obtener un banco por ID
-	bankGroup.Get("/:id", bankHandler.GetByID)
+	bankGroup.Get("/:id", middleware.RequirePermission("banks.read"), bankHandler.GetByID)
 
 	// POST /banks/paginated - Obtener bancos paginados
-	bankGroup.Post("/paginated", bankHandler.GetAllPaginated)
+	bankGroup.Post("/paginated", middleware.RequirePermission("banks.read"), bankHandler.GetAllPaginated)
+
+	// POST /banks/export - Exportar bancos filtrados (CSV/XLSX en streaming)
+	bankGroup.Post("/export", middleware.RequirePermission("banks.export"), bankHandler.Export)
 
 }
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/handlers"
+	"go-fiber-core/internal/middleware"
+	"go-fiber-core/internal/utils"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// RegisterMeRoutes define los endpoints de la cuenta del usuario autenticado. No piden
+// permisos: cada usuario solo accede a sus propios datos. Los cambios exigen la sesión
+// propia (ni API key ni suplantación).
+func RegisterMeRoutes(router fiber.Router, userHandler handlers.UserHandler) {
+	me := router.Group("/me")
+
+	// GET /me - Datos del usuario autenticado
+	me.Get("/", userHandler.GetMe)
+
+	// PATCH /me - Cambiar nombre y/o email
+	me.Patch("/", middleware.RequireSession(), utils.Validate(new(requests.UpdateMeRequest)), userHandler.UpdateMe)
+
+	// POST /me/password - Cambiar la contraseña (verifica la actual y cierra las demás sesiones)
+	me.Post("/password", middleware.RequireSession(), utils.Validate(new(requests.ChangePasswordRequest)), userHandler.ChangeMyPassword)
+}
@@ -4,6 +4,7 @@ package routes
 import (
 	"go-fiber-core/internal/dtos/requests"
 	"go-fiber-core/internal/handlers"
+	"go-fiber-core/internal/middleware"
 	"go-fiber-core/internal/utils"
 
 	fiber "github.com/gofiber/fiber/v2"
@@ -13,21 +14,110 @@ func RegisterMenuRoutes(router fiber.Router, menuHandler handlers.MenuHandler) {
 	menuGroup := router.Group("/menus")
 
 	// --- 1) OBTENER MENÚ DEL USUARIO AUTENTICADO ---
-	// GET /menus/my
+	// GET /menus/my (menús de sus roles + excepciones de menu_user). Sin permiso: solo
+	// devuelve datos del propio usuario.
 	menuGroup.Get("/my", menuHandler.GetMenuByUser)
 
-	// --- 2) ASIGNACIÓN MASIVA MENÚS ↔ USUARIOS ---
-	// POST /menus/users/bulk
+	// --- 2) ÁRBOL COMPLETO: MOVER, EXPORTAR E IMPORTAR ---
+	// PUT /menus/move - Cambia padre y orden de un subárbol en una sola transacción
+	menuGroup.Put(
+		"/move",
+		middleware.RequirePermission("menus.update"),
+		utils.Validate(new(requests.MoveMenusRequest)),
+		menuHandler.Move,
+	)
+
+	// GET /menus/routes - Diagnóstico: grupos y rutas de la API que habilita cada menú
+	menuGroup.Get("/routes", middleware.RequirePermission("menus.assign"), menuHandler.RouteAccess)
+
+	// GET /menus/export - Descarga el árbol en el formato de menus.json
+	menuGroup.Get("/export", middleware.RequirePermission("menus.export"), menuHandler.Export)
+
+	// POST /menus/import - Reemplaza el árbol (body: arreglo JSON como el de /export)
+	menuGroup.Post("/import", middleware.RequirePermission("menus.import"), menuHandler.Import)
+
+	// --- 3) EXCEPCIONES POR USUARIO (menu_user) ---
+	// POST /menus/users/bulk - Otorgar menús a usuarios
 	menuGroup.Post(
 		"/users/bulk",
+		middleware.RequirePermission("menus.assign"),
 		utils.Validate(new(requests.BulkAssignMenuUsersRequest)),
 		menuHandler.AddBulkUsers,
 	)
 
-	// --- 3) REMOCIÓN MASIVA MENÚS ↔ USUARIOS ---
-	// DELETE /menus/users/bulk
+	// POST /menus/users/deny - Denegar menús a usuarios aunque sus roles los otorguen
+	menuGroup.Post(
+		"/users/deny",
+		middleware.RequirePermission("menus.assign"),
+		utils.Validate(new(requests.BulkAssignMenuUsersRequest)),
+		menuHandler.DenyBulkUsers,
+	)
+
+	// DELETE /menus/users/bulk - Quitar excepciones (vuelven a regir los roles)
 	menuGroup.Delete(
 		"/users/bulk",
+		middleware.RequirePermission("menus.assign"),
+		utils.Validate(new(requests.BulkAssignMenuUsersRequest)),
 		menuHandler.BulkRemoveUsers,
 	)
+
+	// --- 4) ASIGNACIÓN MASIVA MENÚS ↔ ROLES (menu_role) ---
+	// POST /menus/roles/bulk
+	menuGroup.Post(
+		"/roles/bulk",
+		middleware.RequirePermission("menus.assign"),
+		utils.Validate(new(requests.BulkAddRolesToMenusRequest)),
+		menuHandler.BulkAddRoles,
+	)
+
+	// DELETE /menus/roles/bulk
+	menuGroup.Delete(
+		"/roles/bulk",
+		middleware.RequirePermission("menus.assign"),
+		utils.Validate(new(requests.BulkAddRolesToMenusRequest)),
+		menuHandler.BulkRemoveRoles,
+	)
+
+	// --- 5) ROLES DE UN MENÚ ---
+	// GET /menus/:id/roles
+	menuGroup.Get("/:id/roles", middleware.RequirePermission("menus.assign"), menuHandler.GetRoles)
+
+	// POST /menus/:id/roles
+	menuGroup.Post(
+		"/:id/roles",
+		middleware.RequirePermission("menus.assign"),
+		utils.Validate(new(requests.AddRolesRequest)),
+		menuHandler.AddRoles,
+	)
+
+	// DELETE /menus/:id/roles
+	menuGroup.Delete(
+		"/:id/roles",
+		middleware.RequirePermission("menus.assign"),
+		utils.Validate(new(requests.AddRolesRequest)),
+		menuHandler.RemoveRoles,
+	)
+
+	// --- 6) CRUD DE ÍTEMS ---
+	// POST /menus
+	menuGroup.Post(
+		"/",
+		middleware.RequirePermission("menus.create"),
+		utils.Validate(new(requests.CreateMenuRequest)),
+		menuHandler.Create,
+	)
+
+	// PUT /menus/:id
+	menuGroup.Put(
+		"/:id",
+		middleware.RequirePermission("menus.update"),
+		utils.Validate(new(requests.UpdateMenuRequest)),
+		menuHandler.Update,
+	)
+
+	// DELETE /menus/:id - Borrado lógico del ítem y sus hijos
+	menuGroup.Delete("/:id", middleware.RequirePermission("menus.delete"), menuHandler.SoftDelete)
+
+	// PUT /menus/:id/restore - Restaura el ítem y los hijos borrados junto con él
+	menuGroup.Put("/:id/restore", middleware.RequirePermission("menus.delete"), menuHandler.Restore)
 }
@@ -0,0 +1,79 @@
+package routes
+
+import (
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/handlers"
+	"go-fiber-core/internal/middleware"
+	"go-fiber-core/internal/utils"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoleRoutes define los endpoints de roles, su asignación a usuarios y sus permisos.
+func RegisterRoleRoutes(router fiber.Router, roleHandler handlers.RoleHandler) {
+	roles := router.Group("/roles")
+
+	// --- RUTAS DE LECTURA ---
+
+	// GET /roles - Todos los roles
+	roles.Get("/", middleware.RequirePermission("roles.read"), roleHandler.GetAll)
+
+	// GET /roles/paginated - Roles paginados con la query string (antes de /:id)
+	roles.Get("/paginated", middleware.RequirePermission("roles.read"), roleHandler.GetAllPaginatedQuery)
+
+	// GET /roles/permissions - Catálogo de permisos asignables (antes de /:id)
+	roles.Get("/permissions", middleware.RequirePermission("roles.read"), roleHandler.ListPermissions)
+
+	// GET /roles/:id - Un rol con sus permisos
+	roles.Get("/:id", middleware.RequirePermission("roles.read"), roleHandler.GetByID)
+
+	// POST /roles/paginated - Roles paginados
+	roles.Post("/paginated", middleware.RequirePermission("roles.read"), roleHandler.GetAllPaginated)
+
+	// --- RUTAS DE ESCRITURA ---
+
+	// POST /roles - Crear un rol
+	roles.Post(
+		"/",
+		middleware.RequirePermission("roles.create"),
+		utils.Validate(new(requests.CreateRoleRequest)),
+		roleHandler.Create,
+	)
+
+	// PUT /roles/:id - Renombrar y activar/desactivar un rol
+	roles.Put(
+		"/:id",
+		middleware.RequirePermission("roles.update"),
+		utils.Validate(new(requests.UpdateRoleRequest)),
+		roleHandler.Update,
+	)
+
+	// DELETE /roles/:id - Borrado lógico
+	roles.Delete("/:id", middleware.RequirePermission("roles.delete"), roleHandler.SoftDelete)
+
+	// PUT /roles/:id/permissions - Reemplazar los permisos del rol
+	roles.Put(
+		"/:id/permissions",
+		middleware.RequirePermission("roles.update"),
+		utils.Validate(new(requests.SetRolePermissionsRequest)),
+		roleHandler.SetPermissions,
+	)
+
+	// --- ASIGNACIÓN MASIVA ROLES ↔ USUARIOS ---
+
+	// POST /roles/users/bulk
+	roles.Post(
+		"/users/bulk",
+		middleware.RequirePermission("roles.assign"),
+		utils.Validate(new(requests.BulkAssignRoleUsersRequest)),
+		roleHandler.AssignUsers,
+	)
+
+	// DELETE /roles/users/bulk
+	roles.Delete(
+		"/users/bulk",
+		middleware.RequirePermission("roles.assign"),
+		utils.Validate(new(requests.BulkAssignRoleUsersRequest)),
+		roleHandler.RevokeUsers,
+	)
+}
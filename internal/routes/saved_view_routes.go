@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/handlers"
+	"go-fiber-core/internal/utils"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// RegisterSavedViewRoutes define los endpoints de las vistas guardadas de los listados paginados.
+func RegisterSavedViewRoutes(router fiber.Router, savedViewHandler handlers.SavedViewHandler) {
+	views := router.Group("/views")
+
+	// GET /views?resource=banks - Vistas propias y compartidas con mis roles
+	views.Get("/", savedViewHandler.List)
+
+	// GET /views/:id - Obtener una vista
+	views.Get("/:id", savedViewHandler.GetByID)
+
+	// POST /views - Guardar una vista
+	views.Post("/", utils.Validate(new(requests.SavedViewRequest)), savedViewHandler.Create)
+
+	// PUT /views/:id - Reemplazar una vista propia
+	views.Put("/:id", utils.Validate(new(requests.SavedViewRequest)), savedViewHandler.Update)
+
+	// PUT /views/:id/default - Marcar como vista por defecto del recurso
+	views.Put("/:id/default", savedViewHandler.SetDefault)
+
+	// DELETE /views/:id - Borrado lógico de una vista propia
+	views.Delete("/:id", savedViewHandler.Delete)
+}
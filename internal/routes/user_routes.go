@@ -3,7 +3,10 @@
 package routes
 
 import (
+	"go-fiber-core/internal/dtos/requests"
 	"go-fiber-core/internal/handlers"
+	"go-fiber-core/internal/middleware"
+	"go-fiber-core/internal/utils"
 
 	fiber "github.com/gofiber/fiber/v2"
 )
@@ -13,18 +16,30 @@ func RegisterUserRoutes(router fiber.Router, userHandler handlers.UserHandler) {
 	users := router.Group("/users")
 
 	// Rutas principales
-	users.Post("/", userHandler.CreateUser)
+	users.Post("/", middleware.RequirePermission("users.create"), userHandler.CreateUser)
 	// users.Post("/full", userHandler.CreateUserWithRelations) // 👈 Nuevo endpoint
 	// users.Post("/full-existing", userHandler.CreateUserWithExistingRelations)
 	// users.Post("/full-new-if-not-exist", userHandler.CreateUserWithNewProductsAndRolesIfNotExist)
-	users.Get("/", userHandler.GetAllUsers)
-	users.Get("/:id", userHandler.GetUserByID)
-	users.Put("/:id", userHandler.UpdateUser)
-	users.Delete("/:id", userHandler.SoftDelete) // Pendiente: cambiar password
-	users.Delete("/hard/:id", userHandler.HardDelete)
+	users.Get("/", middleware.RequirePermission("users.read"), userHandler.GetAllUsers)
+	users.Get("/paginated", middleware.RequirePermission("users.read"), userHandler.GetAllPaginatedUsersQuery) // Antes de /:id para que no lo capture
+	users.Get("/:id", middleware.RequirePermission("users.read"), userHandler.GetUserByID)
+	users.Put("/:id", middleware.DenyImpersonation(), middleware.RequirePermission("users.update"), utils.Validate(new(requests.UpdateUserRequest)), userHandler.UpdateUser)
+	users.Delete("/:id", middleware.RequirePermission("users.delete"), userHandler.SoftDelete)
+	users.Delete("/hard/:id", middleware.RequirePermission("users.force_delete"), userHandler.HardDelete)
 
-	// Pendiente: cambiar password
+	// Cierra todas las sesiones del usuario (sus access tokens dejan de valer al instante)
+	users.Delete("/:id/sessions", middleware.RequirePermission("users.revoke_sessions"), userHandler.RevokeSessions)
+
+	// Levanta el bloqueo por intentos fallidos de login
+	users.Post("/:id/unlock", middleware.DenyImpersonation(), middleware.RequirePermission("users.unlock"), userHandler.UnlockLogin)
 
 	// Ruta para obtener usuarios paginados
-	users.Post("/paginated", userHandler.GetAllPaginatedUsers)
+	users.Post("/paginated", middleware.RequirePermission("users.read"), userHandler.GetAllPaginatedUsers)
+
+	// Ruta para exportar los usuarios filtrados (CSV/XLSX)
+	users.Post("/export", middleware.RequirePermission("users.export"), userHandler.ExportUsers)
+
+	// Alta masiva desde CSV/XLSX (?dry_run=true solo valida) y estado de los imports en segundo plano
+	users.Post("/import", middleware.DenyImpersonation(), middleware.RequirePermission("users.import"), userHandler.ImportUsers)
+	users.Get("/import/:job_id", middleware.RequirePermission("users.import"), userHandler.ImportUsersStatus)
 }
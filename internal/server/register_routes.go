@@ -3,11 +3,14 @@ package server
 import (
 	"net"
 
+	"go-fiber-core/internal/dtos/requests"
 	"go-fiber-core/internal/handlers"
 	"go-fiber-core/internal/middleware"
 	"go-fiber-core/internal/routes"
 	"go-fiber-core/internal/services"
 	authService "go-fiber-core/internal/services/auth"
+	menuService "go-fiber-core/internal/services/menu"
+	permissionService "go-fiber-core/internal/services/permission"
 	"go-fiber-core/internal/utils"
 
 	fiber "github.com/gofiber/fiber/v2"
@@ -15,38 +18,67 @@ import (
 
 func (s *FiberServer) RegisterRoutes(
 	authHandler handlers.AuthHandler,
+	accountHandler handlers.AccountHandler,
 	userHandler handlers.UserHandler,
 	bankHandler handlers.BankHandler,
 	// productHandler handlers.ProductHandler,
 	menuHandler handlers.MenuHandler,
 	dbHandler handlers.DatabaseHandler,
+	savedViewHandler handlers.SavedViewHandler,
+	roleHandler handlers.RoleHandler,
+	apiKeyHandler handlers.APIKeyHandler,
 	tokenService authService.TokenService,
+	denylist authService.TokenDenylist,
+	apiKeys authService.APIKeyService,
+	permissions permissionService.PermissionService,
+	menus menuService.MenuReaderService,
 ) {
 	blacklistBankService := services.NewBlacklistBankService()
 	utils.SetupValidator(blacklistBankService)
+	middleware.SetupPermissions(permissions)
+	middleware.SetupMenuAccess(menus)
 
 	// --- REGISTRO DE RUTAS ---
 	s.App.Get("/", s.HelloWorldHandler)
+	s.App.Get("/.well-known/jwks.json", authHandler.JWKS)
 
 	// Grupo base para la API v1
 	api := s.App.Group("/api/v1")
 
 	// --- Rutas Públicas ---
 	// No requieren token de autenticación.
-	routes.RegisterAuthRoutes(api, authHandler)   // Registra /login y /refresh
-	routes.RegisterDatabaseRoutes(api, dbHandler) // Registra /health
+	routes.RegisterAuthRoutes(api, authHandler, accountHandler) // Registra /login, /refresh, contraseña y email
+	routes.RegisterDatabaseRoutes(api, dbHandler)               // Registra /health
 
 	// --- Rutas Protegidas ---
-	// Requieren un token de autenticación válido.
-	authMiddleware := middleware.AuthMiddleware(tokenService)
+	// Requieren un token de autenticación válido o una API key.
+	authMiddleware := middleware.AuthMiddleware(tokenService, denylist, apiKeys)
 	protected := api.Group("/", authMiddleware)
 
 	// Registramos las rutas que usarán este grupo protegido.
-	protected.Post("/auth/logout", authHandler.Logout)
+	// Sesiones: solo con la sesión propia (ni API key ni suplantación). El logout acepta el
+	// token de suplantación para terminarla.
+	protected.Post("/auth/logout", middleware.RequireTokenSession(), authHandler.Logout)
+	sessions := protected.Group("/auth/sessions", middleware.RequireSession())
+	sessions.Get("/", authHandler.ListSessions)
+	sessions.Delete("/", authHandler.RevokeOtherSessions)
+	sessions.Delete("/:id", authHandler.RevokeSession)
+	twoFactor := protected.Group("/auth/2fa", middleware.RequireSession())
+	twoFactor.Get("/", authHandler.TwoFactorStatus)
+	twoFactor.Post("/enroll", authHandler.EnrollTwoFactor)
+	twoFactor.Post("/confirm", utils.Validate(new(requests.TwoFactorCodeRequest)), authHandler.ConfirmTwoFactor)
+	twoFactor.Post("/recovery-codes", utils.Validate(new(requests.TwoFactorCodeRequest)), authHandler.RegenerateRecoveryCodes)
+	twoFactor.Delete("/", utils.Validate(new(requests.TwoFactorCodeRequest)), authHandler.DisableTwoFactor)
+	routes.RegisterAPIKeyRoutes(protected, apiKeyHandler)
+	// Suplantación: el token lleva al usuario real (act) y al suplantado (sub)
+	protected.Post("/auth/impersonate/:id", middleware.RequireSession(), middleware.RequirePermission("users.impersonate"), authHandler.Impersonate)
 	routes.RegisterBankRoutes(protected, bankHandler)
 	routes.RegisterUserRoutes(protected, userHandler)
+	routes.RegisterMeRoutes(protected, userHandler)
 	// routes.RegisterProductRoutes(protected, productHandler)
 	routes.RegisterMenuRoutes(protected, menuHandler)
+	routes.RegisterSavedViewRoutes(protected, savedViewHandler)
+	routes.RegisterRoleRoutes(protected, roleHandler)
 }
 
 // --- Handlers del Servidor ---
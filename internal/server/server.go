@@ -6,6 +6,8 @@ import (
 	"go-fiber-core/internal/handlers"
 	"go-fiber-core/internal/middleware"
 	authService "go-fiber-core/internal/services/auth"
+	menuService "go-fiber-core/internal/services/menu"
+	permissionService "go-fiber-core/internal/services/permission"
 	userService "go-fiber-core/internal/services/user"
 	"time"
 
@@ -25,12 +27,20 @@ func NewFiberServer(
 	appConfig *config.AppConfig,
 	connect *connect.ConnectDTO,
 	authHandler handlers.AuthHandler,
+	accountHandler handlers.AccountHandler,
 	userHandler handlers.UserHandler,
 	bankHandler handlers.BankHandler,
 	// productHandler handlers.ProductHandler,
 	menuHandler handlers.MenuHandler,
 	dbHandler handlers.DatabaseHandler,
+	savedViewHandler handlers.SavedViewHandler,
+	roleHandler handlers.RoleHandler,
+	apiKeyHandler handlers.APIKeyHandler,
 	tokenService authService.TokenService,
+	denylist authService.TokenDenylist,
+	apiKeys authService.APIKeyService,
+	permissions permissionService.PermissionService,
+	menus menuService.MenuReaderService,
 	userWriterService userService.UserWriterService, // 👈 agregado
 ) (*FiberServer, func(), error) {
 
@@ -38,6 +48,7 @@ func NewFiberServer(
 		App: fiber.New(fiber.Config{
 			ServerHeader: appConfig.Server.ServerHeader,
 			AppName:      appConfig.App.AppName,
+			ErrorHandler: middleware.ErrorHandler,
 		}),
 		AppConfig:         appConfig,
 		UserWriterService: userWriterService, // 👈 guardamos la instancia para uso externo
@@ -47,8 +58,8 @@ func NewFiberServer(
 	server.App.Use(cors.New(cors.Config{
 		AllowOrigins:     "http://localhost:9050",
 		AllowCredentials: true,
-		AllowMethods:     "GET, POST, PUT, DELETE, OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Client-Code",
+		AllowMethods:     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-API-Key, X-Client-Code",
 	}))
 
 	// Rate Limiting
@@ -56,11 +67,15 @@ func NewFiberServer(
 		Limit:  100,
 		Window: 1 * time.Minute,
 	}
+
+	// API keys: se verifican antes del rate limit para contar por el cliente verificado; las
+	// inválidas cuentan contra la IP.
+	server.App.Use(middleware.APIKeyMiddleware(apiKeys, connect.ConnectRedis, rateLimitConfig))
 	server.App.Use(middleware.RateLimitMiddleware(connect.ConnectRedis, rateLimitConfig))
 
 	// Registrar rutas
-	server.RegisterRoutes(authHandler, userHandler, bankHandler, menuHandler, dbHandler, tokenService)
-	// server.RegisterRoutes(authHandler, userHandler, bankHandler, dbHandler, tokenService)
+	server.RegisterRoutes(authHandler, accountHandler, userHandler, bankHandler, menuHandler, dbHandler, savedViewHandler, roleHandler, apiKeyHandler, tokenService, denylist, apiKeys, permissions, menus)
+	// server.RegisterRoutes(authHandler, accountHandler, userHandler, bankHandler, dbHandler, tokenService)
 
 	// Cleanup combinado (Wire lo mezcla con cleanup global)
 	cleanup := func() {}
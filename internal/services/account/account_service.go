@@ -0,0 +1,316 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/models"
+	userRepo "go-fiber-core/internal/repositories/user"
+	userTokenRepo "go-fiber-core/internal/repositories/usertoken"
+	"go-fiber-core/internal/services/email"
+	userService "go-fiber-core/internal/services/user"
+	"go-fiber-core/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPasswordResetTTL     = time.Hour
+	defaultEmailVerificationTTL = 48 * time.Hour
+	// invitationTTL es la vida del enlace de bienvenida: más largo que un restablecimiento
+	// porque el usuario no lo pidió y puede tardar en verlo.
+	invitationTTL = 72 * time.Hour
+
+	// sendTimeout limita el envío en segundo plano de cada email.
+	sendTimeout = 30 * time.Second
+)
+
+// errInvalidToken es la respuesta para cualquier token desconocido, usado o vencido.
+var errInvalidToken = domain.NewValidationError(map[string][]string{
+	"token": {"El enlace no es válido, ya fue usado o venció."},
+})
+
+// AccountService maneja los flujos por email que el usuario inicia sin estar autenticado:
+// recuperar la contraseña y verificar su email. También envía la invitación de los
+// usuarios creados por otro (ej: la importación masiva).
+//
+// Los pedidos por email nunca indican si la cuenta existe: siempre responden igual y el
+// correo se envía en segundo plano. Los tokens son de un solo uso, vencen y solo se guarda
+// su hash; pedir uno nuevo invalida los anteriores del mismo tipo.
+type AccountService interface {
+	ForgotPassword(ctx context.Context, emailAddress string) error
+	// ResetPassword cambia la contraseña, marca el email como verificado (el enlace llegó a
+	// su casilla) y cierra todas las sesiones del usuario. Una contraseña débil se rechaza
+	// sin consumir el token.
+	ResetPassword(ctx context.Context, token, password string) error
+	RequestEmailVerification(ctx context.Context, emailAddress string) error
+	VerifyEmail(ctx context.Context, token string) error
+	// SendInvitation envía a un usuario recién creado el enlace para elegir su contraseña:
+	// un token de restablecimiento de un solo uso que vence en invitationTTL.
+	SendInvitation(ctx context.Context, user *models.User) error
+}
+
+type accountService struct {
+	conn        *connect.ConnectDTO
+	userReader  userRepo.UserReader
+	users       userService.UserWriterService
+	tokens      userTokenRepo.UserTokenRepository
+	mailer      email.TemplateSender
+	frontendURL string
+	resetTTL    time.Duration
+	verifyTTL   time.Duration
+	now         func() time.Time
+	// send despacha el email; en producción en una goroutine (ver NewAccountService).
+	send func(fn func())
+}
+
+func NewAccountService(
+	conn *connect.ConnectDTO,
+	userReader userRepo.UserReader,
+	users userService.UserWriterService,
+	tokens userTokenRepo.UserTokenRepository,
+	mailer email.TemplateSender,
+	cfg *config.AppConfig,
+) AccountService {
+	resetTTL := time.Duration(cfg.Auth.PasswordResetTtlMinutes) * time.Minute
+	if resetTTL <= 0 {
+		resetTTL = defaultPasswordResetTTL
+	}
+	verifyTTL := time.Duration(cfg.Auth.EmailVerificationTtlHours) * time.Hour
+	if verifyTTL <= 0 {
+		verifyTTL = defaultEmailVerificationTTL
+	}
+	return &accountService{
+		conn:        conn,
+		userReader:  userReader,
+		users:       users,
+		tokens:      tokens,
+		mailer:      mailer,
+		frontendURL: strings.TrimRight(cfg.Auth.FrontendUrl, "/"),
+		resetTTL:    resetTTL,
+		verifyTTL:   verifyTTL,
+		now:         time.Now,
+		send:        func(fn func()) { go fn() },
+	}
+}
+
+// ────────────────────────────────────────────────
+// RECUPERAR CONTRASEÑA
+// ────────────────────────────────────────────────
+func (s *accountService) ForgotPassword(ctx context.Context, emailAddress string) error {
+	user, err := s.findActiveUser(ctx, emailAddress)
+	if err != nil || user == nil {
+		return err
+	}
+
+	token, err := s.issueToken(ctx, user, models.UserTokenPasswordReset, s.resetTTL)
+	if err != nil {
+		return err
+	}
+	s.sendEmail(user.Email, "Restablecer tu contraseña", "password_reset.md", map[string]any{
+		"Name":             user.Name,
+		"Link":             s.link("/reset-password", token),
+		"ExpiresInMinutes": int(s.resetTTL.Minutes()),
+	})
+	return nil
+}
+
+func (s *accountService) ResetPassword(ctx context.Context, token, password string) error {
+	if !utils.IsStrongPassword(password) {
+		return domain.NewValidationError(map[string][]string{
+			"password": {utils.PasswordStrengthMessage},
+		})
+	}
+	stored, user, err := s.consumeToken(ctx, models.UserTokenPasswordReset, token)
+	if err != nil {
+		return err
+	}
+
+	verified := true
+	if _, err := s.users.Update(ctx, user.ID, userService.UpdateUserDTO{Password: &password, EmailVerified: &verified}); err != nil {
+		return fmt.Errorf("error al cambiar la contraseña del usuario %d: %w", user.ID, err)
+	}
+	log.Printf("Usuario %d restableció su contraseña (token %d)", user.ID, stored.ID)
+
+	s.sendEmail(user.Email, "Tu contraseña fue cambiada", "password_changed.md", map[string]any{
+		"Name":      user.Name,
+		"ChangedAt": s.now().Format("02/01/2006 15:04"),
+	})
+	return nil
+}
+
+// ────────────────────────────────────────────────
+// INVITACIÓN
+// ────────────────────────────────────────────────
+func (s *accountService) SendInvitation(ctx context.Context, user *models.User) error {
+	token, err := s.issueToken(ctx, user, models.UserTokenPasswordReset, invitationTTL)
+	if err != nil {
+		return err
+	}
+	s.sendEmail(user.Email, "Tu cuenta fue creada", "user_invitation.md", map[string]any{
+		"Name":           user.Name,
+		"Email":          user.Email,
+		"Link":           s.link("/reset-password", token),
+		"ExpiresInHours": int(invitationTTL.Hours()),
+	})
+	return nil
+}
+
+// ────────────────────────────────────────────────
+// VERIFICAR EMAIL
+// ────────────────────────────────────────────────
+func (s *accountService) RequestEmailVerification(ctx context.Context, emailAddress string) error {
+	user, err := s.findActiveUser(ctx, emailAddress)
+	if err != nil || user == nil || user.EmailVerifiedAt != nil {
+		return err
+	}
+
+	token, err := s.issueToken(ctx, user, models.UserTokenEmailVerification, s.verifyTTL)
+	if err != nil {
+		return err
+	}
+	s.sendEmail(user.Email, "Confirmá tu email", "email_verification.md", map[string]any{
+		"Name":           user.Name,
+		"Email":          user.Email,
+		"Link":           s.link("/verify-email", token),
+		"ExpiresInHours": int(s.verifyTTL.Hours()),
+	})
+	return nil
+}
+
+func (s *accountService) VerifyEmail(ctx context.Context, token string) error {
+	_, user, err := s.consumeToken(ctx, models.UserTokenEmailVerification, token)
+	if err != nil {
+		return err
+	}
+
+	verified := true
+	if _, err := s.users.Update(ctx, user.ID, userService.UpdateUserDTO{EmailVerified: &verified}); err != nil {
+		return fmt.Errorf("error al verificar el email del usuario %d: %w", user.ID, err)
+	}
+	return nil
+}
+
+// ────────────────────────────────────────────────
+// HELPERS
+// ────────────────────────────────────────────────
+
+// findActiveUser devuelve nil (sin error) si no hay un usuario activo con ese email.
+func (s *accountService) findActiveUser(ctx context.Context, emailAddress string) (*models.User, error) {
+	user, err := s.userReader.GetByEmail(ctx, s.conn.ConnectGormRead, strings.TrimSpace(emailAddress))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+	if !user.IsActive {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// issueToken invalida los tokens pendientes del mismo tipo y guarda el hash de uno nuevo.
+// Devuelve el token en claro, que solo viaja en el enlace del email.
+func (s *accountService) issueToken(ctx context.Context, user *models.User, purpose string, ttl time.Duration) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	now := s.now()
+	err = s.conn.ConnectGormWrite.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.tokens.InvalidateByUser(ctx, tx, user.ID, purpose, now); err != nil {
+			return err
+		}
+		return s.tokens.Create(ctx, tx, &models.UserToken{
+			UserID:    user.ID,
+			Purpose:   purpose,
+			TokenHash: hashToken(token),
+			SentTo:    user.Email,
+			ExpiresAt: now.Add(ttl),
+		})
+	})
+	if err != nil {
+		return "", fmt.Errorf("error al generar el token de %s: %w", purpose, err)
+	}
+	return token, nil
+}
+
+// consumeToken valida el token y lo marca como usado. Cualquier problema (desconocido,
+// usado, vencido, usuario inactivo o con otro email) devuelve el mismo error.
+func (s *accountService) consumeToken(ctx context.Context, purpose, token string) (*models.UserToken, *models.User, error) {
+	stored, err := s.tokens.GetByHash(ctx, s.conn.ConnectGormWrite, purpose, hashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errInvalidToken
+		}
+		return nil, nil, fmt.Errorf("error al buscar el token: %w", err)
+	}
+	now := s.now()
+	if stored.UsedAt != nil || !now.Before(stored.ExpiresAt) {
+		return nil, nil, errInvalidToken
+	}
+
+	user, err := s.userReader.GetByID(ctx, s.conn.ConnectGormWrite, stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errInvalidToken
+		}
+		return nil, nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+	// Si cambió el email después del envío, el enlace ya no prueba nada sobre el actual.
+	if !user.IsActive || !strings.EqualFold(user.Email, stored.SentTo) {
+		return nil, nil, errInvalidToken
+	}
+
+	used, err := s.tokens.MarkUsed(ctx, s.conn.ConnectGormWrite, stored.ID, now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al consumir el token: %w", err)
+	}
+	if !used {
+		return nil, nil, errInvalidToken
+	}
+	return stored, user, nil
+}
+
+// sendEmail envía la plantilla sin demorar la respuesta: el tiempo de respuesta no debe
+// revelar si la cuenta existe. Los errores solo se registran.
+func (s *accountService) sendEmail(to, subject, templateName string, data map[string]any) {
+	s.send(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+		if err := s.mailer.SendFromTemplate(ctx, to, subject, templateName, data); err != nil {
+			log.Printf("Error al enviar el email '%s' a %s: %v", templateName, to, err)
+		}
+	})
+}
+
+func (s *accountService) link(path, token string) string {
+	return s.frontendURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// newToken genera 256 bits aleatorios en base64 para URL.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("no se pudo generar el token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,295 @@
+package account
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/models"
+	userRepo "go-fiber-core/internal/repositories/user"
+	userService "go-fiber-core/internal/services/user"
+	"go-fiber-core/internal/utils"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// --- FAKES (solo los métodos que usa el servicio) ---
+
+type fakeUserReader struct {
+	userRepo.UserReader
+	users map[uint64]*models.User
+}
+
+func (f *fakeUserReader) GetByID(_ context.Context, _ *gorm.DB, id uint64) (*models.User, error) {
+	if u, ok := f.users[id]; ok {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeUserReader) GetByEmail(_ context.Context, _ *gorm.DB, email string) (*models.User, error) {
+	for _, u := range f.users {
+		if u.Email == email {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeUserWriter struct {
+	userService.UserWriterService
+	reader  *fakeUserReader
+	updates []userService.UpdateUserDTO
+}
+
+func (f *fakeUserWriter) Update(_ context.Context, id uint64, data userService.UpdateUserDTO) (*models.User, error) {
+	f.updates = append(f.updates, data)
+	u := f.reader.users[id]
+	if data.EmailVerified != nil && *data.EmailVerified {
+		now := time.Now()
+		u.EmailVerifiedAt = &now
+	}
+	return u, nil
+}
+
+type fakeTokenRepo struct {
+	tokens []*models.UserToken
+}
+
+func (f *fakeTokenRepo) GetByHash(_ context.Context, _ *gorm.DB, purpose, tokenHash string) (*models.UserToken, error) {
+	for _, t := range f.tokens {
+		if t.Purpose == purpose && t.TokenHash == tokenHash {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeTokenRepo) Create(_ context.Context, _ *gorm.DB, token *models.UserToken) error {
+	token.ID = uint64(len(f.tokens) + 1)
+	f.tokens = append(f.tokens, token)
+	return nil
+}
+
+func (f *fakeTokenRepo) MarkUsed(_ context.Context, _ *gorm.DB, id uint64, at time.Time) (bool, error) {
+	for _, t := range f.tokens {
+		if t.ID == id && t.UsedAt == nil {
+			t.UsedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeTokenRepo) InvalidateByUser(_ context.Context, _ *gorm.DB, userID uint64, purpose string, at time.Time) error {
+	for _, t := range f.tokens {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			t.UsedAt = &at
+		}
+	}
+	return nil
+}
+
+type sentEmail struct {
+	to, template string
+	data         map[string]any
+}
+
+type fakeMailer struct {
+	sent []sentEmail
+}
+
+func (f *fakeMailer) SendFromTemplate(_ context.Context, to, _, templateName string, data any) error {
+	f.sent = append(f.sent, sentEmail{to: to, template: templateName, data: data.(map[string]any)})
+	return nil
+}
+
+type testEnv struct {
+	service *accountService
+	users   *fakeUserWriter
+	tokens  *fakeTokenRepo
+	mailer  *fakeMailer
+	mock    sqlmock.Sqlmock
+}
+
+func newTestEnv(t *testing.T, users ...*models.User) *testEnv {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	require.NoError(t, err)
+
+	reader := &fakeUserReader{users: map[uint64]*models.User{}}
+	for _, u := range users {
+		reader.users[u.ID] = u
+	}
+	env := &testEnv{
+		users:  &fakeUserWriter{reader: reader},
+		tokens: &fakeTokenRepo{},
+		mailer: &fakeMailer{},
+		mock:   mock,
+	}
+	cfg := &config.AppConfig{Auth: config.AuthConfig{FrontendUrl: "https://app.test/"}}
+	env.service = NewAccountService(&connect.ConnectDTO{ConnectGormRead: gormDB, ConnectGormWrite: gormDB},
+		reader, env.users, env.tokens, env.mailer, cfg).(*accountService)
+	// Los emails se envían en el acto para poder verificarlos.
+	env.service.send = func(fn func()) { fn() }
+	return env
+}
+
+// tokenFromLink extrae el token del enlace del último email enviado.
+func (e *testEnv) tokenFromLink(t *testing.T) string {
+	require.NotEmpty(t, e.mailer.sent)
+	link, err := url.Parse(e.mailer.sent[len(e.mailer.sent)-1].data["Link"].(string))
+	require.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+func TestAccountService_ForgotAndResetPassword(t *testing.T) {
+	env := newTestEnv(t, &models.User{ID: 7, Name: "Ana", Email: "ana@test.com", IsActive: true})
+	ctx := context.Background()
+
+	env.mock.ExpectBegin()
+	env.mock.ExpectCommit()
+	require.NoError(t, env.service.ForgotPassword(ctx, "ana@test.com"))
+
+	require.Len(t, env.mailer.sent, 1)
+	assert.Equal(t, "password_reset.md", env.mailer.sent[0].template)
+	assert.True(t, strings.HasPrefix(env.mailer.sent[0].data["Link"].(string), "https://app.test/reset-password?token="))
+	token := env.tokenFromLink(t)
+	// Solo se guarda el hash.
+	assert.Equal(t, hashToken(token), env.tokens.tokens[0].TokenHash)
+	assert.NotContains(t, env.tokens.tokens[0].TokenHash, token)
+
+	require.NoError(t, env.service.ResetPassword(ctx, token, "nueva-clave-123"))
+	require.Len(t, env.users.updates, 1)
+	assert.Equal(t, "nueva-clave-123", *env.users.updates[0].Password)
+	assert.True(t, *env.users.updates[0].EmailVerified)
+	assert.Equal(t, "password_changed.md", env.mailer.sent[1].template)
+
+	// Un solo uso.
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, env.service.ResetPassword(ctx, token, "otra-clave-123"), &validationErr)
+	assert.NoError(t, env.mock.ExpectationsWereMet())
+}
+
+func TestAccountService_ResetPasswordRejectsWeakPassword(t *testing.T) {
+	env := newTestEnv(t, &models.User{ID: 7, Name: "Ana", Email: "ana@test.com", IsActive: true})
+	ctx := context.Background()
+
+	env.mock.ExpectBegin()
+	env.mock.ExpectCommit()
+	require.NoError(t, env.service.ForgotPassword(ctx, "ana@test.com"))
+	token := env.tokenFromLink(t)
+
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, env.service.ResetPassword(ctx, token, "abcdefgh"), &validationErr)
+	assert.Equal(t, []string{utils.PasswordStrengthMessage}, validationErr.Fields["password"])
+	assert.Empty(t, env.users.updates)
+
+	// El token sigue valiendo para una contraseña que cumple la política.
+	require.NoError(t, env.service.ResetPassword(ctx, token, "Nueva-clave-123"))
+	assert.NoError(t, env.mock.ExpectationsWereMet())
+}
+
+func TestAccountService_SendInvitation(t *testing.T) {
+	env := newTestEnv(t, &models.User{ID: 7, Name: "Ana", Email: "ana@test.com", IsActive: true})
+	ctx := context.Background()
+
+	env.mock.ExpectBegin()
+	env.mock.ExpectCommit()
+	require.NoError(t, env.service.SendInvitation(ctx, &models.User{ID: 7, Name: "Ana", Email: "ana@test.com"}))
+
+	require.Len(t, env.mailer.sent, 1)
+	assert.Equal(t, "user_invitation.md", env.mailer.sent[0].template)
+	assert.NotContains(t, env.mailer.sent[0].data, "Password")
+	assert.Equal(t, 72, env.mailer.sent[0].data["ExpiresInHours"])
+	assert.WithinDuration(t, time.Now().Add(invitationTTL), env.tokens.tokens[0].ExpiresAt, time.Minute)
+
+	// El enlace es un restablecimiento de un solo uso.
+	token := env.tokenFromLink(t)
+	require.NoError(t, env.service.ResetPassword(ctx, token, "nueva-clave-123"))
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, env.service.ResetPassword(ctx, token, "otra-clave-123"), &validationErr)
+	assert.NoError(t, env.mock.ExpectationsWereMet())
+}
+
+func TestAccountService_ForgotPasswordDoesNotRevealAccounts(t *testing.T) {
+	env := newTestEnv(t, &models.User{ID: 7, Email: "inactivo@test.com", IsActive: false})
+	ctx := context.Background()
+
+	assert.NoError(t, env.service.ForgotPassword(ctx, "nadie@test.com"))
+	assert.NoError(t, env.service.ForgotPassword(ctx, "inactivo@test.com"))
+	assert.Empty(t, env.mailer.sent)
+	assert.Empty(t, env.tokens.tokens)
+}
+
+func TestAccountService_NewTokenInvalidatesPrevious(t *testing.T) {
+	env := newTestEnv(t, &models.User{ID: 7, Email: "ana@test.com", IsActive: true})
+	ctx := context.Background()
+
+	env.mock.ExpectBegin()
+	env.mock.ExpectCommit()
+	require.NoError(t, env.service.ForgotPassword(ctx, "ana@test.com"))
+	first := env.tokenFromLink(t)
+	env.mock.ExpectBegin()
+	env.mock.ExpectCommit()
+	require.NoError(t, env.service.ForgotPassword(ctx, "ana@test.com"))
+	second := env.tokenFromLink(t)
+
+	assert.Error(t, env.service.ResetPassword(ctx, first, "nueva-clave-123"))
+	assert.NoError(t, env.service.ResetPassword(ctx, second, "nueva-clave-123"))
+}
+
+func TestAccountService_ExpiredToken(t *testing.T) {
+	env := newTestEnv(t, &models.User{ID: 7, Email: "ana@test.com", IsActive: true})
+	ctx := context.Background()
+
+	env.mock.ExpectBegin()
+	env.mock.ExpectCommit()
+	require.NoError(t, env.service.ForgotPassword(ctx, "ana@test.com"))
+	token := env.tokenFromLink(t)
+
+	env.service.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	assert.Error(t, env.service.ResetPassword(ctx, token, "nueva-clave-123"))
+	assert.Empty(t, env.users.updates)
+}
+
+func TestAccountService_VerifyEmail(t *testing.T) {
+	user := &models.User{ID: 7, Email: "ana@test.com", IsActive: true}
+	env := newTestEnv(t, user)
+	ctx := context.Background()
+
+	env.mock.ExpectBegin()
+	env.mock.ExpectCommit()
+	require.NoError(t, env.service.RequestEmailVerification(ctx, "ana@test.com"))
+	assert.Equal(t, "email_verification.md", env.mailer.sent[0].template)
+	token := env.tokenFromLink(t)
+
+	// Si el email cambió después del envío, el enlace ya no sirve.
+	user.Email = "otra@test.com"
+	assert.Error(t, env.service.VerifyEmail(ctx, token))
+	user.Email = "ana@test.com"
+
+	env.mock.ExpectBegin()
+	env.mock.ExpectCommit()
+	require.NoError(t, env.service.RequestEmailVerification(ctx, "ana@test.com"))
+	require.NoError(t, env.service.VerifyEmail(ctx, env.tokenFromLink(t)))
+	assert.NotNil(t, user.EmailVerifiedAt)
+
+	// Ya verificado: no se envía otro enlace.
+	require.NoError(t, env.service.RequestEmailVerification(ctx, "ana@test.com"))
+	assert.Len(t, env.mailer.sent, 2)
+	assert.NoError(t, env.mock.ExpectationsWereMet())
+}
@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/dtos/responses"
+	"go-fiber-core/internal/models"
+	apiKeyRepo "go-fiber-core/internal/repositories/apikey"
+	permissionRepo "go-fiber-core/internal/repositories/permission"
+	userRepo "go-fiber-core/internal/repositories/user"
+)
+
+const (
+	// apiKeyPrefix marca las claves de esta API (facilita detectarlas si se filtran).
+	apiKeyPrefix = "fck_"
+	// apiKeyDisplayLength es el largo del comienzo de la clave que se guarda para listarla.
+	apiKeyDisplayLength = 12
+	// apiKeyTouchInterval evita escribir last_used_at en cada request del cliente.
+	apiKeyTouchInterval = time.Minute
+)
+
+var clientCodePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// APIKeyService administra las API keys de los clientes máquina a máquina.
+//
+// Una clave actúa en nombre del usuario que la creó, limitada a los permisos de scopes, y
+// solo vale presentada junto a su X-Client-Code. Se guarda su SHA-256: la clave completa
+// se entrega una única vez, al crearla.
+type APIKeyService interface {
+	Create(ctx context.Context, userID uint64, req requests.CreateAPIKeyRequest) (*responses.APIKeyCreatedResponse, error)
+	List(ctx context.Context, userID uint64) ([]responses.APIKeyResponse, error)
+	// Revoke revoca una clave del usuario (domain.ErrNotFound si no existe o ya estaba revocada).
+	Revoke(ctx context.Context, userID, id uint64) error
+	// Authenticate valida la clave y su X-Client-Code y registra el uso. Devuelve
+	// domain.ErrAuthentication si la clave no existe, venció, fue revocada, el código no
+	// coincide o el dueño está inactivo.
+	Authenticate(ctx context.Context, key, clientCode string) (*models.APIKey, error)
+}
+
+type apiKeyService struct {
+	conn        *connect.ConnectDTO
+	repo        apiKeyRepo.APIKeyRepository
+	userReader  userRepo.UserReader
+	permissions permissionRepo.PermissionReader
+	now         func() time.Time
+}
+
+func NewAPIKeyService(
+	conn *connect.ConnectDTO,
+	repo apiKeyRepo.APIKeyRepository,
+	userReader userRepo.UserReader,
+	permissions permissionRepo.PermissionReader,
+) APIKeyService {
+	return &apiKeyService{
+		conn:        conn,
+		repo:        repo,
+		userReader:  userReader,
+		permissions: permissions,
+		now:         time.Now,
+	}
+}
+
+func (s *apiKeyService) Create(ctx context.Context, userID uint64, req requests.CreateAPIKeyRequest) (*responses.APIKeyCreatedResponse, error) {
+	clientCode := strings.TrimSpace(req.ClientCode)
+	if !clientCodePattern.MatchString(clientCode) {
+		return nil, domain.NewValidationError(map[string][]string{
+			"client_code": {"Solo puede tener letras, números, punto, guion y guion bajo."},
+		})
+	}
+
+	dbRead := s.conn.ConnectGormRead
+	granted, err := s.permissions.GetNamesByUserID(ctx, dbRead, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los permisos del usuario: %w", err)
+	}
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !slices.Contains(granted, scope) {
+			return nil, domain.NewValidationError(map[string][]string{
+				"scopes": {fmt.Sprintf("No tiene el permiso %q.", scope)},
+			})
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	// El client_code es la identidad del rate limit: no se comparte entre usuarios.
+	taken, err := s.repo.ClientCodeOwnedByOther(ctx, dbRead, clientCode, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al verificar el client_code: %w", err)
+	}
+	if taken {
+		return nil, domain.NewValidationError(map[string][]string{
+			"client_code": {"Ya lo usa otro usuario."},
+		})
+	}
+
+	plain := newAPIKey()
+	now := s.now()
+	key := &models.APIKey{
+		UserID:     userID,
+		Name:       strings.TrimSpace(req.Name),
+		ClientCode: clientCode,
+		Prefix:     plain[:apiKeyDisplayLength],
+		KeyHash:    hashAPIKey(plain),
+		Scopes:     scopes,
+		CreatedAt:  now,
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := now.AddDate(0, 0, *req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.Create(ctx, s.conn.ConnectGormWrite, key); err != nil {
+		return nil, fmt.Errorf("error al crear la API key: %w", err)
+	}
+
+	return &responses.APIKeyCreatedResponse{APIKeyResponse: apiKeyResponse(key), Key: plain}, nil
+}
+
+func (s *apiKeyService) List(ctx context.Context, userID uint64) ([]responses.APIKeyResponse, error) {
+	keys, err := s.repo.ListByUserID(ctx, s.conn.ConnectGormRead, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al listar las API keys: %w", err)
+	}
+	result := make([]responses.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		result = append(result, apiKeyResponse(&keys[i]))
+	}
+	return result, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, userID, id uint64) error {
+	revoked, err := s.repo.Revoke(ctx, s.conn.ConnectGormWrite, userID, id, s.now())
+	if err != nil {
+		return fmt.Errorf("error al revocar la API key: %w", err)
+	}
+	if !revoked {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, key, clientCode string) (*models.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, domain.ErrAuthentication
+	}
+	apiKey, err := s.repo.GetByHash(ctx, s.conn.ConnectGormRead, hashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAuthentication
+		}
+		return nil, fmt.Errorf("error al buscar la API key: %w", err)
+	}
+	now := s.now()
+	if !apiKey.Active(now) || apiKey.ClientCode != clientCode {
+		return nil, domain.ErrAuthentication
+	}
+
+	owner, err := s.userReader.GetByID(ctx, s.conn.ConnectGormRead, apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAuthentication
+		}
+		return nil, fmt.Errorf("error al buscar el dueño de la API key: %w", err)
+	}
+	if !owner.IsActive {
+		return nil, domain.ErrAuthentication
+	}
+
+	// El registro de uso es informativo: si falla, la solicitud sigue.
+	if err := s.repo.TouchLastUsed(ctx, s.conn.ConnectGormWrite, apiKey.ID, now, now.Add(-apiKeyTouchInterval)); err != nil {
+		log.Printf("Error al registrar el uso de la API key %d: %v", apiKey.ID, err)
+	}
+	return apiKey, nil
+}
+
+func apiKeyResponse(key *models.APIKey) responses.APIKeyResponse {
+	return responses.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		ClientCode: key.ClientCode,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// newAPIKey genera una clave de 256 bits con el prefijo de la API.
+func newAPIKey() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("no se pudo generar la API key: %v", err))
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/models"
+	apiKeyRepo "go-fiber-core/internal/repositories/apikey"
+	permissionRepo "go-fiber-core/internal/repositories/permission"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeAPIKeyRepo guarda las claves en memoria (ignora el *gorm.DB).
+type fakeAPIKeyRepo struct {
+	apiKeyRepo.APIKeyRepository
+	keys []*models.APIKey
+}
+
+func (f *fakeAPIKeyRepo) GetByHash(_ context.Context, _ *gorm.DB, keyHash string) (*models.APIKey, error) {
+	for _, key := range f.keys {
+		if key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeAPIKeyRepo) ClientCodeOwnedByOther(_ context.Context, _ *gorm.DB, clientCode string, userID uint64) (bool, error) {
+	for _, key := range f.keys {
+		if key.ClientCode == clientCode && key.UserID != userID && key.RevokedAt == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeAPIKeyRepo) Create(_ context.Context, _ *gorm.DB, key *models.APIKey) error {
+	key.ID = uint64(len(f.keys) + 1)
+	f.keys = append(f.keys, key)
+	return nil
+}
+
+func (f *fakeAPIKeyRepo) Revoke(_ context.Context, _ *gorm.DB, userID, id uint64, at time.Time) (bool, error) {
+	for _, key := range f.keys {
+		if key.ID == id && key.UserID == userID && key.RevokedAt == nil {
+			key.RevokedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeAPIKeyRepo) TouchLastUsed(_ context.Context, _ *gorm.DB, id uint64, at, since time.Time) error {
+	for _, key := range f.keys {
+		if key.ID == id && (key.LastUsedAt == nil || key.LastUsedAt.Before(since)) {
+			key.LastUsedAt = &at
+		}
+	}
+	return nil
+}
+
+type fakePermissionReader struct {
+	permissionRepo.PermissionReader
+	names []string
+}
+
+func (f fakePermissionReader) GetNamesByUserID(context.Context, *gorm.DB, uint64) ([]string, error) {
+	return f.names, nil
+}
+
+func newTestAPIKeyService(user *models.User) (*apiKeyService, *fakeAPIKeyRepo) {
+	repo := &fakeAPIKeyRepo{}
+	s := NewAPIKeyService(
+		&connect.ConnectDTO{},
+		repo,
+		&fakeLoginUserReader{user: user},
+		fakePermissionReader{names: []string{"banks.read", "banks.delete"}},
+	).(*apiKeyService)
+	return s, repo
+}
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestAPIKeyService(&models.User{ID: 7, IsActive: true})
+
+	created, err := s.Create(ctx, 7, requests.CreateAPIKeyRequest{
+		Name: "ERP", ClientCode: "erp-sync", Scopes: []string{"banks.read", "banks.read"},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, apiKeyPrefix))
+	assert.Equal(t, created.Key[:apiKeyDisplayLength], created.Prefix)
+	assert.Equal(t, []string{"banks.read"}, created.Scopes)
+	// Solo se guarda el hash.
+	assert.NotContains(t, repo.keys[0].KeyHash, created.Key)
+
+	key, err := s.Authenticate(ctx, created.Key, "erp-sync")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), key.UserID)
+	assert.NotNil(t, repo.keys[0].LastUsedAt)
+
+	_, err = s.Authenticate(ctx, created.Key, "otro-cliente")
+	assert.ErrorIs(t, err, domain.ErrAuthentication)
+	_, err = s.Authenticate(ctx, created.Key+"x", "erp-sync")
+	assert.ErrorIs(t, err, domain.ErrAuthentication)
+}
+
+func TestAPIKeyService_CreateRejectsScopeNotGranted(t *testing.T) {
+	s, repo := newTestAPIKeyService(&models.User{ID: 7, IsActive: true})
+
+	_, err := s.Create(context.Background(), 7, requests.CreateAPIKeyRequest{
+		Name: "ERP", ClientCode: "erp-sync", Scopes: []string{"users.delete"},
+	})
+
+	var validationErr *domain.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Contains(t, validationErr.Fields, "scopes")
+	assert.Empty(t, repo.keys)
+}
+
+func TestAPIKeyService_CreateRejectsClientCodeOfOtherUser(t *testing.T) {
+	s, repo := newTestAPIKeyService(&models.User{ID: 7, IsActive: true})
+	repo.keys = append(repo.keys, &models.APIKey{ID: 1, UserID: 8, ClientCode: "erp-sync"})
+
+	_, err := s.Create(context.Background(), 7, requests.CreateAPIKeyRequest{
+		Name: "ERP", ClientCode: "erp-sync", Scopes: []string{"banks.read"},
+	})
+
+	var validationErr *domain.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Contains(t, validationErr.Fields, "client_code")
+}
+
+func TestAPIKeyService_RevokedExpiredOrInactiveOwner(t *testing.T) {
+	ctx := context.Background()
+	owner := &models.User{ID: 7, IsActive: true}
+	s, _ := newTestAPIKeyService(owner)
+	days := 1
+
+	revoked, err := s.Create(ctx, 7, requests.CreateAPIKeyRequest{Name: "a", ClientCode: "a", Scopes: []string{"banks.read"}})
+	require.NoError(t, err)
+	require.NoError(t, s.Revoke(ctx, 7, revoked.ID))
+	_, err = s.Authenticate(ctx, revoked.Key, "a")
+	assert.ErrorIs(t, err, domain.ErrAuthentication)
+	assert.ErrorIs(t, s.Revoke(ctx, 7, revoked.ID), domain.ErrNotFound)
+
+	expiring, err := s.Create(ctx, 7, requests.CreateAPIKeyRequest{Name: "b", ClientCode: "b", Scopes: []string{"banks.read"}, ExpiresInDays: &days})
+	require.NoError(t, err)
+	s.now = func() time.Time { return time.Now().AddDate(0, 0, 2) }
+	_, err = s.Authenticate(ctx, expiring.Key, "b")
+	assert.ErrorIs(t, err, domain.ErrAuthentication)
+
+	s.now = time.Now
+	active, err := s.Create(ctx, 7, requests.CreateAPIKeyRequest{Name: "c", ClientCode: "c", Scopes: []string{"banks.read"}})
+	require.NoError(t, err)
+	owner.IsActive = false
+	_, err = s.Authenticate(ctx, active.Key, "c")
+	assert.ErrorIs(t, err, domain.ErrAuthentication)
+}
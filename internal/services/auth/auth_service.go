@@ -9,13 +9,17 @@ import (
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
+	"go-fiber-core/internal/contextkeys"
 	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/config"
 	"go-fiber-core/internal/dtos/connect"
 	"go-fiber-core/internal/dtos/requests"
 	"go-fiber-core/internal/dtos/responses"
+	"go-fiber-core/internal/logger"
 	"go-fiber-core/internal/models"
 	refreshTokenRepo "go-fiber-core/internal/repositories/refreshtoken"
 	userRepo "go-fiber-core/internal/repositories/user"
@@ -29,15 +33,30 @@ type authService struct {
 	userReader       userRepo.UserReader
 	refreshTokenRepo refreshTokenRepo.RefreshTokenRepository
 	tokenService     TokenService
+	denylist         TokenDenylist
 	menuReader       menuService.MenuReaderService
+	twoFactor        TwoFactorService
+	challenges       TwoFactorChallenges
+	loginGuard       LoginGuard
+	securityLog      *zap.Logger
+
+	requireVerifiedEmail bool
 }
 
+// refreshTokenLifetime es la vida de cada fila de refresh_tokens.
+const refreshTokenLifetime = 7 * 24 * time.Hour
+
 // NewAuthService crea una nueva instancia del servicio de autenticación.
 func NewAuthService(
 	userReader userRepo.UserReader,
 	refreshTokenRepo refreshTokenRepo.RefreshTokenRepository,
 	tokenService TokenService,
+	denylist TokenDenylist,
 	menuReader menuService.MenuReaderService,
+	twoFactor TwoFactorService,
+	challenges TwoFactorChallenges,
+	loginGuard LoginGuard,
+	cfg *config.AppConfig,
 	connect *connect.ConnectDTO,
 ) AuthService {
 	return &authService{
@@ -45,62 +64,128 @@ func NewAuthService(
 		userReader:         userReader,
 		refreshTokenRepo:   refreshTokenRepo,
 		tokenService:       tokenService,
+		denylist:           denylist,
 		menuReader:         menuReader,
+		twoFactor:          twoFactor,
+		challenges:         challenges,
+		loginGuard:         loginGuard,
+		securityLog:        logger.GetLogger("security"),
+
+		requireVerifiedEmail: cfg.Auth.RequireEmailVerification,
 	}
 }
 
 // ────────────────────────────────────────────────
 // LOGIN
 // ────────────────────────────────────────────────
-func (s *authService) Login(ctx context.Context, req requests.LoginRequest) (*responses.LoginResponse, error) {
+func (s *authService) Login(ctx context.Context, req requests.LoginRequest, client ClientInfo) (*responses.LoginResponse, error) {
 	dbRead := s.TransactionManager.Conn.ConnectGormRead
 
+	// 0️⃣ Fuerza bruta: cuenta o IP bloqueadas, o espera progresiva tras fallos recientes
+	if err := s.loginGuard.Check(ctx, req.Email, client.IPAddress); err != nil {
+		return nil, err
+	}
+
 	// 1️⃣ Buscar usuario por email, incluyendo Roles (asumimos GetByEmailWithRoles existe)
 	user, err := s.userReader.GetByEmailWithRoles(ctx, dbRead, req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, domain.ErrAuthentication
+			return nil, s.loginFailed(ctx, req.Email, client)
 		}
 		return nil, fmt.Errorf("error al buscar usuario: %w", err)
 	}
 
 	if !user.IsActive {
-		return nil, domain.ErrAuthentication
+		return nil, s.loginFailed(ctx, req.Email, client)
 	}
 
 	// 2️⃣ Verificar contraseña usando bcrypt
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, domain.ErrAuthentication
+		return nil, s.loginFailed(ctx, req.Email, client)
+	}
+	if s.requireVerifiedEmail && user.EmailVerifiedAt == nil {
+		return nil, domain.ErrEmailNotVerified
 	}
 
-	// 3️⃣ Generar tokens
-	userIDStr := strconv.FormatUint(user.ID, 10)
-	accessToken, refreshToken, err := s.tokenService.GenerateTokens(userIDStr)
-	if err != nil {
-		return nil, errors.New("error al generar tokens")
+	// 3️⃣ Segundo factor: en vez de tokens se devuelve un desafío de corta vida
+	// El contador de fallos se reinicia recién cuando pasa el segundo factor.
+	challenge, err := s.twoFactorChallenge(ctx, user)
+	if err != nil || challenge != nil {
+		return challenge, err
+	}
+	if err := s.loginGuard.RecordSuccess(ctx, req.Email); err != nil {
+		return nil, err
 	}
 
-	// 4️⃣ Guardar nuevo refresh token y limpiar el anterior dentro de una transacción
-	err = s.TransactionManager.ExecuteTx(ctx, func(tx *gorm.DB) error {
-		if err := s.refreshTokenRepo.DeleteByUserID(ctx, tx, user.ID); err != nil {
-			log.Printf("ADVERTENCIA: no se pudo eliminar el refresh token anterior para el usuario %d: %v", user.ID, err)
-		}
-		expiresAt := time.Now().Add(7 * 24 * time.Hour)
-		newRefreshToken := &models.RefreshToken{
-			UserID:    user.ID,
-			Token:     refreshToken,
-			ExpiresAt: expiresAt,
-		}
-		if err := s.refreshTokenRepo.Create(ctx, tx, newRefreshToken); err != nil {
-			return errors.New("error al guardar la sesión")
+	return s.completeLogin(ctx, user, client)
+}
+
+// loginFailed cuenta el intento fallido y devuelve el error para el cliente: siempre el
+// mismo, exista o no la cuenta.
+func (s *authService) loginFailed(ctx context.Context, email string, client ClientInfo) error {
+	if err := s.loginGuard.RecordFailure(ctx, email, client.IPAddress); err != nil {
+		return err
+	}
+	return domain.ErrAuthentication
+}
+
+// twoFactorChallenge devuelve nil si el usuario no necesita el segundo paso.
+func (s *authService) twoFactorChallenge(ctx context.Context, user *models.User) (*responses.LoginResponse, error) {
+	enabled, err := s.twoFactor.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	purpose := ChallengeVerify
+	if !enabled {
+		if !s.twoFactor.IsRequired(user.Roles) {
+			return nil, nil
 		}
-		return nil
-	})
+		purpose = ChallengeSetup
+	}
+
+	token, err := s.challenges.Create(ctx, user.ID, purpose)
+	if err != nil {
+		return nil, err
+	}
+	return &responses.LoginResponse{
+		UserName:               user.Name,
+		TwoFactorRequired:      purpose == ChallengeVerify,
+		TwoFactorSetupRequired: purpose == ChallengeSetup,
+		ChallengeToken:         token,
+	}, nil
+}
+
+// completeLogin abre la sesión de un usuario ya autenticado (contraseña y, si corresponde,
+// segundo factor) y arma la respuesta con tokens, roles y menú.
+func (s *authService) completeLogin(ctx context.Context, user *models.User, client ClientInfo) (*responses.LoginResponse, error) {
+	// 1️⃣ Generar tokens de una sesión nueva
+	userIDStr := strconv.FormatUint(user.ID, 10)
+	sessionID := NewSessionID()
+	accessToken, refreshToken, err := s.tokenService.GenerateTokens(userIDStr, sessionID)
 	if err != nil {
+		return nil, errors.New("error al generar tokens")
+	}
+	if err := s.denylist.TrackSession(ctx, user.ID, sessionID); err != nil {
 		return nil, err
 	}
 
-	// 5️⃣ Construir lista de roles
+	// 2️⃣ Guardar el refresh token de la sesión. Las sesiones de otros dispositivos siguen abiertas.
+	now := time.Now()
+	newRefreshToken := &models.RefreshToken{
+		UserID:     user.ID,
+		SessionID:  sessionID,
+		Token:      refreshToken,
+		UserAgent:  client.userAgent(),
+		IPAddress:  client.IPAddress,
+		ExpiresAt:  now.Add(refreshTokenLifetime),
+		LastUsedAt: now,
+	}
+	if err := s.refreshTokenRepo.Create(ctx, s.TransactionManager.Conn.ConnectGormWrite, newRefreshToken); err != nil {
+		log.Printf("ERROR al guardar el refresh token del usuario %d: %v", user.ID, err)
+		return nil, errors.New("error al guardar la sesión")
+	}
+
+	// 3️⃣ Construir lista de roles
 	var roleIDs []uint64
 	var roleNames []string
 	for _, r := range user.Roles {
@@ -108,13 +193,13 @@ func (s *authService) Login(ctx context.Context, req requests.LoginRequest) (*re
 		roleNames = append(roleNames, r.Name)
 	}
 
-	// 6️⃣ Obtener menús del usuario: Llama al servicio de menú que construye la jerarquía
+	// 4️⃣ Obtener menús del usuario: Llama al servicio de menú que construye la jerarquía
 	menuItems, err := s.menuReader.GetMenuByUser(ctx, user.ID)
 	if err != nil {
 		return nil, fmt.Errorf("error al obtener el menú: %w", err)
 	}
 
-	// 7️⃣ Construir respuesta
+	// 5️⃣ Construir respuesta
 	resp := &responses.LoginResponse{
 		Token:        accessToken,
 		RefreshToken: refreshToken,
@@ -127,10 +212,79 @@ func (s *authService) Login(ctx context.Context, req requests.LoginRequest) (*re
 	return resp, nil
 }
 
+// ────────────────────────────────────────────────
+// LOGIN: SEGUNDO PASO
+// ────────────────────────────────────────────────
+func (s *authService) SetupTwoFactor(ctx context.Context, challengeToken string) (*responses.TwoFactorEnrollResponse, error) {
+	challenge, err := s.challenges.Get(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Purpose != ChallengeSetup {
+		return nil, domain.ErrAuthentication
+	}
+	return s.twoFactor.Enroll(ctx, challenge.UserID)
+}
+
+func (s *authService) VerifyTwoFactor(ctx context.Context, challengeToken, code string, client ClientInfo) (*responses.LoginResponse, error) {
+	challenge, err := s.challenges.Attempt(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Se relee el usuario: pudo ser desactivado entre los dos pasos, y los códigos fallidos
+	// cuentan para el bloqueo de su cuenta igual que las contraseñas.
+	user, err := s.userReader.GetByID(ctx, s.TransactionManager.Conn.ConnectGormWrite, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAuthentication
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+	if !user.IsActive {
+		return nil, domain.ErrAuthentication
+	}
+	if err := s.loginGuard.Check(ctx, user.Email, client.IPAddress); err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	switch challenge.Purpose {
+	case ChallengeVerify:
+		err = s.twoFactor.Verify(ctx, challenge.UserID, code)
+	case ChallengeSetup:
+		recoveryCodes, err = s.twoFactor.Confirm(ctx, challenge.UserID, code)
+	default:
+		err = domain.ErrAuthentication
+	}
+	if errors.Is(err, errInvalidTwoFactorCode) {
+		if guardErr := s.loginGuard.RecordFailure(ctx, user.Email, client.IPAddress); guardErr != nil {
+			return nil, guardErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// El desafío sirve para una sola sesión.
+	if err := s.challenges.Delete(ctx, challengeToken); err != nil {
+		return nil, fmt.Errorf("error al descartar el desafío de 2FA: %w", err)
+	}
+	if err := s.loginGuard.RecordSuccess(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.completeLogin(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	resp.RecoveryCodes = recoveryCodes
+	return resp, nil
+}
+
 // ────────────────────────────────────────────────
 // REFRESH TOKEN
 // ────────────────────────────────────────────────
-func (s *authService) Refresh(ctx context.Context, refreshTokenString string) (string, string, error) {
+func (s *authService) Refresh(ctx context.Context, refreshTokenString string, client ClientInfo) (string, string, error) {
 	token, err := s.tokenService.ValidateToken(refreshTokenString)
 	if err != nil || !token.Valid {
 		return "", "", domain.ErrAuthentication
@@ -141,8 +295,6 @@ func (s *authService) Refresh(ctx context.Context, refreshTokenString string) (s
 		return "", "", domain.ErrAuthentication
 	}
 
-	var newAccessToken, newRefreshToken string
-
 	dbRead := s.TransactionManager.Conn.ConnectGormRead
 	storedToken, err := s.refreshTokenRepo.GetByToken(ctx, dbRead, refreshTokenString)
 	if err != nil {
@@ -152,20 +304,57 @@ func (s *authService) Refresh(ctx context.Context, refreshTokenString string) (s
 		return "", "", fmt.Errorf("error al buscar refresh token: %w", err)
 	}
 
-	err = s.TransactionManager.ExecuteTx(ctx, func(tx *gorm.DB) error {
-		if err := s.refreshTokenRepo.DeleteByUserID(ctx, tx, storedToken.UserID); err != nil {
-			return fmt.Errorf("error al eliminar refresh token anterior por UserID: %w", err)
+	now := time.Now()
+	if storedToken.RevokedAt != nil || !now.Before(storedToken.ExpiresAt) {
+		return "", "", domain.ErrAuthentication
+	}
+	// Un token ya rotado solo lo tiene quien lo copió: se cierra la sesión entera.
+	if storedToken.RotatedAt != nil {
+		return "", "", s.revokeReusedFamily(ctx, storedToken, client)
+	}
+
+	// La sesión (familia) es la de la fila guardada; el sid del token debe coincidir.
+	session := contextkeys.Session{ID: storedToken.SessionID}
+	if claimed, ok := SessionFromClaims(claims); ok {
+		if claimed.ID != storedToken.SessionID {
+			return "", "", domain.ErrAuthentication
 		}
-		userIDStr := strconv.FormatUint(storedToken.UserID, 10)
-		newAccessToken, newRefreshToken, err = s.tokenService.GenerateTokens(userIDStr)
+		session = claimed
+	}
+	revoked, err := s.denylist.IsRevoked(ctx, session)
+	if err != nil {
+		return "", "", err
+	}
+	if revoked {
+		return "", "", domain.ErrAuthentication
+	}
+
+	userIDStr := strconv.FormatUint(storedToken.UserID, 10)
+	newAccessToken, newRefreshToken, err := s.tokenService.GenerateTokens(userIDStr, storedToken.SessionID)
+	if err != nil {
+		return "", "", errors.New("error al generar nuevos tokens")
+	}
+
+	reused := false
+	err = s.TransactionManager.ExecuteTx(ctx, func(tx *gorm.DB) error {
+		rotated, err := s.refreshTokenRepo.MarkRotated(ctx, tx, storedToken.ID, now)
 		if err != nil {
-			return errors.New("error al generar nuevos tokens")
+			return fmt.Errorf("error al rotar el refresh token: %w", err)
+		}
+		if !rotated {
+			// Otra solicitud usó el mismo token entre la lectura y la rotación.
+			reused = true
+			return domain.ErrAuthentication
 		}
-		expiresAt := time.Now().Add(7 * 24 * time.Hour)
 		newRefreshTokenModel := &models.RefreshToken{
-			UserID:    storedToken.UserID,
-			Token:     newRefreshToken,
-			ExpiresAt: expiresAt,
+			UserID:     storedToken.UserID,
+			SessionID:  storedToken.SessionID,
+			Token:      newRefreshToken,
+			UserAgent:  client.userAgent(),
+			IPAddress:  client.IPAddress,
+			ExpiresAt:  now.Add(refreshTokenLifetime),
+			LastUsedAt: now,
+			CreatedAt:  storedToken.CreatedAt, // Inicio de la sesión, no de la rotación
 		}
 		if err := s.refreshTokenRepo.Create(ctx, tx, newRefreshTokenModel); err != nil {
 			return errors.New("error al guardar la nueva sesión")
@@ -173,6 +362,9 @@ func (s *authService) Refresh(ctx context.Context, refreshTokenString string) (s
 		return nil
 	})
 
+	if reused {
+		return "", "", s.revokeReusedFamily(ctx, storedToken, client)
+	}
 	if err != nil {
 		if errors.Is(err, domain.ErrAuthentication) {
 			return "", "", err
@@ -180,21 +372,115 @@ func (s *authService) Refresh(ctx context.Context, refreshTokenString string) (s
 		log.Printf("ERROR en Refresh transaction: %v", err)
 		return "", "", domain.ErrAuthentication
 	}
+	if err := s.denylist.TrackSession(ctx, storedToken.UserID, storedToken.SessionID); err != nil {
+		return "", "", err
+	}
 
 	return newAccessToken, newRefreshToken, nil
 }
 
+// revokeReusedFamily responde al reuso de un refresh token ya rotado: revoca todos los
+// tokens de la sesión, deniega sus access tokens y registra el evento de seguridad.
+// Siempre devuelve un error para el cliente.
+func (s *authService) revokeReusedFamily(ctx context.Context, reused *models.RefreshToken, client ClientInfo) error {
+	s.securityLog.Warn("refresh token reutilizado: se revoca la sesión",
+		zap.Uint64("user_id", reused.UserID),
+		zap.String("session_id", reused.SessionID),
+		zap.Uint("refresh_token_id", reused.ID),
+		zap.String("user_agent", client.UserAgent),
+		zap.String("ip_address", client.IPAddress),
+	)
+	log.Printf("SEGURIDAD: reuso de refresh token del usuario %d (sesión %s, IP %s); se revoca la sesión",
+		reused.UserID, reused.SessionID, client.IPAddress)
+
+	if err := s.revokeSession(ctx, reused.UserID, reused.SessionID); err != nil {
+		return err
+	}
+	return domain.ErrAuthentication
+}
+
 // ────────────────────────────────────────────────
 // LOGOUT
 // ────────────────────────────────────────────────
-func (s *authService) Logout(ctx context.Context, userID uint64) error {
+func (s *authService) Logout(ctx context.Context, userID uint64, session contextkeys.Session) error {
 	dbWrite := s.TransactionManager.Conn.ConnectGormWrite
-	err := s.refreshTokenRepo.DeleteByUserID(ctx, dbWrite, userID)
+	if _, err := s.refreshTokenRepo.RevokeSession(ctx, dbWrite, userID, session.ID, time.Now()); err != nil {
+		return fmt.Errorf("error al cerrar sesión para el usuario %d: %w", userID, err)
+	}
+	return s.denylist.RevokeSession(ctx, userID, session)
+}
+
+// ────────────────────────────────────────────────
+// SESIONES
+// ────────────────────────────────────────────────
+func (s *authService) ListSessions(ctx context.Context, userID uint64, currentSessionID string) ([]responses.SessionResponse, error) {
+	dbRead := s.TransactionManager.Conn.ConnectGormRead
+	tokens, err := s.refreshTokenRepo.GetActiveSessions(ctx, dbRead, userID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil
+		return nil, fmt.Errorf("error al listar las sesiones del usuario %d: %w", userID, err)
+	}
+	sessions := make([]responses.SessionResponse, 0, len(tokens))
+	for _, t := range tokens {
+		sessions = append(sessions, responses.SessionResponse{
+			ID:         t.SessionID,
+			UserAgent:  t.UserAgent,
+			IPAddress:  t.IPAddress,
+			CreatedAt:  t.CreatedAt,
+			LastUsedAt: t.LastUsedAt,
+			ExpiresAt:  t.ExpiresAt,
+			Current:    t.SessionID == currentSessionID,
+		})
+	}
+	return sessions, nil
+}
+
+func (s *authService) RevokeSession(ctx context.Context, userID uint64, sessionID string) error {
+	dbRead := s.TransactionManager.Conn.ConnectGormRead
+	tokens, err := s.refreshTokenRepo.GetActiveSessions(ctx, dbRead, userID)
+	if err != nil {
+		return fmt.Errorf("error al buscar las sesiones del usuario %d: %w", userID, err)
+	}
+	for _, t := range tokens {
+		if t.SessionID == sessionID {
+			return s.revokeSession(ctx, userID, sessionID)
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (s *authService) RevokeOtherSessions(ctx context.Context, userID uint64, currentSessionID string) error {
+	dbRead := s.TransactionManager.Conn.ConnectGormRead
+	tokens, err := s.refreshTokenRepo.GetActiveSessions(ctx, dbRead, userID)
+	if err != nil {
+		return fmt.Errorf("error al buscar las sesiones del usuario %d: %w", userID, err)
+	}
+	for _, t := range tokens {
+		if t.SessionID == currentSessionID {
+			continue
+		}
+		if err := s.revokeSession(ctx, userID, t.SessionID); err != nil {
+			return err
 		}
-		return fmt.Errorf("error al cerrar sesión para el usuario %d: %w", userID, err)
 	}
 	return nil
 }
+
+// revokeSession revoca la familia de refresh tokens y deniega los access tokens de la sesión.
+func (s *authService) revokeSession(ctx context.Context, userID uint64, sessionID string) error {
+	dbWrite := s.TransactionManager.Conn.ConnectGormWrite
+	if _, err := s.refreshTokenRepo.RevokeSession(ctx, dbWrite, userID, sessionID, time.Now()); err != nil {
+		return fmt.Errorf("error al revocar la sesión %s del usuario %d: %w", sessionID, userID, err)
+	}
+	return s.denylist.RevokeSession(ctx, userID, contextkeys.Session{ID: sessionID})
+}
+
+// ────────────────────────────────────────────────
+// CERRAR TODAS LAS SESIONES
+// ────────────────────────────────────────────────
+func (s *authService) RevokeUserSessions(ctx context.Context, userID uint64) error {
+	dbWrite := s.TransactionManager.Conn.ConnectGormWrite
+	if err := s.refreshTokenRepo.RevokeByUserID(ctx, dbWrite, userID, time.Now()); err != nil {
+		return fmt.Errorf("error al cerrar las sesiones del usuario %d: %w", userID, err)
+	}
+	return s.denylist.RevokeUser(ctx, userID)
+}
@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-fiber-core/internal/contextkeys"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/models"
+	userRepo "go-fiber-core/internal/repositories/user"
+	"go-fiber-core/internal/services"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// fakeRefreshTokenRepo guarda los refresh tokens en memoria (ignora el *gorm.DB).
+type fakeRefreshTokenRepo struct {
+	tokens []*models.RefreshToken
+}
+
+func (r *fakeRefreshTokenRepo) GetByToken(_ context.Context, _ *gorm.DB, token string) (*models.RefreshToken, error) {
+	for _, t := range r.tokens {
+		if t.Token == token {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRefreshTokenRepo) GetActiveSessions(_ context.Context, _ *gorm.DB, userID uint64) ([]models.RefreshToken, error) {
+	var out []models.RefreshToken
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.RotatedAt == nil && t.RevokedAt == nil && time.Now().Before(t.ExpiresAt) {
+			out = append(out, *t)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeRefreshTokenRepo) Create(_ context.Context, _ *gorm.DB, token *models.RefreshToken) error {
+	token.ID = uint(len(r.tokens) + 1)
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) DeleteByUserID(context.Context, *gorm.DB, uint64) error { return nil }
+
+func (r *fakeRefreshTokenRepo) MarkRotated(_ context.Context, _ *gorm.DB, id uint, at time.Time) (bool, error) {
+	for _, t := range r.tokens {
+		if t.ID == id && t.RotatedAt == nil && t.RevokedAt == nil {
+			t.RotatedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeRefreshTokenRepo) RevokeSession(_ context.Context, _ *gorm.DB, userID uint64, sessionID string, at time.Time) (int64, error) {
+	var n int64
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.SessionID == sessionID && t.RevokedAt == nil {
+			t.RevokedAt = &at
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeRefreshTokenRepo) RevokeByUserID(_ context.Context, _ *gorm.DB, userID uint64, at time.Time) error {
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func newTestAuthService(t *testing.T) (*authService, *fakeRefreshTokenRepo, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	require.NoError(t, err)
+
+	denylist, _ := newTestDenylist(t)
+	repo := &fakeRefreshTokenRepo{}
+	cfg := &config.AppConfig{JWTConfig: config.JWTConfig{
+		JwtAccessSecret: "access-secret", JwtRefreshSecret: "refresh-secret",
+		JwtAccessTtlMinutes: 15, JwtRefreshTtlDays: 7,
+	}}
+	keys, err := NewKeyRing(cfg)
+	require.NoError(t, err)
+	return &authService{
+		TransactionManager: services.NewTransactionManager(&connect.ConnectDTO{ConnectGormRead: gormDB, ConnectGormWrite: gormDB}),
+		refreshTokenRepo:   repo,
+		tokenService:       NewTokenService(cfg, keys),
+		denylist:           denylist,
+		loginGuard:         NewLoginGuard(&connect.ConnectDTO{}, cfg, nil),
+		securityLog:        zap.NewNop(),
+	}, repo, mock
+}
+
+// openSession simula un login: guarda el refresh token de una sesión nueva.
+func openSession(t *testing.T, s *authService, repo *fakeRefreshTokenRepo, userID uint64) (string, string) {
+	sessionID := NewSessionID()
+	_, refresh, err := s.tokenService.GenerateTokens(strconv.FormatUint(userID, 10), sessionID)
+	require.NoError(t, err)
+	require.NoError(t, s.denylist.TrackSession(context.Background(), userID, sessionID))
+	require.NoError(t, repo.Create(context.Background(), nil, &models.RefreshToken{
+		UserID: userID, SessionID: sessionID, Token: refresh,
+		ExpiresAt: time.Now().Add(time.Hour), LastUsedAt: time.Now(),
+	}))
+	return sessionID, refresh
+}
+
+func TestAuthService_RefreshRotatesWithinFamily(t *testing.T) {
+	s, repo, mock := newTestAuthService(t)
+	ctx := context.Background()
+	sessionID, refresh := openSession(t, s, repo, 7)
+	client := ClientInfo{UserAgent: "firefox", IPAddress: "10.0.0.1"}
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	_, newRefresh, err := s.Refresh(ctx, refresh, client)
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Len(t, repo.tokens, 2)
+	assert.NotNil(t, repo.tokens[0].RotatedAt)
+	assert.Equal(t, newRefresh, repo.tokens[1].Token)
+	assert.Equal(t, sessionID, repo.tokens[1].SessionID)
+	assert.Equal(t, "firefox", repo.tokens[1].UserAgent)
+	assert.Equal(t, "10.0.0.1", repo.tokens[1].IPAddress)
+}
+
+func TestAuthService_RefreshReuseRevokesFamily(t *testing.T) {
+	s, repo, mock := newTestAuthService(t)
+	ctx := context.Background()
+	sessionID, refresh := openSession(t, s, repo, 7)
+	otherSessionID, _ := openSession(t, s, repo, 7)
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	_, _, err := s.Refresh(ctx, refresh, ClientInfo{})
+	require.NoError(t, err)
+
+	// El token viejo se vuelve a presentar: se revoca toda la sesión.
+	_, _, err = s.Refresh(ctx, refresh, ClientInfo{IPAddress: "203.0.113.9"})
+	assert.ErrorIs(t, err, domain.ErrAuthentication)
+
+	for _, token := range repo.tokens {
+		if token.SessionID == sessionID {
+			assert.NotNil(t, token.RevokedAt)
+		} else {
+			assert.Nil(t, token.RevokedAt)
+		}
+	}
+	revoked, err := s.denylist.IsRevoked(ctx, contextkeys.Session{ID: sessionID})
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	// La sesión del otro dispositivo sigue abierta.
+	sessions, err := s.ListSessions(ctx, 7, otherSessionID)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, otherSessionID, sessions[0].ID)
+		assert.True(t, sessions[0].Current)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthService_RevokeSession(t *testing.T) {
+	s, repo, _ := newTestAuthService(t)
+	ctx := context.Background()
+	current, _ := openSession(t, s, repo, 7)
+	other, _ := openSession(t, s, repo, 7)
+
+	assert.ErrorIs(t, s.RevokeSession(ctx, 7, "no-existe"), domain.ErrNotFound)
+	assert.ErrorIs(t, s.RevokeSession(ctx, 8, other), domain.ErrNotFound)
+
+	assert.NoError(t, s.RevokeOtherSessions(ctx, 7, current))
+	sessions, err := s.ListSessions(ctx, 7, current)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, current, sessions[0].ID)
+	}
+	revoked, _ := s.denylist.IsRevoked(ctx, contextkeys.Session{ID: other})
+	assert.True(t, revoked)
+}
+
+// fakeLoginUserReader devuelve siempre el mismo usuario por email.
+type fakeLoginUserReader struct {
+	userRepo.UserReader
+	user *models.User
+}
+
+func (f *fakeLoginUserReader) GetByEmailWithRoles(context.Context, *gorm.DB, string) (*models.User, error) {
+	return f.user, nil
+}
+
+func TestAuthService_LoginRefusesUnverifiedEmail(t *testing.T) {
+	s, repo, _ := newTestAuthService(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("clave-segura"), bcrypt.MinCost)
+	require.NoError(t, err)
+	s.userReader = &fakeLoginUserReader{user: &models.User{ID: 7, Password: string(hash), IsActive: true}}
+	s.requireVerifiedEmail = true
+
+	// Con la contraseña incorrecta no se revela que falta verificar el email.
+	_, err = s.Login(context.Background(), requests.LoginRequest{Email: "ana@test.com", Password: "otra-clave"}, ClientInfo{})
+	assert.ErrorIs(t, err, domain.ErrAuthentication)
+
+	_, err = s.Login(context.Background(), requests.LoginRequest{Email: "ana@test.com", Password: "clave-segura"}, ClientInfo{})
+	assert.ErrorIs(t, err, domain.ErrEmailNotVerified)
+	assert.Empty(t, repo.tokens)
+}
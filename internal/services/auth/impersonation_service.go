@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/dtos/responses"
+	permissionRepo "go-fiber-core/internal/repositories/permission"
+	userRepo "go-fiber-core/internal/repositories/user"
+)
+
+const (
+	defaultImpersonationTTL = 15 * time.Minute
+	// impersonationSessionPrefix distingue en el sid las sesiones de suplantación.
+	impersonationSessionPrefix = "imp-"
+)
+
+// ImpersonationService emite tokens para que el personal de soporte vea la aplicación
+// como otro usuario (su menú, sus permisos).
+//
+// El token es un access token del usuario suplantado con la claim act del usuario real y
+// una sesión propia, registrada para los dos: desactivar o cerrar las sesiones de
+// cualquiera de ellos también corta la suplantación. Durante la suplantación
+// AuthMiddleware bloquea las escrituras y registra cada solicitud.
+type ImpersonationService interface {
+	// Impersonate devuelve domain.ErrForbidden si el suplantado tiene permisos que el actor
+	// no tiene: suplantar no sirve para escalar privilegios.
+	Impersonate(ctx context.Context, actorID, subjectID uint64) (*responses.ImpersonationResponse, error)
+}
+
+type impersonationService struct {
+	conn         *connect.ConnectDTO
+	userReader   userRepo.UserReader
+	permissions  permissionRepo.PermissionReader
+	tokenService TokenService
+	denylist     TokenDenylist
+	ttl          time.Duration
+	now          func() time.Time
+}
+
+func NewImpersonationService(
+	conn *connect.ConnectDTO,
+	userReader userRepo.UserReader,
+	permissions permissionRepo.PermissionReader,
+	tokenService TokenService,
+	denylist TokenDenylist,
+	cfg *config.AppConfig,
+) ImpersonationService {
+	// El token no puede vivir más que un access token: la denylist deniega las sesiones
+	// cerradas solo por ese tiempo, y un token más largo volvería a valer después.
+	ttl := minutesOr(cfg.Auth.ImpersonationTtlMinutes, defaultImpersonationTTL)
+	if maxTTL := accessTTL(cfg.JWTConfig); maxTTL > 0 && ttl > maxTTL {
+		ttl = maxTTL
+	}
+	return &impersonationService{
+		conn:         conn,
+		userReader:   userReader,
+		permissions:  permissions,
+		tokenService: tokenService,
+		denylist:     denylist,
+		ttl:          ttl,
+		now:          time.Now,
+	}
+}
+
+func (s *impersonationService) Impersonate(ctx context.Context, actorID, subjectID uint64) (*responses.ImpersonationResponse, error) {
+	if actorID == subjectID {
+		return nil, domain.NewValidationError(map[string][]string{
+			"id": {"No podés suplantarte a vos mismo."},
+		})
+	}
+
+	dbRead := s.conn.ConnectGormRead
+	subject, err := s.userReader.GetByID(ctx, dbRead, subjectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+	if !subject.IsActive {
+		return nil, domain.NewValidationError(map[string][]string{
+			"id": {"El usuario está inactivo."},
+		})
+	}
+
+	actorPermissions, err := s.permissions.GetNamesByUserID(ctx, dbRead, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los permisos del usuario: %w", err)
+	}
+	subjectPermissions, err := s.permissions.GetNamesByUserID(ctx, dbRead, subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los permisos del usuario suplantado: %w", err)
+	}
+	for _, permission := range subjectPermissions {
+		if !slices.Contains(actorPermissions, permission) {
+			return nil, domain.ErrForbidden
+		}
+	}
+
+	sessionID := impersonationSessionPrefix + NewSessionID()
+	for _, userID := range []uint64{actorID, subjectID} {
+		if err := s.denylist.TrackSession(ctx, userID, sessionID); err != nil {
+			return nil, err
+		}
+	}
+	expiresAt := s.now().Add(s.ttl)
+	token, err := s.tokenService.GenerateImpersonationToken(
+		strconv.FormatUint(actorID, 10), strconv.FormatUint(subjectID, 10), sessionID, s.ttl)
+	if err != nil {
+		return nil, fmt.Errorf("error al generar el token de suplantación: %w", err)
+	}
+
+	log.Printf("SUPLANTACIÓN: actor=%d inicia la suplantación de usuario=%d (sesión %s, vence %s)",
+		actorID, subjectID, sessionID, expiresAt.Format(time.RFC3339))
+	return &responses.ImpersonationResponse{
+		AccessToken: token,
+		ExpiresAt:   expiresAt,
+		ActorID:     actorID,
+		UserID:      subjectID,
+		UserName:    subject.Name,
+	}, nil
+}
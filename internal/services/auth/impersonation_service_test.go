@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/models"
+	permissionRepo "go-fiber-core/internal/repositories/permission"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeUserPermissions devuelve los permisos de cada usuario.
+type fakeUserPermissions struct {
+	permissionRepo.PermissionReader
+	byUser map[uint64][]string
+}
+
+func (f fakeUserPermissions) GetNamesByUserID(_ context.Context, _ *gorm.DB, userID uint64) ([]string, error) {
+	return f.byUser[userID], nil
+}
+
+// newTestImpersonation arma el servicio con el actor 1 (soporte) y el sujeto 2.
+func newTestImpersonation(t *testing.T, subject *models.User, byUser map[uint64][]string) (*impersonationService, TokenService, TokenDenylist) {
+	cfg := &config.AppConfig{
+		JWTConfig: config.JWTConfig{JwtAccessSecret: "access-secret", JwtRefreshSecret: "refresh-secret", JwtAccessTtlMinutes: 15, JwtRefreshTtlDays: 7},
+		Auth:      config.AuthConfig{ImpersonationTtlMinutes: 10},
+	}
+	ring, err := NewKeyRing(cfg)
+	require.NoError(t, err)
+	tokens := NewTokenService(cfg, ring)
+	denylist, _ := newTestDenylist(t)
+	s := NewImpersonationService(
+		&connect.ConnectDTO{},
+		&fakeLoginUserReader{user: subject},
+		fakeUserPermissions{byUser: byUser},
+		tokens,
+		denylist,
+		cfg,
+	).(*impersonationService)
+	return s, tokens, denylist
+}
+
+func TestImpersonationService_IssuesTokenWithActor(t *testing.T) {
+	ctx := context.Background()
+	s, tokens, denylist := newTestImpersonation(t, &models.User{ID: 2, Name: "Coordinadora", IsActive: true}, map[uint64][]string{
+		1: {"users.impersonate", "menus.read"},
+		2: {"menus.read"},
+	})
+
+	resp, err := s.Impersonate(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), resp.ActorID)
+	assert.Equal(t, uint64(2), resp.UserID)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), resp.ExpiresAt, 5*time.Second)
+
+	token, err := tokens.ValidateToken(resp.AccessToken)
+	require.NoError(t, err)
+	claims := token.Claims.(jwt.MapClaims)
+	assert.Equal(t, "2", claims["sub"])
+	assert.Equal(t, "access", claims["typ"])
+	actorID, ok := ActorFromClaims(claims)
+	assert.True(t, ok)
+	assert.Equal(t, "1", actorID)
+
+	// Cerrar las sesiones del actor corta también la suplantación.
+	session, ok := SessionFromClaims(claims)
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(session.ID, impersonationSessionPrefix))
+	require.NoError(t, denylist.RevokeUser(ctx, 1))
+	revoked, err := denylist.IsRevoked(ctx, session)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestNewImpersonationService_CapsTTLAtAccessTTL(t *testing.T) {
+	cfg := &config.AppConfig{
+		JWTConfig: config.JWTConfig{JwtAccessTtlMinutes: 15},
+		Auth:      config.AuthConfig{ImpersonationTtlMinutes: 120},
+	}
+	s := NewImpersonationService(&connect.ConnectDTO{}, nil, nil, nil, nil, cfg).(*impersonationService)
+	assert.Equal(t, 15*time.Minute, s.ttl)
+
+	cfg.Auth.ImpersonationTtlMinutes = 5
+	s = NewImpersonationService(&connect.ConnectDTO{}, nil, nil, nil, nil, cfg).(*impersonationService)
+	assert.Equal(t, 5*time.Minute, s.ttl)
+}
+
+func TestImpersonationService_RejectsPrivilegeEscalation(t *testing.T) {
+	s, _, _ := newTestImpersonation(t, &models.User{ID: 2, IsActive: true}, map[uint64][]string{
+		1: {"users.impersonate", "menus.read"},
+		2: {"menus.read", "users.force_delete"},
+	})
+
+	_, err := s.Impersonate(context.Background(), 1, 2)
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+func TestImpersonationService_RejectsSelfAndInactive(t *testing.T) {
+	s, _, _ := newTestImpersonation(t, &models.User{ID: 2, IsActive: false}, map[uint64][]string{})
+	var validationErr *domain.ValidationError
+
+	_, err := s.Impersonate(context.Background(), 1, 1)
+	assert.True(t, errors.As(err, &validationErr))
+
+	_, err = s.Impersonate(context.Background(), 1, 2)
+	assert.True(t, errors.As(err, &validationErr))
+}
+
+func TestActorFromClaims_RegularToken(t *testing.T) {
+	_, ok := ActorFromClaims(jwt.MapClaims{"sub": "2", "typ": "access"})
+	assert.False(t, ok)
+}
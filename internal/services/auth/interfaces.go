@@ -2,21 +2,70 @@ package auth
 
 import (
 	"context"
+	"go-fiber-core/internal/contextkeys"
 	"go-fiber-core/internal/dtos/requests"
 	"go-fiber-core/internal/dtos/responses"
+	"strings"
+	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
 )
 
 // AuthService define la interfaz para la lógica de autenticación.
 type AuthService interface {
-	Login(ctx context.Context, req requests.LoginRequest) (*responses.LoginResponse, error)
-	Refresh(ctx context.Context, refreshTokenString string) (newAccessToken string, newRefreshToken string, err error)
-	Logout(ctx context.Context, userID uint64) error
+	// Login abre una sesión nueva (un dispositivo) sin cerrar las demás del usuario.
+	// Si el usuario tiene 2FA (o un rol lo exige) no devuelve tokens sino un challenge_token
+	// que se completa con VerifyTwoFactor.
+	Login(ctx context.Context, req requests.LoginRequest, client ClientInfo) (*responses.LoginResponse, error)
+	// SetupTwoFactor inscribe el segundo factor durante el login, cuando un rol lo exige y
+	// el usuario todavía no lo configuró.
+	SetupTwoFactor(ctx context.Context, challengeToken string) (*responses.TwoFactorEnrollResponse, error)
+	// VerifyTwoFactor completa el login con el código TOTP o uno de recuperación (o con el
+	// primer código, si el desafío es de configuración) y abre la sesión.
+	VerifyTwoFactor(ctx context.Context, challengeToken, code string, client ClientInfo) (*responses.LoginResponse, error)
+	// Refresh rota el refresh token dentro de su sesión. Si el token ya había sido rotado
+	// (reuso), revoca la sesión completa y registra un evento de seguridad.
+	Refresh(ctx context.Context, refreshTokenString string, client ClientInfo) (newAccessToken string, newRefreshToken string, err error)
+	// Logout cierra la sesión actual: revoca sus refresh tokens y deniega los access tokens
+	// de la sesión hasta que vencen.
+	Logout(ctx context.Context, userID uint64, session contextkeys.Session) error
+	// ListSessions devuelve las sesiones abiertas del usuario, marcando la actual.
+	ListSessions(ctx context.Context, userID uint64, currentSessionID string) ([]responses.SessionResponse, error)
+	// RevokeSession cierra una sesión abierta del usuario (domain.ErrNotFound si no existe).
+	RevokeSession(ctx context.Context, userID uint64, sessionID string) error
+	SessionRevoker
+}
+
+// ClientInfo identifica el dispositivo que abre o usa una sesión.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// maxUserAgentLength es el largo de refresh_tokens.user_agent.
+const maxUserAgentLength = 255
+
+func (c ClientInfo) userAgent() string {
+	if len(c.UserAgent) <= maxUserAgentLength {
+		return c.UserAgent
+	}
+	return strings.ToValidUTF8(c.UserAgent[:maxUserAgentLength], "")
+}
+
+// SessionRevoker cierra todas las sesiones de un usuario (logout en todos los dispositivos).
+// Se usa al cambiar la contraseña, al desactivar o borrar el usuario y desde el endpoint de administración.
+type SessionRevoker interface {
+	RevokeUserSessions(ctx context.Context, userID uint64) error
+	// RevokeOtherSessions cierra todas las sesiones del usuario salvo la actual.
+	RevokeOtherSessions(ctx context.Context, userID uint64, currentSessionID string) error
 }
 
 // TokenService define la interfaz para la generación y validación de tokens.
 type TokenService interface {
-	GenerateTokens(userID string) (accessToken string, refreshToken string, err error)
+	// GenerateTokens emite el par de tokens de la sesión sessionID (ver NewSessionID).
+	GenerateTokens(userID, sessionID string) (accessToken string, refreshToken string, err error)
+	// GenerateImpersonationToken emite un access token de subjectID con la claim act del
+	// usuario real (RFC 8693). No tiene refresh token: al vencer hay que volver a pedirlo.
+	GenerateImpersonationToken(actorID, subjectID, sessionID string, ttl time.Duration) (string, error)
 	ValidateToken(tokenString string) (*jwt.Token, error)
 }
@@ -0,0 +1,310 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/responses"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// Algoritmos de firma admitidos en jwt.jwt_algorithm.
+const (
+	AlgorithmHS256 = "HS256" // Secretos compartidos; pensado para desarrollo local
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	// minRSAKeyBits es el tamaño mínimo aceptado para las claves RSA.
+	minRSAKeyBits = 2048
+)
+
+// KeyRing firma y verifica los JWT de la aplicación.
+//
+// Con HS256 usa jwt_access_secret y jwt_refresh_secret según el tipo de token. Con RS256
+// o EdDSA usa las claves de jwt.jwt_keys, cada una identificada por su kid:
+//
+//	jwt_keys: "2025-11=/run/keys/2025-11.pem,2026-01=/run/keys/2026-01.pem@2026-01-01T00:00:00Z"
+//
+// Cada entrada es kid=archivo PEM y, opcionalmente, @fecha RFC3339 desde la que la clave
+// firma. Se firma con la clave privada más reciente ya activa, así la rotación ocurre sola
+// en la fecha programada; todas las claves listadas siguen sirviendo para verificar. Una
+// clave vieja puede dejarse solo con su PEM público y quitarse cuando venzan sus tokens
+// (la vida del refresh token).
+type KeyRing interface {
+	// Sign firma las claims de un token del tipo indicado ("access" o "refresh").
+	Sign(tokenType string, claims jwt.MapClaims) (string, error)
+	// Verify valida la firma y las fechas del token.
+	Verify(tokenString string) (*jwt.Token, error)
+	// JWKS devuelve las claves públicas para /.well-known/jwks.json (vacío con HS256).
+	JWKS() responses.JWKSResponse
+}
+
+// NewKeyRing arma las claves según jwt.jwt_algorithm (HS256 si está vacío).
+// Falla si las claves configuradas no se pueden leer o no sirven para el algoritmo.
+func NewKeyRing(cfg *config.AppConfig) (KeyRing, error) {
+	jwtCfg := cfg.JWTConfig
+	switch alg := strings.TrimSpace(jwtCfg.JwtAlgorithm); alg {
+	case "", AlgorithmHS256:
+		return &hmacKeyRing{accessSecret: jwtCfg.JwtAccessSecret, refreshSecret: jwtCfg.JwtRefreshSecret}, nil
+	case AlgorithmRS256, AlgorithmEdDSA:
+		keys, err := loadSigningKeys(alg, jwtCfg.JwtKeys)
+		if err != nil {
+			return nil, err
+		}
+		return &asymmetricKeyRing{method: jwt.GetSigningMethod(alg), keys: keys, now: time.Now}, nil
+	default:
+		return nil, fmt.Errorf("algoritmo JWT no soportado: %q (usar HS256, RS256 o EdDSA)", alg)
+	}
+}
+
+// ────────────────────────────────────────────────
+// HS256
+// ────────────────────────────────────────────────
+
+type hmacKeyRing struct {
+	accessSecret  string
+	refreshSecret string
+}
+
+func (k *hmacKeyRing) Sign(tokenType string, claims jwt.MapClaims) (string, error) {
+	secret, err := k.secret(tokenType)
+	if err != nil {
+		return "", err
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+func (k *hmacKeyRing) Verify(tokenString string) (*jwt.Token, error) {
+	parser := jwt.Parser{}
+	token, _, err := parser.ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return nil, fmt.Errorf("error al parsear el token: %w", err)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("claims de token inválidos")
+	}
+	tokenType, _ := claims["typ"].(string)
+	secret, err := k.secret(tokenType)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{AlgorithmHS256}))
+}
+
+func (k *hmacKeyRing) JWKS() responses.JWKSResponse {
+	// Los secretos compartidos no se publican.
+	return responses.JWKSResponse{Keys: []responses.JWK{}}
+}
+
+func (k *hmacKeyRing) secret(tokenType string) (string, error) {
+	var secret string
+	switch tokenType {
+	case "access":
+		secret = k.accessSecret
+	case "refresh":
+		secret = k.refreshSecret
+	default:
+		return "", fmt.Errorf("tipo de token desconocido: %s", tokenType)
+	}
+	if secret == "" {
+		return "", fmt.Errorf("el secreto JWT para '%s' no está configurado", tokenType)
+	}
+	return secret, nil
+}
+
+// ────────────────────────────────────────────────
+// RS256 / EdDSA
+// ────────────────────────────────────────────────
+
+// signingKey es una clave del anillo. private es nil si solo se cargó la clave pública.
+type signingKey struct {
+	kid        string
+	private    crypto.Signer
+	public     crypto.PublicKey
+	activeFrom time.Time
+}
+
+type asymmetricKeyRing struct {
+	method jwt.SigningMethod
+	keys   []signingKey
+	now    func() time.Time
+}
+
+func (k *asymmetricKeyRing) Sign(_ string, claims jwt.MapClaims) (string, error) {
+	key, ok := k.current()
+	if !ok {
+		return "", errors.New("no hay una clave JWT activa para firmar")
+	}
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+func (k *asymmetricKeyRing) Verify(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		for _, key := range k.keys {
+			if key.kid == kid {
+				return key.public, nil
+			}
+		}
+		return nil, fmt.Errorf("clave JWT desconocida: %q", kid)
+	}, jwt.WithValidMethods([]string{k.method.Alg()}))
+}
+
+func (k *asymmetricKeyRing) JWKS() responses.JWKSResponse {
+	keys := make([]responses.JWK, 0, len(k.keys))
+	for _, key := range k.keys {
+		jwk := responses.JWK{Kid: key.kid, Use: "sig", Alg: k.method.Alg()}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		keys = append(keys, jwk)
+	}
+	return responses.JWKSResponse{Keys: keys}
+}
+
+// current devuelve la clave privada activa más reciente (a igual fecha, la última listada).
+func (k *asymmetricKeyRing) current() (signingKey, bool) {
+	now := k.now()
+	var found *signingKey
+	for i := range k.keys {
+		key := &k.keys[i]
+		if key.private == nil || key.activeFrom.After(now) {
+			continue
+		}
+		if found == nil || !key.activeFrom.Before(found.activeFrom) {
+			found = key
+		}
+	}
+	if found == nil {
+		return signingKey{}, false
+	}
+	return *found, true
+}
+
+// loadSigningKeys lee las entradas de jwt_keys y valida que sirvan para el algoritmo.
+func loadSigningKeys(alg, spec string) ([]signingKey, error) {
+	var keys []signingKey
+	seen := make(map[string]bool)
+	hasPrivate := false
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, path, err := parseKeyEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		if seen[key.kid] {
+			return nil, fmt.Errorf("kid JWT repetido: %q", key.kid)
+		}
+		seen[key.kid] = true
+
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer la clave JWT %q: %w", key.kid, err)
+		}
+		if err := parsePEMKey(alg, raw, &key); err != nil {
+			return nil, fmt.Errorf("clave JWT %q inválida: %w", key.kid, err)
+		}
+		hasPrivate = hasPrivate || key.private != nil
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwt_keys es obligatorio con el algoritmo %s", alg)
+	}
+	if !hasPrivate {
+		return nil, errors.New("jwt_keys no tiene ninguna clave privada para firmar")
+	}
+	return keys, nil
+}
+
+// parseKeyEntry separa "kid=ruta" o "kid=ruta@2026-01-01T00:00:00Z".
+func parseKeyEntry(entry string) (signingKey, string, error) {
+	kid, path, ok := strings.Cut(entry, "=")
+	kid, path = strings.TrimSpace(kid), strings.TrimSpace(path)
+	if !ok || kid == "" || path == "" {
+		return signingKey{}, "", fmt.Errorf("entrada de jwt_keys inválida: %q (formato kid=ruta[@fecha])", entry)
+	}
+	key := signingKey{kid: kid}
+	if i := strings.LastIndex(path, "@"); i >= 0 {
+		activeFrom, err := time.Parse(time.RFC3339, path[i+1:])
+		if err != nil {
+			return signingKey{}, "", fmt.Errorf("fecha de activación inválida para la clave JWT %q: %w", kid, err)
+		}
+		key.activeFrom = activeFrom
+		path = path[:i]
+	}
+	return key, path, nil
+}
+
+// parsePEMKey acepta una clave privada (PKCS#8, o PKCS#1 para RSA) o una clave pública
+// (PKIX) que solo sirve para verificar.
+func parsePEMKey(alg string, raw []byte, key *signingKey) error {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return errors.New("el archivo no contiene un bloque PEM")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return fmt.Errorf("tipo de bloque PEM no soportado: %s", block.Type)
+	}
+	if err != nil {
+		return err
+	}
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.private = signer
+		key.public = signer.Public()
+	} else {
+		key.public = parsed
+	}
+
+	switch pub := key.public.(type) {
+	case *rsa.PublicKey:
+		if alg != AlgorithmRS256 {
+			return fmt.Errorf("es una clave RSA y el algoritmo es %s", alg)
+		}
+		if pub.N.BitLen() < minRSAKeyBits {
+			return fmt.Errorf("la clave RSA debe tener al menos %d bits", minRSAKeyBits)
+		}
+	case ed25519.PublicKey:
+		if alg != AlgorithmEdDSA {
+			return fmt.Errorf("es una clave Ed25519 y el algoritmo es %s", alg)
+		}
+	default:
+		return fmt.Errorf("tipo de clave no soportado: %T", key.public)
+	}
+	return nil
+}
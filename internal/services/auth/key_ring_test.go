@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-fiber-core/internal/dtos/config"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM guarda la clave en un archivo temporal y devuelve su ruta.
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name+".pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func writeEd25519Key(t *testing.T, name string) string {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	return writePEM(t, name, "PRIVATE KEY", der)
+}
+
+func newTestKeyRing(t *testing.T, alg, keys string) KeyRing {
+	ring, err := NewKeyRing(&config.AppConfig{JWTConfig: config.JWTConfig{JwtAlgorithm: alg, JwtKeys: keys}})
+	require.NoError(t, err)
+	return ring
+}
+
+func TestKeyRing_HS256(t *testing.T) {
+	ring, err := NewKeyRing(&config.AppConfig{JWTConfig: config.JWTConfig{
+		JwtAccessSecret: "access-secret", JwtRefreshSecret: "refresh-secret",
+	}})
+	require.NoError(t, err)
+
+	signed, err := ring.Sign("access", jwt.MapClaims{"typ": "access", "exp": time.Now().Add(time.Minute).Unix()})
+	require.NoError(t, err)
+	token, err := ring.Verify(signed)
+	require.NoError(t, err)
+	assert.True(t, token.Valid)
+	assert.Equal(t, AlgorithmHS256, token.Method.Alg())
+
+	// Un token de otro tipo se verifica con el otro secreto.
+	forged, err := ring.Sign("access", jwt.MapClaims{"typ": "refresh"})
+	require.NoError(t, err)
+	_, err = ring.Verify(forged)
+	assert.Error(t, err)
+	assert.Empty(t, ring.JWKS().Keys)
+}
+
+func TestKeyRing_ScheduledRotation(t *testing.T) {
+	oldPath := writeEd25519Key(t, "old")
+	newPath := writeEd25519Key(t, "new")
+	rotation := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ring := newTestKeyRing(t, AlgorithmEdDSA, "old="+oldPath+", new="+newPath+"@"+rotation.Format(time.RFC3339))
+	asym := ring.(*asymmetricKeyRing)
+	claims := func() jwt.MapClaims { return jwt.MapClaims{"typ": "access", "exp": time.Now().Add(time.Hour).Unix()} }
+
+	asym.now = func() time.Time { return rotation.Add(-time.Minute) }
+	beforeRotation, err := ring.Sign("access", claims())
+	require.NoError(t, err)
+
+	asym.now = func() time.Time { return rotation }
+	afterRotation, err := ring.Sign("access", claims())
+	require.NoError(t, err)
+
+	token, err := ring.Verify(beforeRotation)
+	require.NoError(t, err)
+	assert.Equal(t, "old", token.Header["kid"])
+	// La clave anterior sigue verificando después de la rotación.
+	token, err = ring.Verify(afterRotation)
+	require.NoError(t, err)
+	assert.Equal(t, "new", token.Header["kid"])
+
+	jwks := ring.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[0].Crv)
+	assert.Equal(t, AlgorithmEdDSA, jwks.Keys[1].Alg)
+	assert.NotEqual(t, jwks.Keys[0].X, jwks.Keys[1].X)
+}
+
+func TestKeyRing_RS256PublicOnlyKeyVerifies(t *testing.T) {
+	retired, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	current, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(&retired.PublicKey)
+	require.NoError(t, err)
+
+	retiredPath := writePEM(t, "retired", "PUBLIC KEY", pubDER)
+	currentPath := writePEM(t, "current", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(current))
+	ring := newTestKeyRing(t, AlgorithmRS256, "retired="+retiredPath+",current="+currentPath)
+
+	// Un token firmado por la clave retirada todavía se acepta.
+	old := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"typ": "access"})
+	old.Header["kid"] = "retired"
+	signed, err := old.SignedString(retired)
+	require.NoError(t, err)
+	_, err = ring.Verify(signed)
+	assert.NoError(t, err)
+
+	// Se firma siempre con la que tiene clave privada.
+	signed, err = ring.Sign("refresh", jwt.MapClaims{"typ": "refresh"})
+	require.NoError(t, err)
+	token, err := ring.Verify(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "current", token.Header["kid"])
+
+	jwks := ring.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+}
+
+func TestKeyRing_RejectsUnknownKidAndAlgorithm(t *testing.T) {
+	path := writeEd25519Key(t, "k1")
+	ring := newTestKeyRing(t, AlgorithmEdDSA, "k1="+path)
+
+	_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	foreign := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"typ": "access"})
+	foreign.Header["kid"] = "k2"
+	signed, err := foreign.SignedString(otherPriv)
+	require.NoError(t, err)
+	_, err = ring.Verify(signed)
+	assert.Error(t, err)
+
+	// Un HS256 no se acepta aunque el kid exista.
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"typ": "access"})
+	hs.Header["kid"] = "k1"
+	signed, err = hs.SignedString([]byte("secreto"))
+	require.NoError(t, err)
+	_, err = ring.Verify(signed)
+	assert.Error(t, err)
+}
+
+func TestNewKeyRing_InvalidConfig(t *testing.T) {
+	edPath := writeEd25519Key(t, "ed")
+	cases := map[string]config.JWTConfig{
+		"algoritmo desconocido":  {JwtAlgorithm: "HS512"},
+		"sin claves":             {JwtAlgorithm: AlgorithmRS256},
+		"archivo inexistente":    {JwtAlgorithm: AlgorithmEdDSA, JwtKeys: "k1=/no/existe.pem"},
+		"clave de otro tipo":     {JwtAlgorithm: AlgorithmRS256, JwtKeys: "k1=" + edPath},
+		"kid repetido":           {JwtAlgorithm: AlgorithmEdDSA, JwtKeys: "k1=" + edPath + ",k1=" + edPath},
+		"entrada sin kid":        {JwtAlgorithm: AlgorithmEdDSA, JwtKeys: edPath},
+		"fecha de rotación mala": {JwtAlgorithm: AlgorithmEdDSA, JwtKeys: "k1=" + edPath + "@mañana"},
+	}
+	for name, cfg := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewKeyRing(&config.AppConfig{JWTConfig: cfg})
+			assert.Error(t, err)
+		})
+	}
+}
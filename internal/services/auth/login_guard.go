@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	resty "github.com/go-resty/resty/v2"
+	redis "github.com/redis/go-redis/v9"
+
+	"go-fiber-core/internal/adapters"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+)
+
+const (
+	loginFailEmailKeyPrefix = "auth:login:fail:email:"
+	loginFailIPKeyPrefix    = "auth:login:fail:ip:"
+	loginLockKeyPrefix      = "auth:login:lock:"
+
+	defaultLoginMaxAttempts   = 5
+	defaultLoginIPMaxAttempts = 50
+	defaultLoginWindow        = 15 * time.Minute
+	defaultLoginLockout       = 15 * time.Minute
+
+	// Retardo progresivo: los primeros fallos no esperan; después se duplica hasta el máximo.
+	loginFreeAttempts = 2
+	loginBaseDelay    = time.Second
+	loginMaxDelay     = 8 * time.Second
+
+	notifyTimeout = 10 * time.Second
+)
+
+// LoginGuard protege el login contra fuerza bruta con contadores de fallos en Redis por
+// email y por IP.
+//
+// Cada fallo alarga la espera del siguiente intento del mismo email. Al llegar al máximo
+// la cuenta queda bloqueada un tiempo (y se avisa por Discord); una IP que acumula
+// demasiados fallos, con cualquier email, también se bloquea. Los contadores son por email
+// exista o no la cuenta, así que el bloqueo no revela qué emails están registrados.
+type LoginGuard interface {
+	// Check se llama antes de verificar la contraseña: devuelve domain.ErrAccountLocked si
+	// el email o la IP están bloqueados y, si no, aplica el retardo que corresponda.
+	Check(ctx context.Context, email, ip string) error
+	// RecordFailure cuenta una contraseña o un código de segundo factor incorrectos.
+	RecordFailure(ctx context.Context, email, ip string) error
+	// RecordSuccess reinicia el contador del email (no el de la IP). Se llama al completar el
+	// login, después del segundo factor si el usuario lo tiene.
+	RecordSuccess(ctx context.Context, email string) error
+	// Unlock levanta el bloqueo de la cuenta y reinicia su contador.
+	Unlock(ctx context.Context, email string) error
+}
+
+// lockoutNotifier es la parte de adapters.DiscordAdapter que usa el guard.
+type lockoutNotifier interface {
+	Send(ctx context.Context, notification dtos.NotificationDiscord) (*resty.Response, error)
+}
+
+type redisLoginGuard struct {
+	client       *redis.Client
+	notifier     lockoutNotifier
+	maxAttempts  int64
+	ipMaxAttempt int64
+	window       time.Duration
+	lockout      time.Duration
+	sleep        func(ctx context.Context, d time.Duration) error
+	// notify despacha el aviso de bloqueo; en producción en una goroutine.
+	notify func(fn func())
+}
+
+// NewLoginGuard crea el guard sobre la conexión de Redis de la aplicación. Sin cliente
+// (ej: en tests) no cuenta nada; sin URL de Discord no envía avisos.
+func NewLoginGuard(conn *connect.ConnectDTO, cfg *config.AppConfig, discord *adapters.DiscordAdapter) LoginGuard {
+	g := &redisLoginGuard{
+		client:       conn.ConnectRedis,
+		maxAttempts:  positiveOr(cfg.Auth.LoginMaxAttempts, defaultLoginMaxAttempts),
+		ipMaxAttempt: positiveOr(cfg.Auth.LoginIpMaxAttempts, defaultLoginIPMaxAttempts),
+		window:       minutesOr(cfg.Auth.LoginAttemptWindowMinutes, defaultLoginWindow),
+		lockout:      minutesOr(cfg.Auth.LoginLockoutMinutes, defaultLoginLockout),
+		sleep:        sleepContext,
+		notify:       func(fn func()) { go fn() },
+	}
+	if discord != nil && cfg.ApiDiscord.Url != "" {
+		g.notifier = discord
+	}
+	return g
+}
+
+func (g *redisLoginGuard) Check(ctx context.Context, email, ip string) error {
+	if g.client == nil {
+		return nil
+	}
+	email = normalizeLoginEmail(email)
+
+	var locked *redis.IntCmd
+	var emailFails, ipFails *redis.StringCmd
+	_, err := g.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		locked = pipe.Exists(ctx, loginLockKeyPrefix+email)
+		emailFails = pipe.Get(ctx, loginFailEmailKeyPrefix+email)
+		ipFails = pipe.Get(ctx, loginFailIPKeyPrefix+ip)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("error al consultar los intentos de login: %w", err)
+	}
+
+	if locked.Val() > 0 {
+		return domain.ErrAccountLocked
+	}
+	if n, _ := ipFails.Int64(); n >= g.ipMaxAttempt {
+		return domain.ErrAccountLocked
+	}
+	n, _ := emailFails.Int64()
+	if delay := loginDelay(n); delay > 0 {
+		return g.sleep(ctx, delay)
+	}
+	return nil
+}
+
+func (g *redisLoginGuard) RecordFailure(ctx context.Context, email, ip string) error {
+	if g.client == nil {
+		return nil
+	}
+	email = normalizeLoginEmail(email)
+	emailKey := loginFailEmailKeyPrefix + email
+
+	var emailFails *redis.IntCmd
+	_, err := g.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		// Ventana deslizante, como en RateLimitMiddleware: cada fallo renueva el vencimiento.
+		emailFails = pipe.Incr(ctx, emailKey)
+		pipe.Expire(ctx, emailKey, g.window)
+		ipKey := loginFailIPKeyPrefix + ip
+		pipe.Incr(ctx, ipKey)
+		pipe.Expire(ctx, ipKey, g.window)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error al registrar el intento de login: %w", err)
+	}
+	if emailFails.Val() < g.maxAttempts {
+		return nil
+	}
+
+	// Solo el fallo que crea el bloqueo avisa; el contador vuelve a cero para después del bloqueo.
+	created, err := g.client.SetNX(ctx, loginLockKeyPrefix+email, emailFails.Val(), g.lockout).Result()
+	if err != nil {
+		return fmt.Errorf("error al bloquear la cuenta: %w", err)
+	}
+	if err := g.client.Del(ctx, emailKey).Err(); err != nil {
+		return fmt.Errorf("error al reiniciar los intentos de login: %w", err)
+	}
+	if created {
+		log.Printf("SEGURIDAD: login de %s bloqueado por %s tras %d intentos fallidos (última IP %s)",
+			email, g.lockout, emailFails.Val(), ip)
+		g.notifyLockout(email, ip, emailFails.Val())
+	}
+	return nil
+}
+
+func (g *redisLoginGuard) RecordSuccess(ctx context.Context, email string) error {
+	if g.client == nil {
+		return nil
+	}
+	return g.client.Del(ctx, loginFailEmailKeyPrefix+normalizeLoginEmail(email)).Err()
+}
+
+func (g *redisLoginGuard) Unlock(ctx context.Context, email string) error {
+	if g.client == nil {
+		return nil
+	}
+	email = normalizeLoginEmail(email)
+	if err := g.client.Del(ctx, loginLockKeyPrefix+email, loginFailEmailKeyPrefix+email).Err(); err != nil {
+		return fmt.Errorf("error al desbloquear la cuenta: %w", err)
+	}
+	return nil
+}
+
+// notifyLockout avisa por Discord sin demorar la respuesta; los errores solo se registran.
+func (g *redisLoginGuard) notifyLockout(email, ip string, attempts int64) {
+	if g.notifier == nil {
+		return
+	}
+	g.notify(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		_, err := g.notifier.Send(ctx, dtos.NotificationDiscord{
+			TypeMessage: "warning",
+			Process:     "Login: cuenta bloqueada por intentos fallidos",
+			File:        "internal/services/auth/login_guard.go",
+			Antecedent:  fmt.Sprintf("email=%s ip=%s intentos=%d", email, ip, attempts),
+			Exception:   fmt.Sprintf("La cuenta queda bloqueada %s. Se desbloquea con POST /api/v1/users/:id/unlock.", g.lockout),
+		})
+		if err != nil {
+			log.Printf("Error al notificar el bloqueo de %s: %v", email, err)
+		}
+	})
+}
+
+// loginDelay es la espera antes de verificar la contraseña tras n fallos seguidos.
+func loginDelay(failures int64) time.Duration {
+	if failures < loginFreeAttempts {
+		return 0
+	}
+	shift := failures - loginFreeAttempts
+	if shift > 3 {
+		return loginMaxDelay
+	}
+	return min(loginBaseDelay<<shift, loginMaxDelay)
+}
+
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func positiveOr(value, fallback int) int64 {
+	if value <= 0 {
+		return int64(fallback)
+	}
+	return int64(value)
+}
+
+func minutesOr(minutes int, fallback time.Duration) time.Duration {
+	if minutes <= 0 {
+		return fallback
+	}
+	return time.Duration(minutes) * time.Minute
+}
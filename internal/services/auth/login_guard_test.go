@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	resty "github.com/go-resty/resty/v2"
+	redis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type fakeNotifier struct {
+	sent []dtos.NotificationDiscord
+}
+
+func (f *fakeNotifier) Send(_ context.Context, n dtos.NotificationDiscord) (*resty.Response, error) {
+	f.sent = append(f.sent, n)
+	return nil, nil
+}
+
+// newTestLoginGuard no duerme: registra las esperas que habría aplicado.
+func newTestLoginGuard(t *testing.T, auth config.AuthConfig) (*redisLoginGuard, *fakeNotifier, *[]time.Duration, *miniredis.Miniredis) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	guard := NewLoginGuard(&connect.ConnectDTO{ConnectRedis: client}, &config.AppConfig{Auth: auth}, nil).(*redisLoginGuard)
+	notifier := &fakeNotifier{}
+	guard.notifier = notifier
+	guard.notify = func(fn func()) { fn() }
+	delays := &[]time.Duration{}
+	guard.sleep = func(_ context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return nil
+	}
+	return guard, notifier, delays, s
+}
+
+func TestLoginDelay(t *testing.T) {
+	assert.Zero(t, loginDelay(0))
+	assert.Zero(t, loginDelay(1))
+	assert.Equal(t, time.Second, loginDelay(2))
+	assert.Equal(t, 2*time.Second, loginDelay(3))
+	assert.Equal(t, 4*time.Second, loginDelay(4))
+	assert.Equal(t, loginMaxDelay, loginDelay(5))
+	assert.Equal(t, loginMaxDelay, loginDelay(100))
+}
+
+func TestLoginGuard_LocksAccountAfterMaxAttempts(t *testing.T) {
+	guard, notifier, delays, s := newTestLoginGuard(t, config.AuthConfig{LoginMaxAttempts: 3, LoginLockoutMinutes: 10})
+	ctx := context.Background()
+
+	for range 3 {
+		require.NoError(t, guard.Check(ctx, "Ana@Test.com ", "10.0.0.1"))
+		require.NoError(t, guard.RecordFailure(ctx, "ana@test.com", "10.0.0.1"))
+	}
+	assert.Equal(t, []time.Duration{time.Second}, *delays)
+
+	// Bloqueada aunque se pruebe desde otra IP; el aviso sale una sola vez.
+	assert.ErrorIs(t, guard.Check(ctx, "ana@test.com", "10.0.0.2"), domain.ErrAccountLocked)
+	require.NoError(t, guard.RecordFailure(ctx, "ana@test.com", "10.0.0.2"))
+	require.NoError(t, guard.RecordFailure(ctx, "ana@test.com", "10.0.0.2"))
+	require.NoError(t, guard.RecordFailure(ctx, "ana@test.com", "10.0.0.2"))
+	require.Len(t, notifier.sent, 1)
+	assert.Contains(t, notifier.sent[0].Antecedent, "ana@test.com")
+
+	// Otra cuenta no se ve afectada.
+	assert.NoError(t, guard.Check(ctx, "otra@test.com", "10.0.0.1"))
+
+	// El bloqueo vence solo, o lo levanta un administrador.
+	s.FastForward(10 * time.Minute)
+	assert.NoError(t, guard.Check(ctx, "ana@test.com", "10.0.0.1"))
+	for range 3 {
+		require.NoError(t, guard.RecordFailure(ctx, "ana@test.com", "10.0.0.1"))
+	}
+	assert.ErrorIs(t, guard.Check(ctx, "ana@test.com", "10.0.0.1"), domain.ErrAccountLocked)
+	require.NoError(t, guard.Unlock(ctx, "ANA@test.com"))
+	assert.NoError(t, guard.Check(ctx, "ana@test.com", "10.0.0.1"))
+}
+
+func TestLoginGuard_BlocksIPAcrossEmails(t *testing.T) {
+	guard, notifier, _, _ := newTestLoginGuard(t, config.AuthConfig{LoginIpMaxAttempts: 4})
+	ctx := context.Background()
+
+	for _, email := range []string{"a@test.com", "b@test.com", "c@test.com", "d@test.com"} {
+		require.NoError(t, guard.Check(ctx, email, "10.0.0.1"))
+		require.NoError(t, guard.RecordFailure(ctx, email, "10.0.0.1"))
+	}
+	assert.ErrorIs(t, guard.Check(ctx, "e@test.com", "10.0.0.1"), domain.ErrAccountLocked)
+	assert.NoError(t, guard.Check(ctx, "e@test.com", "10.0.0.2"))
+	assert.Empty(t, notifier.sent)
+}
+
+func TestLoginGuard_SuccessResetsEmailCounter(t *testing.T) {
+	guard, _, delays, _ := newTestLoginGuard(t, config.AuthConfig{})
+	ctx := context.Background()
+
+	for range 2 {
+		require.NoError(t, guard.RecordFailure(ctx, "ana@test.com", "10.0.0.1"))
+	}
+	require.NoError(t, guard.RecordSuccess(ctx, "ana@test.com"))
+	require.NoError(t, guard.Check(ctx, "ana@test.com", "10.0.0.1"))
+	assert.Empty(t, *delays)
+}
+
+func TestAuthService_LockedLoginDoesNotRevealAccount(t *testing.T) {
+	s, repo, _ := newTestAuthService(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("clave-segura"), bcrypt.MinCost)
+	require.NoError(t, err)
+	s.userReader = &fakeLoginUserReader{user: &models.User{ID: 7, Email: "ana@test.com", Password: string(hash), IsActive: true}}
+	guard, _, _, _ := newTestLoginGuard(t, config.AuthConfig{LoginMaxAttempts: 2})
+	s.loginGuard = guard
+	ctx := context.Background()
+	client := ClientInfo{IPAddress: "10.0.0.1"}
+
+	for range 2 {
+		_, err = s.Login(ctx, requests.LoginRequest{Email: "ana@test.com", Password: "otra-clave"}, client)
+		assert.ErrorIs(t, err, domain.ErrAuthentication)
+	}
+	// Con la cuenta bloqueada ni la contraseña correcta entra.
+	_, err = s.Login(ctx, requests.LoginRequest{Email: "ana@test.com", Password: "clave-segura"}, client)
+	assert.ErrorIs(t, err, domain.ErrAccountLocked)
+	assert.Empty(t, repo.tokens)
+
+	// Un email sin cuenta se bloquea igual.
+	require.NoError(t, guard.RecordFailure(ctx, "nadie@test.com", "10.0.0.2"))
+	require.NoError(t, guard.RecordFailure(ctx, "nadie@test.com", "10.0.0.2"))
+	assert.ErrorIs(t, guard.Check(ctx, "nadie@test.com", "10.0.0.3"), domain.ErrAccountLocked)
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"go-fiber-core/internal/contextkeys"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+const (
+	deniedTokenKeyPrefix   = "auth:denied:jti:"
+	deniedSessionKeyPrefix = "auth:denied:sid:"
+	userSessionsKeyPrefix  = "auth:sessions:"
+)
+
+// TokenDenylist es la lista de access tokens revocados antes de su vencimiento.
+//
+// Los access tokens son stateless: para cortarlos se guarda en Redis su jti o su sesión
+// (sid) con un TTL igual a la vida que les queda, y AuthMiddleware los rechaza. Cada
+// usuario tiene además el conjunto de sus sesiones, para poder revocarlas todas.
+type TokenDenylist interface {
+	// TrackSession registra la sesión del usuario (login y refresh).
+	TrackSession(ctx context.Context, userID uint64, sessionID string) error
+	// RevokeSession deniega el access token y todos los de su sesión.
+	RevokeSession(ctx context.Context, userID uint64, session contextkeys.Session) error
+	// RevokeUser deniega todas las sesiones registradas del usuario.
+	RevokeUser(ctx context.Context, userID uint64) error
+	// IsRevoked indica si el token o su sesión fueron revocados.
+	IsRevoked(ctx context.Context, session contextkeys.Session) (bool, error)
+}
+
+type redisTokenDenylist struct {
+	client     *redis.Client
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewTokenDenylist crea la lista sobre la conexión de Redis de la aplicación. Sin cliente
+// (ej: en tests) no guarda nada y ningún token figura como revocado.
+func NewTokenDenylist(conn *connect.ConnectDTO, cfg *config.AppConfig) TokenDenylist {
+	return &redisTokenDenylist{
+		client:     conn.ConnectRedis,
+		accessTTL:  accessTTL(cfg.JWTConfig),
+		refreshTTL: refreshTTL(cfg.JWTConfig),
+	}
+}
+
+func (d *redisTokenDenylist) TrackSession(ctx context.Context, userID uint64, sessionID string) error {
+	if d.client == nil {
+		return nil
+	}
+	key := userSessionsKey(userID)
+	_, err := d.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, key, sessionID)
+		// La sesión puede seguir emitiendo tokens mientras su refresh token sea válido.
+		pipe.Expire(ctx, key, d.refreshTTL)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error al registrar la sesión: %w", err)
+	}
+	return nil
+}
+
+func (d *redisTokenDenylist) RevokeSession(ctx context.Context, userID uint64, session contextkeys.Session) error {
+	if d.client == nil {
+		return nil
+	}
+	_, err := d.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if ttl := time.Until(session.ExpiresAt); ttl > 0 {
+			pipe.Set(ctx, deniedTokenKeyPrefix+session.TokenID, userID, ttl)
+		}
+		// Otro access token de la sesión pudo emitirse recién: vive a lo sumo accessTTL.
+		pipe.Set(ctx, deniedSessionKeyPrefix+session.ID, userID, d.accessTTL)
+		pipe.SRem(ctx, userSessionsKey(userID), session.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error al revocar la sesión: %w", err)
+	}
+	return nil
+}
+
+func (d *redisTokenDenylist) RevokeUser(ctx context.Context, userID uint64) error {
+	if d.client == nil {
+		return nil
+	}
+	key := userSessionsKey(userID)
+	sessions, err := d.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("error al leer las sesiones del usuario %d: %w", userID, err)
+	}
+	if len(sessions) == 0 {
+		return nil
+	}
+	_, err = d.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, sessionID := range sessions {
+			pipe.Set(ctx, deniedSessionKeyPrefix+sessionID, userID, d.accessTTL)
+		}
+		pipe.SRem(ctx, key, toAny(sessions)...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error al revocar las sesiones del usuario %d: %w", userID, err)
+	}
+	return nil
+}
+
+func (d *redisTokenDenylist) IsRevoked(ctx context.Context, session contextkeys.Session) (bool, error) {
+	if d.client == nil {
+		return false, nil
+	}
+	keys := []string{deniedSessionKeyPrefix + session.ID}
+	if session.TokenID != "" {
+		keys = append(keys, deniedTokenKeyPrefix+session.TokenID)
+	}
+	count, err := d.client.Exists(ctx, keys...).Result()
+	if err != nil {
+		return false, fmt.Errorf("error al consultar la lista de tokens revocados: %w", err)
+	}
+	return count > 0, nil
+}
+
+func userSessionsKey(userID uint64) string {
+	return fmt.Sprintf("%s%d", userSessionsKeyPrefix, userID)
+}
+
+func toAny(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
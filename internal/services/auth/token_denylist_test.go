@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-fiber-core/internal/contextkeys"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	redis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDenylist(t *testing.T) (TokenDenylist, *miniredis.Miniredis) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	cfg := &config.AppConfig{JWTConfig: config.JWTConfig{JwtAccessTtlMinutes: 15, JwtRefreshTtlDays: 7}}
+	return NewTokenDenylist(&connect.ConnectDTO{ConnectRedis: client}, cfg), s
+}
+
+func TestTokenDenylist_RevokeSession(t *testing.T) {
+	denylist, s := newTestDenylist(t)
+	ctx := context.Background()
+	current := contextkeys.Session{ID: "sid-1", TokenID: "jti-1", ExpiresAt: time.Now().Add(5 * time.Minute)}
+	other := contextkeys.Session{ID: "sid-2", TokenID: "jti-2", ExpiresAt: time.Now().Add(5 * time.Minute)}
+
+	assert.NoError(t, denylist.TrackSession(ctx, 7, current.ID))
+	assert.NoError(t, denylist.TrackSession(ctx, 7, other.ID))
+	assert.NoError(t, denylist.RevokeSession(ctx, 7, current))
+
+	revoked, err := denylist.IsRevoked(ctx, current)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	// Otro token de la misma sesión también queda denegado.
+	revoked, _ = denylist.IsRevoked(ctx, contextkeys.Session{ID: "sid-1", TokenID: "jti-nuevo"})
+	assert.True(t, revoked)
+	revoked, _ = denylist.IsRevoked(ctx, other)
+	assert.False(t, revoked)
+
+	// El TTL es la vida que le queda al token, no más.
+	assert.LessOrEqual(t, s.TTL(deniedTokenKeyPrefix+"jti-1"), 5*time.Minute)
+	assert.Equal(t, 15*time.Minute, s.TTL(deniedSessionKeyPrefix+"sid-1"))
+}
+
+func TestTokenDenylist_RevokeUser(t *testing.T) {
+	denylist, s := newTestDenylist(t)
+	ctx := context.Background()
+
+	assert.NoError(t, denylist.TrackSession(ctx, 7, "sid-1"))
+	assert.NoError(t, denylist.TrackSession(ctx, 7, "sid-2"))
+	assert.NoError(t, denylist.TrackSession(ctx, 8, "sid-3"))
+	assert.NoError(t, denylist.RevokeUser(ctx, 7))
+
+	for sid, expected := range map[string]bool{"sid-1": true, "sid-2": true, "sid-3": false} {
+		revoked, err := denylist.IsRevoked(ctx, contextkeys.Session{ID: sid, TokenID: "jti"})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, revoked, sid)
+	}
+	assert.False(t, s.Exists(userSessionsKey(7)))
+
+	// Sin sesiones registradas no hay nada que revocar.
+	assert.NoError(t, denylist.RevokeUser(ctx, 99))
+}
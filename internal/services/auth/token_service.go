@@ -1,7 +1,10 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"go-fiber-core/internal/contextkeys"
 	"go-fiber-core/internal/dtos/config"
 	"time"
 
@@ -10,23 +13,22 @@ import (
 
 // tokenService es la implementación de la interfaz TokenService.
 type tokenService struct {
-	cfg config.JWTConfig
+	cfg  config.JWTConfig
+	keys KeyRing
 }
 
-// NewTokenService crea una nueva instancia de TokenService.
-func NewTokenService(cfg *config.AppConfig) TokenService {
-	return &tokenService{cfg: cfg.JWTConfig}
+// NewTokenService crea una nueva instancia de TokenService que firma con las claves del KeyRing.
+func NewTokenService(cfg *config.AppConfig, keys KeyRing) TokenService {
+	return &tokenService{cfg: cfg.JWTConfig, keys: keys}
 }
 
-func (s *tokenService) GenerateTokens(userID string) (string, string, error) {
-	accessTTL := time.Minute * time.Duration(s.cfg.JwtAccessTtlMinutes)
-	accessToken, err := s.createToken(userID, accessTTL, s.cfg.JwtAccessSecret, "access")
+func (s *tokenService) GenerateTokens(userID, sessionID string) (string, string, error) {
+	accessToken, err := s.createToken(userID, sessionID, accessTTL(s.cfg), "access")
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshTTL := time.Hour * 24 * time.Duration(s.cfg.JwtRefreshTtlDays)
-	refreshToken, err := s.createToken(userID, refreshTTL, s.cfg.JwtRefreshSecret, "refresh")
+	refreshToken, err := s.createToken(userID, sessionID, refreshTTL(s.cfg), "refresh")
 	if err != nil {
 		return "", "", err
 	}
@@ -34,50 +36,70 @@ func (s *tokenService) GenerateTokens(userID string) (string, string, error) {
 	return accessToken, refreshToken, nil
 }
 
-func (s *tokenService) createToken(userID string, ttl time.Duration, secret, tokenType string) (string, error) {
-	if secret == "" {
-		return "", fmt.Errorf("el secreto JWT para '%s' no está configurado", tokenType)
-	}
-	claims := jwt.MapClaims{
+func (s *tokenService) GenerateImpersonationToken(actorID, subjectID, sessionID string, ttl time.Duration) (string, error) {
+	claims := tokenClaims(subjectID, sessionID, ttl, "access")
+	claims["act"] = map[string]any{"sub": actorID}
+	return s.keys.Sign("access", claims)
+}
+
+func (s *tokenService) createToken(userID, sessionID string, ttl time.Duration, tokenType string) (string, error) {
+	return s.keys.Sign(tokenType, tokenClaims(userID, sessionID, ttl, tokenType))
+}
+
+func tokenClaims(userID, sessionID string, ttl time.Duration, tokenType string) jwt.MapClaims {
+	return jwt.MapClaims{
 		"sub": userID, "typ": tokenType,
 		"exp": time.Now().Add(ttl).Unix(), "iat": time.Now().Unix(),
+		"jti": newTokenID(), "sid": sessionID,
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
 }
 
 func (s *tokenService) ValidateToken(tokenString string) (*jwt.Token, error) {
-	parser := jwt.Parser{}
-	token, _, err := parser.ParseUnverified(tokenString, jwt.MapClaims{})
-	if err != nil {
-		return nil, fmt.Errorf("error al parsear el token: %w", err)
+	return s.keys.Verify(tokenString)
+}
+
+func accessTTL(cfg config.JWTConfig) time.Duration {
+	return time.Minute * time.Duration(cfg.JwtAccessTtlMinutes)
+}
+
+func refreshTTL(cfg config.JWTConfig) time.Duration {
+	return time.Hour * 24 * time.Duration(cfg.JwtRefreshTtlDays)
+}
+
+// NewSessionID genera el identificador de una sesión nueva (claim sid). Se crea en el
+// login y se conserva en cada refresh, así cerrar la sesión invalida todos sus tokens.
+func NewSessionID() string {
+	return newTokenID()
+}
+
+// newTokenID genera un identificador aleatorio de 128 bits en hexadecimal.
+func newTokenID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("no se pudo generar un identificador aleatorio: %v", err))
 	}
-	claims, ok := token.Claims.(jwt.MapClaims)
+	return hex.EncodeToString(b)
+}
+
+// ActorFromClaims lee la claim act de un token de suplantación: el ID del usuario real.
+// Devuelve false si el token no es de suplantación.
+func ActorFromClaims(claims jwt.MapClaims) (string, bool) {
+	act, ok := claims["act"].(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("claims de token inválidos")
-	}
-	tokenType, _ := claims["typ"].(string)
-	secret, err := s.getSecret(tokenType)
-	if err != nil {
-		return nil, err
+		return "", false
 	}
-	if secret == "" {
-		return nil, fmt.Errorf("el secreto JWT para '%s' no está configurado", tokenType)
-	}
-	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("método de firma inesperado: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
-	})
+	actorID, ok := act["sub"].(string)
+	return actorID, ok && actorID != ""
 }
 
-func (s *tokenService) getSecret(tokenType string) (string, error) {
-	if tokenType == "access" {
-		return s.cfg.JwtAccessSecret, nil
-	}
-	if tokenType == "refresh" {
-		return s.cfg.JwtRefreshSecret, nil
+// SessionFromClaims lee las claims sid, jti y exp del token. Devuelve false si falta alguna
+// (ej: tokens emitidos antes de que existieran las sesiones).
+func SessionFromClaims(claims jwt.MapClaims) (contextkeys.Session, bool) {
+	sessionID, _ := claims["sid"].(string)
+	tokenID, _ := claims["jti"].(string)
+	exp, err := claims.GetExpirationTime()
+	if sessionID == "" || tokenID == "" || err != nil || exp == nil {
+		return contextkeys.Session{}, false
 	}
-	return "", fmt.Errorf("tipo de token desconocido: %s", tokenType)
+	return contextkeys.Session{ID: sessionID, TokenID: tokenID, ExpiresAt: exp.Time}, true
 }
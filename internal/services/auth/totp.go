@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parámetros TOTP (RFC 6238) compatibles con las apps de autenticación habituales.
+const (
+	totpPeriod     = 30 * time.Second
+	totpDigits     = 6
+	totpSkew       = 1  // Pasos aceptados antes y después del actual (desfase de reloj)
+	totpSecretSize = 20 // 160 bits, lo que recomienda la RFC 4226 para HMAC-SHA1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newTOTPSecret genera un secreto aleatorio en base32, el formato que muestran las apps.
+func newTOTPSecret() (string, error) {
+	b := make([]byte, totpSecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("no se pudo generar el secreto TOTP: %w", err)
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// totpStep es el número de período de 30 segundos que contiene t.
+func totpStep(t time.Time) int64 {
+	return t.Unix() / int64(totpPeriod/time.Second)
+}
+
+// totpCode calcula el código de un paso (RFC 4226 con contador = paso).
+func totpCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("secreto TOTP inválido: %w", err)
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1_000_000), nil
+}
+
+// matchTOTP devuelve el paso cuyo código coincide, dentro del desfase permitido.
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	current := totpStep(now)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		expected, err := totpCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// otpauthURI arma el enlace que las apps leen desde el código QR.
+func otpauthURI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(int(totpPeriod/time.Second)))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// secretCipher cifra los secretos TOTP guardados en la base con AES-256-GCM.
+type secretCipher struct {
+	aead cipher.AEAD
+}
+
+// newSecretCipher deriva la clave de AUTH_TWO_FACTOR_KEY. Sin clave devuelve nil: la
+// inscripción en 2FA queda deshabilitada.
+func newSecretCipher(key string) (*secretCipher, error) {
+	if key == "" {
+		return nil, nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &secretCipher{aead: aead}, nil
+}
+
+func (c *secretCipher) encrypt(plain string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *secretCipher) decrypt(encoded string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	size := c.aead.NonceSize()
+	if len(raw) < size {
+		return "", errors.New("secreto cifrado inválido")
+	}
+	plain, err := c.aead.Open(nil, raw[:size], raw[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf("no se pudo descifrar el secreto TOTP: %w", err)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/connect"
+	"strconv"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+const (
+	twoFactorChallengeKeyPrefix = "auth:2fa:challenge:"
+
+	// TwoFactorChallengeTTL es la vida del desafío entre la contraseña y el código.
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// maxTwoFactorAttempts limita los códigos probados con un mismo desafío.
+	maxTwoFactorAttempts = 5
+)
+
+// Propósitos del desafío de 2FA.
+const (
+	// ChallengeVerify: el usuario tiene 2FA y debe ingresar un código.
+	ChallengeVerify = "verify"
+	// ChallengeSetup: un rol del usuario exige 2FA y todavía no lo configuró.
+	ChallengeSetup = "setup"
+)
+
+var errChallengeNotAvailable = errors.New("el segundo factor requiere Redis")
+
+// TwoFactorChallenge es el paso intermedio del login con 2FA.
+type TwoFactorChallenge struct {
+	UserID  uint64
+	Purpose string
+}
+
+// TwoFactorChallenges guarda en Redis los desafíos del login en dos pasos. El token que
+// recibe el cliente es opaco y de corta vida; en Redis solo queda su hash, el usuario y
+// la cantidad de intentos.
+type TwoFactorChallenges interface {
+	Create(ctx context.Context, userID uint64, purpose string) (string, error)
+	// Get devuelve el desafío sin contar un intento (ej: para mostrar el secreto al inscribirse).
+	Get(ctx context.Context, token string) (TwoFactorChallenge, error)
+	// Attempt cuenta un intento de código. Al superar el máximo el desafío se descarta.
+	Attempt(ctx context.Context, token string) (TwoFactorChallenge, error)
+	Delete(ctx context.Context, token string) error
+}
+
+type redisTwoFactorChallenges struct {
+	client *redis.Client
+}
+
+// NewTwoFactorChallenges crea el almacén sobre la conexión de Redis de la aplicación.
+// Sin cliente no se pueden crear desafíos: el login de usuarios con 2FA falla.
+func NewTwoFactorChallenges(conn *connect.ConnectDTO) TwoFactorChallenges {
+	return &redisTwoFactorChallenges{client: conn.ConnectRedis}
+}
+
+func (s *redisTwoFactorChallenges) Create(ctx context.Context, userID uint64, purpose string) (string, error) {
+	if s.client == nil {
+		return "", errChallengeNotAvailable
+	}
+	token := newTokenID() + newTokenID()
+	key := challengeKey(token)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "user_id", userID, "purpose", purpose, "attempts", 0)
+		pipe.Expire(ctx, key, TwoFactorChallengeTTL)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("error al crear el desafío de 2FA: %w", err)
+	}
+	return token, nil
+}
+
+func (s *redisTwoFactorChallenges) Get(ctx context.Context, token string) (TwoFactorChallenge, error) {
+	if s.client == nil {
+		return TwoFactorChallenge{}, errChallengeNotAvailable
+	}
+	values, err := s.client.HGetAll(ctx, challengeKey(token)).Result()
+	if err != nil {
+		return TwoFactorChallenge{}, fmt.Errorf("error al leer el desafío de 2FA: %w", err)
+	}
+	userID, err := strconv.ParseUint(values["user_id"], 10, 64)
+	if err != nil {
+		// Vencido, ya usado o inexistente.
+		return TwoFactorChallenge{}, domain.ErrAuthentication
+	}
+	return TwoFactorChallenge{UserID: userID, Purpose: values["purpose"]}, nil
+}
+
+func (s *redisTwoFactorChallenges) Attempt(ctx context.Context, token string) (TwoFactorChallenge, error) {
+	challenge, err := s.Get(ctx, token)
+	if err != nil {
+		return challenge, err
+	}
+	key := challengeKey(token)
+	var attempts *redis.IntCmd
+	var ttl *redis.DurationCmd
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		attempts = pipe.HIncrBy(ctx, key, "attempts", 1)
+		ttl = pipe.TTL(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return TwoFactorChallenge{}, fmt.Errorf("error al registrar el intento de 2FA: %w", err)
+	}
+	// Sin TTL la clave venció entre Get y HIncrBy y se recreó vacía.
+	if ttl.Val() < 0 {
+		_ = s.Delete(ctx, token)
+		return TwoFactorChallenge{}, domain.ErrAuthentication
+	}
+	if attempts.Val() > maxTwoFactorAttempts {
+		_ = s.Delete(ctx, token)
+		return TwoFactorChallenge{}, domain.ErrAuthentication
+	}
+	return challenge, nil
+}
+
+func (s *redisTwoFactorChallenges) Delete(ctx context.Context, token string) error {
+	if s.client == nil {
+		return nil
+	}
+	return s.client.Del(ctx, challengeKey(token)).Err()
+}
+
+func challengeKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return twoFactorChallengeKeyPrefix + hex.EncodeToString(sum[:])
+}
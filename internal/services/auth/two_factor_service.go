@@ -0,0 +1,337 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/dtos/responses"
+	"go-fiber-core/internal/models"
+	twoFactorRepo "go-fiber-core/internal/repositories/twofactor"
+	userRepo "go-fiber-core/internal/repositories/user"
+)
+
+// recoveryCodeCount es la cantidad de códigos de recuperación que se entregan juntos.
+const recoveryCodeCount = 10
+
+var recoveryCodeEncoding = totpEncoding
+
+var (
+	errInvalidTwoFactorCode = domain.NewValidationError(map[string][]string{
+		"code": {"El código no es válido."},
+	})
+	errTwoFactorNotEnabled = domain.NewValidationError(map[string][]string{
+		"code": {"El usuario no tiene activado el segundo factor."},
+	})
+)
+
+// TwoFactorService administra el segundo factor TOTP del usuario: inscripción, códigos de
+// recuperación y verificación durante el login.
+//
+// El secreto se guarda cifrado (AUTH_TWO_FACTOR_KEY) y los códigos de recuperación solo
+// como hash. Cada código TOTP se acepta una sola vez.
+type TwoFactorService interface {
+	Status(ctx context.Context, userID uint64) (*responses.TwoFactorStatusResponse, error)
+	// Enroll genera un secreto nuevo pendiente de confirmación. Reemplaza una inscripción
+	// pendiente anterior, pero no un segundo factor ya activo.
+	Enroll(ctx context.Context, userID uint64) (*responses.TwoFactorEnrollResponse, error)
+	// Confirm activa el segundo factor con el primer código y devuelve los códigos de recuperación.
+	Confirm(ctx context.Context, userID uint64, code string) ([]string, error)
+	// Disable quita el segundo factor. No se permite si un rol del usuario lo exige.
+	Disable(ctx context.Context, userID uint64, code string) error
+	// RegenerateRecoveryCodes invalida los códigos anteriores y entrega otros.
+	RegenerateRecoveryCodes(ctx context.Context, userID uint64, code string) ([]string, error)
+	// Verify acepta un código TOTP o un código de recuperación (que se consume).
+	Verify(ctx context.Context, userID uint64, code string) error
+	IsEnabled(ctx context.Context, userID uint64) (bool, error)
+	// IsRequired indica si alguno de los roles exige 2FA (AUTH_TWO_FACTOR_REQUIRED_ROLES).
+	IsRequired(roles []models.Role) bool
+}
+
+type twoFactorService struct {
+	conn          *connect.ConnectDTO
+	repo          twoFactorRepo.TwoFactorRepository
+	userReader    userRepo.UserReader
+	cipher        *secretCipher
+	issuer        string
+	requiredRoles map[string]bool
+	now           func() time.Time
+}
+
+func NewTwoFactorService(
+	conn *connect.ConnectDTO,
+	repo twoFactorRepo.TwoFactorRepository,
+	userReader userRepo.UserReader,
+	cfg *config.AppConfig,
+) (TwoFactorService, error) {
+	cipher, err := newSecretCipher(cfg.Auth.TwoFactorKey)
+	if err != nil {
+		return nil, fmt.Errorf("AUTH_TWO_FACTOR_KEY inválida: %w", err)
+	}
+	issuer := cfg.Auth.TwoFactorIssuer
+	if issuer == "" {
+		issuer = cfg.App.AppName
+	}
+	required := map[string]bool{}
+	for _, name := range strings.Split(cfg.Auth.TwoFactorRequiredRoles, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			required[strings.ToLower(name)] = true
+		}
+	}
+	if len(required) > 0 && cipher == nil {
+		return nil, errors.New("AUTH_TWO_FACTOR_REQUIRED_ROLES requiere AUTH_TWO_FACTOR_KEY")
+	}
+	return &twoFactorService{
+		conn:          conn,
+		repo:          repo,
+		userReader:    userReader,
+		cipher:        cipher,
+		issuer:        issuer,
+		requiredRoles: required,
+		now:           time.Now,
+	}, nil
+}
+
+func (s *twoFactorService) Status(ctx context.Context, userID uint64) (*responses.TwoFactorStatusResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &responses.TwoFactorStatusResponse{Required: s.IsRequired(user.Roles)}
+	enabled, err := s.IsEnabled(ctx, user.ID)
+	if err != nil || !enabled {
+		return status, err
+	}
+	status.Enabled = true
+	left, err := s.repo.CountUnusedRecoveryCodes(ctx, s.conn.ConnectGormRead, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error al contar los códigos de recuperación: %w", err)
+	}
+	status.RecoveryCodesLeft = int(left)
+	return status, nil
+}
+
+func (s *twoFactorService) Enroll(ctx context.Context, userID uint64) (*responses.TwoFactorEnrollResponse, error) {
+	if s.cipher == nil {
+		return nil, errors.New("el segundo factor no está configurado (AUTH_TWO_FACTOR_KEY)")
+	}
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, domain.NewValidationError(map[string][]string{
+			"two_factor": {"El segundo factor ya está activo. Desactivalo antes de volver a inscribirte."},
+		})
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.cipher.encrypt(secret)
+	if err != nil {
+		return nil, fmt.Errorf("error al cifrar el secreto TOTP: %w", err)
+	}
+	pending := &models.UserTwoFactor{UserID: userID, SecretEncrypted: encrypted}
+	if err := s.repo.Save(ctx, s.conn.ConnectGormWrite, pending); err != nil {
+		return nil, fmt.Errorf("error al guardar el segundo factor: %w", err)
+	}
+
+	return &responses.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OtpauthURI: otpauthURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+func (s *twoFactorService) Confirm(ctx context.Context, userID uint64, code string) ([]string, error) {
+	twoFactor, secret, err := s.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, domain.NewValidationError(map[string][]string{
+			"code": {"No hay una inscripción pendiente. Iniciá la inscripción de nuevo."},
+		})
+	}
+	if twoFactor.ConfirmedAt != nil {
+		return nil, domain.NewValidationError(map[string][]string{
+			"code": {"El segundo factor ya está activo."},
+		})
+	}
+	step, ok := matchTOTP(secret, code, s.now())
+	if !ok {
+		return nil, errInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.conn.ConnectGormWrite.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.Confirm(ctx, tx, userID, step, s.now()); err != nil {
+			return err
+		}
+		return s.repo.ReplaceRecoveryCodes(ctx, tx, userID, hashes)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al activar el segundo factor: %w", err)
+	}
+	return codes, nil
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, userID uint64, code string) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.IsRequired(user.Roles) {
+		return domain.NewValidationError(map[string][]string{
+			"two_factor": {"Uno de tus roles exige el segundo factor; no se puede desactivar."},
+		})
+	}
+	if err := s.Verify(ctx, user.ID, code); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, s.conn.ConnectGormWrite, user.ID); err != nil {
+		return fmt.Errorf("error al desactivar el segundo factor: %w", err)
+	}
+	return nil
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uint64, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, s.conn.ConnectGormWrite, userID, hashes); err != nil {
+		return nil, fmt.Errorf("error al guardar los códigos de recuperación: %w", err)
+	}
+	return codes, nil
+}
+
+func (s *twoFactorService) Verify(ctx context.Context, userID uint64, code string) error {
+	twoFactor, secret, err := s.load(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if twoFactor == nil || twoFactor.ConfirmedAt == nil {
+		return errTwoFactorNotEnabled
+	}
+	dbWrite := s.conn.ConnectGormWrite
+
+	if step, ok := matchTOTP(secret, code, s.now()); ok {
+		// El paso ya usado no se acepta otra vez, aunque siga dentro de la ventana.
+		used, err := s.repo.UseStep(ctx, dbWrite, userID, step)
+		if err != nil {
+			return fmt.Errorf("error al registrar el código TOTP: %w", err)
+		}
+		if !used {
+			return errInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, dbWrite, userID, hashRecoveryCode(code), s.now())
+	if err != nil {
+		return fmt.Errorf("error al usar el código de recuperación: %w", err)
+	}
+	if !used {
+		return errInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (s *twoFactorService) IsEnabled(ctx context.Context, userID uint64) (bool, error) {
+	twoFactor, err := s.repo.GetByUserID(ctx, s.conn.ConnectGormRead, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error al buscar el segundo factor: %w", err)
+	}
+	return twoFactor.ConfirmedAt != nil, nil
+}
+
+func (s *twoFactorService) IsRequired(roles []models.Role) bool {
+	for _, r := range roles {
+		if r.IsActive && s.requiredRoles[strings.ToLower(r.Name)] {
+			return true
+		}
+	}
+	return false
+}
+
+// getUser carga el usuario con sus roles.
+func (s *twoFactorService) getUser(ctx context.Context, userID uint64) (*models.User, error) {
+	user, err := s.userReader.GetByID(ctx, s.conn.ConnectGormRead, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("error al buscar usuario: %w", err)
+	}
+	return user, nil
+}
+
+// load devuelve el segundo factor (nil si no hay) y su secreto descifrado. Se lee de la
+// base de escritura: Confirm y Verify siguen inmediatamente a Enroll.
+func (s *twoFactorService) load(ctx context.Context, userID uint64) (*models.UserTwoFactor, string, error) {
+	twoFactor, err := s.repo.GetByUserID(ctx, s.conn.ConnectGormWrite, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", nil
+		}
+		return nil, "", fmt.Errorf("error al buscar el segundo factor: %w", err)
+	}
+	if s.cipher == nil {
+		return nil, "", errors.New("el segundo factor no está configurado (AUTH_TWO_FACTOR_KEY)")
+	}
+	secret, err := s.cipher.decrypt(twoFactor.SecretEncrypted)
+	if err != nil {
+		return nil, "", err
+	}
+	return twoFactor, secret, nil
+}
+
+// newRecoveryCodes genera los códigos (formato xxxxx-xxxxx) y sus hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("no se pudo generar el código de recuperación: %w", err)
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignora mayúsculas, espacios y guiones: el usuario lo tipea a mano.
+func hashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,348 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/dtos/responses"
+	"go-fiber-core/internal/models"
+	twoFactorRepo "go-fiber-core/internal/repositories/twofactor"
+	menuService "go-fiber-core/internal/services/menu"
+
+	"github.com/alicebob/miniredis/v2"
+	redis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// fakeTwoFactorRepo guarda el segundo factor en memoria (ignora el *gorm.DB).
+type fakeTwoFactorRepo struct {
+	twoFactorRepo.TwoFactorRepository
+	factors map[uint64]*models.UserTwoFactor
+	codes   map[string]*models.UserRecoveryCode
+}
+
+func newFakeTwoFactorRepo() *fakeTwoFactorRepo {
+	return &fakeTwoFactorRepo{factors: map[uint64]*models.UserTwoFactor{}, codes: map[string]*models.UserRecoveryCode{}}
+}
+
+func (f *fakeTwoFactorRepo) GetByUserID(_ context.Context, _ *gorm.DB, userID uint64) (*models.UserTwoFactor, error) {
+	if tf, ok := f.factors[userID]; ok {
+		copied := *tf
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeTwoFactorRepo) Save(_ context.Context, _ *gorm.DB, twoFactor *models.UserTwoFactor) error {
+	copied := *twoFactor
+	f.factors[twoFactor.UserID] = &copied
+	return nil
+}
+
+func (f *fakeTwoFactorRepo) Confirm(_ context.Context, _ *gorm.DB, userID uint64, step int64, at time.Time) error {
+	f.factors[userID].ConfirmedAt = &at
+	f.factors[userID].LastUsedStep = step
+	return nil
+}
+
+func (f *fakeTwoFactorRepo) UseStep(_ context.Context, _ *gorm.DB, userID uint64, step int64) (bool, error) {
+	tf := f.factors[userID]
+	if tf.LastUsedStep >= step {
+		return false, nil
+	}
+	tf.LastUsedStep = step
+	return true, nil
+}
+
+func (f *fakeTwoFactorRepo) ReplaceRecoveryCodes(_ context.Context, _ *gorm.DB, userID uint64, codeHashes []string) error {
+	f.codes = map[string]*models.UserRecoveryCode{}
+	for _, hash := range codeHashes {
+		f.codes[hash] = &models.UserRecoveryCode{UserID: userID, CodeHash: hash}
+	}
+	return nil
+}
+
+func (f *fakeTwoFactorRepo) UseRecoveryCode(_ context.Context, _ *gorm.DB, userID uint64, codeHash string, at time.Time) (bool, error) {
+	code, ok := f.codes[codeHash]
+	if !ok || code.UserID != userID || code.UsedAt != nil {
+		return false, nil
+	}
+	code.UsedAt = &at
+	return true, nil
+}
+
+func (f *fakeLoginUserReader) GetByID(context.Context, *gorm.DB, uint64) (*models.User, error) {
+	return f.user, nil
+}
+
+type fakeMenuReader struct {
+	menuService.MenuReaderService
+}
+
+func (fakeMenuReader) GetMenuByUser(context.Context, uint64) ([]responses.MenuItemResponse, error) {
+	return nil, nil
+}
+
+func newTestChallenges(t *testing.T) (TwoFactorChallenges, *miniredis.Miniredis) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewTwoFactorChallenges(&connect.ConnectDTO{ConnectRedis: client}), s
+}
+
+// newTestTwoFactorLogin arma un authService con 2FA para un único usuario con contraseña "clave-segura".
+func newTestTwoFactorLogin(t *testing.T, user *models.User, requiredRoles string) (*authService, *twoFactorService) {
+	s, _, mock := newTestAuthService(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("clave-segura"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user.Password = string(hash)
+	reader := &fakeLoginUserReader{user: user}
+
+	cfg := &config.AppConfig{
+		App:  config.App{AppName: "Go Fiber Core"},
+		Auth: config.AuthConfig{TwoFactorKey: "clave-de-prueba", TwoFactorRequiredRoles: requiredRoles},
+	}
+	twoFactor, err := NewTwoFactorService(s.TransactionManager.Conn, newFakeTwoFactorRepo(), reader, cfg)
+	require.NoError(t, err)
+	challenges, _ := newTestChallenges(t)
+
+	s.userReader = reader
+	s.menuReader = fakeMenuReader{}
+	s.twoFactor = twoFactor
+	s.challenges = challenges
+	// Confirm activa el segundo factor en una transacción.
+	mock.MatchExpectationsInOrder(false)
+	for range 3 {
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+	}
+	return s, twoFactor.(*twoFactorService)
+}
+
+// enroll inscribe y confirma el segundo factor; devuelve el secreto y los códigos de recuperación.
+func enroll(t *testing.T, tf *twoFactorService, userID uint64) (string, []string) {
+	ctx := context.Background()
+	enrollment, err := tf.Enroll(ctx, userID)
+	require.NoError(t, err)
+	code, err := totpCode(enrollment.Secret, totpStep(tf.now()))
+	require.NoError(t, err)
+	codes, err := tf.Confirm(ctx, userID, code)
+	require.NoError(t, err)
+	return enrollment.Secret, codes
+}
+
+func TestTOTPCode_RFC6238(t *testing.T) {
+	// Vector de prueba de la RFC 6238 (SHA1, T = 59s), truncado a 6 dígitos.
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	code, err := totpCode(secret, totpStep(time.Unix(59, 0)))
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	now := time.Unix(1111111109, 0)
+	previous, err := totpCode(secret, totpStep(now)-1)
+	require.NoError(t, err)
+	step, ok := matchTOTP(secret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, totpStep(now)-1, step)
+
+	old, err := totpCode(secret, totpStep(now)-2)
+	require.NoError(t, err)
+	_, ok = matchTOTP(secret, old, now)
+	assert.False(t, ok)
+}
+
+func TestOtpauthURI(t *testing.T) {
+	uri, err := url.Parse(otpauthURI("Go Fiber Core", "ana@test.com", "JBSWY3DPEHPK3PXP"))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Go Fiber Core:ana@test.com", uri.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", uri.Query().Get("secret"))
+	assert.Equal(t, "Go Fiber Core", uri.Query().Get("issuer"))
+}
+
+func TestSecretCipher(t *testing.T) {
+	c, err := newSecretCipher("clave")
+	require.NoError(t, err)
+	encrypted, err := c.encrypt("JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "JBSWY3DPEHPK3PXP")
+
+	plain, err := c.decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", plain)
+
+	other, err := newSecretCipher("otra clave")
+	require.NoError(t, err)
+	_, err = other.decrypt(encrypted)
+	assert.Error(t, err)
+
+	none, err := newSecretCipher("")
+	require.NoError(t, err)
+	assert.Nil(t, none)
+}
+
+func TestTwoFactorChallenges_MaxAttempts(t *testing.T) {
+	challenges, s := newTestChallenges(t)
+	ctx := context.Background()
+
+	token, err := challenges.Create(ctx, 7, ChallengeVerify)
+	require.NoError(t, err)
+	// En Redis no queda el token en claro.
+	for _, key := range s.Keys() {
+		assert.NotContains(t, key, token)
+	}
+
+	for range maxTwoFactorAttempts {
+		challenge, err := challenges.Attempt(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), challenge.UserID)
+	}
+	_, err = challenges.Attempt(ctx, token)
+	assert.ErrorIs(t, err, domain.ErrAuthentication)
+	_, err = challenges.Get(ctx, token)
+	assert.ErrorIs(t, err, domain.ErrAuthentication)
+
+	token, err = challenges.Create(ctx, 7, ChallengeVerify)
+	require.NoError(t, err)
+	s.FastForward(TwoFactorChallengeTTL)
+	_, err = challenges.Attempt(ctx, token)
+	assert.ErrorIs(t, err, domain.ErrAuthentication)
+}
+
+func TestAuthService_LoginWithTwoFactor(t *testing.T) {
+	user := &models.User{ID: 7, Name: "Ana", Email: "ana@test.com", IsActive: true}
+	s, tf := newTestTwoFactorLogin(t, user, "")
+	ctx := context.Background()
+	secret, recoveryCodes := enroll(t, tf, user.ID)
+	require.Len(t, recoveryCodes, recoveryCodeCount)
+
+	resp, err := s.Login(ctx, requests.LoginRequest{Email: user.Email, Password: "clave-segura"}, ClientInfo{})
+	require.NoError(t, err)
+	assert.True(t, resp.TwoFactorRequired)
+	assert.Empty(t, resp.Token)
+	require.NotEmpty(t, resp.ChallengeToken)
+
+	// El código que confirmó la inscripción ya fue usado.
+	used, err := totpCode(secret, totpStep(tf.now()))
+	require.NoError(t, err)
+	_, err = s.VerifyTwoFactor(ctx, resp.ChallengeToken, used, ClientInfo{})
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	tf.now = func() time.Time { return time.Now().Add(totpPeriod) }
+	next, err := totpCode(secret, totpStep(tf.now()))
+	require.NoError(t, err)
+	session, err := s.VerifyTwoFactor(ctx, resp.ChallengeToken, next, ClientInfo{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, session.Token)
+	assert.NotEmpty(t, session.RefreshToken)
+
+	// El desafío no sirve para una segunda sesión.
+	_, err = s.VerifyTwoFactor(ctx, resp.ChallengeToken, recoveryCodes[0], ClientInfo{})
+	assert.ErrorIs(t, err, domain.ErrAuthentication)
+
+	// Un código de recuperación sirve una sola vez (sin importar mayúsculas ni guiones).
+	resp, err = s.Login(ctx, requests.LoginRequest{Email: user.Email, Password: "clave-segura"}, ClientInfo{})
+	require.NoError(t, err)
+	_, err = s.VerifyTwoFactor(ctx, resp.ChallengeToken, strings.ToUpper(strings.ReplaceAll(recoveryCodes[0], "-", "")), ClientInfo{})
+	require.NoError(t, err)
+	resp, err = s.Login(ctx, requests.LoginRequest{Email: user.Email, Password: "clave-segura"}, ClientInfo{})
+	require.NoError(t, err)
+	_, err = s.VerifyTwoFactor(ctx, resp.ChallengeToken, recoveryCodes[0], ClientInfo{})
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+// recordingLoginGuard anota los fallos y éxitos por email y nunca bloquea.
+type recordingLoginGuard struct {
+	LoginGuard
+	failures  []string
+	successes []string
+}
+
+func (g *recordingLoginGuard) Check(context.Context, string, string) error { return nil }
+
+func (g *recordingLoginGuard) RecordFailure(_ context.Context, email, _ string) error {
+	g.failures = append(g.failures, email)
+	return nil
+}
+
+func (g *recordingLoginGuard) RecordSuccess(_ context.Context, email string) error {
+	g.successes = append(g.successes, email)
+	return nil
+}
+
+func TestAuthService_TwoFactorCountsForLoginGuard(t *testing.T) {
+	user := &models.User{ID: 7, Name: "Ana", Email: "ana@test.com", IsActive: true}
+	s, tf := newTestTwoFactorLogin(t, user, "")
+	guard := &recordingLoginGuard{}
+	s.loginGuard = guard
+	ctx := context.Background()
+	secret, _ := enroll(t, tf, user.ID)
+
+	// La contraseña correcta no reinicia el contador: falta el segundo factor.
+	resp, err := s.Login(ctx, requests.LoginRequest{Email: user.Email, Password: "clave-segura"}, ClientInfo{})
+	require.NoError(t, err)
+	assert.Empty(t, guard.successes)
+
+	// Un código incorrecto cuenta como fallo de la cuenta.
+	_, err = s.VerifyTwoFactor(ctx, resp.ChallengeToken, "000000", ClientInfo{})
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{user.Email}, guard.failures)
+	assert.Empty(t, guard.successes)
+
+	tf.now = func() time.Time { return time.Now().Add(totpPeriod) }
+	next, err := totpCode(secret, totpStep(tf.now()))
+	require.NoError(t, err)
+	_, err = s.VerifyTwoFactor(ctx, resp.ChallengeToken, next, ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{user.Email}, guard.successes)
+}
+
+func TestAuthService_LoginRequiresTwoFactorSetup(t *testing.T) {
+	user := &models.User{ID: 7, Email: "admin@test.com", IsActive: true, Roles: []models.Role{{Name: "Admin", IsActive: true}}}
+	s, tf := newTestTwoFactorLogin(t, user, "admin, auditor")
+	ctx := context.Background()
+
+	resp, err := s.Login(ctx, requests.LoginRequest{Email: user.Email, Password: "clave-segura"}, ClientInfo{})
+	require.NoError(t, err)
+	assert.True(t, resp.TwoFactorSetupRequired)
+	assert.Empty(t, resp.Token)
+
+	enrollment, err := s.SetupTwoFactor(ctx, resp.ChallengeToken)
+	require.NoError(t, err)
+	code, err := totpCode(enrollment.Secret, totpStep(tf.now()))
+	require.NoError(t, err)
+	session, err := s.VerifyTwoFactor(ctx, resp.ChallengeToken, code, ClientInfo{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, session.Token)
+	assert.Len(t, session.RecoveryCodes, recoveryCodeCount)
+
+	// El rol lo exige: no se puede desactivar.
+	tf.now = func() time.Time { return time.Now().Add(totpPeriod) }
+	next, err := totpCode(enrollment.Secret, totpStep(tf.now()))
+	require.NoError(t, err)
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, tf.Disable(ctx, user.ID, next), &validationErr)
+	enabled, err := tf.IsEnabled(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	// Un desafío de verificación no permite volver a inscribirse.
+	resp, err = s.Login(ctx, requests.LoginRequest{Email: user.Email, Password: "clave-segura"}, ClientInfo{})
+	require.NoError(t, err)
+	assert.True(t, resp.TwoFactorRequired)
+	_, err = s.SetupTwoFactor(ctx, resp.ChallengeToken)
+	assert.ErrorIs(t, err, domain.ErrAuthentication)
+}
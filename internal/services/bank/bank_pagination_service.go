@@ -6,10 +6,13 @@ import (
 	"go-fiber-core/internal/dtos/connect"
 	"go-fiber-core/internal/models"
 	"go-fiber-core/internal/repositories/bank"
+	"go-fiber-core/internal/services/export"
 )
 
 type BankPaginationService interface {
 	GetAllPaginated(ctx context.Context, req dtos.PaginationRequest) (*dtos.PaginationResponse[models.Bank], error)
+	// Export valida la solicitud y devuelve la función que escribe el archivo en streaming.
+	Export(ctx context.Context, req dtos.ExportRequest) (export.StreamFunc, error)
 }
 
 type bankPaginationService struct {
@@ -30,3 +33,10 @@ func NewBankPaginationService(
 func (s *bankPaginationService) GetAllPaginated(ctx context.Context, req dtos.PaginationRequest) (*dtos.PaginationResponse[models.Bank], error) {
 	return s.paginator.GetAllPaginated(ctx, s.conn.ConnectGormRead, req)
 }
+
+func (s *bankPaginationService) Export(ctx context.Context, req dtos.ExportRequest) (export.StreamFunc, error) {
+	filters := export.FilterRequest(req)
+	return export.Prepare(ctx, req, func(lastProcessedID uint) ([]models.Bank, error) {
+		return s.paginator.GetFilteredBatch(ctx, s.conn.ConnectGormRead, filters, export.BatchSize, lastProcessedID)
+	})
+}
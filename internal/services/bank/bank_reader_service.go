@@ -2,13 +2,17 @@ package bank
 
 import (
 	"context"
+	"go-fiber-core/internal/dtos"
 	"go-fiber-core/internal/dtos/connect"
 	"go-fiber-core/internal/models"
 	bankRepo "go-fiber-core/internal/repositories/bank"
+	"go-fiber-core/internal/services/pagination"
 )
 
 type BankReaderService interface {
 	GetByID(ctx context.Context, id uint) (*models.Bank, error)
+	// GetByIDSparse aplica los parámetros fields/include; la respuesta solo serializa lo pedido.
+	GetByIDSparse(ctx context.Context, id uint, fields, include []string) (*dtos.Sparse, error)
 	GetAll(ctx context.Context) ([]models.Bank, error)
 }
 
@@ -28,6 +32,18 @@ func (s *bankReaderService) GetByID(ctx context.Context, id uint) (*models.Bank,
 	return s.bankReader.GetByID(ctx, s.conn.ConnectGormRead, id)
 }
 
+func (s *bankReaderService) GetByIDSparse(ctx context.Context, id uint, fields, include []string) (*dtos.Sparse, error) {
+	selection, err := pagination.ParseSelection[models.Bank](fields, include)
+	if err != nil {
+		return nil, err
+	}
+	bank, err := s.bankReader.GetByIDWithModifier(ctx, s.conn.ConnectGormRead, id, selection.Modifier(nil))
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.Sparse{Value: bank, Fields: selection.ResponseKeys()}, nil
+}
+
 func (s *bankReaderService) GetAll(ctx context.Context) ([]models.Bank, error) {
 	return s.bankReader.GetAll(ctx, s.conn.ConnectGormRead)
 }
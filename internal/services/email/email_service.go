@@ -14,6 +14,9 @@ import (
 	gomail "gopkg.in/gomail.v2"
 )
 
+// DefaultTemplatesDir es el directorio de las plantillas markdown, relativo a la raíz del proyecto.
+const DefaultTemplatesDir = "internal/services/email/templates"
+
 // --- INTERFACES ---
 
 // EmailSender define el contrato para el servicio de envío de correos base.
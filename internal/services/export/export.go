@@ -0,0 +1,210 @@
+// Package export genera archivos CSV/XLSX a partir de los recursos paginados,
+// escribiendo en streaming lote a lote para no cargar la tabla completa en memoria.
+package export
+
+import (
+	"context"
+	"fmt"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos"
+	"go-fiber-core/internal/services/pagination"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// Formatos admitidos.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// BatchSize es la cantidad de filas que se leen por consulta (keyset por PK).
+const BatchSize = 1000
+
+// BatchFunc devuelve el lote (de BatchSize filas) siguiente al último ID procesado.
+// Un lote incompleto indica el final. Normalmente envuelve a PaginationService.GetFilteredBatch.
+type BatchFunc[T any] func(lastProcessedID uint) ([]T, error)
+
+// StreamFunc escribe el archivo completo en w.
+type StreamFunc func(w io.Writer) error
+
+// column es una columna ya resuelta contra el modelo.
+type column struct {
+	field *schema.Field
+	label string
+}
+
+// FilterRequest extrae de un ExportRequest solo los filtros, que es lo único que se respeta al exportar.
+func FilterRequest(req dtos.ExportRequest) dtos.PaginationRequest {
+	return dtos.PaginationRequest{
+		FilterBy:     req.FilterBy,
+		FilterValues: req.FilterValues,
+		Filters:      req.Filters,
+		Search:       req.Search,
+	}
+}
+
+// ContentType devuelve el Content-Type del formato.
+func ContentType(format string) string {
+	if normalizeFormat(format) == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// FileName arma el nombre del archivo con la extensión del formato (ej: "bancos.xlsx").
+func FileName(base, format string) string {
+	return base + "." + normalizeFormat(format)
+}
+
+// Prepare valida el formato y las columnas, y trae el primer lote para que los errores
+// (filtros inválidos, fallas de la base) se informen antes de empezar a escribir la respuesta.
+func Prepare[T any](ctx context.Context, req dtos.ExportRequest, next BatchFunc[T]) (StreamFunc, error) {
+	ms, err := pagination.ParseModelSchema(new(T))
+	if err != nil {
+		return nil, err
+	}
+	pk := ms.PrimaryField()
+	if pk == nil {
+		return nil, fmt.Errorf("%w: el modelo %T no tiene clave primaria", domain.ErrInternal, *new(T))
+	}
+
+	fieldErrors := make(map[string][]string)
+	format := normalizeFormat(req.Format)
+	if format != FormatCSV && format != FormatXLSX {
+		fieldErrors["format"] = append(fieldErrors["format"], "El formato debe ser 'csv' o 'xlsx'.")
+	}
+	columns := resolveColumns(ms, req.Columns, fieldErrors)
+	if len(fieldErrors) > 0 {
+		return nil, domain.NewValidationError(fieldErrors)
+	}
+
+	first, err := next(0)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(w io.Writer) error {
+		out, err := newRowWriter(format, w)
+		if err != nil {
+			return err
+		}
+		header := make([]string, len(columns))
+		for i, col := range columns {
+			header[i] = col.label
+		}
+		if err := out.WriteRow(header); err != nil {
+			return err
+		}
+
+		batch := first
+		for len(batch) > 0 {
+			var lastID uint
+			for i := range batch {
+				rv := reflect.ValueOf(&batch[i]).Elem()
+				record := make([]string, len(columns))
+				for j, col := range columns {
+					value, _ := col.field.ValueOf(ctx, rv)
+					record[j] = formatValue(value)
+				}
+				if err := out.WriteRow(record); err != nil {
+					return err
+				}
+				id, _ := pk.ValueOf(ctx, rv)
+				lastID = toUint(id)
+			}
+			if len(batch) < BatchSize {
+				break
+			}
+			if batch, err = next(lastID); err != nil {
+				return err
+			}
+		}
+		return out.Close()
+	}, nil
+}
+
+// resolveColumns valida las columnas pedidas contra la lista de columnas exportables del modelo.
+func resolveColumns(ms *pagination.ModelSchema, requested []dtos.ExportColumn, fieldErrors map[string][]string) []column {
+	exportable := ms.ExportFields()
+	if len(requested) == 0 {
+		columns := make([]column, len(exportable))
+		for i, field := range exportable {
+			columns[i] = column{field: field, label: field.DBName}
+		}
+		return columns
+	}
+
+	byName := make(map[string]*schema.Field, len(exportable))
+	for _, field := range exportable {
+		byName[field.DBName] = field
+	}
+	columns := make([]column, 0, len(requested))
+	for _, col := range requested {
+		field, ok := byName[col.Field]
+		if !ok {
+			fieldErrors[col.Field] = append(fieldErrors[col.Field], "El campo no está habilitado para exportar.")
+			continue
+		}
+		label := col.Label
+		if label == "" {
+			label = field.DBName
+		}
+		columns = append(columns, column{field: field, label: label})
+	}
+	return columns
+}
+
+func normalizeFormat(format string) string {
+	if format == "" {
+		return FormatCSV
+	}
+	return format
+}
+
+// formatValue convierte un valor de la fila al texto de la celda.
+func formatValue(value any) string {
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+		return ""
+	}
+	value = reflect.Indirect(rv).Interface()
+	switch v := value.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format("2006-01-02 15:04:05")
+	case bool:
+		if v {
+			return "Sí"
+		}
+		return "No"
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+func toUint(value any) uint {
+	switch v := value.(type) {
+	case uint:
+		return v
+	case uint64:
+		return uint(v)
+	case uint32:
+		return uint(v)
+	case int:
+		return uint(v)
+	case int64:
+		return uint(v)
+	case int32:
+		return uint(v)
+	}
+	id, _ := strconv.ParseUint(fmt.Sprint(value), 10, 64)
+	return uint(id)
+}
@@ -0,0 +1,118 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type account struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	Name      string    `json:"name"`
+	Password  string    `json:"-"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// batches simula GetFilteredBatch sobre 'total' filas.
+func batches(total int, calls *[]uint) BatchFunc[account] {
+	return func(lastProcessedID uint) ([]account, error) {
+		*calls = append(*calls, lastProcessedID)
+		var rows []account
+		for id := int(lastProcessedID) + 1; id <= total && len(rows) < BatchSize; id++ {
+			rows = append(rows, account{ID: uint64(id), Name: "Cuenta", Enabled: id%2 == 0})
+		}
+		return rows, nil
+	}
+}
+
+func TestPrepareCSV(t *testing.T) {
+	t.Run("Columnas seleccionadas, títulos y lotes por ID", func(t *testing.T) {
+		var calls []uint
+		req := dtos.ExportRequest{Columns: []dtos.ExportColumn{{Field: "id", Label: "ID"}, {Field: "enabled", Label: "Habilitado"}}}
+		stream, err := Prepare(context.Background(), req, batches(BatchSize+1, &calls))
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, stream(&buf))
+
+		records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\ufeff"))).ReadAll()
+		require.NoError(t, err)
+		assert.Len(t, records, BatchSize+2)
+		assert.Equal(t, []string{"ID", "Habilitado"}, records[0])
+		assert.Equal(t, []string{"2", "Sí"}, records[2])
+		assert.Equal(t, []uint{0, BatchSize}, calls)
+	})
+
+	t.Run("Sin columnas exporta las visibles del modelo", func(t *testing.T) {
+		var calls []uint
+		stream, err := Prepare(context.Background(), dtos.ExportRequest{}, batches(1, &calls))
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, stream(&buf))
+		header := strings.SplitN(strings.TrimPrefix(buf.String(), "\ufeff"), "\n", 2)[0]
+		assert.Equal(t, "id,name,enabled,created_at", header)
+	})
+
+	t.Run("Columnas no exportables y formato inválido", func(t *testing.T) {
+		var calls []uint
+		req := dtos.ExportRequest{Format: "pdf", Columns: []dtos.ExportColumn{{Field: "password"}}}
+		_, err := Prepare(context.Background(), req, batches(1, &calls))
+
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Contains(t, validationErr.Fields, "password")
+		assert.Contains(t, validationErr.Fields, "format")
+		assert.Empty(t, calls, "no debe consultar la base si la solicitud es inválida")
+	})
+
+	t.Run("El error del primer lote se devuelve antes de escribir", func(t *testing.T) {
+		dbErr := errors.New("db caída")
+		_, err := Prepare(context.Background(), dtos.ExportRequest{}, func(uint) ([]account, error) { return nil, dbErr })
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
+
+func TestPrepareXLSX(t *testing.T) {
+	var calls []uint
+	req := dtos.ExportRequest{Format: FormatXLSX, Columns: []dtos.ExportColumn{{Field: "name", Label: "Nombre <banco>"}}}
+	stream, err := Prepare(context.Background(), req, batches(3, &calls))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, stream(&buf))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			sheet = string(content)
+		}
+	}
+	assert.Contains(t, sheet, `<c r="A1" t="inlineStr"><is><t xml:space="preserve">Nombre &lt;banco&gt;</t></is></c>`)
+	assert.Equal(t, 4, strings.Count(sheet, "<row "))
+}
+
+func TestEscapeFormula(t *testing.T) {
+	assert.Equal(t, "'=HYPERLINK(\"x\")", escapeFormula("=HYPERLINK(\"x\")"))
+	assert.Equal(t, "-12.5", escapeFormula("-12.5"))
+	assert.Equal(t, "texto", escapeFormula("texto"))
+	assert.Equal(t, "AB", columnName(27))
+}
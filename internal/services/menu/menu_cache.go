@@ -0,0 +1,111 @@
+package menu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/dtos/responses"
+	"log"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+const (
+	menuCacheVersionKey = "menu:version"
+	menuCacheKeyPrefix  = "menu:tree:"
+
+	// noMenuCacheVersion indica que no se pudo leer la versión: no se guarda nada.
+	noMenuCacheVersion int64 = -1
+
+	// defaultMenuCacheTTL se usa si redis_expires_in_seconds no está configurado.
+	defaultMenuCacheTTL = time.Hour
+)
+
+// MenuCache guarda en Redis el árbol de menús ya armado de cada usuario.
+//
+// Las claves llevan un número de versión global (menu:tree:v<versión>:user:<id>):
+// cualquier cambio de menús, roles o excepciones incrementa la versión y todas las
+// claves anteriores quedan sin uso hasta que vencen. Así no hace falta saber qué
+// usuarios afecta cada cambio.
+//
+// Los errores de Redis no cortan la petición: se registran y se consulta la base.
+type MenuCache interface {
+	// Get devuelve el árbol cacheado y la versión vigente. En un fallo, el árbol armado
+	// desde la base se guarda con esa misma versión: si mientras tanto hubo un cambio,
+	// queda con una versión vieja y no se vuelve a leer.
+	Get(ctx context.Context, userID uint64) ([]responses.MenuItemResponse, int64, bool)
+	Set(ctx context.Context, userID uint64, version int64, items []responses.MenuItemResponse)
+	// Invalidate descarta los árboles de todos los usuarios.
+	Invalidate(ctx context.Context)
+}
+
+type redisMenuCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewMenuCache crea la caché sobre la conexión de Redis de la aplicación. Sin cliente
+// (ej: en tests) la caché no guarda nada.
+func NewMenuCache(conn *connect.ConnectDTO, cfg *config.AppConfig) MenuCache {
+	ttl := time.Duration(cfg.Redis.RedisExpiresInSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = defaultMenuCacheTTL
+	}
+	return &redisMenuCache{client: conn.ConnectRedis, ttl: ttl}
+}
+
+func (c *redisMenuCache) Get(ctx context.Context, userID uint64) ([]responses.MenuItemResponse, int64, bool) {
+	if c.client == nil {
+		return nil, noMenuCacheVersion, false
+	}
+	version, err := c.client.Get(ctx, menuCacheVersionKey).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Printf("Error al leer la versión de la caché de menús: %v", err)
+		return nil, noMenuCacheVersion, false
+	}
+	raw, err := c.client.Get(ctx, menuCacheKey(version, userID)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("Error al leer el menú cacheado del usuario %d: %v", userID, err)
+		}
+		return nil, version, false
+	}
+	var items []responses.MenuItemResponse
+	if err := json.Unmarshal(raw, &items); err != nil {
+		log.Printf("Menú cacheado inválido para el usuario %d: %v", userID, err)
+		return nil, version, false
+	}
+	return items, version, true
+}
+
+func (c *redisMenuCache) Set(ctx context.Context, userID uint64, version int64, items []responses.MenuItemResponse) {
+	if c.client == nil || version == noMenuCacheVersion {
+		return
+	}
+	raw, err := json.Marshal(items)
+	if err != nil {
+		log.Printf("Error al serializar el menú del usuario %d: %v", userID, err)
+		return
+	}
+	if err := c.client.Set(ctx, menuCacheKey(version, userID), raw, c.ttl).Err(); err != nil {
+		log.Printf("Error al cachear el menú del usuario %d: %v", userID, err)
+	}
+}
+
+func (c *redisMenuCache) Invalidate(ctx context.Context) {
+	if c.client == nil {
+		return
+	}
+	if err := c.client.Incr(ctx, menuCacheVersionKey).Err(); err != nil {
+		log.Printf("Error al invalidar la caché de menús: %v", err)
+	}
+}
+
+// menuCacheKey arma la clave del usuario para una versión (0 si nunca se invalidó).
+func menuCacheKey(version int64, userID uint64) string {
+	return fmt.Sprintf("%sv%d:user:%d", menuCacheKeyPrefix, version, userID)
+}
@@ -0,0 +1,69 @@
+package menu
+
+import (
+	"context"
+	"testing"
+
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/dtos/responses"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	redis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMenuCache(t *testing.T) (MenuCache, *miniredis.Miniredis) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewMenuCache(&connect.ConnectDTO{ConnectRedis: client}, &config.AppConfig{}), s
+}
+
+func TestMenuCache_SetGetInvalidate(t *testing.T) {
+	cache, s := newTestMenuCache(t)
+	ctx := context.Background()
+	tree := []responses.MenuItemResponse{{Type: "group", Text: "Tablas", Children: []responses.MenuItemResponse{{Type: "link", Text: "Bancos"}}}}
+
+	_, version, ok := cache.Get(ctx, 7)
+	assert.False(t, ok)
+	cache.Set(ctx, 7, version, tree)
+
+	cached, _, ok := cache.Get(ctx, 7)
+	assert.True(t, ok)
+	assert.Equal(t, tree, cached)
+	assert.Equal(t, defaultMenuCacheTTL, s.TTL(menuCacheKey(version, 7)))
+
+	// Otro usuario no comparte la clave
+	_, _, ok = cache.Get(ctx, 8)
+	assert.False(t, ok)
+
+	cache.Invalidate(ctx)
+	_, _, ok = cache.Get(ctx, 7)
+	assert.False(t, ok)
+}
+
+func TestMenuCache_StaleSetIsIgnored(t *testing.T) {
+	cache, _ := newTestMenuCache(t)
+	ctx := context.Background()
+
+	// Una lectura empieza, un cambio invalida y la lectura guarda el árbol viejo.
+	_, version, _ := cache.Get(ctx, 7)
+	cache.Invalidate(ctx)
+	cache.Set(ctx, 7, version, []responses.MenuItemResponse{{Type: "link", Text: "Viejo"}})
+
+	_, _, ok := cache.Get(ctx, 7)
+	assert.False(t, ok)
+}
+
+func TestMenuCache_RedisDown(t *testing.T) {
+	cache, s := newTestMenuCache(t)
+	ctx := context.Background()
+	s.Close()
+
+	_, version, ok := cache.Get(ctx, 7)
+	assert.False(t, ok)
+	assert.Equal(t, noMenuCacheVersion, version)
+	cache.Set(ctx, 7, version, []responses.MenuItemResponse{})
+	cache.Invalidate(ctx)
+}
@@ -2,8 +2,13 @@ package menu
 
 import (
 	"context"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos"
 	"go-fiber-core/internal/dtos/responses"
+	"go-fiber-core/internal/menuaccess"
+	"go-fiber-core/internal/models"
 	"go-fiber-core/internal/repositories/menu" // Importamos el Repositorio de Menú
+	"slices"
 )
 
 // ────────────────────────────────────────────────
@@ -11,6 +16,15 @@ import (
 // ────────────────────────────────────────────────
 type MenuReaderService interface {
 	GetMenuByUser(ctx context.Context, userID uint64) ([]responses.MenuItemResponse, error)
+	// GetRolesByMenu devuelve los roles a los que está asignado el menú.
+	GetRolesByMenu(ctx context.Context, menuID uint64) ([]models.Role, error)
+	// GetMenuPaths devuelve los to_path de los menús efectivos del usuario. Lo usa
+	// middleware.RequireMenu para controlar el acceso a los grupos de rutas.
+	GetMenuPaths(ctx context.Context, userID uint64) ([]string, error)
+	// RouteAccess arma el diagnóstico de qué grupos de rutas habilita cada menú (menuaccess.Routes).
+	RouteAccess(ctx context.Context) (*responses.MenuRouteAccessResponse, error)
+	// Export devuelve el árbol completo (sin borrados) en el formato de menus.json.
+	Export(ctx context.Context) ([]dtos.MenuTreeItem, error)
 }
 
 // ────────────────────────────────────────────────
@@ -18,18 +32,27 @@ type MenuReaderService interface {
 // ────────────────────────────────────────────────
 type menuReaderService struct {
 	menuReaderRepo menu.MenuReader // Inyectamos el Repositorio
+	cache          MenuCache
 }
 
-// NewMenuReaderService crea una nueva instancia del servicio, inyectando el repositorio.
-func NewMenuReaderService(menuReaderRepo menu.MenuReader) MenuReaderService {
-	return &menuReaderService{menuReaderRepo: menuReaderRepo}
+// NewMenuReaderService crea una nueva instancia del servicio, inyectando el repositorio
+// y la caché de árboles por usuario.
+func NewMenuReaderService(menuReaderRepo menu.MenuReader, cache MenuCache) MenuReaderService {
+	return &menuReaderService{menuReaderRepo: menuReaderRepo, cache: cache}
 }
 
 // ────────────────────────────────────────────────
 // OBTENER MENÚ POR USUARIO
 // ────────────────────────────────────────────────
 // Obtiene la lista plana del repositorio y construye la jerarquía (árbol de menú).
+// El árbol armado se guarda en Redis hasta el próximo cambio de menús o roles.
 func (s *menuReaderService) GetMenuByUser(ctx context.Context, userID uint64) ([]responses.MenuItemResponse, error) {
+	// 0️⃣ CACHÉ
+	cached, version, ok := s.cache.Get(ctx, userID)
+	if ok {
+		return cached, nil
+	}
+
 	// 1️⃣ OBTENER DATOS PLANOS del repositorio
 	// Pasamos 'nil' o la conexión si el repositorio lo requiere, pero idealmente el repo
 	// ya maneja su conexión inyectada.
@@ -38,46 +61,143 @@ func (s *menuReaderService) GetMenuByUser(ctx context.Context, userID uint64) ([
 	if err != nil {
 		return nil, err
 	}
-	if len(menus) == 0 {
-		return []responses.MenuItemResponse{}, nil
+
+	// 2️⃣ Construir jerarquía
+	tree := []responses.MenuItemResponse{}
+	if len(menus) > 0 {
+		tree = buildMenuTree(menus)
+	}
+	s.cache.Set(ctx, userID, version, tree)
+	return tree, nil
+}
+
+// ────────────────────────────────────────────────
+// ROLES DE UN MENÚ
+// ────────────────────────────────────────────────
+func (s *menuReaderService) GetRolesByMenu(ctx context.Context, menuID uint64) ([]models.Role, error) {
+	missing, err := s.menuReaderRepo.MissingIDs(ctx, nil, []uint64{menuID})
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, domain.ErrNotFound
+	}
+	return s.menuReaderRepo.GetRolesByMenuID(ctx, nil, menuID)
+}
+
+// buildMenuTree arma el árbol a partir de la lista plana (ordenada por order_index), con
+// cualquier profundidad. Los ítems cuyo padre no está en la lista (inactivo, borrado o
+// denegado) se descartan junto con su rama.
+func buildMenuTree(menus []models.Menu) []responses.MenuItemResponse {
+	present := make(map[uint]bool, len(menus))
+	children := make(map[uint][]models.Menu)
+	for _, m := range menus {
+		present[m.ID] = true
 	}
 
-	// 2️⃣ Mapear los menús por ID para construcción de jerarquía
-	menuMap := make(map[uint]*responses.MenuItemResponse)
+	var roots []models.Menu
 	for _, m := range menus {
-		menuMap[m.ID] = &responses.MenuItemResponse{
-			Type: m.ItemType,
-			Text: m.ItemName,
-			To:   m.ToPath,
-			Icon: m.Icon,
+		switch {
+		case m.ParentID == nil:
+			roots = append(roots, m)
+		case present[*m.ParentID]:
+			children[*m.ParentID] = append(children[*m.ParentID], m)
 		}
 	}
 
-	// 3️⃣ Construir jerarquía (dos pasadas para anidamiento correcto)
-	var roots []responses.MenuItemResponse
-	processed := make(map[uint]bool) // Mapa para marcar qué ítems son hijos anidados
+	var build func(items []models.Menu) []responses.MenuItemResponse
+	build = func(items []models.Menu) []responses.MenuItemResponse {
+		result := make([]responses.MenuItemResponse, 0, len(items))
+		for _, m := range items {
+			result = append(result, responses.MenuItemResponse{
+				Type:     m.ItemType,
+				Text:     m.ItemName,
+				To:       m.ToPath,
+				Icon:     m.Icon,
+				Children: build(children[m.ID]),
+			})
+		}
+		return result
+	}
+	return build(roots)
+}
 
-	// PASADA 1: ANIDAR HIJOS
-	for _, m := range menus {
-		if m.ParentID != nil {
-			currentMenuItem := menuMap[m.ID]
-			parentID := *m.ParentID
-			parent := menuMap[parentID]
-
-			if parent != nil {
-				// Anidamos al hijo al padre (copia de valor)
-				parent.Children = append(parent.Children, *currentMenuItem)
-				processed[m.ID] = true // Marcamos que este ID es un hijo anidado
+// ────────────────────────────────────────────────
+// EXPORTAR ÁRBOL
+// ────────────────────────────────────────────────
+func (s *menuReaderService) Export(ctx context.Context) ([]dtos.MenuTreeItem, error) {
+	menus, err := s.menuReaderRepo.GetAll(ctx, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	if tree := exportTree(menus); tree != nil {
+		return tree, nil
+	}
+	return []dtos.MenuTreeItem{}, nil
+}
+
+// ────────────────────────────────────────────────
+// ACCESO A RUTAS POR MENÚ
+// ────────────────────────────────────────────────
+// Sale del mismo árbol que ve el usuario (cacheado): un menú inactivo, denegado o cuyo
+// padre no está visible tampoco habilita sus rutas.
+func (s *menuReaderService) GetMenuPaths(ctx context.Context, userID uint64) ([]string, error) {
+	tree, err := s.GetMenuByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	var walk func(items []responses.MenuItemResponse)
+	walk = func(items []responses.MenuItemResponse) {
+		for _, item := range items {
+			if item.To != nil && *item.To != "" {
+				paths = append(paths, *item.To)
 			}
+			walk(item.Children)
 		}
 	}
+	walk(tree)
+	return paths, nil
+}
+
+func (s *menuReaderService) RouteAccess(ctx context.Context) (*responses.MenuRouteAccessResponse, error) {
+	menus, err := s.menuReaderRepo.GetAll(ctx, nil, false)
+	if err != nil {
+		return nil, err
+	}
 
-	// PASADA 2: RECOLECTAR SOLO RAÍCES (ítems que no fueron anidados)
+	result := &responses.MenuRouteAccessResponse{
+		Menus:        []responses.MenuRoutesResponse{},
+		MissingMenus: []string{},
+	}
+	found := make(map[string]bool)
 	for _, m := range menus {
-		if !processed[m.ID] {
-			roots = append(roots, *menuMap[m.ID])
+		if m.ToPath == nil || *m.ToPath == "" {
+			continue
+		}
+		found[*m.ToPath] = true
+		item := responses.MenuRoutesResponse{
+			MenuID:   m.ID,
+			ItemName: m.ItemName,
+			ToPath:   *m.ToPath,
+			IsActive: m.IsActive,
+			Groups:   []string{},
 		}
+		for _, route := range menuaccess.Routes {
+			if slices.Contains(route.Menus, *m.ToPath) {
+				item.Groups = append(item.Groups, route.Group)
+			}
+		}
+		result.Menus = append(result.Menus, item)
 	}
 
-	return roots, nil
+	// Menús del mapeo que no existen (o están borrados): su grupo quedaría inaccesible.
+	for _, route := range menuaccess.Routes {
+		for _, path := range route.Menus {
+			if !found[path] && !slices.Contains(result.MissingMenus, path) {
+				result.MissingMenus = append(result.MissingMenus, path)
+			}
+		}
+	}
+	return result, nil
 }
@@ -0,0 +1,38 @@
+package menu
+
+import (
+	"testing"
+
+	"go-fiber-core/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildMenuTree(t *testing.T) {
+	parent := func(id uint) *uint { return &id }
+
+	// Lista plana ordenada por order_index: el nieto (4) aparece antes que su padre (2)
+	// y el 6 cuelga de un padre que no llegó (inactivo o denegado).
+	menus := []models.Menu{
+		{ID: 1, ItemType: "group", ItemName: "Tablas"},
+		{ID: 4, ItemType: "link", ItemName: "Bancos", ParentID: parent(2)},
+		{ID: 2, ItemType: "group", ItemName: "Maestros", ParentID: parent(1)},
+		{ID: 3, ItemType: "link", ItemName: "Dashboard"},
+		{ID: 6, ItemType: "link", ItemName: "Huérfano", ParentID: parent(5)},
+		{ID: 7, ItemType: "link", ItemName: "Usuarios", ParentID: parent(2)},
+	}
+
+	tree := buildMenuTree(menus)
+
+	assert.Len(t, tree, 2)
+	assert.Equal(t, "Tablas", tree[0].Text)
+	assert.Equal(t, "Dashboard", tree[1].Text)
+	assert.Empty(t, tree[1].Children)
+
+	assert.Len(t, tree[0].Children, 1)
+	maestros := tree[0].Children[0]
+	assert.Equal(t, "Maestros", maestros.Text)
+	assert.Len(t, maestros.Children, 2)
+	assert.Equal(t, "Bancos", maestros.Children[0].Text)
+	assert.Equal(t, "Usuarios", maestros.Children[1].Text)
+}
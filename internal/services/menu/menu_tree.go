@@ -0,0 +1,259 @@
+package menu
+
+import (
+	"fmt"
+	"go-fiber-core/internal/dtos"
+	"go-fiber-core/internal/models"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// menuItemTypes son los valores que admite el CHECK de menus.item_type.
+var menuItemTypes = map[string]bool{"link": true, "separator": true, "group": true, "line": true}
+
+// Largos máximos de las columnas de menus.
+const (
+	maxItemNameLength = 100
+	maxToPathLength   = 255
+	maxIconLength     = 100
+
+	// orderStep es el salto entre order_index consecutivos (como en menus.json).
+	orderStep = 10
+)
+
+// validateMenuFields acumula en fieldErrors los problemas de un ítem. prefix antecede
+// al nombre del campo (ej: "[2].children[0]." al importar).
+func validateMenuFields(itemType, itemName string, toPath, icon *string, prefix string, fieldErrors map[string][]string) {
+	if !menuItemTypes[itemType] {
+		fieldErrors[prefix+"item_type"] = append(fieldErrors[prefix+"item_type"], "El tipo debe ser link, separator, group o line.")
+	}
+	name := strings.TrimSpace(itemName)
+	if name == "" {
+		fieldErrors[prefix+"item_name"] = append(fieldErrors[prefix+"item_name"], "El nombre es obligatorio.")
+	} else if utf8.RuneCountInString(name) > maxItemNameLength {
+		fieldErrors[prefix+"item_name"] = append(fieldErrors[prefix+"item_name"], fmt.Sprintf("El nombre admite hasta %d caracteres.", maxItemNameLength))
+	}
+	if toPath != nil && utf8.RuneCountInString(*toPath) > maxToPathLength {
+		fieldErrors[prefix+"to_path"] = append(fieldErrors[prefix+"to_path"], fmt.Sprintf("La ruta admite hasta %d caracteres.", maxToPathLength))
+	}
+	if icon != nil && utf8.RuneCountInString(*icon) > maxIconLength {
+		fieldErrors[prefix+"icon"] = append(fieldErrors[prefix+"icon"], fmt.Sprintf("El icono admite hasta %d caracteres.", maxIconLength))
+	}
+}
+
+// validateTree valida un árbol importado completo, incluidos los nombres repetidos bajo un
+// mismo padre (índice único (parent_id, lower(item_name))). En la raíz parent_id es NULL y
+// el índice no aplica: menus.json repite "Línea Configuración".
+func validateTree(items []dtos.MenuTreeItem, prefix string, root bool, fieldErrors map[string][]string) {
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		path := fmt.Sprintf("%s[%d].", prefix, i)
+		validateMenuFields(item.ItemType, item.ItemName, item.ToPath, item.Icon, path, fieldErrors)
+
+		key := nameKey(item.ItemName)
+		if !root && key != "" && seen[key] {
+			fieldErrors[path+"item_name"] = append(fieldErrors[path+"item_name"], "El nombre se repite en el mismo nivel.")
+		}
+		seen[key] = true
+
+		validateTree(item.Children, path+"children", false, fieldErrors)
+	}
+}
+
+// nameKey normaliza el nombre como lo compara el índice único.
+func nameKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// parentKey identifica un nivel del árbol (0 = raíz).
+func parentKey(parentID *uint) uint {
+	if parentID == nil {
+		return 0
+	}
+	return *parentID
+}
+
+// childrenIndex agrupa los menús por padre, conservando el orden recibido.
+func childrenIndex(menus []models.Menu) map[uint][]models.Menu {
+	children := make(map[uint][]models.Menu)
+	for _, m := range menus {
+		key := parentKey(m.ParentID)
+		children[key] = append(children[key], m)
+	}
+	return children
+}
+
+// subtreeIDs devuelve el ID del menú y los de todos sus descendientes en menus.
+func subtreeIDs(menus []models.Menu, rootID uint) []uint {
+	children := childrenIndex(menus)
+	ids := []uint{rootID}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			ids = append(ids, child.ID)
+		}
+	}
+	return ids
+}
+
+// exportTree arma el árbol anidado (formato de menus.json) a partir de la lista plana.
+func exportTree(menus []models.Menu) []dtos.MenuTreeItem {
+	sort.SliceStable(menus, func(i, j int) bool {
+		if menus[i].OrderIndex != menus[j].OrderIndex {
+			return menus[i].OrderIndex < menus[j].OrderIndex
+		}
+		return menus[i].ID < menus[j].ID
+	})
+	children := childrenIndex(menus)
+
+	var build func(parent uint) []dtos.MenuTreeItem
+	build = func(parent uint) []dtos.MenuTreeItem {
+		var items []dtos.MenuTreeItem
+		for _, m := range children[parent] {
+			id := m.ID
+			item := dtos.MenuTreeItem{
+				ID:         &id,
+				ItemType:   m.ItemType,
+				ItemName:   m.ItemName,
+				ToPath:     m.ToPath,
+				Icon:       m.Icon,
+				OrderIndex: m.OrderIndex,
+				Children:   build(m.ID),
+			}
+			if !m.IsActive {
+				inactive := false
+				item.IsActive = &inactive
+			}
+			items = append(items, item)
+		}
+		return items
+	}
+	return build(0)
+}
+
+// validateTreeIDs rechaza los IDs repetidos en el árbol importado.
+func validateTreeIDs(items []dtos.MenuTreeItem, prefix string, seen map[uint]bool, fieldErrors map[string][]string) {
+	for i, item := range items {
+		path := fmt.Sprintf("%s[%d].", prefix, i)
+		if item.ID != nil {
+			if seen[*item.ID] {
+				fieldErrors[path+"id"] = append(fieldErrors[path+"id"], "El ID se repite en el árbol.")
+			}
+			seen[*item.ID] = true
+		}
+		validateTreeIDs(item.Children, path+"children", seen, fieldErrors)
+	}
+}
+
+// menuMatcher reconoce los ítems existentes (incluidos los borrados) que corresponden a
+// cada nodo importado: primero por ID, luego por padre y nombre y por último por to_path
+// (solo si la ruta es única). Cada menú existente se usa una sola vez, y los que el árbol
+// nombra por ID quedan reservados para ese nodo.
+type menuMatcher struct {
+	byID     map[uint]models.Menu
+	byName   map[string][]models.Menu
+	byPath   map[string][]models.Menu
+	used     map[uint]bool
+	reserved map[uint]bool
+}
+
+func newMenuMatcher(all []models.Menu, items []dtos.MenuTreeItem) *menuMatcher {
+	m := &menuMatcher{
+		byID:     make(map[uint]models.Menu, len(all)),
+		byName:   make(map[string][]models.Menu),
+		byPath:   make(map[string][]models.Menu),
+		used:     make(map[uint]bool),
+		reserved: make(map[uint]bool),
+	}
+	for _, item := range all {
+		m.byID[item.ID] = item
+		key := siblingKey(item.ParentID, item.ItemName)
+		m.byName[key] = append(m.byName[key], item)
+		if item.ToPath != nil && *item.ToPath != "" {
+			m.byPath[*item.ToPath] = append(m.byPath[*item.ToPath], item)
+		}
+	}
+	var reserve func(nodes []dtos.MenuTreeItem)
+	reserve = func(nodes []dtos.MenuTreeItem) {
+		for _, n := range nodes {
+			if n.ID != nil {
+				m.reserved[*n.ID] = true
+			}
+			reserve(n.Children)
+		}
+	}
+	reserve(items)
+	return m
+}
+
+// siblingKey identifica un nombre dentro de un nivel del árbol.
+func siblingKey(parentID *uint, name string) string {
+	return fmt.Sprintf("%d/%s", parentKey(parentID), nameKey(name))
+}
+
+// match devuelve el menú existente del nodo que se ubicará bajo parent, si lo hay.
+func (m *menuMatcher) match(parent *uint, n dtos.MenuTreeItem) (models.Menu, bool) {
+	if n.ID != nil {
+		if item, ok := m.byID[*n.ID]; ok {
+			return m.take(item), true
+		}
+	}
+	if item, ok := m.first(m.byName[siblingKey(parent, n.ItemName)]); ok {
+		return m.take(item), true
+	}
+	if n.ToPath != nil && *n.ToPath != "" && len(m.byPath[*n.ToPath]) == 1 {
+		if item, ok := m.first(m.byPath[*n.ToPath]); ok {
+			return m.take(item), true
+		}
+	}
+	return models.Menu{}, false
+}
+
+// occupant devuelve el menú, todavía sin procesar, que ocupa el nombre bajo parent. El
+// índice único cuenta también los borrados, así que otro ítem no puede tomar ese nombre.
+func (m *menuMatcher) occupant(parent *uint, name string) (models.Menu, bool) {
+	if parent == nil {
+		return models.Menu{}, false
+	}
+	for _, item := range m.byName[siblingKey(parent, name)] {
+		if !m.used[item.ID] {
+			return item, true
+		}
+	}
+	return models.Menu{}, false
+}
+
+func (m *menuMatcher) first(items []models.Menu) (models.Menu, bool) {
+	for _, item := range items {
+		if !m.used[item.ID] && !m.reserved[item.ID] {
+			return item, true
+		}
+	}
+	return models.Menu{}, false
+}
+
+func (m *menuMatcher) take(item models.Menu) models.Menu {
+	m.used[item.ID] = true
+	return item
+}
+
+// recreated empareja los ítems creados con los que se borran y tienen el mismo nombre o
+// ruta: en la práctica es el mismo ítem con un ID nuevo, sin las asignaciones del anterior.
+func recreated(created, stale []models.Menu) []MenuImportRecreated {
+	var out []MenuImportRecreated
+	taken := make(map[uint]bool)
+	for _, c := range created {
+		for _, s := range stale {
+			if taken[s.ID] {
+				continue
+			}
+			samePath := c.ToPath != nil && s.ToPath != nil && *c.ToPath != "" && *c.ToPath == *s.ToPath
+			if samePath || nameKey(c.ItemName) == nameKey(s.ItemName) {
+				taken[s.ID] = true
+				out = append(out, MenuImportRecreated{ItemName: c.ItemName, OldID: s.ID, NewID: c.ID})
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,132 @@
+package menu
+
+import (
+	"testing"
+
+	"go-fiber-core/internal/dtos"
+	"go-fiber-core/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTree(t *testing.T) {
+	// En la raíz los nombres pueden repetirse (parent_id NULL); bajo un padre, no.
+	items := []dtos.MenuTreeItem{
+		{ItemType: "group", ItemName: "Línea Configuración", Children: []dtos.MenuTreeItem{
+			{ItemType: "link", ItemName: "Bancos"},
+			{ItemType: "link", ItemName: " bancos "},
+			{ItemType: "submenu", ItemName: "Usuarios"},
+		}},
+		{ItemType: "group", ItemName: "Línea Configuración"},
+		{ItemType: "link", ItemName: ""},
+	}
+
+	fieldErrors := make(map[string][]string)
+	validateTree(items, "", true, fieldErrors)
+
+	assert.Len(t, fieldErrors, 3)
+	assert.Contains(t, fieldErrors, "[0].children[1].item_name")
+	assert.Contains(t, fieldErrors, "[0].children[2].item_type")
+	assert.Contains(t, fieldErrors, "[2].item_name")
+}
+
+func TestExportTreeAndSubtree(t *testing.T) {
+	parent := func(id uint) *uint { return &id }
+
+	menus := []models.Menu{
+		{ID: 1, ItemType: "group", ItemName: "Tablas", OrderIndex: 20, IsActive: true},
+		{ID: 2, ItemType: "link", ItemName: "Bancos", OrderIndex: 20, ParentID: parent(1), IsActive: true},
+		{ID: 3, ItemType: "link", ItemName: "Usuarios", OrderIndex: 10, ParentID: parent(1)},
+		{ID: 4, ItemType: "link", ItemName: "Dashboard", OrderIndex: 10, IsActive: true},
+		{ID: 5, ItemType: "link", ItemName: "Sucursales", OrderIndex: 10, ParentID: parent(2), IsActive: true},
+	}
+
+	tree := exportTree(menus)
+
+	assert.Len(t, tree, 2)
+	assert.Equal(t, uint(4), *tree[0].ID)
+	assert.Equal(t, "Dashboard", tree[0].ItemName)
+	assert.Equal(t, "Tablas", tree[1].ItemName)
+	assert.Equal(t, "Usuarios", tree[1].Children[0].ItemName)
+	assert.False(t, tree[1].Children[0].Active())
+	assert.Nil(t, tree[1].Children[1].IsActive)
+	assert.Equal(t, "Sucursales", tree[1].Children[1].Children[0].ItemName)
+
+	assert.ElementsMatch(t, []uint{1, 2, 3, 5}, subtreeIDs(menus, 1))
+	assert.ElementsMatch(t, []uint{4}, subtreeIDs(menus, 4))
+}
+
+func TestMenuMatcher(t *testing.T) {
+	parent := func(id uint) *uint { return &id }
+	path := func(p string) *string { return &p }
+
+	all := []models.Menu{
+		{ID: 1, ItemType: "group", ItemName: "Tablas"},
+		{ID: 2, ItemType: "group", ItemName: "Seguridad"},
+		{ID: 3, ItemType: "link", ItemName: "Bancos", ParentID: parent(1), ToPath: path("/banks")},
+		{ID: 4, ItemType: "link", ItemName: "Usuarios", ParentID: parent(1), ToPath: path("/users")},
+		{ID: 5, ItemType: "separator", ItemName: "Separador", ParentID: parent(1)},
+	}
+	items := []dtos.MenuTreeItem{
+		{ItemType: "group", ItemName: "Tablas"},
+		{ItemType: "group", ItemName: "Seguridad", Children: []dtos.MenuTreeItem{
+			{ID: parent(3), ItemType: "link", ItemName: "Bancos"},
+			{ItemType: "link", ItemName: "Usuarios", ToPath: path("/users")},
+			{ItemType: "separator", ItemName: "Separador"},
+		}},
+	}
+	m := newMenuMatcher(all, items)
+
+	// Por padre y nombre.
+	tablas, ok := m.match(nil, items[0])
+	assert.True(t, ok)
+	assert.Equal(t, uint(1), tablas.ID)
+	seguridad, _ := m.match(nil, items[1])
+
+	// Movidos a otro padre: por ID y por to_path conservan su ID.
+	bancos, ok := m.match(parent(seguridad.ID), items[1].Children[0])
+	assert.True(t, ok)
+	assert.Equal(t, uint(3), bancos.ID)
+	usuarios, ok := m.match(parent(seguridad.ID), items[1].Children[1])
+	assert.True(t, ok)
+	assert.Equal(t, uint(4), usuarios.ID)
+
+	// Sin ID ni ruta no hay cómo reconocerlo: se crea y se informa como recreado.
+	_, ok = m.match(parent(seguridad.ID), items[1].Children[2])
+	assert.False(t, ok)
+	created := []models.Menu{{ID: 9, ItemName: "Separador", ParentID: parent(2)}}
+	assert.Equal(t, []MenuImportRecreated{{ItemName: "Separador", OldID: 5, NewID: 9}}, recreated(created, all[4:]))
+}
+
+func TestMenuMatcherOccupant(t *testing.T) {
+	parent := func(id uint) *uint { return &id }
+
+	// Bancos (ID 3) se mueve bajo Tablas, donde otro "Bancos" (ID 4) sigue ocupando el nombre.
+	all := []models.Menu{
+		{ID: 1, ItemType: "group", ItemName: "Tablas"},
+		{ID: 3, ItemType: "link", ItemName: "Bancos"},
+		{ID: 4, ItemType: "link", ItemName: "Bancos", ParentID: parent(1)},
+	}
+	node := dtos.MenuTreeItem{ID: parent(3), ItemType: "link", ItemName: "Bancos"}
+	m := newMenuMatcher(all, []dtos.MenuTreeItem{node})
+
+	item, ok := m.match(parent(1), node)
+	assert.True(t, ok)
+	assert.Equal(t, uint(3), item.ID)
+	other, ok := m.occupant(parent(1), "bancos")
+	assert.True(t, ok)
+	assert.Equal(t, uint(4), other.ID)
+}
+
+func TestValidateTreeIDs(t *testing.T) {
+	id := func(v uint) *uint { return &v }
+	items := []dtos.MenuTreeItem{
+		{ID: id(1), ItemName: "Tablas", Children: []dtos.MenuTreeItem{{ID: id(1), ItemName: "Bancos"}}},
+		{ItemName: "Dashboard"},
+	}
+
+	fieldErrors := make(map[string][]string)
+	validateTreeIDs(items, "", make(map[uint]bool), fieldErrors)
+
+	assert.Equal(t, map[string][]string{"[0].children[0].id": {"El ID se repite en el árbol."}}, fieldErrors)
+}
@@ -2,25 +2,116 @@ package menu
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos"
 	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/models"
 	"go-fiber-core/internal/repositories/menu"
+	roleRepo "go-fiber-core/internal/repositories/role"
+	"go-fiber-core/internal/services"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// CreateMenuDTO son los datos de un ítem nuevo. Sin OrderIndex va al final de su nivel.
+type CreateMenuDTO struct {
+	ItemType   string
+	ItemName   string
+	ToPath     *string
+	Icon       *string
+	ParentID   *uint64
+	OrderIndex *int
+}
+
+// UpdateMenuDTO son los datos editables de un ítem. La posición se cambia con Move.
+type UpdateMenuDTO struct {
+	ItemType string
+	ItemName string
+	ToPath   *string
+	Icon     *string
+	IsActive bool
+}
+
+// MenuNode es un ítem en una operación de Move: sus Children quedan debajo en ese orden.
+type MenuNode struct {
+	ID       uint64
+	Children []MenuNode
+}
+
+// MenuImportResult resume una importación del árbol.
+type MenuImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Deleted int `json:"deleted"`
+	// Recreated lista los ítems que se crearon de nuevo mientras se borraba uno con el mismo
+	// nombre o ruta: el nuevo no hereda las asignaciones a roles y usuarios del anterior.
+	Recreated []MenuImportRecreated `json:"recreated"`
+}
+
+// MenuImportRecreated es un ítem que la importación reemplazó por otro con ID nuevo.
+type MenuImportRecreated struct {
+	ItemName string `json:"item_name"`
+	OldID    uint   `json:"old_id"`
+	NewID    uint   `json:"new_id"`
+}
+
 // Interfaz del servicio
 type MenuWriterService interface {
+	// AddBulkUsers otorga los menús a los usuarios, además de los que reciben por sus roles.
 	AddBulkUsers(ctx context.Context, menuIDs, userIDs []uint64) error
+	// DenyBulkUsers oculta los menús a los usuarios aunque sus roles los otorguen.
+	DenyBulkUsers(ctx context.Context, menuIDs, userIDs []uint64) error
+	// BulkRemoveUsers quita las excepciones (otorgadas o denegadas) de los usuarios.
 	BulkRemoveUsers(ctx context.Context, menuIDs, userIDs []uint64) error
+
+	AddRoles(ctx context.Context, menuID uint64, roleIDs []uint64) error
+	RemoveRoles(ctx context.Context, menuID uint64, roleIDs []uint64) error
+	BulkAddRoles(ctx context.Context, menuIDs, roleIDs []uint64) error
+	BulkRemoveRoles(ctx context.Context, menuIDs, roleIDs []uint64) error
+
+	Create(ctx context.Context, data CreateMenuDTO) (*models.Menu, error)
+	Update(ctx context.Context, id uint64, data UpdateMenuDTO) (*models.Menu, error)
+	// SoftDelete borra el ítem y toda su rama.
+	SoftDelete(ctx context.Context, id uint64) error
+	// Restore recupera el ítem y los descendientes que se borraron junto con él.
+	Restore(ctx context.Context, id uint64) (*models.Menu, error)
+	// Move deja items (con sus ramas) como hijos de parentID en ese orden, reescribiendo
+	// parent_id y order_index en una sola transacción. nil = raíz.
+	Move(ctx context.Context, parentID *uint64, items []MenuNode) error
+	// Import reemplaza el árbol completo por el recibido (formato de menus.json). Los ítems se
+	// reconocen por ID, por padre y nombre o por to_path, así conservan su ID y sus
+	// asignaciones a roles y usuarios aunque cambien de padre. Los que se crean de nuevo en
+	// lugar de uno borrado se informan en Recreated.
+	Import(ctx context.Context, items []dtos.MenuTreeItem) (*MenuImportResult, error)
 }
 
 type menuWriterService struct {
-	repo menu.MenuWriter
-	conn *connect.ConnectDTO
+	services.TransactionManager
+	repo       menu.MenuWriter
+	reader     menu.MenuReader
+	roleReader roleRepo.RoleReader
+	cache      MenuCache
+	conn       *connect.ConnectDTO
 }
 
-func NewMenuWriterService(repo menu.MenuWriter, conn *connect.ConnectDTO) MenuWriterService {
+func NewMenuWriterService(
+	repo menu.MenuWriter,
+	reader menu.MenuReader,
+	roleReader roleRepo.RoleReader,
+	cache MenuCache,
+	conn *connect.ConnectDTO,
+) MenuWriterService {
 	return &menuWriterService{
-		repo: repo,
-		conn: conn,
+		TransactionManager: services.NewTransactionManager(conn),
+		repo:               repo,
+		reader:             reader,
+		roleReader:         roleReader,
+		cache:              cache,
+		conn:               conn,
 	}
 }
 
@@ -30,10 +121,29 @@ func (s *menuWriterService) AddBulkUsers(
 	userIDs []uint64,
 ) error {
 
+	if err := s.checkUsers(ctx, menuIDs, userIDs); err != nil {
+		return err
+	}
+
 	// Uso el writer exacto como tu repository
 	db := s.conn.ConnectGormWrite
 
-	return s.repo.AddBulkUsers(ctx, db, menuIDs, userIDs)
+	return s.invalidate(ctx, s.repo.AddBulkUsers(ctx, db, menuIDs, userIDs))
+}
+
+func (s *menuWriterService) DenyBulkUsers(
+	ctx context.Context,
+	menuIDs []uint64,
+	userIDs []uint64,
+) error {
+
+	if err := s.checkUsers(ctx, menuIDs, userIDs); err != nil {
+		return err
+	}
+
+	db := s.conn.ConnectGormWrite
+
+	return s.invalidate(ctx, s.repo.DenyBulkUsers(ctx, db, menuIDs, userIDs))
 }
 
 func (s *menuWriterService) BulkRemoveUsers(
@@ -44,5 +154,506 @@ func (s *menuWriterService) BulkRemoveUsers(
 
 	db := s.conn.ConnectGormWrite
 
-	return s.repo.BulkRemoveUsers(ctx, db, menuIDs, userIDs)
+	return s.invalidate(ctx, s.repo.BulkRemoveUsers(ctx, db, menuIDs, userIDs))
+}
+
+func (s *menuWriterService) AddRoles(ctx context.Context, menuID uint64, roleIDs []uint64) error {
+	return s.BulkAddRoles(ctx, []uint64{menuID}, roleIDs)
+}
+
+func (s *menuWriterService) RemoveRoles(ctx context.Context, menuID uint64, roleIDs []uint64) error {
+	return s.BulkRemoveRoles(ctx, []uint64{menuID}, roleIDs)
+}
+
+func (s *menuWriterService) BulkAddRoles(
+	ctx context.Context,
+	menuIDs []uint64,
+	roleIDs []uint64,
+) error {
+
+	if err := s.checkRoles(ctx, menuIDs, roleIDs); err != nil {
+		return err
+	}
+
+	db := s.conn.ConnectGormWrite
+
+	return s.invalidate(ctx, s.repo.BulkAddRoles(ctx, db, menuIDs, roleIDs))
+}
+
+func (s *menuWriterService) BulkRemoveRoles(
+	ctx context.Context,
+	menuIDs []uint64,
+	roleIDs []uint64,
+) error {
+
+	db := s.conn.ConnectGormWrite
+
+	return s.invalidate(ctx, s.repo.BulkRemoveRoles(ctx, db, menuIDs, roleIDs))
+}
+
+// checkUsers valida que existan los menús y los usuarios antes de crear excepciones.
+func (s *menuWriterService) checkUsers(ctx context.Context, menuIDs, userIDs []uint64) error {
+	fieldErrors := make(map[string][]string)
+	if err := s.checkMenus(ctx, menuIDs, fieldErrors); err != nil {
+		return err
+	}
+	missing, err := s.roleReader.MissingUserIDs(ctx, s.conn.ConnectGormWrite, userIDs)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		fieldErrors["user_ids"] = append(fieldErrors["user_ids"], notFoundIDs("usuarios", missing))
+	}
+	if len(fieldErrors) > 0 {
+		return domain.NewValidationError(fieldErrors)
+	}
+	return nil
+}
+
+// checkRoles valida que existan los menús y los roles antes de asignarlos.
+func (s *menuWriterService) checkRoles(ctx context.Context, menuIDs, roleIDs []uint64) error {
+	fieldErrors := make(map[string][]string)
+	if err := s.checkMenus(ctx, menuIDs, fieldErrors); err != nil {
+		return err
+	}
+	missing, err := s.roleReader.MissingIDs(ctx, s.conn.ConnectGormWrite, roleIDs)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		fieldErrors["role_ids"] = append(fieldErrors["role_ids"], notFoundIDs("roles", missing))
+	}
+	if len(fieldErrors) > 0 {
+		return domain.NewValidationError(fieldErrors)
+	}
+	return nil
+}
+
+func (s *menuWriterService) checkMenus(ctx context.Context, menuIDs []uint64, fieldErrors map[string][]string) error {
+	missing, err := s.reader.MissingIDs(ctx, s.conn.ConnectGormWrite, menuIDs)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		fieldErrors["menu_ids"] = append(fieldErrors["menu_ids"], notFoundIDs("menús", missing))
+	}
+	return nil
+}
+
+func notFoundIDs(resource string, ids []uint64) string {
+	return fmt.Sprintf("No existen los %s: %s.", resource, strings.Trim(fmt.Sprint(ids), "[]"))
+}
+
+// ─────────────────────────────────────────────
+// CRUD DE ÍTEMS
+// ─────────────────────────────────────────────
+
+func (s *menuWriterService) Create(ctx context.Context, data CreateMenuDTO) (*models.Menu, error) {
+	db := s.conn.ConnectGormWrite
+
+	fieldErrors := make(map[string][]string)
+	validateMenuFields(data.ItemType, data.ItemName, data.ToPath, data.Icon, "", fieldErrors)
+	if len(fieldErrors) > 0 {
+		return nil, domain.NewValidationError(fieldErrors)
+	}
+
+	parentID, err := s.checkParent(ctx, db, data.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(data.ItemName)
+	if err := s.checkSiblingName(ctx, db, parentID, name, 0); err != nil {
+		return nil, err
+	}
+
+	item := &models.Menu{
+		ItemType: data.ItemType,
+		ItemName: name,
+		ToPath:   data.ToPath,
+		Icon:     data.Icon,
+		ParentID: parentID,
+		IsActive: true,
+	}
+	if data.OrderIndex != nil {
+		item.OrderIndex = *data.OrderIndex
+	} else if item.OrderIndex, err = s.nextOrderIndex(ctx, db, parentID); err != nil {
+		return nil, err
+	}
+
+	if err := s.invalidate(ctx, s.repo.Create(ctx, db, item)); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *menuWriterService) Update(ctx context.Context, id uint64, data UpdateMenuDTO) (*models.Menu, error) {
+	db := s.conn.ConnectGormWrite
+
+	item, err := s.getMenu(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldErrors := make(map[string][]string)
+	validateMenuFields(data.ItemType, data.ItemName, data.ToPath, data.Icon, "", fieldErrors)
+	if len(fieldErrors) > 0 {
+		return nil, domain.NewValidationError(fieldErrors)
+	}
+	name := strings.TrimSpace(data.ItemName)
+	if err := s.checkSiblingName(ctx, db, item.ParentID, name, item.ID); err != nil {
+		return nil, err
+	}
+
+	item.ItemType = data.ItemType
+	item.ItemName = name
+	item.ToPath = data.ToPath
+	item.Icon = data.Icon
+	item.IsActive = data.IsActive
+
+	if err := s.invalidate(ctx, s.repo.Update(ctx, db, item)); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *menuWriterService) SoftDelete(ctx context.Context, id uint64) error {
+	if _, err := s.getMenu(ctx, s.conn.ConnectGormWrite, id); err != nil {
+		return err
+	}
+	err := s.ExecuteTx(ctx, func(tx *gorm.DB) error {
+		all, err := s.reader.GetAll(ctx, tx, false)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		return s.repo.SetDeletedAt(ctx, tx, subtreeIDs(all, uint(id)), &now)
+	})
+	return s.invalidate(ctx, err)
+}
+
+func (s *menuWriterService) Restore(ctx context.Context, id uint64) (*models.Menu, error) {
+	db := s.conn.ConnectGormWrite
+
+	item, err := s.reader.GetDeletedByID(ctx, db, uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if item.ParentID != nil {
+		if _, err := s.getMenu(ctx, db, uint64(*item.ParentID)); errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.NewValidationError(map[string][]string{"parent_id": {"El menú padre está borrado; restáurelo primero."}})
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.ExecuteTx(ctx, func(tx *gorm.DB) error {
+		all, err := s.reader.GetAll(ctx, tx, true)
+		if err != nil {
+			return err
+		}
+		// Solo los descendientes borrados en la misma operación (mismo deleted_at).
+		var deletedTogether []models.Menu
+		for _, m := range all {
+			if m.DeletedAt.Valid && m.DeletedAt.Time.Equal(item.DeletedAt.Time) {
+				deletedTogether = append(deletedTogether, m)
+			}
+		}
+		return s.repo.SetDeletedAt(ctx, tx, subtreeIDs(deletedTogether, item.ID), nil)
+	})
+	if err := s.invalidate(ctx, err); err != nil {
+		return nil, err
+	}
+	return s.getMenu(ctx, db, id)
+}
+
+// ─────────────────────────────────────────────
+// MOVER / REORDENAR
+// ─────────────────────────────────────────────
+
+// menuPosition es el nuevo padre y orden de un ítem.
+type menuPosition struct {
+	id         uint
+	parentID   *uint
+	orderIndex int
+}
+
+func (s *menuWriterService) Move(ctx context.Context, parentID *uint64, items []MenuNode) error {
+	err := s.ExecuteTx(ctx, func(tx *gorm.DB) error {
+		all, err := s.reader.GetAll(ctx, tx, true)
+		if err != nil {
+			return err
+		}
+		byID := make(map[uint]models.Menu, len(all))
+		var active []models.Menu
+		for _, m := range all {
+			byID[m.ID] = m
+			if !m.DeletedAt.Valid {
+				active = append(active, m)
+			}
+		}
+
+		fieldErrors := make(map[string][]string)
+
+		// 1️⃣ Los ítems existen y no se repiten
+		moved := make(map[uint]bool)
+		var collect func(nodes []MenuNode)
+		collect = func(nodes []MenuNode) {
+			for _, n := range nodes {
+				id := uint(n.ID)
+				if m, ok := byID[id]; !ok || m.DeletedAt.Valid {
+					fieldErrors["items"] = append(fieldErrors["items"], fmt.Sprintf("El menú %d no existe.", n.ID))
+				} else if moved[id] {
+					fieldErrors["items"] = append(fieldErrors["items"], fmt.Sprintf("El menú %d aparece más de una vez.", n.ID))
+				}
+				moved[id] = true
+				collect(n.Children)
+			}
+		}
+		collect(items)
+
+		// 2️⃣ El destino existe y no está dentro de una rama que se mueve
+		var target *uint
+		if parentID != nil {
+			id := uint(*parentID)
+			target = &id
+			if m, ok := byID[id]; !ok || m.DeletedAt.Valid {
+				fieldErrors["parent_id"] = append(fieldErrors["parent_id"], "El menú padre no existe.")
+			} else {
+				for cur := target; cur != nil; cur = byID[*cur].ParentID {
+					if moved[*cur] {
+						fieldErrors["parent_id"] = append(fieldErrors["parent_id"], "No se puede mover un ítem dentro de su propia rama.")
+						break
+					}
+				}
+			}
+		}
+		if len(fieldErrors) > 0 {
+			return domain.NewValidationError(fieldErrors)
+		}
+
+		// 3️⃣ Nuevas posiciones: primero los ítems recibidos, en ese orden, y después los
+		// hermanos que no se mencionaron, en su orden actual.
+		children := childrenIndex(active)
+		var positions []menuPosition
+		var assign func(parent *uint, nodes []MenuNode)
+		assign = func(parent *uint, nodes []MenuNode) {
+			order := 0
+			for _, n := range nodes {
+				order += orderStep
+				positions = append(positions, menuPosition{id: uint(n.ID), parentID: parent, orderIndex: order})
+			}
+			for _, m := range children[parentKey(parent)] {
+				if !moved[m.ID] {
+					order += orderStep
+					positions = append(positions, menuPosition{id: m.ID, parentID: parent, orderIndex: order})
+				}
+			}
+			for _, n := range nodes {
+				if len(n.Children) > 0 {
+					id := uint(n.ID)
+					assign(&id, n.Children)
+				}
+			}
+		}
+		assign(target, items)
+
+		// 4️⃣ Nombres únicos bajo cada padre en el resultado (el índice cuenta los borrados)
+		finalParent := make(map[uint]*uint, len(all))
+		for _, m := range all {
+			finalParent[m.ID] = m.ParentID
+		}
+		for _, p := range positions {
+			finalParent[p.id] = p.parentID
+		}
+		names := make(map[string]uint)
+		for _, m := range all {
+			parent := finalParent[m.ID]
+			if parent == nil {
+				continue
+			}
+			key := fmt.Sprintf("%d/%s", *parent, nameKey(m.ItemName))
+			if other, ok := names[key]; ok && (moved[m.ID] || moved[other]) {
+				fieldErrors["items"] = append(fieldErrors["items"], fmt.Sprintf("El nombre '%s' se repetiría bajo el menú %d.", m.ItemName, *parent))
+			}
+			names[key] = m.ID
+		}
+		if len(fieldErrors) > 0 {
+			return domain.NewValidationError(fieldErrors)
+		}
+
+		// 5️⃣ Guardar solo lo que cambió
+		for _, p := range positions {
+			current := byID[p.id]
+			if parentKey(current.ParentID) == parentKey(p.parentID) && current.OrderIndex == p.orderIndex {
+				continue
+			}
+			if err := s.repo.UpdatePosition(ctx, tx, p.id, p.parentID, p.orderIndex); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return s.invalidate(ctx, err)
+}
+
+// ─────────────────────────────────────────────
+// IMPORTAR ÁRBOL
+// ─────────────────────────────────────────────
+
+func (s *menuWriterService) Import(ctx context.Context, items []dtos.MenuTreeItem) (*MenuImportResult, error) {
+	if len(items) == 0 {
+		return nil, domain.NewValidationError(map[string][]string{"items": {"El árbol de menús no puede estar vacío."}})
+	}
+	fieldErrors := make(map[string][]string)
+	validateTree(items, "", true, fieldErrors)
+	validateTreeIDs(items, "", make(map[uint]bool), fieldErrors)
+	if len(fieldErrors) > 0 {
+		return nil, domain.NewValidationError(fieldErrors)
+	}
+
+	result := &MenuImportResult{Recreated: []MenuImportRecreated{}}
+	err := s.ExecuteTx(ctx, func(tx *gorm.DB) error {
+		all, err := s.reader.GetAll(ctx, tx, true)
+		if err != nil {
+			return err
+		}
+		matcher := newMenuMatcher(all, items)
+
+		kept := make(map[uint]bool)
+		var created []models.Menu
+		var walk func(parent *uint, nodes []dtos.MenuTreeItem) error
+		walk = func(parent *uint, nodes []dtos.MenuTreeItem) error {
+			for _, n := range nodes {
+				item, matched := matcher.match(parent, n)
+				if other, ok := matcher.occupant(parent, n.ItemName); ok {
+					return domain.NewValidationError(map[string][]string{"items": {fmt.Sprintf(
+						"El ítem %q (ID %d) ya ocupa ese nombre en el destino. Renómbrelo o muévalo en una importación previa.",
+						other.ItemName, other.ID)}})
+				}
+				item.ParentID = parent
+				item.ItemType = n.ItemType
+				item.ItemName = strings.TrimSpace(n.ItemName)
+				item.ToPath = n.ToPath
+				item.Icon = n.Icon
+				item.OrderIndex = n.OrderIndex
+				item.IsActive = n.Active()
+				item.DeletedAt = gorm.DeletedAt{}
+
+				if matched {
+					if err := s.repo.Update(ctx, tx, &item); err != nil {
+						return err
+					}
+					result.Updated++
+				} else {
+					if err := s.repo.Create(ctx, tx, &item); err != nil {
+						return err
+					}
+					result.Created++
+					created = append(created, item)
+				}
+				kept[item.ID] = true
+
+				id := item.ID
+				if err := walk(&id, n.Children); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err := walk(nil, items); err != nil {
+			return err
+		}
+
+		// Lo que no vino en el archivo se borra lógicamente.
+		var stale []models.Menu
+		var staleIDs []uint
+		for _, m := range all {
+			if !kept[m.ID] && !m.DeletedAt.Valid {
+				stale = append(stale, m)
+				staleIDs = append(staleIDs, m.ID)
+			}
+		}
+		if len(stale) == 0 {
+			return nil
+		}
+		result.Recreated = recreated(created, stale)
+		now := time.Now()
+		result.Deleted = len(stale)
+		return s.repo.SetDeletedAt(ctx, tx, staleIDs, &now)
+	})
+	if err := s.invalidate(ctx, err); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ─────────────────────────────────────────────
+// HELPERS
+// ─────────────────────────────────────────────
+
+// invalidate descarta los árboles cacheados si la operación se completó.
+func (s *menuWriterService) invalidate(ctx context.Context, err error) error {
+	if err == nil {
+		s.cache.Invalidate(ctx)
+	}
+	return err
+}
+
+func (s *menuWriterService) getMenu(ctx context.Context, db *gorm.DB, id uint64) (*models.Menu, error) {
+	item, err := s.reader.GetByID(ctx, db, uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	return item, err
+}
+
+// checkParent valida que el padre exista y lo devuelve en el tipo del modelo.
+func (s *menuWriterService) checkParent(ctx context.Context, db *gorm.DB, parentID *uint64) (*uint, error) {
+	if parentID == nil {
+		return nil, nil
+	}
+	if _, err := s.getMenu(ctx, db, *parentID); errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.NewValidationError(map[string][]string{"parent_id": {"El menú padre no existe."}})
+	} else if err != nil {
+		return nil, err
+	}
+	id := uint(*parentID)
+	return &id, nil
+}
+
+// checkSiblingName respeta el índice único (parent_id, lower(item_name)), que también
+// cuenta los ítems borrados. En la raíz (parent_id NULL) el índice no aplica.
+func (s *menuWriterService) checkSiblingName(ctx context.Context, db *gorm.DB, parentID *uint, name string, excludeID uint) error {
+	if parentID == nil {
+		return nil
+	}
+	sibling, err := s.reader.GetSiblingByName(ctx, db, parentID, name, excludeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if sibling.DeletedAt.Valid {
+		return domain.NewValidationError(map[string][]string{"item_name": {fmt.Sprintf("Ya existe un ítem borrado con ese nombre en el mismo nivel (ID %d); puede restaurarlo.", sibling.ID)}})
+	}
+	return domain.NewValidationError(map[string][]string{"item_name": {"Ya existe un ítem con ese nombre en el mismo nivel."}})
+}
+
+// nextOrderIndex ubica un ítem nuevo al final de su nivel.
+func (s *menuWriterService) nextOrderIndex(ctx context.Context, db *gorm.DB, parentID *uint) (int, error) {
+	all, err := s.reader.GetAll(ctx, db, false)
+	if err != nil {
+		return 0, err
+	}
+	last := 0
+	for _, m := range all {
+		if parentKey(m.ParentID) == parentKey(parentID) && m.OrderIndex > last {
+			last = m.OrderIndex
+		}
+	}
+	return last + orderStep, nil
 }
@@ -0,0 +1,225 @@
+package pagination
+
+import (
+	"fmt"
+	"go-fiber-core/internal/dtos"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Operaciones admitidas en dtos.Aggregate.Op.
+const (
+	AggCount         = "count"
+	AggCountDistinct = "count_distinct"
+	AggSum           = "sum"
+	AggAvg           = "avg"
+	AggMin           = "min"
+	AggMax           = "max"
+	AggGroupCount    = "group_count"
+)
+
+const (
+	maxAggregates        = 20
+	defaultGroupLimit    = 50
+	maxGroupLimit        = 500
+	aggregateErrorPrefix = "aggregates"
+)
+
+// aggregateKey valida los nombres de las claves en Extras.
+var aggregateKey = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,62}$`)
+
+// GroupCount es un elemento del resultado de group_count.
+type GroupCount struct {
+	Value any   `json:"value"`
+	Count int64 `json:"count"`
+}
+
+// aggregateName devuelve la clave del agregado en Extras.
+func aggregateName(agg dtos.Aggregate) string {
+	if agg.As != "" {
+		return agg.As
+	}
+	if agg.Field == "" {
+		return agg.Op
+	}
+	return agg.Op + "_" + strings.NewReplacer(".", "_", "::", "_").Replace(agg.Field)
+}
+
+// validateAggregates acumula en fieldErrors los problemas de los agregados pedidos.
+// Se admiten las columnas filtrables; sum y avg solo sobre columnas numéricas.
+func validateAggregates(ms *ModelSchema, aggregates []dtos.Aggregate, fieldErrors map[string][]string) {
+	if len(aggregates) > maxAggregates {
+		fieldErrors[aggregateErrorPrefix] = append(fieldErrors[aggregateErrorPrefix], fmt.Sprintf("Se admiten hasta %d agregados.", maxAggregates))
+		return
+	}
+	names := make(map[string]bool, len(aggregates))
+	for _, agg := range aggregates {
+		name := aggregateName(agg)
+		key := aggregateErrorPrefix + "." + name
+		if !aggregateKey.MatchString(name) {
+			fieldErrors[key] = append(fieldErrors[key], "El alias solo admite letras, números y guiones bajos.")
+			continue
+		}
+		if names[name] {
+			fieldErrors[key] = append(fieldErrors[key], "El alias está repetido.")
+			continue
+		}
+		names[name] = true
+		if msg := checkAggregate(ms, agg); msg != "" {
+			fieldErrors[key] = append(fieldErrors[key], msg)
+		}
+	}
+}
+
+func checkAggregate(ms *ModelSchema, agg dtos.Aggregate) string {
+	switch agg.Op {
+	case AggCount:
+		if agg.Field == "" {
+			return ""
+		}
+	case AggCountDistinct, AggMin, AggMax, AggGroupCount, AggSum, AggAvg:
+		if agg.Field == "" {
+			return "El agregado requiere un campo."
+		}
+	default:
+		return fmt.Sprintf("Agregado '%s' no soportado.", agg.Op)
+	}
+
+	spec, ok := ms.Resolve(agg.Field)
+	if !ok || !spec.Filterable || strings.Contains(agg.Field, ":fuzzy") {
+		return "El campo no está habilitado para agregados."
+	}
+	if (agg.Op == AggSum || agg.Op == AggAvg) && spec.Kind != FieldNumber {
+		return "El agregado solo se admite en campos numéricos."
+	}
+	if agg.Op == AggGroupCount && (agg.Limit < 0 || agg.Limit > maxGroupLimit) {
+		return fmt.Sprintf("El límite de grupos debe estar entre 1 y %d.", maxGroupLimit)
+	}
+	return ""
+}
+
+// computeAggregates calcula los agregados sobre la consulta ya filtrada.
+// Los escalares se resuelven en una única consulta; cada group_count en una consulta propia.
+func (p *PaginationService[T]) computeAggregates(query *gorm.DB, aggregates []dtos.Aggregate) (map[string]any, error) {
+	ms, err := p.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]any, len(aggregates))
+	var selects []string
+	var scalarNames []string
+	var scalarNumeric []bool
+
+	for _, agg := range aggregates {
+		name := aggregateName(agg)
+		if agg.Op == AggGroupCount {
+			groups, err := groupCount(query, ms, agg)
+			if err != nil {
+				return nil, err
+			}
+			result[name] = groups
+			continue
+		}
+
+		var expr string
+		numeric := true
+		if agg.Field == "" {
+			expr = "COUNT(*)"
+		} else {
+			spec, _ := ms.Resolve(agg.Field)
+			if agg.Op == AggMin || agg.Op == AggMax {
+				numeric = spec.Kind == FieldNumber
+			}
+			switch agg.Op {
+			case AggCount:
+				expr = "COUNT(" + spec.Column + ")"
+			case AggCountDistinct:
+				expr = "COUNT(DISTINCT " + spec.Column + ")"
+			default:
+				expr = strings.ToUpper(agg.Op) + "(" + spec.Column + ")"
+			}
+		}
+		selects = append(selects, fmt.Sprintf("%s AS agg_%d", expr, len(scalarNames)))
+		scalarNames = append(scalarNames, name)
+		scalarNumeric = append(scalarNumeric, numeric)
+	}
+
+	if len(selects) > 0 {
+		row := map[string]any{}
+		tx := withoutPreloadsAndOrder(query.Session(&gorm.Session{}).Select(strings.Join(selects, ", ")))
+		if err := tx.Take(&row).Error; err != nil {
+			return nil, err
+		}
+		for i, name := range scalarNames {
+			result[name] = normalizeAggregate(row[fmt.Sprintf("agg_%d", i)], scalarNumeric[i])
+		}
+	}
+	return result, nil
+}
+
+// groupCount devuelve los valores más frecuentes de una columna con su cantidad.
+func groupCount(query *gorm.DB, ms *ModelSchema, agg dtos.Aggregate) ([]GroupCount, error) {
+	spec, _ := ms.Resolve(agg.Field)
+	limit := agg.Limit
+	if limit == 0 {
+		limit = defaultGroupLimit
+	}
+
+	var rows []map[string]any
+	tx := withoutPreloadsAndOrder(query.Session(&gorm.Session{}).
+		Select(spec.Column + " AS group_value, COUNT(*) AS group_count")).
+		Group(spec.Column).
+		Order("group_count DESC").
+		Limit(limit)
+	if err := tx.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	groups := make([]GroupCount, len(rows))
+	for i, row := range rows {
+		count, _ := normalizeAggregate(row["group_count"], true).(int64)
+		groups[i] = GroupCount{Value: normalizeAggregate(row["group_value"], spec.Kind == FieldNumber), Count: count}
+	}
+	return groups, nil
+}
+
+// withoutPreloadsAndOrder quita los Preloads y el ORDER BY que pudo agregar el modifier:
+// no aplican a una consulta de agregados (y Postgres rechaza ORDER BY sin GROUP BY).
+func withoutPreloadsAndOrder(tx *gorm.DB) *gorm.DB {
+	tx.Statement.Preloads = nil
+	delete(tx.Statement.Clauses, "ORDER BY")
+	return tx
+}
+
+// normalizeAggregate unifica los tipos que devuelven los drivers (ej: NUMERIC como texto o []byte).
+// Solo convierte texto a número si el resultado es numérico (para no alterar códigos como "007").
+func normalizeAggregate(value any, numeric bool) any {
+	switch v := value.(type) {
+	case []byte:
+		return normalizeAggregate(string(v), numeric)
+	case string:
+		if !numeric {
+			return v
+		}
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+		return v
+	case int32:
+		return int64(v)
+	case int:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float32:
+		return float64(v)
+	}
+	return value
+}
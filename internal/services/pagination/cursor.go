@@ -0,0 +1,347 @@
+package pagination
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Modos de paginación admitidos en dtos.PaginationRequest.Mode.
+const (
+	ModeOffset = "offset"
+	ModeCursor = "cursor"
+)
+
+const (
+	cursorNext = "next"
+	cursorPrev = "prev"
+)
+
+var errInvalidCursor = errors.New("cursor inválido")
+
+// cursorPayload es el contenido firmado del cursor. Keys ata el cursor al ordenamiento
+// con el que se generó, para que no se pueda reutilizar con otro SortBy.
+type cursorPayload struct {
+	Keys      []string `json:"k"`
+	Values    []any    `json:"v"`
+	Direction string   `json:"d"`
+}
+
+// keysetColumn es una columna del ordenamiento usada para el "seek method".
+type keysetColumn struct {
+	spec   FieldSpec
+	column clause.Column
+	desc   bool
+}
+
+// IsCursorMode indica si el request pide paginación por cursor.
+func IsCursorMode(req dtos.PaginationRequest) bool {
+	return req.Mode == ModeCursor || req.Cursor != ""
+}
+
+// WithCursorSecret configura la clave HMAC con la que se firman los cursores.
+// Sin clave, el modo cursor devuelve un error interno.
+func (p *PaginationService[T]) WithCursorSecret(secret []byte) *PaginationService[T] {
+	p.cursorSecret = secret
+	return p
+}
+
+// validateCursorMode acumula en fieldErrors los problemas propios del modo cursor.
+func validateCursorMode(ms *ModelSchema, req dtos.PaginationRequest, fieldErrors map[string][]string) {
+	if req.Mode != "" && req.Mode != ModeOffset && req.Mode != ModeCursor {
+		fieldErrors["mode"] = append(fieldErrors["mode"], "El modo debe ser 'offset' o 'cursor'.")
+		return
+	}
+	if !IsCursorMode(req) {
+		return
+	}
+	if ms.PrimaryKey == "" {
+		fieldErrors["mode"] = append(fieldErrors["mode"], "El modo cursor requiere que el modelo tenga clave primaria.")
+	}
+	if req.RowsPerPage <= 0 {
+		fieldErrors["rowsPerPage"] = append(fieldErrors["rowsPerPage"], "El modo cursor requiere un tamaño de página mayor a cero.")
+	}
+	for _, sortBy := range req.SortBy {
+		spec, ok := ms.Resolve(sortBy)
+		if !ok || !spec.Sortable {
+			continue // ya lo reporta validateSort
+		}
+		if !spec.own || strings.HasSuffix(sortBy, ":fuzzy") {
+			fieldErrors[sortBy] = append(fieldErrors[sortBy], "En modo cursor solo se puede ordenar por columnas propias del modelo.")
+			continue
+		}
+		// El seek compara con < y >, que nunca son verdaderos contra NULL: las filas con
+		// NULL se saltearían o repetirían entre páginas.
+		if spec.Nullable {
+			fieldErrors[sortBy] = append(fieldErrors[sortBy], "En modo cursor no se puede ordenar por columnas que admiten valores nulos.")
+		}
+	}
+}
+
+// keysetColumns arma el ordenamiento del modo cursor: las columnas de SortBy y,
+// como desempate, la clave primaria (si no fue incluida).
+func keysetColumns(ms *ModelSchema, req dtos.PaginationRequest) []keysetColumn {
+	var cols []keysetColumn
+	hasPK := false
+	for i, sortBy := range req.SortBy {
+		spec, _ := ms.Resolve(sortBy)
+		desc := i < len(req.SortDesc) && req.SortDesc[i]
+		cols = append(cols, keysetColumn{
+			spec:   spec,
+			column: clause.Column{Table: ms.Table, Name: spec.field.DBName},
+			desc:   desc,
+		})
+		if spec.field.DBName == ms.PrimaryKey {
+			hasPK = true
+		}
+	}
+	if !hasPK {
+		pk := ms.gormSchema.PrioritizedPrimaryField
+		cols = append(cols, keysetColumn{
+			spec:   FieldSpec{Key: pk.DBName, Column: pk.DBName, field: pk, own: true},
+			column: clause.Column{Table: ms.Table, Name: pk.DBName},
+		})
+	}
+	return cols
+}
+
+// cursorKeys es la firma del ordenamiento que se guarda en el cursor (ej: ["name:asc", "id:asc"]).
+func cursorKeys(cols []keysetColumn) []string {
+	keys := make([]string, len(cols))
+	for i, col := range cols {
+		dir := "asc"
+		if col.desc {
+			dir = "desc"
+		}
+		keys[i] = col.column.Name + ":" + dir
+	}
+	return keys
+}
+
+// executeCursor pagina por keyset. No ejecuta COUNT(*): TotalRows y TotalPages quedan en cero.
+// validateCursorMode rechaza las columnas que admiten NULL, que romperían el seek.
+func (p *PaginationService[T]) executeCursor(db *gorm.DB, req dtos.PaginationRequest, modifier QueryModifier, extrasCalc ExtrasCalculator, selection *Selection) (*dtos.PaginationResponse[T], error) {
+	if len(p.cursorSecret) == 0 {
+		return nil, fmt.Errorf("%w: no se configuró la clave de firma de cursores", domain.ErrInternal)
+	}
+	ms, err := p.Schema()
+	if err != nil {
+		return nil, err
+	}
+	cols := keysetColumns(ms, req)
+	keys := cursorKeys(cols)
+
+	// Las columnas del ordenamiento se leen de las filas para armar el cursor,
+	// así que se seleccionan aunque no estén en "fields".
+	for _, col := range cols {
+		selection.ensureColumn(col.column.Name)
+	}
+	modifier = selection.Modifier(modifier)
+
+	query := db.Model(new(T))
+	if modifier != nil {
+		query = modifier(query)
+	}
+	query = p.ApplyFilters(query, req)
+
+	extras, err := p.buildExtras(query, req, extrasCalc)
+	if err != nil {
+		return nil, err
+	}
+
+	direction := cursorNext
+	if req.Cursor != "" {
+		payload, err := p.decodeCursor(req.Cursor, keys)
+		if err != nil {
+			return nil, domain.NewValidationError(map[string][]string{
+				"cursor": {"El cursor no es válido o no corresponde al ordenamiento solicitado."},
+			})
+		}
+		direction = payload.Direction
+		values := make([]any, len(cols))
+		for i, col := range cols {
+			if values[i], err = col.spec.cursorValue(payload.Values[i]); err != nil {
+				return nil, domain.NewValidationError(map[string][]string{
+					"cursor": {"El cursor no es válido o no corresponde al ordenamiento solicitado."},
+				})
+			}
+		}
+		sql, args := keysetCondition(query.Statement, cols, values, direction == cursorPrev)
+		query = query.Where(sql, args...)
+	}
+
+	// Hacia atrás se invierte el orden y luego se invierten los resultados.
+	for _, col := range cols {
+		query = query.Order(clause.OrderByColumn{Column: col.column, Desc: col.desc != (direction == cursorPrev)})
+	}
+
+	var data []T
+	if err := query.Limit(req.RowsPerPage + 1).Find(&data).Error; err != nil {
+		return nil, err
+	}
+	hasMore := len(data) > req.RowsPerPage
+	if hasMore {
+		data = data[:req.RowsPerPage]
+	}
+	if direction == cursorPrev {
+		for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
+	}
+
+	if extras, err = p.addHighlights(db, req, data, extras); err != nil {
+		return nil, err
+	}
+
+	response := &dtos.PaginationResponse[T]{Data: data, RowsPerPage: req.RowsPerPage, Extras: extras, Fields: selection.ResponseKeys()}
+	if len(data) == 0 {
+		response.Data = []T{}
+		return response, nil
+	}
+
+	ctx := query.Statement.Context
+	hasNext := (direction == cursorNext && hasMore) || direction == cursorPrev
+	hasPrev := (direction == cursorPrev && hasMore) || (direction == cursorNext && req.Cursor != "")
+	if hasNext {
+		if response.NextCursor, err = p.encodeCursor(ctx, cols, keys, data[len(data)-1], cursorNext); err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		if response.PrevCursor, err = p.encodeCursor(ctx, cols, keys, data[0], cursorPrev); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// keysetCondition arma la condición del "seek method" para columnas con direcciones mixtas:
+// (a > ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?)
+func keysetCondition(stmt *gorm.Statement, cols []keysetColumn, values []any, backward bool) (string, []any) {
+	var ors []string
+	var args []any
+	for i, col := range cols {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, stmt.Quote(cols[j].column)+" = ?")
+			args = append(args, values[j])
+		}
+		op := ">"
+		if col.desc != backward {
+			op = "<"
+		}
+		ands = append(ands, stmt.Quote(col.column)+" "+op+" ?")
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return strings.Join(ors, " OR "), args
+}
+
+// encodeCursor firma con HMAC-SHA256 los valores de las columnas de ordenamiento de la fila.
+// Formato: base64url(payload) + "." + base64url(firma).
+func (p *PaginationService[T]) encodeCursor(ctx context.Context, cols []keysetColumn, keys []string, row T, direction string) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rv := reflect.Indirect(reflect.ValueOf(&row))
+	values := make([]any, len(cols))
+	for i, col := range cols {
+		values[i], _ = col.spec.field.ValueOf(ctx, rv)
+	}
+	raw, err := json.Marshal(cursorPayload{Keys: keys, Values: values, Direction: direction})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw) + "." + base64.RawURLEncoding.EncodeToString(p.signCursor(raw)), nil
+}
+
+// decodeCursor verifica la firma del cursor y que corresponda al ordenamiento actual.
+func (p *PaginationService[T]) decodeCursor(cursor string, keys []string) (*cursorPayload, error) {
+	encoded, signature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, errInvalidCursor
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, p.signCursor(raw)) {
+		return nil, errInvalidCursor
+	}
+
+	var payload cursorPayload
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, errInvalidCursor
+	}
+	if payload.Direction != cursorNext && payload.Direction != cursorPrev {
+		return nil, errInvalidCursor
+	}
+	if len(payload.Keys) != len(keys) || len(payload.Values) != len(keys) {
+		return nil, errInvalidCursor
+	}
+	for i := range keys {
+		if payload.Keys[i] != keys[i] {
+			return nil, errInvalidCursor
+		}
+	}
+	return &payload, nil
+}
+
+func (p *PaginationService[T]) signCursor(raw []byte) []byte {
+	mac := hmac.New(sha256.New, p.cursorSecret)
+	mac.Write(raw)
+	return mac.Sum(nil)
+}
+
+// cursorValue convierte un valor del cursor (JSON) al tipo de la columna.
+func (spec FieldSpec) cursorValue(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch spec.field.DataType {
+	case schema.Time:
+		str, ok := value.(string)
+		if !ok {
+			return nil, errInvalidCursor
+		}
+		return time.Parse(time.RFC3339Nano, str)
+	case schema.Int, schema.Uint:
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, errInvalidCursor
+		}
+		return number.Int64()
+	case schema.Float:
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, errInvalidCursor
+		}
+		return number.Float64()
+	case schema.Bool:
+		if _, ok := value.(bool); !ok {
+			return nil, errInvalidCursor
+		}
+	case schema.String:
+		if _, ok := value.(string); !ok {
+			return nil, errInvalidCursor
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,319 @@
+package pagination
+
+import (
+	"fmt"
+	"go-fiber-core/internal/dtos"
+	"strings"
+	"time"
+)
+
+// Operadores admitidos en dtos.FilterCondition.Op.
+const (
+	OpEq         = "eq"
+	OpNe         = "ne"
+	OpGt         = "gt"
+	OpGte        = "gte"
+	OpLt         = "lt"
+	OpLte        = "lte"
+	OpBetween    = "between"
+	OpIn         = "in"
+	OpNotIn      = "not_in"
+	OpIsNull     = "is_null"
+	OpNotNull    = "not_null"
+	OpStartsWith = "starts_with"
+	OpContains   = "contains"
+)
+
+const (
+	logicAnd = "and"
+	logicOr  = "or"
+
+	// Límites para evitar consultas abusivas desde el frontend.
+	maxFilterDepth      = 4
+	maxFilterConditions = 50
+)
+
+// validateFilterGroup acumula en fieldErrors los problemas del formato con operadores.
+// Las claves de error son el nombre del campo, igual que en el formato de arrays paralelos.
+func validateFilterGroup(ms *ModelSchema, group *dtos.FilterGroup, fieldErrors map[string][]string) {
+	if group == nil {
+		return
+	}
+	total := 0
+	var walk func(g dtos.FilterGroup, depth int)
+	walk = func(g dtos.FilterGroup, depth int) {
+		if depth > maxFilterDepth {
+			fieldErrors["filters"] = appendOnce(fieldErrors["filters"], fmt.Sprintf("Los grupos de filtros admiten hasta %d niveles.", maxFilterDepth))
+			return
+		}
+		if logic := strings.ToLower(g.Logic); logic != "" && logic != logicAnd && logic != logicOr {
+			fieldErrors["filters"] = appendOnce(fieldErrors["filters"], "La lógica del grupo debe ser 'and' u 'or'.")
+		}
+		for _, cond := range g.Conditions {
+			total++
+			if msg := checkCondition(ms, cond); msg != "" {
+				fieldErrors[cond.Field] = append(fieldErrors[cond.Field], msg)
+			}
+		}
+		for _, sub := range g.Groups {
+			walk(sub, depth+1)
+		}
+	}
+	walk(*group, 1)
+	if total > maxFilterConditions {
+		fieldErrors["filters"] = appendOnce(fieldErrors["filters"], fmt.Sprintf("Se admiten hasta %d condiciones.", maxFilterConditions))
+	}
+}
+
+// checkCondition valida una condición: columna permitida, operador conocido y valor acorde al tipo.
+func checkCondition(ms *ModelSchema, cond dtos.FilterCondition) string {
+	if strings.Contains(cond.Field, ":fuzzy") {
+		return "La búsqueda aproximada solo está disponible en el formato filterBy/filterValues."
+	}
+	spec, ok := ms.Resolve(cond.Field)
+	if !ok || !spec.Filterable {
+		return "El campo no está habilitado para filtrar."
+	}
+
+	switch cond.Op {
+	case OpIsNull, OpNotNull:
+		return ""
+
+	case OpEq, OpNe:
+		if cond.Value == nil {
+			return "El operador requiere un valor."
+		}
+		return spec.checkOperand(cond.Value)
+
+	case OpGt, OpGte, OpLt, OpLte:
+		if spec.Kind == FieldBool || spec.Kind == FieldString {
+			return "El operador no es compatible con el tipo del campo."
+		}
+		if cond.Value == nil {
+			return "El operador requiere un valor."
+		}
+		return spec.checkOperand(cond.Value)
+
+	case OpBetween:
+		if spec.Kind == FieldBool || spec.Kind == FieldString {
+			return "El operador no es compatible con el tipo del campo."
+		}
+		values, ok := cond.Value.([]any)
+		if !ok || len(values) != 2 {
+			return "El operador 'between' requiere exactamente dos valores (desde, hasta)."
+		}
+		for _, v := range values {
+			if msg := spec.checkOperand(v); msg != "" {
+				return msg
+			}
+		}
+		return ""
+
+	case OpIn, OpNotIn:
+		values, ok := cond.Value.([]any)
+		if !ok || len(values) == 0 {
+			return "El operador requiere una lista de valores."
+		}
+		for _, v := range values {
+			if msg := spec.checkOperand(v); msg != "" {
+				return msg
+			}
+		}
+		return ""
+
+	case OpStartsWith, OpContains:
+		if spec.Kind != FieldString {
+			return "El operador solo se admite en campos de texto."
+		}
+		if str, ok := cond.Value.(string); !ok || str == "" {
+			return "El valor debe ser un texto."
+		}
+		return ""
+	}
+	return fmt.Sprintf("Operador '%s' no soportado.", cond.Op)
+}
+
+// checkOperand valida un valor individual de una condición.
+// A diferencia del formato legado, las fechas se aceptan como AAAA-MM-DD o RFC 3339.
+func (spec FieldSpec) checkOperand(value any) string {
+	if spec.Kind == FieldDate {
+		if _, _, ok := parseDateOperand(value); !ok {
+			return "La fecha debe tener el formato AAAA-MM-DD o RFC 3339."
+		}
+		return ""
+	}
+	return spec.checkScalar(value)
+}
+
+// parseDateOperand interpreta una fecha. dayOnly indica que se envió sin hora,
+// en cuyo caso las comparaciones abarcan el día completo.
+func parseDateOperand(value any) (t time.Time, dayOnly bool, ok bool) {
+	str, isString := value.(string)
+	if !isString {
+		return time.Time{}, false, false
+	}
+	if t, err := time.Parse(dateLayout, str); err == nil {
+		return t, true, true
+	}
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return t, false, true
+	}
+	return time.Time{}, false, false
+}
+
+// filterBuilder traduce un dtos.FilterGroup (ya validado) a SQL parametrizado.
+type filterBuilder struct {
+	ms       *ModelSchema
+	postgres bool
+}
+
+// build devuelve la expresión del grupo y sus argumentos. Si el grupo no tiene condiciones, devuelve "".
+func (b filterBuilder) build(group dtos.FilterGroup) (string, []any) {
+	joiner := " AND "
+	if strings.ToLower(group.Logic) == logicOr {
+		joiner = " OR "
+	}
+
+	var parts []string
+	var args []any
+	for _, cond := range group.Conditions {
+		sql, condArgs := b.condition(cond)
+		parts = append(parts, sql)
+		args = append(args, condArgs...)
+	}
+	for _, sub := range group.Groups {
+		sql, subArgs := b.build(sub)
+		if sql == "" {
+			continue
+		}
+		parts = append(parts, "("+sql+")")
+		args = append(args, subArgs...)
+	}
+	if len(parts) == 0 {
+		return "", nil
+	}
+	return strings.Join(parts, joiner), args
+}
+
+// condition traduce una condición individual.
+func (b filterBuilder) condition(cond dtos.FilterCondition) (string, []any) {
+	spec, _ := b.ms.Resolve(cond.Field)
+	column := spec.Column
+
+	switch cond.Op {
+	case OpIsNull:
+		return column + " IS NULL", nil
+	case OpNotNull:
+		return column + " IS NOT NULL", nil
+	case OpStartsWith:
+		return b.like(column, escapeLike(cond.Value.(string))+"%")
+	case OpContains:
+		return b.like(column, "%"+escapeLike(cond.Value.(string))+"%")
+	case OpIn:
+		return column + " IN ?", []any{spec.operands(cond.Value.([]any))}
+	case OpNotIn:
+		return column + " NOT IN ?", []any{spec.operands(cond.Value.([]any))}
+	}
+
+	if spec.Kind == FieldDate {
+		return dateCondition(column, cond)
+	}
+
+	switch cond.Op {
+	case OpEq:
+		return column + " = ?", []any{spec.operand(cond.Value)}
+	case OpNe:
+		return column + " <> ?", []any{spec.operand(cond.Value)}
+	case OpGt:
+		return column + " > ?", []any{spec.operand(cond.Value)}
+	case OpGte:
+		return column + " >= ?", []any{spec.operand(cond.Value)}
+	case OpLt:
+		return column + " < ?", []any{spec.operand(cond.Value)}
+	case OpLte:
+		return column + " <= ?", []any{spec.operand(cond.Value)}
+	case OpBetween:
+		values := cond.Value.([]any)
+		return column + " BETWEEN ? AND ?", []any{spec.operand(values[0]), spec.operand(values[1])}
+	}
+	return "1 = 0", nil
+}
+
+// dateCondition compara fechas. Cuando el valor es AAAA-MM-DD, el operador abarca el día completo
+// (ej: lte "2025-01-31" incluye todo el 31), igual que el rango del formato legado.
+func dateCondition(column string, cond dtos.FilterCondition) (string, []any) {
+	if cond.Op == OpBetween {
+		values := cond.Value.([]any)
+		start, _, _ := parseDateOperand(values[0])
+		end, dayOnly, _ := parseDateOperand(values[1])
+		if dayOnly {
+			return column + " >= ? AND " + column + " < ?", []any{start, end.Add(24 * time.Hour)}
+		}
+		return column + " BETWEEN ? AND ?", []any{start, end}
+	}
+
+	t, dayOnly, _ := parseDateOperand(cond.Value)
+	if !dayOnly {
+		ops := map[string]string{OpEq: "=", OpNe: "<>", OpGt: ">", OpGte: ">=", OpLt: "<", OpLte: "<="}
+		return column + " " + ops[cond.Op] + " ?", []any{t}
+	}
+	next := t.Add(24 * time.Hour)
+	switch cond.Op {
+	case OpEq:
+		return "(" + column + " >= ? AND " + column + " < ?)", []any{t, next}
+	case OpNe:
+		return "(" + column + " < ? OR " + column + " >= ?)", []any{t, next}
+	case OpGt:
+		return column + " >= ?", []any{next}
+	case OpGte:
+		return column + " >= ?", []any{t}
+	case OpLt:
+		return column + " < ?", []any{t}
+	case OpLte:
+		return column + " < ?", []any{next}
+	}
+	return "1 = 0", nil
+}
+
+// like arma la comparación de texto sin distinguir mayúsculas en Postgres y MySQL.
+func (b filterBuilder) like(column, pattern string) (string, []any) {
+	if b.postgres {
+		return column + " ILIKE ?", []any{pattern}
+	}
+	return "LOWER(" + column + ") LIKE LOWER(?)", []any{pattern}
+}
+
+// operand normaliza un valor según el tipo de la columna.
+func (spec FieldSpec) operand(value any) any {
+	switch spec.Kind {
+	case FieldNumber:
+		return normalizeNumber(value)
+	case FieldDate:
+		t, _, _ := parseDateOperand(value)
+		return t
+	}
+	return value
+}
+
+func (spec FieldSpec) operands(values []any) []any {
+	normalized := make([]any, len(values))
+	for i, v := range values {
+		normalized[i] = spec.operand(v)
+	}
+	return normalized
+}
+
+// escapeLike escapa los comodines de LIKE para que el valor del usuario se busque literalmente.
+// Postgres y MySQL usan '\' como carácter de escape por defecto.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func appendOnce(messages []string, msg string) []string {
+	for _, m := range messages {
+		if m == msg {
+			return messages
+		}
+	}
+	return append(messages, msg)
+}
@@ -0,0 +1,44 @@
+package pagination
+
+import "go-fiber-core/internal/dtos"
+
+// MergeRequest combina una solicitud guardada (ej: una vista) con los parámetros enviados
+// en el momento. Los filtros se suman (AND); el orden, las columnas, la búsqueda y el
+// tamaño de página del override reemplazan a los de base solo si se enviaron.
+// La página, el modo y el cursor siempre son los del override.
+func MergeRequest(base, override dtos.PaginationRequest) dtos.PaginationRequest {
+	merged := override
+
+	merged.FilterBy = append(append([]string{}, base.FilterBy...), override.FilterBy...)
+	merged.FilterValues = append(append([]any{}, base.FilterValues...), override.FilterValues...)
+	switch {
+	case base.Filters != nil && override.Filters != nil:
+		merged.Filters = &dtos.FilterGroup{Groups: []dtos.FilterGroup{*base.Filters, *override.Filters}}
+	case base.Filters != nil:
+		merged.Filters = base.Filters
+	}
+
+	if len(override.SortBy) == 0 {
+		merged.SortBy, merged.SortDesc = base.SortBy, base.SortDesc
+	}
+	if override.RowsPerPage == 0 {
+		merged.RowsPerPage = base.RowsPerPage
+	}
+	if len(override.Fields) == 0 {
+		merged.Fields = base.Fields
+	}
+	if len(override.Include) == 0 {
+		merged.Include = base.Include
+	}
+	// Highlight acompaña a la búsqueda: si se hereda la búsqueda, también se hereda Highlight.
+	if override.Search == "" {
+		merged.Search, merged.Highlight = base.Search, base.Highlight
+	}
+	if len(override.Aggregates) == 0 {
+		merged.Aggregates = base.Aggregates
+	}
+	if merged.Page == 0 && merged.RowsPerPage > 0 {
+		merged.Page = 1
+	}
+	return merged
+}
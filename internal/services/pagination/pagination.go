@@ -1,12 +1,15 @@
 package pagination
 
 import (
+	"errors"
 	"fmt"
+	"go-fiber-core/internal/domain"
 	"go-fiber-core/internal/dtos"
 	"log"
 	"math"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -20,19 +23,81 @@ type QueryModifier func(*gorm.DB) *gorm.DB
 type ExtrasCalculator func(query *gorm.DB) (map[string]any, error)
 
 // PaginationService es ahora una struct genérica.
-type PaginationService[T any] struct{}
+// Cachea la lista blanca de columnas (ModelSchema) del modelo T.
+type PaginationService[T any] struct {
+	schemaOnce sync.Once
+	schema     *ModelSchema
+	schemaErr  error
+
+	cursorSecret []byte // Firma de los cursores del modo keyset (ver WithCursorSecret)
+}
 
 // NewPaginationService es el constructor genérico.
 func NewPaginationService[T any]() *PaginationService[T] {
 	return &PaginationService[T]{}
 }
 
+// Schema devuelve la lista blanca de columnas filtrables y ordenables del modelo T,
+// construida a partir de los tags `filter` y `sort`.
+func (p *PaginationService[T]) Schema() (*ModelSchema, error) {
+	p.schemaOnce.Do(func() {
+		p.schema, p.schemaErr = ParseModelSchema(new(T))
+	})
+	return p.schema, p.schemaErr
+}
+
+// Validate rechaza cualquier columna de FilterBy/SortBy que no esté declarada en el modelo,
+// y cualquier valor que no coincida con el tipo de la columna.
+// Devuelve un *domain.ValidationError (que envuelve a domain.ErrInvalidArgument).
+func (p *PaginationService[T]) Validate(req dtos.PaginationRequest) error {
+	ms, err := p.Schema()
+	if err != nil {
+		return err
+	}
+	fieldErrors := make(map[string][]string)
+	validateFilters(ms, req, fieldErrors)
+	validateFilterGroup(ms, req.Filters, fieldErrors)
+	validateSort(ms, req, fieldErrors)
+	validateSearch(ms, req, fieldErrors)
+	validateCursorMode(ms, req, fieldErrors)
+	validateAggregates(ms, req.Aggregates, fieldErrors)
+	var selectionErr *domain.ValidationError
+	if _, err := ParseSelection[T](req.Fields, req.Include); errors.As(err, &selectionErr) {
+		for field, messages := range selectionErr.Fields {
+			fieldErrors[field] = append(fieldErrors[field], messages...)
+		}
+	} else if err != nil {
+		return err
+	}
+	if req.OptimizeWithKey != "" && req.OptimizeWithKey != ms.PrimaryKey {
+		fieldErrors["optimize_with_key"] = append(fieldErrors["optimize_with_key"], "Solo se admite la clave primaria del modelo.")
+	}
+	if len(fieldErrors) > 0 {
+		return domain.NewValidationError(fieldErrors)
+	}
+	return nil
+}
+
 // Execute es el método genérico principal para paginar resultados.
 func (p *PaginationService[T]) Execute(db *gorm.DB, req dtos.PaginationRequest, modifier QueryModifier, extrasCalc ExtrasCalculator) (*dtos.PaginationResponse[T], error) {
 	var totalRows int64
-	var extras map[string]any
 	var modelInstance T
-	dateColumns := p.GetDateColumnsFromModel(modelInstance)
+
+	if err := p.Validate(req); err != nil {
+		return nil, err
+	}
+
+	// fields/include se aplican como un modifier más, después del del repositorio
+	selection, err := ParseSelection[T](req.Fields, req.Include)
+	if err != nil {
+		return nil, err
+	}
+
+	// Modo cursor: keyset sobre SortBy, sin COUNT(*)
+	if IsCursorMode(req) {
+		return p.executeCursor(db, req, modifier, extrasCalc, selection)
+	}
+	modifier = selection.Modifier(modifier)
 
 	query := db.Model(new(T))
 
@@ -42,16 +107,12 @@ func (p *PaginationService[T]) Execute(db *gorm.DB, req dtos.PaginationRequest,
 	}
 
 	// 2. Se aplican los filtros del request
-	query = p.ApplyFilters(query, req, dateColumns)
+	query = p.ApplyFilters(query, req)
 
 	// 3. Se calculan los extras (SUM, COUNT, etc.) sobre la consulta ya filtrada
-	if extrasCalc != nil {
-		var err error
-		extrasQuery := query.Session(&gorm.Session{})
-		extras, err = extrasCalc(extrasQuery)
-		if err != nil {
-			return nil, err
-		}
+	extras, err := p.buildExtras(query, req, extrasCalc)
+	if err != nil {
+		return nil, err
 	}
 
 	// 4. Se cuenta el total de filas (con los mismos filtros)
@@ -69,7 +130,7 @@ func (p *PaginationService[T]) Execute(db *gorm.DB, req dtos.PaginationRequest,
 	}
 
 	if totalRows == 0 {
-		return &dtos.PaginationResponse[T]{Data: []T{}, TotalRows: 0, TotalPages: 0, Page: 1, RowsPerPage: req.RowsPerPage, Extras: extras}, nil
+		return &dtos.PaginationResponse[T]{Data: []T{}, TotalRows: 0, TotalPages: 0, Page: 1, RowsPerPage: req.RowsPerPage, Extras: extras, Fields: selection.ResponseKeys()}, nil
 	}
 
 	// 5. Se decide qué estrategia de paginación usar
@@ -90,19 +151,51 @@ func (p *PaginationService[T]) Execute(db *gorm.DB, req dtos.PaginationRequest,
 		return nil, err
 	}
 
-	return &dtos.PaginationResponse[T]{Data: data, TotalRows: totalRows, TotalPages: totalPages, Page: req.Page, RowsPerPage: req.RowsPerPage, Extras: extras}, nil
+	// 7. Fragmentos resaltados de la búsqueda de texto
+	if extras, err = p.addHighlights(db, req, data, extras); err != nil {
+		return nil, err
+	}
+
+	return &dtos.PaginationResponse[T]{Data: data, TotalRows: totalRows, TotalPages: totalPages, Page: req.Page, RowsPerPage: req.RowsPerPage, Extras: extras, Fields: selection.ResponseKeys()}, nil
+}
+
+// buildExtras combina el resultado del ExtrasCalculator del repositorio con los
+// agregados pedidos por el frontend (req.Aggregates). Ambos usan la consulta filtrada.
+func (p *PaginationService[T]) buildExtras(query *gorm.DB, req dtos.PaginationRequest, extrasCalc ExtrasCalculator) (map[string]any, error) {
+	var extras map[string]any
+	if extrasCalc != nil {
+		var err error
+		extras, err = extrasCalc(query.Session(&gorm.Session{}))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(req.Aggregates) > 0 {
+		aggregates, err := p.computeAggregates(query, req.Aggregates)
+		if err != nil {
+			return nil, err
+		}
+		if extras == nil {
+			extras = make(map[string]any, len(aggregates))
+		}
+		for name, value := range aggregates {
+			extras[name] = value
+		}
+	}
+	return extras, nil
 }
 
 // GetAllFiltered obtiene TODOS los registros que coinciden con los filtros, sin paginación.
 // (Añadido para exportaciones o sumatorias en Go)
 func (p *PaginationService[T]) GetAllFiltered(db *gorm.DB, req dtos.PaginationRequest, modifier QueryModifier) ([]T, error) {
-	var modelInstance T
-	dateColumns := p.GetDateColumnsFromModel(modelInstance)
+	if err := p.Validate(req); err != nil {
+		return nil, err
+	}
 	query := db.Model(new(T))
 	if modifier != nil {
 		query = modifier(query)
 	}
-	query = p.ApplyFilters(query, req, dateColumns)
+	query = p.ApplyFilters(query, req)
 	query = p.applySorting(query, req) // Usa la lógica de ordenamiento refactorizada
 	var data []T
 	if err := query.Find(&data).Error; err != nil {
@@ -112,197 +205,158 @@ func (p *PaginationService[T]) GetAllFiltered(db *gorm.DB, req dtos.PaginationRe
 }
 
 // ApplyFilters aplica los filtros de la solicitud a la consulta de GORM.
-// (Sin cambios)
-func (p *PaginationService[T]) ApplyFilters(db *gorm.DB, req dtos.PaginationRequest, dateColumns map[string]bool) *gorm.DB {
-	if len(req.FilterBy) == 0 {
+// Las columnas se resuelven contra la lista blanca del modelo; si alguna no está
+// declarada, el error de validación queda registrado en la consulta (db.Error).
+// El formato de arrays paralelos (FilterBy/FilterValues) y el de operadores (Filters)
+// se combinan con AND.
+func (p *PaginationService[T]) ApplyFilters(db *gorm.DB, req dtos.PaginationRequest) *gorm.DB {
+	if len(req.FilterBy) == 0 && req.Filters == nil && req.Search == "" {
 		return db
 	}
+	ms, err := p.Schema()
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+	fieldErrors := make(map[string][]string)
+	validateFilters(ms, req, fieldErrors)
+	validateFilterGroup(ms, req.Filters, fieldErrors)
+	validateSearch(ms, req, fieldErrors)
+	if len(fieldErrors) > 0 {
+		_ = db.AddError(domain.NewValidationError(fieldErrors))
+		return db
+	}
+
+	db = applySearch(db, ms, req.Search)
+
+	if req.Filters != nil {
+		builder := filterBuilder{ms: ms, postgres: db.Name() == "postgres"}
+		if sql, args := builder.build(*req.Filters); sql != "" {
+			db = db.Where(sql, args...)
+		}
+	}
+
 	for i, filterBy := range req.FilterBy {
 		if i >= len(req.FilterValues) {
 			continue
 		}
 		filterValue := req.FilterValues[i]
+		spec, _ := ms.Resolve(filterBy)
+		column := spec.Column
 
-		if strings.Contains(filterBy, "::") {
-			parts := strings.SplitN(filterBy, "::", 2)
-			finalColumnName := parts[0] + "." + parts[1]
-			if values, ok := filterValue.([]any); ok {
-				db = db.Where(finalColumnName+" IN ?", values)
+		if strings.HasSuffix(filterBy, ":fuzzy") {
+			if db.Name() == "postgres" {
+				db = db.Where(column+" % ?", filterValue)
 			} else {
-				db = db.Where(finalColumnName+" = ?", filterValue)
+				if str, ok := filterValue.(string); ok {
+					db = db.Where("LOWER("+column+") LIKE LOWER(?)", "%"+str+"%")
+				}
 			}
 			continue
 		}
 
-		if strings.Contains(filterBy, ".") && !strings.HasSuffix(filterBy, ":fuzzy") {
-			lastDotIndex := strings.LastIndex(filterBy, ".")
-			joinPath, finalFieldName := filterBy[:lastDotIndex], filterBy[lastDotIndex+1:]
-			relations := strings.Split(joinPath, ".")
-
-			var currentAlias string
-			for _, rel := range relations {
-				currentAlias = rel
-			}
-
-			gormRelations := map[string]bool{
-				"profile": true,
-				"offer":   true,
-				"address": true,
-			}
+		if filterValue == nil {
+			continue
+		}
 
-			var finalColumnName string
-
-			if gormRelations[strings.ToLower(currentAlias)] {
-				currentAlias = strings.ToUpper(currentAlias[:1]) + strings.ToLower(currentAlias[1:])
-				finalColumnName = `"` + currentAlias + `".` + finalFieldName
-			} else {
-				finalColumnName = currentAlias + "." + finalFieldName
-			}
+		switch spec.Kind {
+		case FieldDate:
+			values := filterValue.([]any)
+			start, _ := time.Parse(dateLayout, values[0].(string))
+			end, _ := time.Parse(dateLayout, values[1].(string))
+			endOfDay := end.Add(24 * time.Hour)
+			db = db.Where(column+" >= ? AND "+column+" < ?", start, endOfDay)
 
+		case FieldNumber:
 			if values, ok := filterValue.([]any); ok {
-				db = db.Where(finalColumnName+" IN ?", values)
-			} else if val, ok := filterValue.(bool); ok {
-				db = db.Where(finalColumnName+" = ?", val)
-			} else if str, ok := filterValue.(string); ok {
-				if p.isLike(str) {
-					if db.Name() == "postgres" {
-						db = db.Where(finalColumnName+" ILIKE ?", str)
-					} else {
-						db = db.Where("LOWER("+finalColumnName+") LIKE LOWER(?)", str)
-					}
-				} else {
-					db = db.Where("LOWER("+finalColumnName+") = LOWER(?)", str)
-				}
-			} else if filterValue != nil {
-				db = db.Where(finalColumnName+" = ?", filterValue)
+				db = db.Where(column+" IN ?", normalizeNumbers(values))
+			} else {
+				db = db.Where(column+" = ?", normalizeNumber(filterValue))
 			}
 
-			continue
-		}
+		case FieldBool:
+			db = db.Where(column+" = ?", filterValue)
 
-		if _, isDate := dateColumns[filterBy]; isDate {
-			if values, ok := filterValue.([]any); ok && len(values) == 2 {
-				startStr, ok1 := values[0].(string)
-				endStr, ok2 := values[1].(string)
-				if ok1 && ok2 {
-					start, err1 := time.Parse("2006-01-02", startStr)
-					end, err2 := time.Parse("2006-01-02", endStr)
-					if err1 == nil && err2 == nil {
-						endOfDay := end.Add(24 * time.Hour)
-						db = db.Where(filterBy+" >= ? AND "+filterBy+" < ?", start, endOfDay)
-						continue
-					}
-				}
-			}
-		}
-		if strings.HasSuffix(filterBy, ":fuzzy") {
-			columnName := strings.TrimSuffix(filterBy, ":fuzzy")
-			if db.Name() == "postgres" {
-				db = db.Where(columnName+" % ?", filterValue)
-			} else {
-				if str, ok := filterValue.(string); ok {
-					db = db.Where("LOWER("+columnName+") LIKE LOWER(?)", "%"+str+"%")
-				}
+		case FieldString:
+			if values, ok := filterValue.([]any); ok {
+				db = db.Where(column+" IN ?", values)
+				continue
 			}
-			continue
-		}
-
-		if values, ok := filterValue.([]any); ok {
-			db = db.Where(filterBy+" IN ?", values)
-		} else if val, ok := filterValue.(bool); ok {
-			db = db.Where(filterBy+" = ?", val)
-		} else if str, ok := filterValue.(string); ok {
-			if p.isLike(str) {
+			str := filterValue.(string)
+			if strings.Contains(filterBy, "::") {
+				// Las tablas unidas por el modifier comparan de forma exacta (comportamiento histórico).
+				db = db.Where(column+" = ?", str)
+			} else if p.isLike(str) {
 				if db.Name() == "postgres" {
-					db = db.Where(filterBy+" ILIKE ?", str)
+					db = db.Where(column+" ILIKE ?", str)
 				} else {
-					db = db.Where("LOWER("+filterBy+") LIKE LOWER(?)", str)
+					db = db.Where("LOWER("+column+") LIKE LOWER(?)", str)
 				}
 			} else {
-				db = db.Where("LOWER("+filterBy+") = LOWER(?)", str)
+				db = db.Where("LOWER("+column+") = LOWER(?)", str)
 			}
-		} else if filterValue != nil {
-			db = db.Where(filterBy+" = ?", filterValue)
 		}
 	}
 	return db
 }
 
 // ApplyOrder aplica el ordenamiento a la consulta.
-// (Sin cambios)
+// Solo admite columnas declaradas como `sort:"true"` en el modelo.
 func (p *PaginationService[T]) ApplyOrder(db *gorm.DB, req dtos.PaginationRequest) *gorm.DB {
-	if len(req.SortBy) > 0 {
-		for i, sortBy := range req.SortBy {
-			order := "ASC"
-			if i < len(req.SortDesc) && req.SortDesc[i] {
-				order = "DESC"
-			}
+	if len(req.SortBy) == 0 {
+		return db
+	}
+	ms, err := p.Schema()
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+	fieldErrors := make(map[string][]string)
+	if validateSort(ms, req, fieldErrors); len(fieldErrors) > 0 {
+		_ = db.AddError(domain.NewValidationError(fieldErrors))
+		return db
+	}
 
-			if strings.Contains(sortBy, "::") {
-				db = db.Order(strings.Replace(sortBy, "::", ".", 1) + " " + order)
-				continue
-			}
+	for i, sortBy := range req.SortBy {
+		order := "ASC"
+		if i < len(req.SortDesc) && req.SortDesc[i] {
+			order = "DESC"
+		}
+		spec, _ := ms.Resolve(sortBy)
 
-			if strings.HasSuffix(sortBy, ":fuzzy") {
-				columnName := strings.TrimSuffix(sortBy, ":fuzzy")
-				if db.Name() == "postgres" {
-					var matchingValue any
-					for j, filterBy := range req.FilterBy {
-						if filterBy == sortBy {
-							if j < len(req.FilterValues) {
-								matchingValue = req.FilterValues[j]
-								break
-							}
+		if strings.HasSuffix(sortBy, ":fuzzy") {
+			if db.Name() == "postgres" {
+				var matchingValue any
+				for j, filterBy := range req.FilterBy {
+					if filterBy == sortBy {
+						if j < len(req.FilterValues) {
+							matchingValue = req.FilterValues[j]
+							break
 						}
 					}
-					if matchingValue != nil {
-						db = db.Order(gorm.Expr(columnName+" <-> ? "+order, matchingValue))
-					}
-				} else {
-					db = db.Order(columnName + " " + order)
-				}
-				continue
-			}
-
-			if strings.Contains(sortBy, ".") {
-				lastDotIndex := strings.LastIndex(sortBy, ".")
-				joinPath, finalFieldName := sortBy[:lastDotIndex], sortBy[lastDotIndex+1:]
-				relations := strings.Split(joinPath, ".")
-
-				var currentAlias string
-				for _, rel := range relations {
-					currentAlias = rel
 				}
-
-				gormRelations := map[string]bool{
-					"profile": true,
-					"offer":   true,
-					"address": true,
-				}
-
-				if gormRelations[strings.ToLower(currentAlias)] {
-					currentAlias = strings.ToUpper(currentAlias[:1]) + strings.ToLower(currentAlias[1:])
-					db = db.Order(`"` + currentAlias + `".` + finalFieldName + " " + order)
-				} else {
-					db = db.Order(currentAlias + "." + finalFieldName + " " + order)
+				if matchingValue != nil {
+					db = db.Order(gorm.Expr(spec.Column+" <-> ? "+order, matchingValue))
 				}
 			} else {
-				db = db.Order(sortBy + " " + order)
+				db = db.Order(spec.Column + " " + order)
 			}
+			continue
 		}
+
+		db = db.Order(spec.Column + " " + order)
 	}
 	return db
 }
 
 // GetDateColumnsFromModel extrae las columnas de fecha a partir de los tags del modelo.
-// (Sin cambios)
 func (p *PaginationService[T]) GetDateColumnsFromModel(model any) map[string]bool {
 	dateColumns := make(map[string]bool)
 	modelType := reflect.Indirect(reflect.ValueOf(model)).Type()
 	namingStrategy := schema.NamingStrategy{}
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
-		if tag, ok := field.Tag.Lookup("filter"); ok && tag == "date" {
+		if tag, ok := field.Tag.Lookup(filterTag); ok && strings.SplitN(tag, ",", 2)[0] == string(FieldDate) {
 			columnName := namingStrategy.ColumnName("", field.Name)
 			dateColumns[columnName] = true
 		}
@@ -310,6 +364,57 @@ func (p *PaginationService[T]) GetDateColumnsFromModel(model any) map[string]boo
 	return dateColumns
 }
 
+// validateFilters acumula en fieldErrors los problemas de FilterBy/FilterValues.
+func validateFilters(ms *ModelSchema, req dtos.PaginationRequest, fieldErrors map[string][]string) {
+	for i, filterBy := range req.FilterBy {
+		spec, ok := ms.Resolve(filterBy)
+		switch {
+		case !ok || !spec.Filterable:
+			fieldErrors[filterBy] = append(fieldErrors[filterBy], "El campo no está habilitado para filtrar.")
+		case strings.HasSuffix(filterBy, ":fuzzy") && !spec.Fuzzy:
+			fieldErrors[filterBy] = append(fieldErrors[filterBy], "El campo no admite búsqueda aproximada.")
+		case i < len(req.FilterValues):
+			if msg := spec.checkValue(req.FilterValues[i]); msg != "" {
+				fieldErrors[filterBy] = append(fieldErrors[filterBy], msg)
+			}
+		}
+	}
+}
+
+// validateSort acumula en fieldErrors las columnas de SortBy no permitidas.
+func validateSort(ms *ModelSchema, req dtos.PaginationRequest, fieldErrors map[string][]string) {
+	for _, sortBy := range req.SortBy {
+		spec, ok := ms.Resolve(sortBy)
+		switch {
+		case !ok || !spec.Sortable:
+			fieldErrors[sortBy] = append(fieldErrors[sortBy], "El campo no está habilitado para ordenar.")
+		case strings.HasSuffix(sortBy, ":fuzzy") && !spec.Fuzzy:
+			fieldErrors[sortBy] = append(fieldErrors[sortBy], "El campo no admite búsqueda aproximada.")
+		}
+	}
+}
+
+// normalizeNumber convierte números de JSON a int64 cuando no tienen decimales,
+// para que el driver los envíe con el tipo correcto.
+func normalizeNumber(value any) any {
+	f, ok := toNumber(value)
+	if !ok {
+		return value
+	}
+	if f == math.Trunc(f) {
+		return int64(f)
+	}
+	return f
+}
+
+func normalizeNumbers(values []any) []any {
+	normalized := make([]any, len(values))
+	for i, v := range values {
+		normalized[i] = normalizeNumber(v)
+	}
+	return normalized
+}
+
 // applyLimitOffset es una función helper interna.
 // (Sin cambios)
 func (p *PaginationService[T]) applyLimitOffset(db *gorm.DB, req dtos.PaginationRequest) *gorm.DB {
@@ -325,21 +430,23 @@ func (p *PaginationService[T]) applyLimitOffset(db *gorm.DB, req dtos.Pagination
 func (p *PaginationService[T]) applySorting(db *gorm.DB, req dtos.PaginationRequest) *gorm.DB {
 	if len(req.SortBy) == 0 {
 		var model T
-		stmt := &gorm.Statement{DB: db}
+		ms, err := p.Schema()
+		if err != nil {
+			log.Printf("Advertencia: Falló el parseo del modelo para ordenamiento por defecto: %v", err)
+			return db
+		}
 
-		// Lógica mejorada para parsear el schema si no existe
-		if stmt.Schema == nil {
-			if err := stmt.Parse(db.Model(model)); err != nil {
-				log.Printf("Advertencia: Falló el parseo del modelo para ordenamiento por defecto: %v", err)
-				return db
-			}
+		var pkOrder string
+		if ms.PrimaryKey != "" {
+			pkOrder = fmt.Sprintf(`"%s"."%s" DESC`, ms.Table, ms.PrimaryKey)
 		}
 
-		if stmt.Schema != nil && stmt.Schema.PrioritizedPrimaryField != nil {
-			primaryKey := stmt.Schema.PrioritizedPrimaryField.DBName
-			tableName := stmt.Schema.Table
+		// Con búsqueda de texto se ordena por relevancia (la PK desempata)
+		if ranked, ok := applySearchRank(db, ms, req.Search, pkOrder); ok {
+			db = ranked
+		} else if pkOrder != "" {
 			// Aplica el orden DESC por defecto (tu solicitud)
-			db = db.Order(fmt.Sprintf(`"%s"."%s" DESC`, tableName, primaryKey))
+			db = db.Order(pkOrder)
 		} else {
 			log.Printf("Advertencia: No se pudo determinar la clave primaria para el ordenamiento por defecto del modelo %T", model)
 		}
@@ -362,12 +469,12 @@ func (p *PaginationService[T]) applyStandardPagination(db *gorm.DB, req dtos.Pag
 func (p *PaginationService[T]) applyDeferredJoinPagination(db *gorm.DB, query *gorm.DB, req dtos.PaginationRequest, model any, modifier QueryModifier) *gorm.DB {
 	tableName := query.Statement.Table
 	if tableName == "" {
-		stmt := &gorm.Statement{DB: query}
-		if err := stmt.Parse(model); err != nil {
+		ms, err := p.Schema()
+		if err != nil {
 			log.Printf("ERROR: Falló el parseo del modelo en paginación diferida: %v", err)
 			return query.Where("1 = 0")
 		}
-		tableName = stmt.Schema.Table
+		tableName = ms.Table
 	}
 	var pageIDs []uint
 
@@ -413,7 +520,10 @@ func (p *PaginationService[T]) GetFilteredBatch(
 ) ([]T, error) {
 
 	var modelInstance T
-	dateColumns := p.GetDateColumnsFromModel(modelInstance)
+
+	if err := p.Validate(req); err != nil {
+		return nil, err
+	}
 
 	query := db.Model(new(T))
 
@@ -423,14 +533,17 @@ func (p *PaginationService[T]) GetFilteredBatch(
 	}
 
 	// 2. Aplicar los mismos filtros complejos de la paginación
-	query = p.ApplyFilters(query, req, dateColumns)
+	query = p.ApplyFilters(query, req)
 
 	// 3. Obtener el nombre de la Clave Primaria (ej: "id")
 	//    (Usamos la misma lógica que 'applySorting' pero simplificada)
 	var pkColumn string
-	stmt := &gorm.Statement{DB: db}
-	if err := stmt.Parse(modelInstance); err == nil && stmt.Schema != nil && stmt.Schema.PrioritizedPrimaryField != nil {
-		pkColumn = stmt.Schema.PrioritizedPrimaryField.DBName
+	ms, err := p.Schema()
+	if err != nil {
+		return nil, err
+	}
+	if ms.PrimaryKey != "" {
+		pkColumn = ms.PrimaryKey
 	} else {
 		// Fallback o error si no hay PK, aunque 'id' es estándar
 		pkColumn = "id"
@@ -441,14 +554,12 @@ func (p *PaginationService[T]) GetFilteredBatch(
 	//    Esto es lo que te certificaron como una excelente práctica.
 	if lastProcessedID > 0 {
 		// Usamos el nombre de la tabla para desambiguar (ej: "products"."id")
-		tableName := stmt.Schema.Table
-		query = query.Where(fmt.Sprintf(`"%s"."%s" > ?`, tableName, pkColumn), lastProcessedID)
+		query = query.Where(fmt.Sprintf(`"%s"."%s" > ?`, ms.Table, pkColumn), lastProcessedID)
 	}
 
 	// 5. Aplicar orden y límite
 	// Es OBLIGATORIO ordenar por la PK para que el Keyset funcione.
-	tableName := stmt.Schema.Table
-	query = query.Order(fmt.Sprintf(`"%s"."%s" ASC`, tableName, pkColumn))
+	query = query.Order(fmt.Sprintf(`"%s"."%s" ASC`, ms.Table, pkColumn))
 	query = query.Limit(batchSize)
 
 	// 6. Ejecutar y devolver el lote
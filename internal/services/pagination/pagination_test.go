@@ -2,8 +2,11 @@ package pagination
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"go-fiber-core/internal/domain"
 	"go-fiber-core/internal/dtos"
+	"net/url"
 	"regexp"
 	"testing"
 	"time"
@@ -11,6 +14,7 @@ import (
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -19,19 +23,19 @@ import (
 type Profile struct {
 	ID     uint
 	UserID uint
-	Status string
+	Status string `filter:"string" sort:"true"`
 }
 
 type Bank struct {
 	ID         uint
 	Name       string
-	EntityCode string `gorm:"column:entity_code"`
+	EntityCode string `gorm:"column:entity_code" filter:"string" sort:"true"`
 }
 
 type Offer struct {
 	ID        uint
 	DateOffer time.Time `gorm:"column:date_offer"`
-	CodeOffer string    `gorm:"column:code_offer"`
+	CodeOffer string    `gorm:"column:code_offer" filter:"string"`
 }
 
 type Product struct {
@@ -56,12 +60,15 @@ func (Sale) TableName() string {
 }
 
 type User struct {
-	ID        uint
-	Name      string `gorm:"column:user_name"`
-	Email     string
-	IsActive  bool
-	CreatedAt time.Time `filter:"date"`
-	Profile   Profile
+	ID        uint   `filter:"number"`
+	Name      string `gorm:"column:user_name" filter:"string,fuzzy" sort:"true" search:"A"`
+	Email     string `filter:"string" search:"B"`
+	IsActive  bool   `filter:"bool"`
+	Password  string
+	CreatedAt time.Time `filter:"date" sort:"true"`
+	// EmailVerifiedAt admite NULL (como en models.User).
+	EmailVerifiedAt *time.Time `sort:"true"`
+	Profile         Profile
 }
 
 // --- Helper para configurar la DB de prueba ---
@@ -93,7 +100,6 @@ func TestGetDateColumnsFromModel(t *testing.T) {
 
 func TestApplyFilters(t *testing.T) {
 	service := NewPaginationService[User]()
-	dateColumns := service.GetDateColumnsFromModel(&User{})
 
 	t.Run("Filtro simple de igualdad (case-insensitive)", func(t *testing.T) {
 		db, mock := setupTestDB(t)
@@ -104,7 +110,7 @@ func TestApplyFilters(t *testing.T) {
 		mock.ExpectQuery(`SELECT .* FROM "users" WHERE LOWER\(user_name\) = LOWER\(\$1\)`).
 			WithArgs("test").
 			WillReturnRows(sqlmock.NewRows([]string{"id"}))
-		service.ApplyFilters(db.Model(&User{}), req, dateColumns).Find(&[]User{})
+		service.ApplyFilters(db.Model(&User{}), req).Find(&[]User{})
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -120,7 +126,7 @@ func TestApplyFilters(t *testing.T) {
 		mock.ExpectQuery(`SELECT .* FROM "users" WHERE created_at >= \$1 AND created_at < \$2`).
 			WithArgs(start, endOfDay).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}))
-		service.ApplyFilters(db.Model(&User{}), req, dateColumns).Find(&[]User{})
+		service.ApplyFilters(db.Model(&User{}), req).Find(&[]User{})
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -133,7 +139,7 @@ func TestApplyFilters(t *testing.T) {
 		mock.ExpectQuery(`SELECT .* FROM "users" WHERE user_name % \$1`).
 			WithArgs("test").
 			WillReturnRows(sqlmock.NewRows([]string{"id"}))
-		service.ApplyFilters(db.Model(&User{}), req, dateColumns).Find(&[]User{})
+		service.ApplyFilters(db.Model(&User{}), req).Find(&[]User{})
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -146,7 +152,7 @@ func TestApplyFilters(t *testing.T) {
 		mock.ExpectQuery(`SELECT .* FROM "users" WHERE email ILIKE \$1`).
 			WithArgs("%test.com%").
 			WillReturnRows(sqlmock.NewRows([]string{"id"}))
-		service.ApplyFilters(db.Model(&User{}), req, dateColumns).Find(&[]User{})
+		service.ApplyFilters(db.Model(&User{}), req).Find(&[]User{})
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -159,7 +165,7 @@ func TestApplyFilters(t *testing.T) {
 		mock.ExpectQuery(`SELECT .* FROM "users" WHERE id IN \(\$1,\$2,\$3\)`).
 			WithArgs(1, 2, 3).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}))
-		service.ApplyFilters(db.Model(&User{}), req, dateColumns).Find(&[]User{})
+		service.ApplyFilters(db.Model(&User{}), req).Find(&[]User{})
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -169,10 +175,10 @@ func TestApplyFilters(t *testing.T) {
 			FilterBy:     []string{"profile.status"},
 			FilterValues: []any{"active"},
 		}
-		mock.ExpectQuery(`SELECT .* FROM "users" LEFT JOIN "profiles" "Profile" ON "users"."id" = "Profile"."user_id" WHERE profiles.status = \$1`).
+		mock.ExpectQuery(`SELECT .* FROM "users" LEFT JOIN "profiles" "Profile" ON "users"."id" = "Profile"."user_id" WHERE LOWER\("Profile".status\) = LOWER\(\$1\)`).
 			WithArgs("active").
 			WillReturnRows(sqlmock.NewRows([]string{"id"}))
-		service.ApplyFilters(db.Model(&User{}), req, dateColumns).Find(&[]User{})
+		service.ApplyFilters(db.Model(&User{}).Joins("Profile"), req).Find(&[]User{})
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
@@ -210,9 +216,9 @@ func TestApplyOrder(t *testing.T) {
 			SortBy:   []string{"profile.status"},
 			SortDesc: []bool{false},
 		}
-		mock.ExpectQuery(`SELECT .* FROM "users" LEFT JOIN "profiles" "Profile" ON "users"."id" = "Profile"."user_id" ORDER BY profiles.status ASC`).
+		mock.ExpectQuery(`SELECT .* FROM "users" LEFT JOIN "profiles" "Profile" ON "users"."id" = "Profile"."user_id" ORDER BY "Profile".status ASC`).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}))
-		service.ApplyOrder(db.Model(&User{}), req).Find(&[]User{})
+		service.ApplyOrder(db.Model(&User{}).Joins("Profile"), req).Find(&[]User{})
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
@@ -226,7 +232,7 @@ func TestExecute(t *testing.T) {
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(22))
 
 		rows := sqlmock.NewRows([]string{"id", "user_name"}).AddRow(6, "User 6").AddRow(7, "User 7")
-		mock.ExpectQuery(`SELECT \* FROM "users" ORDER BY "users"\."id" ASC LIMIT \$1 OFFSET \$2`).
+		mock.ExpectQuery(`SELECT \* FROM "users" ORDER BY "users"\."id" DESC LIMIT \$1 OFFSET \$2`).
 			WithArgs(5, 5).
 			WillReturnRows(rows)
 
@@ -269,7 +275,7 @@ func TestExecute(t *testing.T) {
 
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT SUM(id) as total_sum FROM "users"`)).WillReturnRows(sqlmock.NewRows([]string{"total_sum"}).AddRow(55))
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
-		mock.ExpectQuery(`SELECT \* FROM "users" ORDER BY "users"\."id" ASC LIMIT \$1`).
+		mock.ExpectQuery(`SELECT \* FROM "users" ORDER BY "users"\."id" DESC LIMIT \$1`).
 			WithArgs(10).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
@@ -328,7 +334,7 @@ func TestExecute(t *testing.T) {
 		db, mock := setupTestDB(t)
 		req := dtos.PaginationRequest{Page: 10, RowsPerPage: 10, OptimizeWithKey: "id"}
 		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(150))
-		mock.ExpectQuery(`SELECT \* FROM "users" ORDER BY "users"\."id" ASC LIMIT \$1 OFFSET \$2`).
+		mock.ExpectQuery(`SELECT \* FROM "users" ORDER BY "users"\."id" DESC LIMIT \$1 OFFSET \$2`).
 			WithArgs(10, 90).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(91))
 		_, err := service.Execute(db, req, nil, nil)
@@ -336,19 +342,19 @@ func TestExecute(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("Deferred Join SÍ se activa en página 11", func(t *testing.T) {
+	t.Run("Deferred Join SÍ se activa en página 16", func(t *testing.T) {
 		service := NewPaginationService[User]()
 		db, mock := setupTestDB(t)
-		req := dtos.PaginationRequest{Page: 11, RowsPerPage: 10, OptimizeWithKey: "id"}
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(150))
+		req := dtos.PaginationRequest{Page: 16, RowsPerPage: 10, OptimizeWithKey: "id"}
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(200))
 
 		idRows := sqlmock.NewRows([]string{"id"}).AddRow(101).AddRow(102)
-		mock.ExpectQuery(`SELECT "id" FROM "users" ORDER BY "users"\."id" ASC LIMIT \$1 OFFSET \$2`).
-			WithArgs(10, 100).
+		mock.ExpectQuery(`SELECT "id" FROM "users" ORDER BY "users"\."id" DESC LIMIT \$1 OFFSET \$2`).
+			WithArgs(10, 150).
 			WillReturnRows(idRows)
 
 		dataRows := sqlmock.NewRows([]string{"id"}).AddRow(101).AddRow(102)
-		mock.ExpectQuery(`SELECT \* FROM "users" WHERE "users"\."id" IN \(\$1,\$2\) ORDER BY "users"\."id" ASC`).
+		mock.ExpectQuery(`SELECT \* FROM "users" WHERE "users"\."id" IN \(\$1,\$2\) ORDER BY "users"\."id" DESC`).
 			WithArgs(101, 102).
 			WillReturnRows(dataRows)
 		_, err := service.Execute(db, req, nil, nil)
@@ -356,3 +362,482 @@ func TestExecute(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestValidate(t *testing.T) {
+	service := NewPaginationService[User]()
+
+	t.Run("Columnas declaradas son aceptadas", func(t *testing.T) {
+		req := dtos.PaginationRequest{
+			FilterBy:     []string{"user_name:fuzzy", "is_active", "id", "profile.status", "users.email"},
+			FilterValues: []any{"ana", true, []any{float64(1), "2"}, "ok", "%@test.com%"},
+			SortBy:       []string{"created_at", "users.user_name:fuzzy"},
+		}
+		assert.NoError(t, service.Validate(req))
+	})
+
+	t.Run("Columna no declarada es rechazada con error por campo", func(t *testing.T) {
+		req := dtos.PaginationRequest{
+			FilterBy:     []string{"password"},
+			FilterValues: []any{"secreto"},
+			SortBy:       []string{"email", "1; DROP TABLE users"},
+		}
+		err := service.Validate(req)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Contains(t, validationErr.Fields, "password")
+		assert.Contains(t, validationErr.Fields, "email")
+		assert.Contains(t, validationErr.Fields, "1; DROP TABLE users")
+	})
+
+	t.Run("Valor incompatible con el tipo de la columna", func(t *testing.T) {
+		req := dtos.PaginationRequest{
+			FilterBy:     []string{"is_active", "created_at", "email:fuzzy"},
+			FilterValues: []any{"si", []any{"2025-01-01"}, "a"},
+		}
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, service.Validate(req), &validationErr)
+		assert.Len(t, validationErr.Fields, 3)
+	})
+
+	t.Run("OptimizeWithKey solo admite la clave primaria", func(t *testing.T) {
+		req := dtos.PaginationRequest{OptimizeWithKey: "id) OR (1=1"}
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, service.Validate(req), &validationErr)
+		assert.Contains(t, validationErr.Fields, "optimize_with_key")
+	})
+
+	t.Run("Execute no consulta la base si el request es inválido", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		req := dtos.PaginationRequest{Page: 1, RowsPerPage: 10, SortBy: []string{"password"}}
+		_, err := service.Execute(db, req, nil, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func setupMySQLTestDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return gormDB, mock
+}
+
+func TestApplyFiltersWithOperators(t *testing.T) {
+	service := NewPaginationService[User]()
+
+	t.Run("Grupo AND con comparación numérica e IN", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		req := dtos.PaginationRequest{Filters: &dtos.FilterGroup{
+			Conditions: []dtos.FilterCondition{
+				{Field: "id", Op: "gte", Value: float64(10)},
+				{Field: "email", Op: "not_in", Value: []any{"a@test.com", "b@test.com"}},
+				{Field: "email", Op: "not_null"},
+			},
+		}}
+		mock.ExpectQuery(`SELECT .* FROM "users" WHERE id >= \$1 AND email NOT IN \(\$2,\$3\) AND email IS NOT NULL`).
+			WithArgs(int64(10), "a@test.com", "b@test.com").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		require.NoError(t, service.ApplyFilters(db.Model(&User{}), req).Find(&[]User{}).Error)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Grupo OR anidado combinado con el formato legado", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		req := dtos.PaginationRequest{
+			FilterBy:     []string{"is_active"},
+			FilterValues: []any{true},
+			Filters: &dtos.FilterGroup{
+				Logic: "or",
+				Conditions: []dtos.FilterCondition{
+					{Field: "user_name", Op: "starts_with", Value: "50%_"},
+				},
+				Groups: []dtos.FilterGroup{{
+					Conditions: []dtos.FilterCondition{
+						{Field: "created_at", Op: "between", Value: []any{"2025-01-01", "2025-01-31"}},
+						{Field: "email", Op: "contains", Value: "test"},
+					},
+				}},
+			},
+		}
+		start, _ := time.Parse("2006-01-02", "2025-01-01")
+		end, _ := time.Parse("2006-01-02", "2025-02-01")
+		mock.ExpectQuery(`SELECT .* FROM "users" WHERE \(user_name ILIKE \$1 OR \(created_at >= \$2 AND created_at < \$3 AND email ILIKE \$4\)\) AND is_active = \$5`).
+			WithArgs(`50\%\_%`, start, end, "%test%", true).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		require.NoError(t, service.ApplyFilters(db.Model(&User{}), req).Find(&[]User{}).Error)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Fecha sin hora abarca el día completo", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		req := dtos.PaginationRequest{Filters: &dtos.FilterGroup{
+			Conditions: []dtos.FilterCondition{{Field: "created_at", Op: "lte", Value: "2025-01-31"}},
+		}}
+		end, _ := time.Parse("2006-01-02", "2025-02-01")
+		mock.ExpectQuery(`SELECT .* FROM "users" WHERE created_at < \$1`).
+			WithArgs(end).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		require.NoError(t, service.ApplyFilters(db.Model(&User{}), req).Find(&[]User{}).Error)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("MySQL usa LOWER LIKE", func(t *testing.T) {
+		db, mock := setupMySQLTestDB(t)
+		req := dtos.PaginationRequest{Filters: &dtos.FilterGroup{
+			Conditions: []dtos.FilterCondition{{Field: "user_name", Op: "contains", Value: "ana"}},
+		}}
+		mock.ExpectQuery("SELECT .* FROM `users` WHERE LOWER\\(user_name\\) LIKE LOWER\\(\\?\\)").
+			WithArgs("%ana%").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		require.NoError(t, service.ApplyFilters(db.Model(&User{}), req).Find(&[]User{}).Error)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Operadores inválidos se reportan por campo", func(t *testing.T) {
+		req := dtos.PaginationRequest{Filters: &dtos.FilterGroup{
+			Logic: "xor",
+			Conditions: []dtos.FilterCondition{
+				{Field: "is_active", Op: "gt", Value: true},
+				{Field: "id", Op: "contains", Value: "1"},
+				{Field: "email", Op: "like", Value: "x"},
+				{Field: "password", Op: "eq", Value: "x"},
+				{Field: "created_at", Op: "between", Value: []any{"2025-01-01"}},
+			},
+		}}
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, service.Validate(req), &validationErr)
+		for _, key := range []string{"filters", "is_active", "id", "email", "password", "created_at"} {
+			assert.Contains(t, validationErr.Fields, key)
+		}
+	})
+}
+
+func TestExecuteCursorMode(t *testing.T) {
+	service := NewPaginationService[User]().WithCursorSecret([]byte("secreto-de-prueba"))
+
+	t.Run("Primera página sin COUNT y con next_cursor", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		req := dtos.PaginationRequest{Mode: "cursor", RowsPerPage: 2, SortBy: []string{"user_name"}, SortDesc: []bool{true}}
+		rows := sqlmock.NewRows([]string{"id", "user_name"}).AddRow(9, "Zoe").AddRow(4, "Luis").AddRow(7, "Ana")
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY "users"."user_name" DESC,"users"."id" LIMIT $1`)).
+			WithArgs(3).
+			WillReturnRows(rows)
+
+		resp, err := service.Execute(db, req, nil, nil)
+		require.NoError(t, err)
+		assert.Len(t, resp.Data, 2)
+		assert.NotEmpty(t, resp.NextCursor)
+		assert.Empty(t, resp.PrevCursor)
+		assert.Zero(t, resp.TotalRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		t.Run("La página siguiente usa el seek sobre el cursor", func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			next := req
+			next.Cursor = resp.NextCursor
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE ("users"."user_name" < $1) OR ("users"."user_name" = $2 AND "users"."id" > $3) ORDER BY "users"."user_name" DESC,"users"."id" LIMIT $4`)).
+				WithArgs("Luis", "Luis", int64(4), 3).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_name"}).AddRow(7, "Ana"))
+
+			page, err := service.Execute(db, next, nil, nil)
+			require.NoError(t, err)
+			assert.Len(t, page.Data, 1)
+			assert.Empty(t, page.NextCursor)
+			assert.NotEmpty(t, page.PrevCursor)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+
+		t.Run("El cursor no se puede usar con otro ordenamiento", func(t *testing.T) {
+			db, _ := setupTestDB(t)
+			other := dtos.PaginationRequest{Cursor: resp.NextCursor, RowsPerPage: 2, SortBy: []string{"created_at"}}
+			var validationErr *domain.ValidationError
+			_, err := service.Execute(db, other, nil, nil)
+			require.ErrorAs(t, err, &validationErr)
+			assert.Contains(t, validationErr.Fields, "cursor")
+		})
+
+		t.Run("Un cursor alterado es rechazado", func(t *testing.T) {
+			db, _ := setupTestDB(t)
+			tampered := req
+			tampered.Cursor = "e30." + resp.NextCursor[len(resp.NextCursor)-10:]
+			_, err := service.Execute(db, tampered, nil, nil)
+			assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+		})
+	})
+
+	t.Run("Solo admite columnas propias del modelo", func(t *testing.T) {
+		req := dtos.PaginationRequest{Mode: "cursor", RowsPerPage: 10, SortBy: []string{"profile.status"}}
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, service.Validate(req), &validationErr)
+		assert.Contains(t, validationErr.Fields, "profile.status")
+	})
+
+	t.Run("No admite columnas que aceptan NULL", func(t *testing.T) {
+		req := dtos.PaginationRequest{Mode: "cursor", RowsPerPage: 10, SortBy: []string{"email_verified_at"}}
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, service.Validate(req), &validationErr)
+		assert.Contains(t, validationErr.Fields, "email_verified_at")
+
+		// En modo offset sí se puede ordenar por ella.
+		req.Mode = "offset"
+		assert.NoError(t, service.Validate(req))
+	})
+
+	t.Run("Sin clave de firma devuelve error interno", func(t *testing.T) {
+		db, _ := setupTestDB(t)
+		req := dtos.PaginationRequest{Mode: "cursor", RowsPerPage: 10}
+		_, err := NewPaginationService[User]().Execute(db, req, nil, nil)
+		assert.ErrorIs(t, err, domain.ErrInternal)
+	})
+}
+
+func TestExecuteWithAggregates(t *testing.T) {
+	service := NewPaginationService[User]()
+
+	t.Run("Agregados escalares y por grupo en Extras", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		req := dtos.PaginationRequest{
+			Page: 1, RowsPerPage: 10,
+			FilterBy:     []string{"is_active"},
+			FilterValues: []any{true},
+			Aggregates: []dtos.Aggregate{
+				{Op: "count"},
+				{Op: "sum", Field: "id", As: "total_ids"},
+				{Op: "max", Field: "email"},
+				{Op: "group_count", Field: "email", Limit: 5},
+			},
+		}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT email AS group_value, COUNT(*) AS group_count FROM "users" WHERE is_active = $1 GROUP BY "email" ORDER BY group_count DESC LIMIT $2`)).
+			WithArgs(true, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"group_value", "group_count"}).AddRow("007", int64(3)))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) AS agg_0, SUM(id) AS agg_1, MAX(email) AS agg_2 FROM "users" WHERE is_active = $1 LIMIT $2`)).
+			WithArgs(true, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"agg_0", "agg_1", "agg_2"}).AddRow(int64(3), "42", "999"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE is_active = $1`)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery(`SELECT \* FROM "users" WHERE is_active = \$1 ORDER BY "users"."id" DESC LIMIT \$2`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		resp, err := service.Execute(db, req, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), resp.Extras["count"])
+		assert.Equal(t, int64(42), resp.Extras["total_ids"])
+		assert.Equal(t, "999", resp.Extras["max_email"], "MAX de un texto no se convierte a número")
+		assert.Equal(t, []GroupCount{{Value: "007", Count: 3}}, resp.Extras["group_count_email"])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Agregados inválidos", func(t *testing.T) {
+		req := dtos.PaginationRequest{Aggregates: []dtos.Aggregate{
+			{Op: "sum", Field: "email"},
+			{Op: "avg", Field: "password"},
+			{Op: "median", Field: "id"},
+			{Op: "count", As: "x; DROP"},
+		}}
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, service.Validate(req), &validationErr)
+		assert.Len(t, validationErr.Fields, 4)
+	})
+}
+
+type Tag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type Article struct {
+	ID     uint   `json:"id" filter:"number" sort:"true"`
+	Title  string `json:"title" filter:"string"`
+	Secret string `json:"-"`
+	Tags   []Tag  `gorm:"many2many:article_tags" json:"tags,omitempty" include:"tags"`
+}
+
+func TestExecuteWithSelection(t *testing.T) {
+	service := NewPaginationService[Article]()
+
+	t.Run("fields selecciona columnas e include precarga la relación", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		req := dtos.PaginationRequest{Page: 1, RowsPerPage: 10, Fields: []string{"title"}, Include: []string{"tags"}}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "articles"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT articles.id,articles.title FROM "articles" ORDER BY "articles"."id" DESC LIMIT $1`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Hola"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "article_tags" WHERE "article_tags"."article_id" = $1`)).
+			WillReturnRows(sqlmock.NewRows([]string{"article_id", "tag_id"}).AddRow(1, 7))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE "tags"."id" = $1`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "go"))
+
+		resp, err := service.Execute(db, req, nil, nil)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		body, err := json.Marshal(resp)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `"data":[{"id":1,"title":"Hola","tags":[{"id":7,"name":"go"}]}]`)
+	})
+
+	t.Run("Campos y relaciones no declarados", func(t *testing.T) {
+		req := dtos.PaginationRequest{Fields: []string{"Secret", "title"}, Include: []string{"authors"}}
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, service.Validate(req), &validationErr)
+		assert.Contains(t, validationErr.Fields, "fields")
+		assert.Contains(t, validationErr.Fields, "include")
+	})
+
+	t.Run("Sin fields la respuesta se serializa completa", func(t *testing.T) {
+		body, err := json.Marshal(dtos.Sparse{Value: Article{ID: 1, Title: "Hola"}})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":1,"title":"Hola"}`, string(body))
+	})
+}
+
+func TestParseQuery(t *testing.T) {
+	t.Run("Traduce sort, filtros y paginación", func(t *testing.T) {
+		values, err := url.ParseQuery("sort=-created_at,user_name&filter[user_name][contains]=galicia&filter[is_active]=true&filter[id][in]=1,2&filter[email][is_null]&page=2&per_page=50&fields=id,email")
+		require.NoError(t, err)
+
+		req, err := ParseQuery[User](values)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"created_at", "user_name"}, req.SortBy)
+		assert.Equal(t, []bool{true, false}, req.SortDesc)
+		assert.Equal(t, 2, req.Page)
+		assert.Equal(t, 50, req.RowsPerPage)
+		assert.Equal(t, []string{"id", "email"}, req.Fields)
+		require.NotNil(t, req.Filters)
+		assert.Equal(t, []dtos.FilterCondition{
+			{Field: "email", Op: OpIsNull},
+			{Field: "id", Op: OpIn, Value: []any{"1", "2"}},
+			{Field: "is_active", Op: OpEq, Value: true},
+			{Field: "user_name", Op: OpContains, Value: "galicia"},
+		}, req.Filters.Conditions)
+		assert.NoError(t, NewPaginationService[User]().Validate(req))
+	})
+
+	t.Run("Fuzzy va al formato legado y per_page sin page arranca en 1", func(t *testing.T) {
+		values, _ := url.ParseQuery("filter[user_name:fuzzy]=juan&per_page=10")
+		req, err := ParseQuery[User](values)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user_name:fuzzy"}, req.FilterBy)
+		assert.Equal(t, []any{"juan"}, req.FilterValues)
+		assert.Nil(t, req.Filters)
+		assert.Equal(t, 1, req.Page)
+	})
+
+	t.Run("Parámetros mal formados", func(t *testing.T) {
+		values, _ := url.ParseQuery("filter[name=x&page=dos&per_page=-1")
+		_, err := ParseQuery[User](values)
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Contains(t, validationErr.Fields, "filter")
+		assert.Contains(t, validationErr.Fields, "page")
+		assert.Contains(t, validationErr.Fields, "per_page")
+	})
+}
+
+func TestExecuteWithSearch(t *testing.T) {
+	service := NewPaginationService[User]()
+	vector := `setweight(to_tsvector('simple', coalesce(users.user_name, '')), 'A') || setweight(to_tsvector('simple', coalesce(users.email, '')), 'B')`
+
+	t.Run("Postgres: tsvector, ranking y fragmentos resaltados", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		req := dtos.PaginationRequest{Page: 1, RowsPerPage: 10, Search: "juan gmail", Highlight: true}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE ` + vector + ` @@ websearch_to_tsquery('simple', $1)`)).
+			WithArgs("juan gmail").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY ts_rank(`+vector+`, websearch_to_tsquery('simple', $2)) DESC, "users"."id" DESC LIMIT $3`)).
+			WithArgs("juan gmail", "juan gmail", 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_name", "email"}).AddRow(7, "Juan", "juan@gmail.com"))
+		mock.ExpectQuery(regexp.QuoteMeta(`ts_headline('simple', coalesce(users.user_name, ''), websearch_to_tsquery('simple', $1)`)).
+			WillReturnRows(sqlmock.NewRows([]string{"pk", "h_0", "h_1"}).AddRow(7, "<mark>Juan</mark>", "juan@gmail.com"))
+
+		resp, err := service.Execute(db, req, nil, nil)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, map[string]map[string]string{"7": {"user_name": "<mark>Juan</mark>"}}, resp.Extras["highlights"])
+	})
+
+	t.Run("MySQL: cada palabra con LIKE en alguna columna", func(t *testing.T) {
+		db, mock := setupMySQLTestDB(t)
+		req := dtos.PaginationRequest{Search: "juan 100%"}
+
+		query := service.ApplyFilters(db.Model(&User{}), req)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE (LOWER(users.user_name) LIKE LOWER(?) OR LOWER(users.email) LIKE LOWER(?)) AND (LOWER(users.user_name) LIKE LOWER(?) OR LOWER(users.email) LIKE LOWER(?))")).
+			WithArgs("%juan%", "%juan%", `%100\%%`, `%100\%%`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		var users []User
+		require.NoError(t, query.Find(&users).Error)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Modelo sin columnas buscables y resaltado sin búsqueda", func(t *testing.T) {
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, NewPaginationService[Bank]().Validate(dtos.PaginationRequest{Search: "nación"}), &validationErr)
+		assert.Contains(t, validationErr.Fields, "search")
+
+		require.ErrorAs(t, service.Validate(dtos.PaginationRequest{Highlight: true}), &validationErr)
+		assert.Contains(t, validationErr.Fields, "highlight")
+	})
+}
+
+func TestMergeRequest(t *testing.T) {
+	view := dtos.PaginationRequest{
+		SortBy:       []string{"user_name"},
+		SortDesc:     []bool{false},
+		FilterBy:     []string{"is_active"},
+		FilterValues: []any{true},
+		Filters:      &dtos.FilterGroup{Conditions: []dtos.FilterCondition{{Field: "email", Op: OpContains, Value: "@banco"}}},
+		RowsPerPage:  25,
+		Fields:       []string{"id", "email"},
+	}
+
+	t.Run("Los filtros se suman y el resto se hereda de la vista", func(t *testing.T) {
+		adHoc := dtos.PaginationRequest{
+			Page:    3,
+			ViewID:  9,
+			Filters: &dtos.FilterGroup{Conditions: []dtos.FilterCondition{{Field: "user_name", Op: OpStartsWith, Value: "A"}}},
+		}
+		merged := MergeRequest(view, adHoc)
+
+		assert.Equal(t, 3, merged.Page)
+		assert.Equal(t, 25, merged.RowsPerPage)
+		assert.Equal(t, []string{"user_name"}, merged.SortBy)
+		assert.Equal(t, []string{"id", "email"}, merged.Fields)
+		assert.Equal(t, []string{"is_active"}, merged.FilterBy)
+		require.NotNil(t, merged.Filters)
+		assert.Equal(t, []dtos.FilterGroup{*view.Filters, *adHoc.Filters}, merged.Filters.Groups)
+		assert.NoError(t, NewPaginationService[User]().Validate(merged))
+	})
+
+	t.Run("El orden y el tamaño enviados reemplazan a los de la vista", func(t *testing.T) {
+		merged := MergeRequest(view, dtos.PaginationRequest{SortBy: []string{"created_at"}, SortDesc: []bool{true}, RowsPerPage: 10})
+		assert.Equal(t, []string{"created_at"}, merged.SortBy)
+		assert.Equal(t, []bool{true}, merged.SortDesc)
+		assert.Equal(t, 10, merged.RowsPerPage)
+		assert.Equal(t, 1, merged.Page)
+		assert.Same(t, view.Filters, merged.Filters)
+	})
+
+	t.Run("Highlight se hereda junto con la búsqueda", func(t *testing.T) {
+		searchView := dtos.PaginationRequest{Search: "banco nación", Highlight: true}
+
+		merged := MergeRequest(searchView, dtos.PaginationRequest{Page: 2})
+		assert.Equal(t, "banco nación", merged.Search)
+		assert.True(t, merged.Highlight)
+
+		merged = MergeRequest(searchView, dtos.PaginationRequest{Search: "galicia"})
+		assert.Equal(t, "galicia", merged.Search)
+		assert.False(t, merged.Highlight)
+	})
+}
@@ -0,0 +1,170 @@
+package pagination
+
+import (
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parámetros de la variante GET de las rutas paginadas:
+//
+//	?sort=-created_at,name&filter[name][contains]=galicia&filter[enabled]=true&page=2&per_page=50
+//
+// sort recibe columnas separadas por coma; el prefijo "-" ordena descendente.
+// filter[campo]=valor equivale al operador eq y filter[campo][op]=valor usa cualquier operador
+// del DSL: in, not_in y between reciben los valores separados por coma (o repitiendo el parámetro),
+// is_null y not_null no necesitan valor. Las claves "campo:fuzzy" van al formato filterBy/filterValues.
+// page, per_page, mode, cursor, fields, include, search, highlight y view_id significan lo mismo que en el body JSON.
+//
+// Todas las condiciones se combinan con AND; para grupos OR se sigue usando el POST.
+const (
+	QueryPage      = "page"
+	QueryPerPage   = "per_page"
+	QuerySort      = "sort"
+	QueryFilter    = "filter"
+	QueryMode      = "mode"
+	QueryCursor    = "cursor"
+	QueryFields    = "fields"
+	QueryInclude   = "include"
+	QuerySearch    = "search"
+	QueryHighlight = "highlight"
+	QueryViewID    = "view_id"
+)
+
+// ParseQuery traduce los parámetros de la query string a un dtos.PaginationRequest.
+// Los valores de columnas booleanas se convierten según el modelo T; el resto se valida
+// luego en PaginationService.Validate como con el body JSON.
+func ParseQuery[T any](values url.Values) (dtos.PaginationRequest, error) {
+	var req dtos.PaginationRequest
+	fieldErrors := make(map[string][]string)
+
+	req.Page = queryInt(values, QueryPage, fieldErrors)
+	req.RowsPerPage = queryInt(values, QueryPerPage, fieldErrors)
+	if req.Page == 0 && req.RowsPerPage > 0 {
+		req.Page = 1
+	}
+	req.Mode = values.Get(QueryMode)
+	req.Cursor = values.Get(QueryCursor)
+	req.Fields = splitQuery(values[QueryFields])
+	req.Include = splitQuery(values[QueryInclude])
+	req.Search = values.Get(QuerySearch)
+	req.ViewID = uint64(queryInt(values, QueryViewID, fieldErrors))
+	if raw := values.Get(QueryHighlight); raw != "" {
+		highlight, err := strconv.ParseBool(raw)
+		if err != nil {
+			fieldErrors[QueryHighlight] = append(fieldErrors[QueryHighlight], "El valor debe ser verdadero o falso.")
+		}
+		req.Highlight = highlight
+	}
+
+	for _, key := range splitQuery(values[QuerySort]) {
+		desc := strings.HasPrefix(key, "-")
+		req.SortBy = append(req.SortBy, strings.TrimPrefix(key, "-"))
+		req.SortDesc = append(req.SortDesc, desc)
+	}
+
+	ms, err := ParseModelSchema(new(T))
+	if err != nil {
+		return req, err
+	}
+
+	// Orden estable de las condiciones (url.Values es un map).
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if strings.HasPrefix(key, QueryFilter+"[") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var group dtos.FilterGroup
+	for _, key := range keys {
+		field, op, ok := parseFilterKey(key)
+		if !ok {
+			fieldErrors[QueryFilter] = append(fieldErrors[QueryFilter], "El parámetro '"+key+"' no tiene el formato filter[campo] o filter[campo][operador].")
+			continue
+		}
+		raw := values[key]
+
+		if strings.Contains(field, ":fuzzy") {
+			req.FilterBy = append(req.FilterBy, field)
+			req.FilterValues = append(req.FilterValues, raw[len(raw)-1])
+			continue
+		}
+
+		spec, _ := ms.Resolve(field)
+		cond := dtos.FilterCondition{Field: field, Op: op}
+		switch op {
+		case OpIsNull, OpNotNull:
+		case OpIn, OpNotIn, OpBetween:
+			list := []any{}
+			for _, v := range splitQuery(raw) {
+				list = append(list, queryValue(spec, v))
+			}
+			cond.Value = list
+		default:
+			cond.Value = queryValue(spec, raw[len(raw)-1])
+		}
+		group.Conditions = append(group.Conditions, cond)
+	}
+	if len(group.Conditions) > 0 {
+		req.Filters = &group
+	}
+
+	if len(fieldErrors) > 0 {
+		return req, domain.NewValidationError(fieldErrors)
+	}
+	return req, nil
+}
+
+// parseFilterKey separa "filter[campo]" o "filter[campo][op]". El operador por defecto es eq.
+func parseFilterKey(key string) (field, op string, ok bool) {
+	rest, found := strings.CutPrefix(key, QueryFilter+"[")
+	if !found || !strings.HasSuffix(rest, "]") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(rest, "]"), "][")
+	switch {
+	case len(parts) == 1 && parts[0] != "":
+		return parts[0], OpEq, true
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		return parts[0], strings.ToLower(parts[1]), true
+	}
+	return "", "", false
+}
+
+// queryValue convierte el texto al tipo que espera la validación. Los números y las fechas
+// se aceptan como texto; los booleanos no, por eso se convierten aquí.
+func queryValue(spec FieldSpec, value string) any {
+	if spec.Kind == FieldBool {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func queryInt(values url.Values, key string, fieldErrors map[string][]string) int {
+	raw := values.Get(key)
+	if raw == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		fieldErrors[key] = append(fieldErrors[key], "El valor debe ser un número entero positivo.")
+		return 0
+	}
+	return n
+}
+
+// splitQuery admite tanto "a,b" como parámetros repetidos ("?x=a&x=b").
+func splitQuery(values []string) []string {
+	var out []string
+	for _, v := range values {
+		out = append(out, compact(strings.Split(v, ","))...)
+	}
+	return out
+}
@@ -0,0 +1,286 @@
+package pagination
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// FieldKind es el tipo lógico de una columna expuesta a la paginación.
+// Define qué valores se aceptan al filtrar por ella.
+type FieldKind string
+
+const (
+	FieldString FieldKind = "string"
+	FieldNumber FieldKind = "number"
+	FieldBool   FieldKind = "bool"
+	FieldDate   FieldKind = "date"
+)
+
+// Tags de struct que declaran qué columnas se pueden filtrar y ordenar.
+//
+//	Name      string    `filter:"string,fuzzy" sort:"true"`
+//	Enabled   bool      `filter:"bool"`
+//	CreatedAt time.Time `filter:"date" sort:"true"`
+//
+// Las columnas sin tag no se pueden usar desde el frontend.
+const (
+	filterTag = "filter"
+	sortTag   = "sort"
+	fuzzyOpt  = "fuzzy"
+)
+
+// FieldSpec describe una columna permitida y cómo se traduce a SQL.
+type FieldSpec struct {
+	Key        string    // Nombre tal como lo envía el cliente (ej: "name", "offers::code_offer")
+	Column     string    // Expresión SQL de la columna (ej: "name", "offers.code_offer", `"Profile".status`)
+	Kind       FieldKind // Tipo lógico, usado para validar valores
+	Filterable bool
+	Sortable   bool
+	Fuzzy      bool // Admite el sufijo ":fuzzy" (pg_trgm)
+	Nullable   bool // Puntero o sql.Null*: la columna admite NULL
+
+	field *schema.Field // Campo de GORM, para leer el valor desde una fila
+	own   bool          // La columna pertenece a la tabla del modelo (no a una relación)
+}
+
+// ModelSchema es la lista blanca de columnas de un modelo, construida a partir de sus tags.
+type ModelSchema struct {
+	Table      string
+	PrimaryKey string
+	gormSchema *schema.Schema
+}
+
+var schemaCache = &sync.Map{}
+
+// ParseModelSchema construye el ModelSchema de un modelo usando el parser de GORM.
+func ParseModelSchema(model any) (*ModelSchema, error) {
+	s, err := schema.Parse(model, schemaCache, schema.NamingStrategy{})
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo parsear el modelo %T: %w", model, err)
+	}
+	ms := &ModelSchema{Table: s.Table, gormSchema: s}
+	if s.PrioritizedPrimaryField != nil {
+		ms.PrimaryKey = s.PrioritizedPrimaryField.DBName
+	}
+	return ms, nil
+}
+
+// Resolve busca una clave enviada por el cliente en la lista blanca.
+// Soporta columnas propias ("name"), tablas unidas por el modifier ("offers::code_offer")
+// y relaciones de GORM ("profile.status"). Devuelve false si la columna no fue declarada.
+func (ms *ModelSchema) Resolve(key string) (FieldSpec, bool) {
+	key = strings.TrimSuffix(key, ":fuzzy")
+
+	if strings.Contains(key, "::") {
+		parts := strings.SplitN(key, "::", 2)
+		if parts[0] == ms.Table {
+			return ownSpec(fieldSpec(ms.gormSchema, key, parts[1], parts[0]+"."+parts[1]))
+		}
+		rel := findRelation(ms.gormSchema, parts[0])
+		if rel == nil {
+			return FieldSpec{}, false
+		}
+		return fieldSpec(rel.FieldSchema, key, parts[1], parts[0]+"."+parts[1])
+	}
+
+	if strings.Contains(key, ".") {
+		segments := strings.Split(key, ".")
+		// Columna propia calificada con la tabla del modelo (ej: "banks.name").
+		if len(segments) == 2 && segments[0] == ms.Table {
+			return ownSpec(fieldSpec(ms.gormSchema, key, segments[1], key))
+		}
+		current := ms.gormSchema
+		var alias string
+		for _, segment := range segments[:len(segments)-1] {
+			rel := findRelation(current, segment)
+			if rel == nil {
+				return FieldSpec{}, false
+			}
+			// Si el segmento es el nombre de la relación, GORM la une con ese alias (Joins("Profile")).
+			// Si es el nombre de la tabla, la unió el modifier y se referencia por tabla.
+			if strings.EqualFold(rel.Name, segment) {
+				alias = `"` + rel.Name + `"`
+			} else {
+				alias = segment
+			}
+			current = rel.FieldSchema
+		}
+		column := segments[len(segments)-1]
+		return fieldSpec(current, key, column, alias+"."+column)
+	}
+
+	return ownSpec(fieldSpec(ms.gormSchema, key, key, key))
+}
+
+func ownSpec(spec FieldSpec, ok bool) (FieldSpec, bool) {
+	spec.own = ok
+	return spec, ok
+}
+
+// ExportFields devuelve las columnas propias del modelo visibles en la API (sin `json:"-"`),
+// en el orden en que se declararon. Son las únicas que se pueden exportar.
+func (ms *ModelSchema) ExportFields() []*schema.Field {
+	var fields []*schema.Field
+	for _, field := range ms.gormSchema.Fields {
+		if field.DBName == "" || !field.Readable {
+			continue
+		}
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name == "-" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// PrimaryField devuelve el campo de la clave primaria, o nil si el modelo no tiene.
+func (ms *ModelSchema) PrimaryField() *schema.Field {
+	return ms.gormSchema.PrioritizedPrimaryField
+}
+
+// fieldSpec lee los tags de la columna 'column' del schema 's'.
+func fieldSpec(s *schema.Schema, key, column, expr string) (FieldSpec, bool) {
+	field := s.LookUpField(column)
+	if field == nil || field.DBName != column {
+		return FieldSpec{}, false
+	}
+	spec := FieldSpec{Key: key, Column: expr, Nullable: isNullable(field.FieldType), field: field}
+	if tag, ok := field.Tag.Lookup(filterTag); ok {
+		opts := strings.Split(tag, ",")
+		spec.Kind = FieldKind(strings.TrimSpace(opts[0]))
+		spec.Filterable = spec.Kind.valid()
+		for _, opt := range opts[1:] {
+			if strings.TrimSpace(opt) == fuzzyOpt {
+				spec.Fuzzy = true
+			}
+		}
+	}
+	if tag, ok := field.Tag.Lookup(sortTag); ok {
+		spec.Sortable, _ = strconv.ParseBool(tag)
+	}
+	if !spec.Filterable && !spec.Sortable {
+		return FieldSpec{}, false
+	}
+	return spec, true
+}
+
+// isNullable indica si el tipo Go del campo puede guardar NULL: un puntero o un struct con
+// Valid (sql.NullTime, gorm.DeletedAt, ...).
+func isNullable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		return true
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	valid, ok := t.FieldByName("Valid")
+	return ok && valid.Type.Kind() == reflect.Bool
+}
+
+// findRelation busca una relación por nombre de campo o por nombre de tabla.
+func findRelation(s *schema.Schema, name string) *schema.Relationship {
+	for _, rel := range s.Relationships.Relations {
+		if strings.EqualFold(rel.Name, name) || rel.FieldSchema.Table == name {
+			return rel
+		}
+	}
+	return nil
+}
+
+func (k FieldKind) valid() bool {
+	switch k {
+	case FieldString, FieldNumber, FieldBool, FieldDate:
+		return true
+	}
+	return false
+}
+
+// --- VALIDACIÓN DE VALORES ---
+
+const dateLayout = "2006-01-02"
+
+// checkValue valida que un valor del formato de arrays paralelos sea compatible con el tipo de la columna.
+func (spec FieldSpec) checkValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	if values, ok := value.([]any); ok {
+		if spec.Kind == FieldDate {
+			if len(values) != 2 {
+				return "El rango de fechas debe tener exactamente dos valores (desde, hasta)."
+			}
+		}
+		if spec.Kind == FieldBool {
+			return "El campo no admite una lista de valores."
+		}
+		for _, v := range values {
+			if msg := spec.checkScalar(v); msg != "" {
+				return msg
+			}
+		}
+		return ""
+	}
+	if spec.Kind == FieldDate {
+		return "El filtro de fecha debe ser un rango [desde, hasta]."
+	}
+	return spec.checkScalar(value)
+}
+
+// checkScalar valida un único valor contra el tipo de la columna.
+func (spec FieldSpec) checkScalar(value any) string {
+	switch spec.Kind {
+	case FieldString:
+		if _, ok := value.(string); !ok {
+			return "El valor debe ser un texto."
+		}
+	case FieldNumber:
+		if _, ok := toNumber(value); !ok {
+			return "El valor debe ser numérico."
+		}
+	case FieldBool:
+		if _, ok := value.(bool); !ok {
+			return "El valor debe ser verdadero o falso."
+		}
+	case FieldDate:
+		str, ok := value.(string)
+		if !ok {
+			return "La fecha debe tener el formato AAAA-MM-DD."
+		}
+		if _, err := time.Parse(dateLayout, str); err != nil {
+			return "La fecha debe tener el formato AAAA-MM-DD."
+		}
+	}
+	return ""
+}
+
+// toNumber normaliza los números que llegan desde JSON (float64, json.Number o texto).
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
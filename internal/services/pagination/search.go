@@ -0,0 +1,204 @@
+package pagination
+
+import (
+	"fmt"
+	"go-fiber-core/internal/dtos"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// searchTag declara las columnas que participan del parámetro "search" y su peso en el ranking
+// (A es el más relevante, D el menos). "true" equivale a D.
+//
+//	Name       string `search:"A"`
+//	EntityCode string `search:"B"`
+//
+// En Postgres se busca con to_tsvector/websearch_to_tsquery y se ordena por ts_rank;
+// en MySQL cada palabra debe aparecer (LIKE) en alguna de las columnas, sin ranking.
+const searchTag = "search"
+
+// searchConfig es la configuración de text search de Postgres. Se usa "simple" (sin stemming)
+// porque las columnas buscables son nombres, códigos y emails. Los índices GIN de las
+// migraciones usan la misma expresión.
+const searchConfig = "simple"
+
+const (
+	maxSearchLength = 200
+	highlightsKey   = "highlights"
+	highlightStart  = "<mark>"
+	highlightStop   = "</mark>"
+)
+
+// SearchField es una columna propia declarada con el tag `search`.
+type SearchField struct {
+	Column string // Columna en la base
+	Key    string // Clave JSON, usada en los fragmentos resaltados
+	Weight string // Peso de ts_rank: A, B, C o D
+}
+
+// SearchFields devuelve las columnas buscables del modelo, en el orden en que se declararon.
+func (ms *ModelSchema) SearchFields() []SearchField {
+	var fields []SearchField
+	for _, field := range ms.gormSchema.Fields {
+		weight, ok := field.Tag.Lookup(searchTag)
+		if !ok || field.DBName == "" {
+			continue
+		}
+		weight = strings.ToUpper(weight)
+		if weight != "A" && weight != "B" && weight != "C" {
+			weight = "D"
+		}
+		fields = append(fields, SearchField{
+			Column: field.DBName,
+			Key:    jsonName(field.Tag.Get("json"), field.DBName),
+			Weight: weight,
+		})
+	}
+	return fields
+}
+
+// validateSearch acumula en fieldErrors los problemas del parámetro search.
+func validateSearch(ms *ModelSchema, req dtos.PaginationRequest, fieldErrors map[string][]string) {
+	search := strings.TrimSpace(req.Search)
+	if search == "" {
+		if req.Highlight {
+			fieldErrors["highlight"] = append(fieldErrors["highlight"], "El resaltado requiere un texto de búsqueda.")
+		}
+		return
+	}
+	if len(ms.SearchFields()) == 0 {
+		fieldErrors["search"] = append(fieldErrors["search"], "El recurso no admite búsqueda de texto.")
+		return
+	}
+	if utf8.RuneCountInString(search) > maxSearchLength {
+		fieldErrors["search"] = append(fieldErrors["search"], fmt.Sprintf("La búsqueda admite hasta %d caracteres.", maxSearchLength))
+	}
+}
+
+// searchVector arma la expresión tsvector ponderada sobre las columnas buscables.
+func searchVector(ms *ModelSchema) string {
+	fields := ms.SearchFields()
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = fmt.Sprintf("setweight(to_tsvector('%s', coalesce(%s.%s, '')), '%s')", searchConfig, ms.Table, field.Column, field.Weight)
+	}
+	return strings.Join(parts, " || ")
+}
+
+// searchQuery es la expresión tsquery; websearch_to_tsquery admite la sintaxis de buscador
+// ("frase exacta", -excluir, or) y nunca falla por la entrada del usuario.
+func searchQuery() string {
+	return fmt.Sprintf("websearch_to_tsquery('%s', ?)", searchConfig)
+}
+
+// applySearch filtra por el texto de búsqueda. Se llama desde ApplyFilters (ya validado).
+func applySearch(db *gorm.DB, ms *ModelSchema, search string) *gorm.DB {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return db
+	}
+	if db.Name() == "postgres" {
+		return db.Where(searchVector(ms)+" @@ "+searchQuery(), search)
+	}
+
+	// MySQL: cada palabra debe aparecer en al menos una de las columnas.
+	fields := ms.SearchFields()
+	for _, term := range strings.Fields(search) {
+		conditions := make([]string, len(fields))
+		args := make([]any, len(fields))
+		for i, field := range fields {
+			conditions[i] = "LOWER(" + ms.Table + "." + field.Column + ") LIKE LOWER(?)"
+			args[i] = "%" + escapeLike(term) + "%"
+		}
+		// GORM agrega los paréntesis al combinarla con otras condiciones.
+		db = db.Where(strings.Join(conditions, " OR "), args...)
+	}
+	return db
+}
+
+// applySearchRank ordena por relevancia cuando hay búsqueda y no se pidió otro orden.
+// tiebreak (ej: la PK) va en la misma expresión: GORM descarta un ORDER BY con parámetros
+// (clause.OrderBy.Expression) si después se agrega otra columna. Solo en Postgres; devuelve false si no aplicó ningún orden.
+func applySearchRank(db *gorm.DB, ms *ModelSchema, search, tiebreak string) (*gorm.DB, bool) {
+	search = strings.TrimSpace(search)
+	if search == "" || db.Name() != "postgres" {
+		return db, false
+	}
+	order := "ts_rank(" + searchVector(ms) + ", " + searchQuery() + ") DESC"
+	if tiebreak != "" {
+		order += ", " + tiebreak
+	}
+	return db.Order(clause.OrderBy{Expression: clause.Expr{SQL: order, Vars: []any{search}, WithoutParentheses: true}}), true
+}
+
+// addHighlights agrega a extras los fragmentos resaltados si el request los pidió.
+func (p *PaginationService[T]) addHighlights(db *gorm.DB, req dtos.PaginationRequest, data []T, extras map[string]any) (map[string]any, error) {
+	if !req.Highlight {
+		return extras, nil
+	}
+	ms, err := p.Schema()
+	if err != nil {
+		return nil, err
+	}
+	snippets, err := highlights(db, ms, req.Search, data)
+	if err != nil {
+		return nil, err
+	}
+	if extras == nil {
+		extras = make(map[string]any, 1)
+	}
+	extras[highlightsKey] = snippets
+	return extras, nil
+}
+
+// highlights devuelve, por clave primaria, los fragmentos de cada columna buscable que
+// contienen coincidencias, marcadas con <mark>. Solo en Postgres (ts_headline).
+func highlights[T any](db *gorm.DB, ms *ModelSchema, search string, data []T) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string)
+	pk := ms.PrimaryField()
+	if db.Name() != "postgres" || pk == nil || len(data) == 0 {
+		return result, nil
+	}
+
+	ids := make([]any, len(data))
+	for i := range data {
+		ids[i], _ = pk.ValueOf(db.Statement.Context, reflect.ValueOf(&data[i]).Elem())
+	}
+
+	fields := ms.SearchFields()
+	selects := []string{ms.Table + "." + ms.PrimaryKey + " AS pk"}
+	args := make([]any, 0, len(fields))
+	options := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxFragments=2, MaxWords=20, MinWords=5", highlightStart, highlightStop)
+	for i, field := range fields {
+		selects = append(selects, fmt.Sprintf("ts_headline('%s', coalesce(%s.%s, ''), %s, '%s') AS h_%d",
+			searchConfig, ms.Table, field.Column, searchQuery(), options, i))
+		args = append(args, search)
+	}
+
+	var rows []map[string]any
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table(ms.Table).
+		Select(strings.Join(selects, ", "), args...).
+		Where(ms.Table+"."+ms.PrimaryKey+" IN ?", ids).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		snippets := make(map[string]string)
+		for i, field := range fields {
+			if snippet, ok := row[fmt.Sprintf("h_%d", i)].(string); ok && strings.Contains(snippet, highlightStart) {
+				snippets[field.Key] = snippet
+			}
+		}
+		if len(snippets) > 0 {
+			result[fmt.Sprint(normalizeAggregate(row["pk"], true))] = snippets
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,148 @@
+package pagination
+
+import (
+	"go-fiber-core/internal/domain"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// includeTag declara qué relaciones se pueden precargar con el parámetro "include".
+//
+//	Roles []Role `gorm:"many2many:role_user" json:"roles,omitempty" include:"roles"`
+const includeTag = "include"
+
+// Selection es el resultado de validar los parámetros "fields" (sparse fieldsets) e "include"
+// contra el modelo. Se aplica a la consulta con Modifier y a la respuesta con Keys (ver dtos.Sparse).
+type Selection struct {
+	Columns  []string // Columnas propias a seleccionar (siempre incluye la PK)
+	Preloads []string // Relaciones de GORM a precargar
+	Keys     []string // Claves JSON que se devuelven; vacío = todas
+
+	table string
+}
+
+// ParseSelection valida fields e include contra el modelo T.
+// "fields" admite las columnas visibles en la API (las mismas que se pueden exportar).
+// Devuelve nil si no se pidió ninguna de las dos cosas.
+func ParseSelection[T any](fields, include []string) (*Selection, error) {
+	fields, include = compact(fields), compact(include)
+	if len(fields) == 0 && len(include) == 0 {
+		return nil, nil
+	}
+	ms, err := ParseModelSchema(new(T))
+	if err != nil {
+		return nil, err
+	}
+
+	fieldErrors := make(map[string][]string)
+	sel := &Selection{table: ms.Table}
+
+	if len(fields) > 0 {
+		visible := make(map[string]string) // columna -> clave JSON
+		for _, field := range ms.ExportFields() {
+			visible[field.DBName] = jsonName(field.Tag.Get("json"), field.DBName)
+		}
+		pk := ms.PrimaryKey
+		sel.Columns = append(sel.Columns, pk)
+		sel.Keys = append(sel.Keys, visible[pk])
+		for _, name := range fields {
+			key, ok := visible[name]
+			if !ok {
+				fieldErrors["fields"] = append(fieldErrors["fields"], "El campo '"+name+"' no existe o no se puede seleccionar.")
+				continue
+			}
+			if name != pk {
+				sel.Columns = append(sel.Columns, name)
+				sel.Keys = append(sel.Keys, key)
+			}
+		}
+	}
+
+	for _, name := range include {
+		found := false
+		for _, rel := range ms.gormSchema.Relationships.Relations {
+			if rel.Field.Tag.Get(includeTag) == name {
+				sel.Preloads = append(sel.Preloads, rel.Name)
+				if len(fields) > 0 {
+					sel.Keys = append(sel.Keys, jsonName(rel.Field.Tag.Get("json"), name))
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			fieldErrors["include"] = append(fieldErrors["include"], "La relación '"+name+"' no se puede incluir.")
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, domain.NewValidationError(fieldErrors)
+	}
+	return sel, nil
+}
+
+// Modifier devuelve un QueryModifier que aplica el Select y los Preloads, encadenado
+// después del modifier del repositorio (que puede ser nil).
+func (s *Selection) Modifier(next QueryModifier) QueryModifier {
+	if s == nil {
+		return next
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		if next != nil {
+			db = next(db)
+		}
+		if len(s.Columns) > 0 {
+			// Calificadas con la tabla para no chocar con las columnas de los JOINs del modifier.
+			columns := make([]string, len(s.Columns))
+			for i, name := range s.Columns {
+				columns[i] = s.table + "." + name
+			}
+			db = db.Select(columns)
+		}
+		for _, preload := range s.Preloads {
+			db = db.Preload(preload)
+		}
+		return db
+	}
+}
+
+// ensureColumn agrega una columna al Select sin exponerla en la respuesta.
+func (s *Selection) ensureColumn(name string) {
+	if s == nil || len(s.Columns) == 0 {
+		return
+	}
+	for _, column := range s.Columns {
+		if column == name {
+			return
+		}
+	}
+	s.Columns = append(s.Columns, name)
+}
+
+// ResponseKeys devuelve las claves JSON a conservar en la respuesta (nil = todas).
+func (s *Selection) ResponseKeys() []string {
+	if s == nil {
+		return nil
+	}
+	return s.Keys
+}
+
+func jsonName(tag, fallback string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// compact limpia espacios y valores vacíos (ej: "?fields=id,,name").
+func compact(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,32 @@
+package permission
+
+import (
+	"context"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/models"
+	permissionRepo "go-fiber-core/internal/repositories/permission"
+)
+
+type PermissionService interface {
+	// GetUserPermissions devuelve los nombres de los permisos del usuario según sus roles.
+	GetUserPermissions(ctx context.Context, userID uint64) ([]string, error)
+	// List devuelve el catálogo de permisos que se pueden asignar a los roles.
+	List(ctx context.Context) ([]models.Permission, error)
+}
+
+type permissionService struct {
+	conn   *connect.ConnectDTO
+	reader permissionRepo.PermissionReader
+}
+
+func NewPermissionService(conn *connect.ConnectDTO, reader permissionRepo.PermissionReader) PermissionService {
+	return &permissionService{conn: conn, reader: reader}
+}
+
+func (s *permissionService) GetUserPermissions(ctx context.Context, userID uint64) ([]string, error) {
+	return s.reader.GetNamesByUserID(ctx, s.conn.ConnectGormRead, userID)
+}
+
+func (s *permissionService) List(ctx context.Context) ([]models.Permission, error) {
+	return s.reader.GetAll(ctx, s.conn.ConnectGormRead)
+}
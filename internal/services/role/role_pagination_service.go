@@ -0,0 +1,32 @@
+package role
+
+import (
+	"context"
+	"go-fiber-core/internal/dtos"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/models"
+	"go-fiber-core/internal/repositories/role"
+)
+
+type RolePaginationService interface {
+	GetAllPaginated(ctx context.Context, req dtos.PaginationRequest) (*dtos.PaginationResponse[models.Role], error)
+}
+
+type rolePaginationService struct {
+	conn      *connect.ConnectDTO
+	paginator role.RolePagination
+}
+
+func NewRolePaginationService(
+	conn *connect.ConnectDTO,
+	paginator role.RolePagination,
+) RolePaginationService {
+	return &rolePaginationService{
+		conn:      conn,
+		paginator: paginator,
+	}
+}
+
+func (s *rolePaginationService) GetAllPaginated(ctx context.Context, req dtos.PaginationRequest) (*dtos.PaginationResponse[models.Role], error) {
+	return s.paginator.GetAllPaginated(ctx, s.conn.ConnectGormRead, req)
+}
@@ -0,0 +1,42 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/models"
+	roleRepo "go-fiber-core/internal/repositories/role"
+
+	"gorm.io/gorm"
+)
+
+type RoleReaderService interface {
+	// GetByID devuelve el rol con sus permisos.
+	GetByID(ctx context.Context, id uint64) (*models.Role, error)
+	GetAll(ctx context.Context) ([]models.Role, error)
+}
+
+type roleReaderService struct {
+	conn       *connect.ConnectDTO
+	roleReader roleRepo.RoleReader
+}
+
+func NewRoleReaderService(conn *connect.ConnectDTO, reader roleRepo.RoleReader) RoleReaderService {
+	return &roleReaderService{
+		conn:       conn,
+		roleReader: reader,
+	}
+}
+
+func (s *roleReaderService) GetByID(ctx context.Context, id uint64) (*models.Role, error) {
+	role, err := s.roleReader.GetByID(ctx, s.conn.ConnectGormRead, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	return role, err
+}
+
+func (s *roleReaderService) GetAll(ctx context.Context) ([]models.Role, error) {
+	return s.roleReader.GetAll(ctx, s.conn.ConnectGormRead)
+}
@@ -0,0 +1,249 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/models"
+	permissionRepo "go-fiber-core/internal/repositories/permission"
+	roleRepo "go-fiber-core/internal/repositories/role"
+	"go-fiber-core/internal/services"
+	menuService "go-fiber-core/internal/services/menu"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// UpdateRoleDTO son los datos editables de un rol.
+type UpdateRoleDTO struct {
+	Name     string
+	IsActive bool
+}
+
+type RoleWriterService interface {
+	Create(ctx context.Context, name string) (*models.Role, error)
+	// Update renombra el rol y lo activa o desactiva. Un rol inactivo no otorga permisos.
+	Update(ctx context.Context, id uint64, data UpdateRoleDTO) (*models.Role, error)
+	SoftDelete(ctx context.Context, id uint64) error
+	// AssignUsers asigna cada rol a cada usuario (asignación masiva). El actor solo puede
+	// asignar roles cuyos permisos ya tiene.
+	AssignUsers(ctx context.Context, actorID uint64, roleIDs, userIDs []uint64) error
+	// RevokeUsers quita cada rol a cada usuario.
+	RevokeUsers(ctx context.Context, roleIDs, userIDs []uint64) error
+	// SetPermissions reemplaza los permisos del rol y lo devuelve actualizado. El actor solo
+	// puede agregar permisos que ya tiene; quitar no tiene restricción.
+	SetPermissions(ctx context.Context, actorID, id uint64, permissionIDs []uint64) (*models.Role, error)
+}
+
+type roleWriterService struct {
+	services.TransactionManager
+	conn             *connect.ConnectDTO
+	roleWriter       roleRepo.RoleWriter
+	roleReader       roleRepo.RoleReader
+	permissionReader permissionRepo.PermissionReader
+	menuCache        menuService.MenuCache // Los menús se derivan de los roles (menu_role)
+}
+
+func NewRoleWriterService(
+	conn *connect.ConnectDTO,
+	writer roleRepo.RoleWriter,
+	reader roleRepo.RoleReader,
+	permissionReader permissionRepo.PermissionReader,
+	menuCache menuService.MenuCache,
+) RoleWriterService {
+	return &roleWriterService{
+		TransactionManager: services.NewTransactionManager(conn),
+		conn:               conn,
+		roleWriter:         writer,
+		roleReader:         reader,
+		permissionReader:   permissionReader,
+		menuCache:          menuCache,
+	}
+}
+
+func (s *roleWriterService) Create(ctx context.Context, name string) (*models.Role, error) {
+	name = strings.TrimSpace(name)
+	if err := s.checkName(ctx, name, 0); err != nil {
+		return nil, err
+	}
+	role := &models.Role{Name: name, IsActive: true}
+	if err := s.roleWriter.Create(ctx, s.conn.ConnectGormWrite, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (s *roleWriterService) Update(ctx context.Context, id uint64, data UpdateRoleDTO) (*models.Role, error) {
+	role, err := s.getByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(data.Name)
+	if err := s.checkName(ctx, name, id); err != nil {
+		return nil, err
+	}
+
+	role.Name = name
+	role.IsActive = data.IsActive
+
+	if err := s.invalidateMenus(ctx, s.roleWriter.Update(ctx, s.conn.ConnectGormWrite, role)); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (s *roleWriterService) SoftDelete(ctx context.Context, id uint64) error {
+	if _, err := s.getByID(ctx, id); err != nil {
+		return err
+	}
+	return s.invalidateMenus(ctx, s.roleWriter.SoftDelete(ctx, s.conn.ConnectGormWrite, id))
+}
+
+func (s *roleWriterService) AssignUsers(ctx context.Context, actorID uint64, roleIDs, userIDs []uint64) error {
+	if err := s.checkAssignment(ctx, roleIDs, userIDs); err != nil {
+		return err
+	}
+	granted, err := s.permissionReader.GetNamesByRoleIDs(ctx, s.conn.ConnectGormWrite, roleIDs)
+	if err != nil {
+		return err
+	}
+	if err := s.checkGrantable(ctx, actorID, granted); err != nil {
+		return err
+	}
+	err = s.ExecuteTx(ctx, func(tx *gorm.DB) error {
+		return s.roleWriter.AddBulkUsers(ctx, tx, roleIDs, userIDs)
+	})
+	return s.invalidateMenus(ctx, err)
+}
+
+func (s *roleWriterService) RevokeUsers(ctx context.Context, roleIDs, userIDs []uint64) error {
+	return s.invalidateMenus(ctx, s.roleWriter.BulkRemoveUsers(ctx, s.conn.ConnectGormWrite, roleIDs, userIDs))
+}
+
+func (s *roleWriterService) SetPermissions(ctx context.Context, actorID, id uint64, permissionIDs []uint64) (*models.Role, error) {
+	role, err := s.getByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(permissionIDs) > 0 {
+		missing, err := s.permissionReader.MissingIDs(ctx, s.conn.ConnectGormWrite, permissionIDs)
+		if err != nil {
+			return nil, err
+		}
+		if len(missing) > 0 {
+			return nil, domain.NewValidationError(map[string][]string{"permission_ids": {notFoundIDs("permisos", missing)}})
+		}
+	}
+	if added := addedPermissionIDs(role, permissionIDs); len(added) > 0 {
+		granted, err := s.permissionReader.GetNamesByIDs(ctx, s.conn.ConnectGormWrite, added)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkGrantable(ctx, actorID, granted); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.ExecuteTx(ctx, func(tx *gorm.DB) error {
+		return s.roleWriter.ReplacePermissions(ctx, tx, id, permissionIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.getByID(ctx, id)
+}
+
+// invalidateMenus descarta los menús cacheados si la operación se completó: activar,
+// desactivar, borrar o asignar un rol cambia los menús de sus usuarios.
+func (s *roleWriterService) invalidateMenus(ctx context.Context, err error) error {
+	if err == nil {
+		s.menuCache.Invalidate(ctx)
+	}
+	return err
+}
+
+func (s *roleWriterService) getByID(ctx context.Context, id uint64) (*models.Role, error) {
+	role, err := s.roleReader.GetByID(ctx, s.conn.ConnectGormWrite, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	return role, err
+}
+
+// checkName valida que el nombre no lo use otro rol, incluidos los borrados (la columna es única).
+func (s *roleWriterService) checkName(ctx context.Context, name string, excludeID uint64) error {
+	exists, err := s.roleReader.ExistsByName(ctx, s.conn.ConnectGormWrite, name, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return domain.NewValidationError(map[string][]string{"name": {"Ya existe un rol con ese nombre."}})
+	}
+	return nil
+}
+
+// checkAssignment valida que existan todos los roles y usuarios antes de asignarlos.
+func (s *roleWriterService) checkAssignment(ctx context.Context, roleIDs, userIDs []uint64) error {
+	fieldErrors := make(map[string][]string)
+
+	missingRoles, err := s.roleReader.MissingIDs(ctx, s.conn.ConnectGormWrite, roleIDs)
+	if err != nil {
+		return err
+	}
+	if len(missingRoles) > 0 {
+		fieldErrors["role_ids"] = append(fieldErrors["role_ids"], notFoundIDs("roles", missingRoles))
+	}
+
+	missingUsers, err := s.roleReader.MissingUserIDs(ctx, s.conn.ConnectGormWrite, userIDs)
+	if err != nil {
+		return err
+	}
+	if len(missingUsers) > 0 {
+		fieldErrors["user_ids"] = append(fieldErrors["user_ids"], notFoundIDs("usuarios", missingUsers))
+	}
+
+	if len(fieldErrors) > 0 {
+		return domain.NewValidationError(fieldErrors)
+	}
+	return nil
+}
+
+// checkGrantable rechaza con ErrForbidden si se otorgaría algún permiso que el actor no tiene:
+// nadie puede dar (ni darse) más de lo que ya tiene, igual que al suplantar o importar usuarios.
+func (s *roleWriterService) checkGrantable(ctx context.Context, actorID uint64, granted []string) error {
+	if len(granted) == 0 {
+		return nil
+	}
+	actorPermissions, err := s.permissionReader.GetNamesByUserID(ctx, s.conn.ConnectGormWrite, actorID)
+	if err != nil {
+		return fmt.Errorf("error al obtener los permisos del usuario: %w", err)
+	}
+	for _, permission := range granted {
+		if !slices.Contains(actorPermissions, permission) {
+			return domain.ErrForbidden
+		}
+	}
+	return nil
+}
+
+// addedPermissionIDs devuelve los permisos pedidos que el rol todavía no tiene.
+func addedPermissionIDs(role *models.Role, permissionIDs []uint64) []uint64 {
+	current := make(map[uint64]bool, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		current[permission.ID] = true
+	}
+	var added []uint64
+	for _, id := range permissionIDs {
+		if !current[id] {
+			added = append(added, id)
+		}
+	}
+	return added
+}
+
+func notFoundIDs(resource string, ids []uint64) string {
+	return fmt.Sprintf("No existen los %s: %s.", resource, strings.Trim(fmt.Sprint(ids), "[]"))
+}
@@ -0,0 +1,167 @@
+package role
+
+import (
+	"context"
+	"testing"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/config"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/models"
+	permissionRepo "go-fiber-core/internal/repositories/permission"
+	roleRepo "go-fiber-core/internal/repositories/role"
+	menuService "go-fiber-core/internal/services/menu"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// catalog son los permisos del sistema, por ID.
+var catalog = map[uint64]string{1: "banks.read", 2: "users.read", 3: "users.delete", 4: "roles.assign"}
+
+// fakeRoles tiene el rol "Operador" (1) con banks.read y el rol "Admin" (2) con todos los
+// permisos, y registra las escrituras.
+type fakeRoles struct {
+	roleRepo.RoleReader
+	roleRepo.RoleWriter
+	permissions map[uint64][]uint64
+	assigned    map[uint64][]uint64
+}
+
+func newFakeRoles() *fakeRoles {
+	return &fakeRoles{
+		permissions: map[uint64][]uint64{1: {1}, 2: {1, 2, 3, 4}},
+		assigned:    map[uint64][]uint64{},
+	}
+}
+
+func (f *fakeRoles) GetByID(_ context.Context, _ *gorm.DB, id uint64) (*models.Role, error) {
+	ids, ok := f.permissions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	role := &models.Role{ID: id, IsActive: true}
+	for _, permissionID := range ids {
+		role.Permissions = append(role.Permissions, models.Permission{ID: permissionID, Name: catalog[permissionID]})
+	}
+	return role, nil
+}
+
+func (f *fakeRoles) MissingIDs(_ context.Context, _ *gorm.DB, ids []uint64) ([]uint64, error) {
+	var missing []uint64
+	for _, id := range ids {
+		if _, ok := f.permissions[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+func (f *fakeRoles) MissingUserIDs(context.Context, *gorm.DB, []uint64) ([]uint64, error) {
+	return nil, nil
+}
+
+func (f *fakeRoles) AddBulkUsers(_ context.Context, _ *gorm.DB, roleIDs, userIDs []uint64) error {
+	for _, userID := range userIDs {
+		f.assigned[userID] = append(f.assigned[userID], roleIDs...)
+	}
+	return nil
+}
+
+func (f *fakeRoles) ReplacePermissions(_ context.Context, _ *gorm.DB, roleID uint64, permissionIDs []uint64) error {
+	f.permissions[roleID] = permissionIDs
+	return nil
+}
+
+// fakePermissions: el actor 1 tiene roles.assign, banks.read y users.read (no users.delete).
+type fakePermissions struct {
+	permissionRepo.PermissionReader
+	roles *fakeRoles
+}
+
+func (f fakePermissions) GetNamesByUserID(_ context.Context, _ *gorm.DB, userID uint64) ([]string, error) {
+	if userID == 1 {
+		return []string{"roles.assign", "banks.read", "users.read"}, nil
+	}
+	return nil, nil
+}
+
+func (f fakePermissions) GetNamesByRoleIDs(_ context.Context, _ *gorm.DB, roleIDs []uint64) ([]string, error) {
+	var names []string
+	for _, roleID := range roleIDs {
+		for _, id := range f.roles.permissions[roleID] {
+			names = append(names, catalog[id])
+		}
+	}
+	return names, nil
+}
+
+func (f fakePermissions) GetNamesByIDs(_ context.Context, _ *gorm.DB, ids []uint64) ([]string, error) {
+	var names []string
+	for _, id := range ids {
+		names = append(names, catalog[id])
+	}
+	return names, nil
+}
+
+func (f fakePermissions) MissingIDs(_ context.Context, _ *gorm.DB, ids []uint64) ([]uint64, error) {
+	var missing []uint64
+	for _, id := range ids {
+		if _, ok := catalog[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// newTestRoleWriter arma el servicio con una base simulada (solo la transacción) y la caché
+// de menús sin Redis.
+func newTestRoleWriter(t *testing.T) (*roleWriterService, *fakeRoles, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	require.NoError(t, err)
+
+	roles := newFakeRoles()
+	conn := &connect.ConnectDTO{ConnectGormRead: gormDB, ConnectGormWrite: gormDB}
+	s := NewRoleWriterService(conn, roles, roles, fakePermissions{roles: roles}, menuService.NewMenuCache(conn, &config.AppConfig{}))
+	return s.(*roleWriterService), roles, mock
+}
+
+func TestRoleWriterService_AssignUsersRejectsEscalation(t *testing.T) {
+	ctx := context.Background()
+	s, roles, mock := newTestRoleWriter(t)
+
+	// El rol Admin otorga users.delete, que el actor no tiene.
+	err := s.AssignUsers(ctx, 1, []uint64{2}, []uint64{1, 7})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.Empty(t, roles.assigned)
+
+	// Operador solo otorga banks.read: se asigna.
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	require.NoError(t, s.AssignUsers(ctx, 1, []uint64{1}, []uint64{7}))
+	assert.Equal(t, []uint64{1}, roles.assigned[7])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleWriterService_SetPermissionsRejectsEscalation(t *testing.T) {
+	ctx := context.Background()
+	s, roles, mock := newTestRoleWriter(t)
+
+	// Agregar users.delete a Operador: el actor no lo tiene.
+	_, err := s.SetPermissions(ctx, 1, 1, []uint64{1, 3})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.Equal(t, []uint64{1}, roles.permissions[1])
+
+	// Quitar users.delete de Admin no otorga nada: se permite aunque el actor no lo tenga.
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	role, err := s.SetPermissions(ctx, 1, 2, []uint64{1, 2, 4})
+	require.NoError(t, err)
+	assert.Len(t, role.Permissions, 3)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,247 @@
+package savedview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/models"
+	savedViewRepo "go-fiber-core/internal/repositories/savedview"
+	"go-fiber-core/internal/services"
+	"go-fiber-core/internal/services/pagination"
+
+	"gorm.io/gorm"
+)
+
+// Recursos paginados que admiten vistas guardadas.
+const (
+	ResourceBanks = "banks"
+	ResourceUsers = "users"
+)
+
+// SaveViewDTO son los datos editables de una vista.
+type SaveViewDTO struct {
+	Resource  string
+	Name      string
+	Request   dtos.PaginationRequest
+	RoleID    *uint64
+	IsDefault bool
+}
+
+type SavedViewService interface {
+	List(ctx context.Context, userID uint64, resource string) ([]models.SavedView, error)
+	GetByID(ctx context.Context, userID, id uint64) (*models.SavedView, error)
+	Create(ctx context.Context, userID uint64, data SaveViewDTO) (*models.SavedView, error)
+	Update(ctx context.Context, userID, id uint64, data SaveViewDTO) (*models.SavedView, error)
+	Delete(ctx context.Context, userID, id uint64) error
+	SetDefault(ctx context.Context, userID, id uint64) (*models.SavedView, error)
+	// Apply combina la vista req.ViewID (si viene) con el resto de req.
+	Apply(ctx context.Context, userID uint64, resource string, req dtos.PaginationRequest) (dtos.PaginationRequest, error)
+}
+
+type savedViewService struct {
+	services.TransactionManager
+	conn   *connect.ConnectDTO
+	reader savedViewRepo.SavedViewReader
+	writer savedViewRepo.SavedViewWriter
+
+	// validators valida la solicitud guardada contra la lista blanca del modelo de cada recurso.
+	validators map[string]func(dtos.PaginationRequest) error
+}
+
+func NewSavedViewService(
+	conn *connect.ConnectDTO,
+	reader savedViewRepo.SavedViewReader,
+	writer savedViewRepo.SavedViewWriter,
+	banks *pagination.PaginationService[models.Bank],
+	users *pagination.PaginationService[models.User],
+) SavedViewService {
+	return &savedViewService{
+		TransactionManager: services.NewTransactionManager(conn),
+		conn:               conn,
+		reader:             reader,
+		writer:             writer,
+		validators: map[string]func(dtos.PaginationRequest) error{
+			ResourceBanks: banks.Validate,
+			ResourceUsers: users.Validate,
+		},
+	}
+}
+
+func (s *savedViewService) List(ctx context.Context, userID uint64, resource string) ([]models.SavedView, error) {
+	if _, ok := s.validators[resource]; resource != "" && !ok {
+		return nil, unknownResource(resource)
+	}
+	return s.reader.ListVisible(ctx, s.conn.ConnectGormRead, userID, resource)
+}
+
+func (s *savedViewService) GetByID(ctx context.Context, userID, id uint64) (*models.SavedView, error) {
+	view, err := s.reader.GetVisibleByID(ctx, s.conn.ConnectGormRead, id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	return view, err
+}
+
+func (s *savedViewService) Create(ctx context.Context, userID uint64, data SaveViewDTO) (*models.SavedView, error) {
+	if err := s.validate(ctx, userID, 0, data); err != nil {
+		return nil, err
+	}
+	view := &models.SavedView{
+		UserID:    userID,
+		RoleID:    data.RoleID,
+		Resource:  data.Resource,
+		Name:      data.Name,
+		Request:   models.ViewRequest(storable(data.Request)),
+		IsDefault: data.IsDefault,
+	}
+	err := s.ExecuteTx(ctx, func(tx *gorm.DB) error {
+		if view.IsDefault {
+			if err := s.writer.ClearDefault(ctx, tx, userID, view.Resource); err != nil {
+				return err
+			}
+		}
+		return s.writer.Create(ctx, tx, view)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+func (s *savedViewService) Update(ctx context.Context, userID, id uint64, data SaveViewDTO) (*models.SavedView, error) {
+	view, err := s.ownView(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(ctx, userID, id, data); err != nil {
+		return nil, err
+	}
+
+	view.RoleID = data.RoleID
+	view.Resource = data.Resource
+	view.Name = data.Name
+	view.Request = models.ViewRequest(storable(data.Request))
+	view.IsDefault = data.IsDefault
+
+	err = s.ExecuteTx(ctx, func(tx *gorm.DB) error {
+		if view.IsDefault {
+			if err := s.writer.ClearDefault(ctx, tx, userID, view.Resource); err != nil {
+				return err
+			}
+		}
+		return s.writer.Update(ctx, tx, view)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+func (s *savedViewService) Delete(ctx context.Context, userID, id uint64) error {
+	if _, err := s.ownView(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.writer.SoftDelete(ctx, s.conn.ConnectGormWrite, id)
+}
+
+func (s *savedViewService) SetDefault(ctx context.Context, userID, id uint64) (*models.SavedView, error) {
+	view, err := s.ownView(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	view.IsDefault = true
+	err = s.ExecuteTx(ctx, func(tx *gorm.DB) error {
+		if err := s.writer.ClearDefault(ctx, tx, userID, view.Resource); err != nil {
+			return err
+		}
+		return s.writer.Update(ctx, tx, view)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+func (s *savedViewService) Apply(ctx context.Context, userID uint64, resource string, req dtos.PaginationRequest) (dtos.PaginationRequest, error) {
+	if req.ViewID == 0 {
+		return req, nil
+	}
+	view, err := s.GetByID(ctx, userID, req.ViewID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return req, domain.NewValidationError(map[string][]string{"view_id": {"La vista no existe o no está disponible."}})
+		}
+		return req, err
+	}
+	if view.Resource != resource {
+		return req, domain.NewValidationError(map[string][]string{"view_id": {"La vista pertenece a otro recurso."}})
+	}
+	return pagination.MergeRequest(dtos.PaginationRequest(view.Request), req), nil
+}
+
+// ownView devuelve la vista solo si pertenece al usuario: las compartidas no se modifican.
+func (s *savedViewService) ownView(ctx context.Context, userID, id uint64) (*models.SavedView, error) {
+	view, err := s.reader.GetVisibleByID(ctx, s.conn.ConnectGormWrite, id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && view.UserID != userID) {
+		return nil, domain.ErrNotFound
+	}
+	return view, err
+}
+
+// validate comprueba el recurso, el nombre (único por usuario y recurso, salvo la vista
+// excludeID que se está editando), la solicitud guardada y el rol con el que se comparte.
+func (s *savedViewService) validate(ctx context.Context, userID, excludeID uint64, data SaveViewDTO) error {
+	validateRequest, ok := s.validators[data.Resource]
+	if !ok {
+		return unknownResource(data.Resource)
+	}
+	taken, err := s.reader.NameTaken(ctx, s.conn.ConnectGormRead, userID, data.Resource, data.Name, excludeID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return domain.NewValidationError(map[string][]string{"name": {"Ya tiene una vista con ese nombre en este recurso."}})
+	}
+	if data.Request.ViewID != 0 {
+		return domain.NewValidationError(map[string][]string{"request.view_id": {"Una vista no puede referenciar a otra vista."}})
+	}
+	if err := validateRequest(data.Request); err != nil {
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			fields := make(map[string][]string, len(validationErr.Fields))
+			for key, messages := range validationErr.Fields {
+				fields["request."+key] = messages
+			}
+			return domain.NewValidationError(fields)
+		}
+		return err
+	}
+	if data.RoleID != nil {
+		hasRole, err := s.reader.UserHasRole(ctx, s.conn.ConnectGormRead, userID, *data.RoleID)
+		if err != nil {
+			return err
+		}
+		if !hasRole {
+			return domain.NewValidationError(map[string][]string{"role_id": {"Solo se puede compartir la vista con un rol propio."}})
+		}
+	}
+	return nil
+}
+
+// storable descarta lo que no tiene sentido guardar en una vista (página y cursor).
+func storable(req dtos.PaginationRequest) dtos.PaginationRequest {
+	req.Page = 0
+	req.Mode = ""
+	req.Cursor = ""
+	req.ViewID = 0
+	return req
+}
+
+func unknownResource(resource string) error {
+	return domain.NewValidationError(map[string][]string{
+		"resource": {fmt.Sprintf("El recurso '%s' no admite vistas guardadas.", resource)},
+	})
+}
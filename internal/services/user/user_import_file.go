@@ -0,0 +1,213 @@
+package user
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go-fiber-core/internal/domain"
+)
+
+// maxImportXMLSize limita lo que se descomprime de cada parte del XLSX (evita zip bombs).
+const maxImportXMLSize = 20 << 20
+
+// importRecord es una fila del archivo con su número de fila (el encabezado es la 1).
+type importRecord struct {
+	row    int
+	values []string
+}
+
+// readImportFile lee un CSV o XLSX (según la extensión) y devuelve el encabezado y las
+// filas con datos. Las filas vacías se omiten.
+func readImportFile(filename string, content []byte) ([]string, []importRecord, error) {
+	var records []importRecord
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, err = readCSV(content)
+	case ".xlsx":
+		records, err = readXLSX(content)
+	default:
+		return nil, nil, fileError("El archivo debe ser CSV o XLSX.")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, fileError("El archivo está vacío.")
+	}
+
+	rows := make([]importRecord, 0, len(records)-1)
+	for _, record := range records[1:] {
+		if !isBlankRecord(record.values) {
+			rows = append(rows, record)
+		}
+	}
+	return records[0].values, rows, nil
+}
+
+func fileError(message string) error {
+	return domain.NewValidationError(map[string][]string{"file": {message}})
+}
+
+func isBlankRecord(values []string) bool {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// --- CSV ---
+
+// readCSV acepta coma o punto y coma como separador (Excel en español exporta con ";").
+func readCSV(content []byte) ([]importRecord, error) {
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+	firstLine, _, _ := bytes.Cut(content, []byte("\n"))
+
+	r := csv.NewReader(bytes.NewReader(content))
+	r.FieldsPerRecord = -1
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	var records []importRecord
+	for {
+		values, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fileError(fmt.Sprintf("El CSV no es válido: %v", err))
+		}
+		line, _ := r.FieldPos(0)
+		records = append(records, importRecord{row: line, values: values})
+	}
+}
+
+// --- XLSX ---
+
+// xlsxText es el texto de una celda, simple o con formato (varios runs).
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX lee la primera hoja del libro (xl/worksheets/sheet1.xml).
+func readXLSX(content []byte) ([]importRecord, error) {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fileError("El XLSX no es válido.")
+	}
+
+	var shared struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if err := decodeZipXML(zr, "xl/sharedStrings.xml", &shared); err != nil && !errors.Is(err, errZipPartMissing) {
+		return nil, err
+	}
+	var sheet xlsxSheet
+	if err := decodeZipXML(zr, "xl/worksheets/sheet1.xml", &sheet); err != nil {
+		if errors.Is(err, errZipPartMissing) {
+			return nil, fileError("El XLSX no tiene hojas.")
+		}
+		return nil, err
+	}
+
+	records := make([]importRecord, 0, len(sheet.Rows))
+	for i, row := range sheet.Rows {
+		number := row.Number
+		if number == 0 {
+			number = i + 1
+		}
+		var values []string
+		for j, cell := range row.Cells {
+			col := columnIndex(cell.Ref)
+			if col < 0 {
+				col = j
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fileError(fmt.Sprintf("El XLSX tiene una celda inválida en la fila %d.", number))
+				}
+				values[col] = shared.Items[idx].String()
+			case "inlineStr":
+				values[col] = cell.Inline.String()
+			default:
+				values[col] = cell.Value
+			}
+		}
+		records = append(records, importRecord{row: number, values: values})
+	}
+	return records, nil
+}
+
+var errZipPartMissing = errors.New("parte del zip inexistente")
+
+func decodeZipXML(zr *zip.Reader, name string, v any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return errZipPartMissing
+	}
+	defer f.Close()
+
+	limited := &io.LimitedReader{R: f, N: maxImportXMLSize + 1}
+	if err := xml.NewDecoder(limited).Decode(v); err != nil {
+		if limited.N <= 0 {
+			return fileError("El XLSX es demasiado grande.")
+		}
+		return fileError("El XLSX no es válido.")
+	}
+	return nil
+}
+
+// columnIndex convierte la referencia de una celda ("C12") en su columna desde 0; -1 si
+// no tiene referencia.
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
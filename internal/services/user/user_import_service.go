@@ -0,0 +1,476 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/dtos/requests"
+	"go-fiber-core/internal/dtos/responses"
+	"go-fiber-core/internal/models"
+	permissionRepo "go-fiber-core/internal/repositories/permission"
+	roleRepo "go-fiber-core/internal/repositories/role"
+	userRepo "go-fiber-core/internal/repositories/user"
+	"go-fiber-core/internal/services"
+	"go-fiber-core/internal/utils"
+
+	redis "github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// ImportMaxRows es la cantidad máxima de filas de un archivo de importación.
+	ImportMaxRows = 5000
+	// importSyncRows es el límite para importar en la misma petición; los archivos más
+	// grandes se importan en segundo plano (el hash de cada contraseña lleva su tiempo).
+	importSyncRows = 50
+
+	importJobKeyPrefix = "users:import:job:"
+	importJobTTL       = 24 * time.Hour
+	importJobTimeout   = 15 * time.Minute
+	invitationTimeout  = 30 * time.Second
+)
+
+// importColumns mapea los encabezados admitidos (en minúsculas) al campo de la fila.
+var importColumns = map[string]string{
+	"name":       "name",
+	"nombre":     "name",
+	"email":      "email",
+	"correo":     "email",
+	"roles":      "roles",
+	"password":   "password",
+	"contraseña": "password",
+}
+
+// UserImportService da de alta usuarios en lote desde un CSV/XLSX con las columnas name,
+// email, roles (nombres separados por coma, punto y coma o "|") y password (opcional).
+//
+// Todas las filas se validan antes de escribir: si alguna tiene errores no se crea ningún
+// usuario. Los usuarios se crean en una única transacción, con sus roles (y por ellos sus
+// menús), y cada uno recibe un email de invitación con un enlace de un solo uso para
+// elegir su contraseña (ninguna contraseña viaja por email).
+type UserImportService interface {
+	// Import procesa el archivo. Con dryRun solo valida. Los archivos de más de
+	// importSyncRows filas se importan en segundo plano: el resultado trae el JobID.
+	Import(ctx context.Context, actorID uint64, filename string, content []byte, dryRun bool) (*responses.UserImportResult, error)
+	// Status devuelve el resultado de una importación en segundo plano (domain.ErrNotFound
+	// si no existe, ya venció o la inició otro usuario).
+	Status(ctx context.Context, actorID uint64, jobID string) (*responses.UserImportResult, error)
+}
+
+// Inviter envía a un usuario recién creado el enlace de un solo uso para elegir su
+// contraseña. Lo implementa account.AccountService; la interfaz vive acá porque el paquete
+// account depende de este.
+type Inviter interface {
+	SendInvitation(ctx context.Context, user *models.User) error
+}
+
+type userImportService struct {
+	services.TransactionManager
+	conn        *connect.ConnectDTO
+	userReader  userRepo.UserReader
+	userWriter  userRepo.UserWriter
+	roleReader  roleRepo.RoleReader
+	roleWriter  roleRepo.RoleWriter
+	permissions permissionRepo.PermissionReader
+	inviter     Inviter
+	syncRows    int
+	now         func() time.Time
+	// run ejecuta el job y los envíos de email; en producción en una goroutine.
+	run func(fn func())
+}
+
+// plannedUser es una fila válida lista para crear.
+type plannedUser struct {
+	id       uint64 // Se completa al crearlo
+	row      int
+	name     string
+	email    string
+	password string
+	roleIDs  []uint64
+}
+
+func NewUserImportService(
+	conn *connect.ConnectDTO,
+	userReader userRepo.UserReader,
+	userWriter userRepo.UserWriter,
+	roleReader roleRepo.RoleReader,
+	roleWriter roleRepo.RoleWriter,
+	permissions permissionRepo.PermissionReader,
+	inviter Inviter,
+) UserImportService {
+	return &userImportService{
+		TransactionManager: services.NewTransactionManager(conn),
+		conn:               conn,
+		userReader:         userReader,
+		userWriter:         userWriter,
+		roleReader:         roleReader,
+		roleWriter:         roleWriter,
+		permissions:        permissions,
+		inviter:            inviter,
+		syncRows:           importSyncRows,
+		now:                time.Now,
+		run:                func(fn func()) { go fn() },
+	}
+}
+
+func (s *userImportService) Import(ctx context.Context, actorID uint64, filename string, content []byte, dryRun bool) (*responses.UserImportResult, error) {
+	header, records, err := readImportFile(filename, content)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := mapImportColumns(header)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fileError("El archivo no tiene filas con datos.")
+	}
+	if len(records) > ImportMaxRows {
+		return nil, fileError(fmt.Sprintf("El archivo no puede tener más de %d filas.", ImportMaxRows))
+	}
+
+	planned, rowErrors, err := s.validateRows(ctx, actorID, columns, records)
+	if err != nil {
+		return nil, err
+	}
+	result := &responses.UserImportResult{
+		Status: responses.UserImportValidated,
+		DryRun: dryRun,
+		Total:  len(records),
+		Valid:  len(planned),
+		Errors: rowErrors,
+	}
+	if dryRun {
+		return result, nil
+	}
+	if len(rowErrors) > 0 {
+		result.Status = responses.UserImportRejected
+		return result, nil
+	}
+
+	// Sin Redis no hay dónde guardar el estado del job: se importa en la petición.
+	if len(planned) > s.syncRows && s.conn.ConnectRedis != nil {
+		return s.startJob(ctx, actorID, result, planned)
+	}
+
+	if err := s.commit(ctx, planned); err != nil {
+		return nil, err
+	}
+	s.finish(result, planned)
+	log.Printf("Usuario %d importó %d usuarios", actorID, result.Created)
+	return result, nil
+}
+
+func (s *userImportService) Status(ctx context.Context, actorID uint64, jobID string) (*responses.UserImportResult, error) {
+	if s.conn.ConnectRedis == nil {
+		return nil, domain.ErrNotFound
+	}
+	payload, err := s.conn.ConnectRedis.Get(ctx, importJobKeyPrefix+jobID).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("error al leer la importación: %w", err)
+	}
+	var job importJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return nil, fmt.Errorf("error al leer la importación: %w", err)
+	}
+	// El resultado trae los errores por fila (emails incluidos): solo lo ve quien importó.
+	if job.ActorID != actorID {
+		return nil, domain.ErrNotFound
+	}
+	return &job.Result, nil
+}
+
+// mapImportColumns ubica cada campo en el encabezado; name y email son obligatorios.
+func mapImportColumns(header []string) (map[string]int, error) {
+	columns := make(map[string]int)
+	for i, title := range header {
+		field, ok := importColumns[strings.ToLower(strings.TrimSpace(title))]
+		if !ok {
+			continue
+		}
+		if _, repeated := columns[field]; repeated {
+			return nil, fileError(fmt.Sprintf("La columna %q está repetida.", field))
+		}
+		columns[field] = i
+	}
+	var missing []string
+	for _, field := range []string{"name", "email"} {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fileError("Faltan las columnas: " + strings.Join(missing, ", ") + ".")
+	}
+	return columns, nil
+}
+
+// validateRows valida cada fila con el validador de la API y contra la base (emails
+// registrados, roles existentes) y devuelve las filas listas para crear y los errores.
+//
+// Asignar roles exige el permiso roles.assign, y solo se asignan roles cuyos permisos tiene
+// el actor (como al suplantar): importar no sirve para crear cuentas con más privilegios.
+func (s *userImportService) validateRows(ctx context.Context, actorID uint64, columns map[string]int, records []importRecord) ([]plannedUser, []responses.UserImportRowError, error) {
+	rows := make([]requests.ImportUserRow, len(records))
+	var emails, roleNames []string
+	for i, record := range records {
+		rows[i] = parseImportRow(columns, record.values)
+		emails = append(emails, rows[i].Email)
+		roleNames = append(roleNames, rows[i].Roles...)
+	}
+
+	dbRead := s.conn.ConnectGormRead
+	registered, err := s.userReader.ExistingEmails(ctx, dbRead, emails)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al verificar los emails: %w", err)
+	}
+	taken := make(map[string]bool, len(registered))
+	for _, email := range registered {
+		taken[email] = true
+	}
+	roleIDs := make(map[string]uint64)
+	exceeding := make(map[string]bool)
+	if len(roleNames) > 0 {
+		actorPermissions, err := s.permissions.GetNamesByUserID(ctx, dbRead, actorID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error al obtener los permisos del usuario: %w", err)
+		}
+		if !slices.Contains(actorPermissions, "roles.assign") {
+			return nil, nil, domain.ErrForbidden
+		}
+		roles, err := s.roleReader.GetByNames(ctx, dbRead, roleNames)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error al buscar los roles: %w", err)
+		}
+		for _, role := range roles {
+			key := strings.ToLower(role.Name)
+			roleIDs[key] = role.ID
+			for _, permission := range role.Permissions {
+				if !slices.Contains(actorPermissions, permission.Name) {
+					exceeding[key] = true
+				}
+			}
+		}
+	}
+
+	planned := make([]plannedUser, 0, len(rows))
+	rowErrors := []responses.UserImportRowError{}
+	firstRowByEmail := make(map[string]int)
+	for i, row := range rows {
+		fieldErrors := utils.ValidateStruct(&row)
+		if fieldErrors == nil {
+			fieldErrors = make(map[string][]string)
+		}
+		addError := func(field, message string) {
+			fieldErrors[field] = append(fieldErrors[field], message)
+		}
+
+		if row.Password != "" && fieldErrors["password"] == nil && !utils.IsStrongPassword(row.Password) {
+			addError("password", utils.PasswordStrengthMessage)
+		}
+		if key := strings.ToLower(row.Email); key != "" {
+			if first, repeated := firstRowByEmail[key]; repeated {
+				addError("email", fmt.Sprintf("El email está repetido en la fila %d.", first))
+			} else {
+				firstRowByEmail[key] = records[i].row
+			}
+			if taken[key] {
+				addError("email", "El email ya está registrado.")
+			}
+		}
+		ids := make([]uint64, 0, len(row.Roles))
+		for _, name := range row.Roles {
+			id, ok := roleIDs[strings.ToLower(name)]
+			if !ok {
+				addError("roles", fmt.Sprintf("El rol %q no existe.", name))
+				continue
+			}
+			if exceeding[strings.ToLower(name)] {
+				addError("roles", fmt.Sprintf("No podés asignar el rol %q: tiene permisos que no tenés.", name))
+				continue
+			}
+			ids = append(ids, id)
+		}
+
+		if len(fieldErrors) > 0 {
+			rowErrors = append(rowErrors, responses.UserImportRowError{Row: records[i].row, Email: row.Email, Errors: fieldErrors})
+			continue
+		}
+		planned = append(planned, plannedUser{
+			row:      records[i].row,
+			name:     row.Name,
+			email:    row.Email,
+			password: row.Password,
+			roleIDs:  ids,
+		})
+	}
+	return planned, rowErrors, nil
+}
+
+func parseImportRow(columns map[string]int, values []string) requests.ImportUserRow {
+	cell := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(values) {
+			return ""
+		}
+		return strings.TrimSpace(values[i])
+	}
+
+	var roles []string
+	for _, name := range strings.FieldsFunc(cell("roles"), func(r rune) bool { return r == ',' || r == ';' || r == '|' }) {
+		if name = strings.TrimSpace(name); name != "" {
+			roles = append(roles, name)
+		}
+	}
+	return requests.ImportUserRow{
+		Name:     cell("name"),
+		Email:    cell("email"),
+		Roles:    roles,
+		Password: cell("password"),
+	}
+}
+
+// commit crea todos los usuarios y sus roles en una transacción. Las filas sin contraseña
+// reciben una aleatoria que nadie conoce: el usuario elige la suya con la invitación.
+func (s *userImportService) commit(ctx context.Context, planned []plannedUser) error {
+	// Los hashes se calculan antes de abrir la transacción para no alargarla.
+	hashes := make([]string, len(planned))
+	for i := range planned {
+		if planned[i].password == "" {
+			password, err := randomPassword()
+			if err != nil {
+				return err
+			}
+			planned[i].password = password
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(planned[i].password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		hashes[i] = string(hash)
+	}
+
+	return s.ExecuteTx(ctx, func(tx *gorm.DB) error {
+		for i, p := range planned {
+			user := &models.User{Name: p.name, Email: p.email, Password: hashes[i], IsActive: true}
+			if err := s.userWriter.Create(ctx, tx, user); err != nil {
+				return fmt.Errorf("error al crear el usuario de la fila %d: %w", p.row, err)
+			}
+			planned[i].id = user.ID
+			if len(p.roleIDs) == 0 {
+				continue
+			}
+			if err := s.roleWriter.AddBulkUsers(ctx, tx, p.roleIDs, []uint64{user.ID}); err != nil {
+				return fmt.Errorf("error al asignar los roles de la fila %d: %w", p.row, err)
+			}
+		}
+		return nil
+	})
+}
+
+// finish completa el resultado de una importación confirmada y envía las invitaciones.
+func (s *userImportService) finish(result *responses.UserImportResult, planned []plannedUser) {
+	finishedAt := s.now()
+	result.Status = responses.UserImportCompleted
+	result.Created = len(planned)
+	result.FinishedAt = &finishedAt
+	s.sendInvitations(planned)
+}
+
+// startJob guarda el estado inicial y lanza la importación en segundo plano.
+func (s *userImportService) startJob(ctx context.Context, actorID uint64, result *responses.UserImportResult, planned []plannedUser) (*responses.UserImportResult, error) {
+	jobID, err := newImportJobID()
+	if err != nil {
+		return nil, err
+	}
+	result.JobID = jobID
+	result.Status = responses.UserImportProcessing
+	if err := s.saveJob(ctx, actorID, result); err != nil {
+		return nil, err
+	}
+
+	job := *result
+	s.run(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), importJobTimeout)
+		defer cancel()
+
+		if err := s.commit(ctx, planned); err != nil {
+			log.Printf("Error en la importación de usuarios %s: %v", jobID, err)
+			finishedAt := s.now()
+			job.Status = responses.UserImportFailed
+			job.Message = "No se pudo completar la importación; no se creó ningún usuario."
+			job.FinishedAt = &finishedAt
+		} else {
+			s.finish(&job, planned)
+			log.Printf("Usuario %d importó %d usuarios (job %s)", actorID, job.Created, jobID)
+		}
+		if err := s.saveJob(ctx, actorID, &job); err != nil {
+			log.Printf("Error al guardar el resultado de la importación %s: %v", jobID, err)
+		}
+	})
+	return result, nil
+}
+
+// importJob es lo que se guarda en Redis: el resultado y quién inició la importación.
+type importJob struct {
+	ActorID uint64                     `json:"actor_id"`
+	Result  responses.UserImportResult `json:"result"`
+}
+
+func (s *userImportService) saveJob(ctx context.Context, actorID uint64, result *responses.UserImportResult) error {
+	payload, err := json.Marshal(importJob{ActorID: actorID, Result: *result})
+	if err != nil {
+		return err
+	}
+	if err := s.conn.ConnectRedis.Set(ctx, importJobKeyPrefix+result.JobID, payload, importJobTTL).Err(); err != nil {
+		return fmt.Errorf("error al guardar la importación: %w", err)
+	}
+	return nil
+}
+
+// sendInvitations envía en segundo plano el email de bienvenida con el enlace para elegir
+// la contraseña. Los errores solo se registran: los usuarios ya están creados.
+func (s *userImportService) sendInvitations(planned []plannedUser) {
+	s.run(func() {
+		for _, p := range planned {
+			ctx, cancel := context.WithTimeout(context.Background(), invitationTimeout)
+			err := s.inviter.SendInvitation(ctx, &models.User{ID: p.id, Name: p.name, Email: p.email})
+			cancel()
+			if err != nil {
+				log.Printf("Error al enviar la invitación a %s: %v", p.email, err)
+			}
+		}
+	})
+}
+
+// randomPassword genera una contraseña que cumple utils.IsStrongPassword.
+func randomPassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("no se pudo generar la contraseña: %w", err)
+	}
+	return "Tmp-" + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func newImportJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("no se pudo generar el ID de la importación: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
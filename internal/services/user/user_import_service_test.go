@@ -0,0 +1,339 @@
+package user
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/dtos/responses"
+	"go-fiber-core/internal/models"
+	permissionRepo "go-fiber-core/internal/repositories/permission"
+	roleRepo "go-fiber-core/internal/repositories/role"
+	"go-fiber-core/internal/utils"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	redis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func (f *fakeUsers) ExistingEmails(_ context.Context, _ *gorm.DB, emails []string) ([]string, error) {
+	var found []string
+	for _, u := range f.users {
+		for _, email := range emails {
+			if strings.EqualFold(u.Email, email) {
+				found = append(found, strings.ToLower(u.Email))
+			}
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeUsers) Create(_ context.Context, _ *gorm.DB, user *models.User) error {
+	user.ID = uint64(len(f.users) + 100)
+	f.users[user.ID] = user
+	return nil
+}
+
+// fakeRoles tiene los roles "Cajero" (1), "Supervisor" (2) y "Auditor" (3) y registra las
+// asignaciones.
+type fakeRoles struct {
+	roleRepo.RoleReader
+	roleRepo.RoleWriter
+	assigned map[uint64][]uint64
+}
+
+func (f *fakeRoles) GetByNames(_ context.Context, _ *gorm.DB, names []string) ([]models.Role, error) {
+	var roles []models.Role
+	permissions := func(names ...string) []models.Permission {
+		var out []models.Permission
+		for _, name := range names {
+			out = append(out, models.Permission{Name: name})
+		}
+		return out
+	}
+	for _, role := range []models.Role{
+		{ID: 1, Name: "Cajero", Permissions: permissions("banks.read")},
+		{ID: 2, Name: "Supervisor", Permissions: permissions("banks.read", "users.read")},
+		{ID: 3, Name: "Auditor", Permissions: permissions("audit.read")},
+	} {
+		for _, name := range names {
+			if strings.EqualFold(role.Name, name) {
+				roles = append(roles, role)
+				break
+			}
+		}
+	}
+	return roles, nil
+}
+
+func (f *fakeRoles) AddBulkUsers(_ context.Context, _ *gorm.DB, roleIDs, userIDs []uint64) error {
+	for _, userID := range userIDs {
+		f.assigned[userID] = append(f.assigned[userID], roleIDs...)
+	}
+	return nil
+}
+
+// fakeActorPermissions: el actor 1 puede asignar roles (y tiene los permisos de Cajero y
+// Supervisor); el 2 no puede asignar roles.
+type fakeActorPermissions struct {
+	permissionRepo.PermissionReader
+}
+
+func (fakeActorPermissions) GetNamesByUserID(_ context.Context, _ *gorm.DB, userID uint64) ([]string, error) {
+	if userID == 1 {
+		return []string{"users.import", "roles.assign", "banks.read", "users.read"}, nil
+	}
+	return []string{"users.import"}, nil
+}
+
+// fakeInviter registra a quién se invitó, por email.
+type fakeInviter struct {
+	sent map[string]*models.User
+}
+
+func (f *fakeInviter) SendInvitation(_ context.Context, user *models.User) error {
+	f.sent[user.Email] = user
+	return nil
+}
+
+type importFixture struct {
+	service *userImportService
+	users   *fakeUsers
+	roles   *fakeRoles
+	inviter *fakeInviter
+	db      sqlmock.Sqlmock
+	jobs    []func()
+}
+
+// newTestUserImport arma el servicio con una base simulada (solo la transacción), Redis en
+// memoria y los jobs en una cola que el test ejecuta cuando quiere.
+func newTestUserImport(t *testing.T) *importFixture {
+	utils.SetupValidator(nil)
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	require.NoError(t, err)
+	redisClient := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+
+	f := &importFixture{
+		users:   &fakeUsers{users: map[uint64]*models.User{7: {ID: 7, Name: "Ana", Email: "ana@test.com"}}},
+		roles:   &fakeRoles{assigned: map[uint64][]uint64{}},
+		inviter: &fakeInviter{sent: map[string]*models.User{}},
+		db:      mock,
+	}
+	conn := &connect.ConnectDTO{ConnectGormRead: gormDB, ConnectGormWrite: gormDB, ConnectRedis: redisClient}
+	f.service = NewUserImportService(conn, f.users, f.users, f.roles, f.roles, fakeActorPermissions{}, f.inviter).(*userImportService)
+	f.service.run = func(fn func()) { f.jobs = append(f.jobs, fn) }
+	return f
+}
+
+func (f *importFixture) runJobs() {
+	for len(f.jobs) > 0 {
+		job := f.jobs[0]
+		f.jobs = f.jobs[1:]
+		job()
+	}
+}
+
+func TestUserImport_DryRunReportsRowErrors(t *testing.T) {
+	f := newTestUserImport(t)
+	csv := "name;email;roles;password\n" +
+		"Bruno;bruno@test.com;cajero, Supervisor;\n" +
+		";no-es-email;;\n" +
+		"Carla;BRUNO@test.com;;\n" +
+		"Dani;ana@test.com;Gerente;\n" +
+		"Eva;eva@test.com;;abcdefgh\n"
+
+	result, err := f.service.Import(context.Background(), 1, "alta.csv", []byte(csv), true)
+
+	require.NoError(t, err)
+	assert.Equal(t, responses.UserImportValidated, result.Status)
+	assert.Equal(t, 5, result.Total)
+	assert.Equal(t, 1, result.Valid)
+	require.Len(t, result.Errors, 4)
+	byRow := make(map[int]map[string][]string)
+	for _, rowErr := range result.Errors {
+		byRow[rowErr.Row] = rowErr.Errors
+	}
+	assert.Contains(t, byRow[3], "name")
+	assert.Contains(t, byRow[3], "email")
+	assert.Equal(t, []string{"El email está repetido en la fila 2."}, byRow[4]["email"])
+	assert.Equal(t, []string{"El email ya está registrado."}, byRow[5]["email"])
+	assert.Equal(t, []string{`El rol "Gerente" no existe.`}, byRow[5]["roles"])
+	assert.Equal(t, []string{utils.PasswordStrengthMessage}, byRow[6]["password"])
+
+	// Nada se escribe ni se envía.
+	assert.Len(t, f.users.users, 1)
+	f.runJobs()
+	assert.Empty(t, f.inviter.sent)
+}
+
+func TestUserImport_RoleAssignmentNeedsPermissions(t *testing.T) {
+	f := newTestUserImport(t)
+	ctx := context.Background()
+
+	// Sin roles.assign no se pueden asignar roles, pero sí importar usuarios sin roles.
+	_, err := f.service.Import(ctx, 2, "alta.csv", []byte("name,email,roles\nBruno,bruno@test.com,Cajero\n"), true)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	result, err := f.service.Import(ctx, 2, "alta.csv", []byte("name,email,roles\nBruno,bruno@test.com,\n"), true)
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+
+	// Un rol con permisos que el actor no tiene se rechaza en la fila.
+	result, err = f.service.Import(ctx, 1, "alta.csv", []byte("name,email,roles\nBruno,bruno@test.com,Cajero|Auditor\n"), true)
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, []string{`No podés asignar el rol "Auditor": tiene permisos que no tenés.`}, result.Errors[0].Errors["roles"])
+}
+
+func TestUserImport_RejectsFileWithErrors(t *testing.T) {
+	f := newTestUserImport(t)
+	csv := "name,email\nBruno,bruno@test.com\nDani,ana@test.com\n"
+
+	result, err := f.service.Import(context.Background(), 1, "alta.csv", []byte(csv), false)
+
+	require.NoError(t, err)
+	assert.Equal(t, responses.UserImportRejected, result.Status)
+	assert.Zero(t, result.Created)
+	assert.Len(t, f.users.users, 1)
+	assert.NoError(t, f.db.ExpectationsWereMet())
+}
+
+func TestUserImport_CommitsInTransactionAndInvites(t *testing.T) {
+	f := newTestUserImport(t)
+	f.db.ExpectBegin()
+	f.db.ExpectCommit()
+	csv := "Nombre,Correo,Roles,Contraseña\n" +
+		"Bruno,bruno@test.com,Cajero|Supervisor,Clave-Temporal-1\n" +
+		"Carla,carla@test.com,,\n"
+
+	result, err := f.service.Import(context.Background(), 1, "alta.csv", []byte(csv), false)
+
+	require.NoError(t, err)
+	assert.Equal(t, responses.UserImportCompleted, result.Status)
+	assert.Equal(t, 2, result.Created)
+	assert.NoError(t, f.db.ExpectationsWereMet())
+
+	created := make(map[string]*models.User)
+	for _, u := range f.users.users {
+		created[u.Email] = u
+	}
+	require.Contains(t, created, "bruno@test.com")
+	require.Contains(t, created, "carla@test.com")
+	assert.True(t, created["bruno@test.com"].IsActive)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(created["bruno@test.com"].Password), []byte("Clave-Temporal-1")))
+	assert.Equal(t, []uint64{1, 2}, f.roles.assigned[created["bruno@test.com"].ID])
+	assert.Empty(t, f.roles.assigned[created["carla@test.com"].ID])
+
+	f.runJobs()
+	// Cada usuario creado recibe la invitación (con su ID, para el enlace de un solo uso).
+	require.Len(t, f.inviter.sent, 2)
+	assert.Equal(t, created["bruno@test.com"].ID, f.inviter.sent["bruno@test.com"].ID)
+	assert.Equal(t, created["carla@test.com"].ID, f.inviter.sent["carla@test.com"].ID)
+	// Sin contraseña en el archivo se guarda una aleatoria que cumple la política.
+	assert.NotEmpty(t, created["carla@test.com"].Password)
+	generated, err := randomPassword()
+	require.NoError(t, err)
+	assert.True(t, utils.IsStrongPassword(generated))
+}
+
+func TestUserImport_LargeFileRunsAsJob(t *testing.T) {
+	ctx := context.Background()
+	f := newTestUserImport(t)
+	f.service.syncRows = 2
+	f.db.ExpectBegin()
+	f.db.ExpectCommit()
+	var b strings.Builder
+	b.WriteString("name,email,roles,password\n")
+	for i := range 3 {
+		fmt.Fprintf(&b, "Usuario %d,u%d@test.com,Cajero,Clave-Temporal-%d\n", i, i, i)
+	}
+
+	result, err := f.service.Import(ctx, 1, "alta.csv", []byte(b.String()), false)
+
+	require.NoError(t, err)
+	assert.Equal(t, responses.UserImportProcessing, result.Status)
+	require.NotEmpty(t, result.JobID)
+	status, err := f.service.Status(ctx, 1, result.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, responses.UserImportProcessing, status.Status)
+	assert.Len(t, f.users.users, 1)
+
+	f.runJobs()
+
+	status, err = f.service.Status(ctx, 1, result.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, responses.UserImportCompleted, status.Status)
+	assert.Equal(t, 3, status.Created)
+	assert.NotNil(t, status.FinishedAt)
+	assert.Len(t, f.users.users, 4)
+	assert.Len(t, f.inviter.sent, 3)
+
+	_, err = f.service.Status(ctx, 1, "no-existe")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// Otro usuario con users.import no ve el job (ni sabe que existe).
+	_, err = f.service.Status(ctx, 2, result.JobID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestUserImport_InvalidFile(t *testing.T) {
+	f := newTestUserImport(t)
+	cases := map[string]struct {
+		filename, content string
+	}{
+		"extensión":     {"alta.txt", "name,email\nBruno,bruno@test.com\n"},
+		"sin columnas":  {"alta.csv", "nombre completo,mail\nBruno,bruno@test.com\n"},
+		"sin filas":     {"alta.csv", "name,email\n,\n"},
+		"xlsx corrupto": {"alta.xlsx", "no es un zip"},
+		"columna doble": {"alta.csv", "name,nombre,email\nBruno,Bruno,bruno@test.com\n"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := f.service.Import(context.Background(), 1, tc.filename, []byte(tc.content), true)
+
+			var validationErr *domain.ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			assert.Contains(t, validationErr.Fields, "file")
+		})
+	}
+}
+
+func TestReadImportFile_XLSX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/sharedStrings.xml": `<sst><si><t>name</t></si><si><t>email</t></si><si><r><t>Bru</t></r><r><t>no</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="inlineStr"><is><t>roles</t></is></c></row>` +
+			`<row r="3"><c r="A3" t="s"><v>2</v></c><c r="B3" t="inlineStr"><is><t>bruno@test.com</t></is></c><c r="D3" t="str"><v>Cajero</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	header, rows, err := readImportFile("ALTA.XLSX", buf.Bytes())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "email", "", "roles"}, header)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 3, rows[0].row)
+	assert.Equal(t, []string{"Bruno", "bruno@test.com", "", "Cajero"}, rows[0].values)
+}
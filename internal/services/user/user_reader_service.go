@@ -7,13 +7,18 @@ import (
 	"go-fiber-core/internal/dtos/connect"
 	"go-fiber-core/internal/models"
 	userRepo "go-fiber-core/internal/repositories/user"
+	"go-fiber-core/internal/services/export"
+	"go-fiber-core/internal/services/pagination"
 )
 
 // UserReaderService define la interfaz para las operaciones de lectura de usuarios.
 type UserReaderService interface {
 	GetByID(ctx context.Context, id uint64) (*models.User, error)
+	// GetByIDSparse aplica fields/include. Sin parámetros mantiene la carga de Roles de GetByID.
+	GetByIDSparse(ctx context.Context, id uint64, fields, include []string) (*dtos.Sparse, error)
 	GetAll(ctx context.Context) ([]models.User, error)
 	GetAllPaginated(ctx context.Context, req dtos.PaginationRequest) (*dtos.PaginationResponse[models.User], error)
+	Export(ctx context.Context, req dtos.ExportRequest) (export.StreamFunc, error)
 }
 
 type userReaderService struct {
@@ -39,6 +44,25 @@ func (s *userReaderService) GetByID(ctx context.Context, id uint64) (*models.Use
 	return s.userReader.GetByID(ctx, s.conn.ConnectGormRead, id)
 }
 
+func (s *userReaderService) GetByIDSparse(ctx context.Context, id uint64, fields, include []string) (*dtos.Sparse, error) {
+	selection, err := pagination.ParseSelection[models.User](fields, include)
+	if err != nil {
+		return nil, err
+	}
+	if selection == nil {
+		user, err := s.userReader.GetByID(ctx, s.conn.ConnectGormRead, id)
+		if err != nil {
+			return nil, err
+		}
+		return &dtos.Sparse{Value: user}, nil
+	}
+	user, err := s.userReader.GetByIDWithModifier(ctx, s.conn.ConnectGormRead, id, selection.Modifier(nil))
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.Sparse{Value: user, Fields: selection.ResponseKeys()}, nil
+}
+
 func (s *userReaderService) GetAll(ctx context.Context) ([]models.User, error) {
 	return s.userReader.GetAll(ctx, s.conn.ConnectGormRead)
 }
@@ -46,3 +70,11 @@ func (s *userReaderService) GetAll(ctx context.Context) ([]models.User, error) {
 func (s *userReaderService) GetAllPaginated(ctx context.Context, req dtos.PaginationRequest) (*dtos.PaginationResponse[models.User], error) {
 	return s.userPaginator.GetAllPaginated(ctx, s.conn.ConnectGormRead, req)
 }
+
+// Export recorre los usuarios filtrados en lotes por ID y los escribe como CSV/XLSX.
+func (s *userReaderService) Export(ctx context.Context, req dtos.ExportRequest) (export.StreamFunc, error) {
+	filters := export.FilterRequest(req)
+	return export.Prepare(ctx, req, func(lastProcessedID uint) ([]models.User, error) {
+		return s.userPaginator.GetFilteredBatch(ctx, s.conn.ConnectGormRead, filters, export.BatchSize, lastProcessedID)
+	})
+}
@@ -2,10 +2,18 @@ package user
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"go-fiber-core/internal/domain"
 	"go-fiber-core/internal/dtos/connect"
 	"go-fiber-core/internal/models"
+	permissionRepo "go-fiber-core/internal/repositories/permission"
 	userRepo "go-fiber-core/internal/repositories/user"
 	"go-fiber-core/internal/services"
+	authService "go-fiber-core/internal/services/auth"
+	"go-fiber-core/internal/utils"
+	"slices"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -16,32 +24,61 @@ type UpdateUserDTO struct {
 	Email    *string
 	Password *string
 	IsActive *bool
+	// EmailVerified marca (true) o desmarca (false) el email como verificado.
+	// Cambiar el email sin indicarlo lo deja sin verificar.
+	EmailVerified *bool
+	// KeepSessionID es la sesión que se conserva al cambiar la contraseña (el usuario que
+	// cambia la propia sigue conectado); vacío cierra todas.
+	KeepSessionID string
 }
 
 type UserWriterService interface {
 	Create(ctx context.Context, user *models.User) error
 	Update(ctx context.Context, id uint64, data UpdateUserDTO) (*models.User, error)
+	// UpdateAsActor es Update hecho por actorID sobre otro usuario. Devuelve domain.ErrForbidden
+	// si el usuario tiene permisos que el actor no tiene: no se puede tomar una cuenta con más
+	// privilegios cambiándole el email.
+	UpdateAsActor(ctx context.Context, actorID, id uint64, data UpdateUserDTO) (*models.User, error)
 	SoftDelete(ctx context.Context, id uint64) error
 	HardDelete(ctx context.Context, id uint) error
+	// RevokeSessions cierra todas las sesiones del usuario (sus access tokens dejan de valer).
+	RevokeSessions(ctx context.Context, id uint64) error
+	// UnlockLogin levanta el bloqueo por intentos fallidos de login del usuario.
+	UnlockLogin(ctx context.Context, id uint64) error
+	// UpdateProfile cambia el nombre y/o el email del propio usuario. Cambiar el email exige
+	// la contraseña actual: con una sesión robada no se puede desviar la cuenta a otro email.
+	UpdateProfile(ctx context.Context, id uint64, name, email *string, currentPassword string) (*models.User, error)
+	// ChangePassword cambia la contraseña del propio usuario verificando la actual. Cierra
+	// sus demás sesiones y conserva currentSessionID.
+	ChangePassword(ctx context.Context, id uint64, currentPassword, newPassword, currentSessionID string) error
 }
 
 type userWriterService struct {
 	services.TransactionManager
-	conn       connect.ConnectDTO
-	userWriter userRepo.UserWriter
-	userReader userRepo.UserReader
+	conn        connect.ConnectDTO
+	userWriter  userRepo.UserWriter
+	userReader  userRepo.UserReader
+	sessions    authService.SessionRevoker
+	loginGuard  authService.LoginGuard
+	permissions permissionRepo.PermissionReader
 }
 
 func NewUserWriterService(
 	conn *connect.ConnectDTO,
 	writer userRepo.UserWriter,
 	reader userRepo.UserReader,
+	sessions authService.SessionRevoker,
+	loginGuard authService.LoginGuard,
+	permissions permissionRepo.PermissionReader,
 ) UserWriterService {
 	return &userWriterService{
 		TransactionManager: services.NewTransactionManager(conn),
 		conn:               *conn,
 		userWriter:         writer,
 		userReader:         reader,
+		sessions:           sessions,
+		loginGuard:         loginGuard,
+		permissions:        permissions,
 	}
 }
 
@@ -64,8 +101,23 @@ func (s *userWriterService) Update(ctx context.Context, id uint64, data UpdateUs
 		existingUser.Name = *data.Name
 	}
 	if data.Email != nil {
+		if *data.Email != existingUser.Email {
+			if err := s.ensureEmailAvailable(ctx, *data.Email, id); err != nil {
+				return nil, err
+			}
+			existingUser.EmailVerifiedAt = nil
+		}
 		existingUser.Email = *data.Email
 	}
+	if data.EmailVerified != nil {
+		switch {
+		case !*data.EmailVerified:
+			existingUser.EmailVerifiedAt = nil
+		case existingUser.EmailVerifiedAt == nil:
+			now := time.Now()
+			existingUser.EmailVerifiedAt = &now
+		}
+	}
 	if data.IsActive != nil {
 		existingUser.IsActive = *data.IsActive
 	}
@@ -79,16 +131,153 @@ func (s *userWriterService) Update(ctx context.Context, id uint64, data UpdateUs
 	if err := s.userWriter.Update(ctx, s.conn.ConnectGormWrite, existingUser); err != nil {
 		return nil, err
 	}
+
+	// Cambiar la contraseña o desactivar al usuario cierra todas sus sesiones (salvo
+	// KeepSessionID, si el usuario cambió su propia contraseña).
+	passwordChanged := data.Password != nil && *data.Password != ""
+	deactivated := data.IsActive != nil && !*data.IsActive
+	switch {
+	case passwordChanged && !deactivated && data.KeepSessionID != "":
+		if err := s.sessions.RevokeOtherSessions(ctx, id, data.KeepSessionID); err != nil {
+			return nil, err
+		}
+	case passwordChanged || deactivated:
+		if err := s.sessions.RevokeUserSessions(ctx, id); err != nil {
+			return nil, err
+		}
+	}
 	return existingUser, nil
 }
 
+func (s *userWriterService) UpdateAsActor(ctx context.Context, actorID, id uint64, data UpdateUserDTO) (*models.User, error) {
+	if _, err := s.userReader.GetByID(ctx, s.conn.ConnectGormWrite, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	actorPermissions, err := s.permissions.GetNamesByUserID(ctx, s.conn.ConnectGormWrite, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los permisos del usuario: %w", err)
+	}
+	targetPermissions, err := s.permissions.GetNamesByUserID(ctx, s.conn.ConnectGormWrite, id)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los permisos del usuario a editar: %w", err)
+	}
+	for _, permission := range targetPermissions {
+		if !slices.Contains(actorPermissions, permission) {
+			return nil, domain.ErrForbidden
+		}
+	}
+
+	return s.Update(ctx, id, data)
+}
+
+func (s *userWriterService) UpdateProfile(ctx context.Context, id uint64, name, email *string, currentPassword string) (*models.User, error) {
+	user, err := s.userReader.GetByID(ctx, s.conn.ConnectGormWrite, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if email != nil && *email != user.Email {
+		switch {
+		case currentPassword == "":
+			return nil, domain.NewValidationError(map[string][]string{
+				"current_password": {"Para cambiar el email ingresá la contraseña actual."},
+			})
+		case !utils.CheckPasswordHash(currentPassword, user.Password):
+			return nil, domain.NewValidationError(map[string][]string{
+				"current_password": {"La contraseña actual no es correcta."},
+			})
+		}
+	}
+
+	return s.Update(ctx, id, UpdateUserDTO{Name: name, Email: email})
+}
+
+func (s *userWriterService) ChangePassword(ctx context.Context, id uint64, currentPassword, newPassword, currentSessionID string) error {
+	user, err := s.userReader.GetByID(ctx, s.conn.ConnectGormWrite, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	if !utils.CheckPasswordHash(currentPassword, user.Password) {
+		return domain.NewValidationError(map[string][]string{
+			"current_password": {"La contraseña actual no es correcta."},
+		})
+	}
+	if newPassword == currentPassword {
+		return domain.NewValidationError(map[string][]string{
+			"password": {"La contraseña nueva debe ser distinta de la actual."},
+		})
+	}
+	if !utils.IsStrongPassword(newPassword) {
+		return domain.NewValidationError(map[string][]string{
+			"password": {utils.PasswordStrengthMessage},
+		})
+	}
+
+	_, err = s.Update(ctx, id, UpdateUserDTO{Password: &newPassword, KeepSessionID: currentSessionID})
+	return err
+}
+
+// ensureEmailAvailable evita el error de clave única al cambiar el email a uno de otro usuario.
+func (s *userWriterService) ensureEmailAvailable(ctx context.Context, email string, id uint64) error {
+	other, err := s.userReader.GetByEmail(ctx, s.conn.ConnectGormWrite, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if other.ID != id {
+		return domain.NewValidationError(map[string][]string{
+			"email": {"El email ya está registrado."},
+		})
+	}
+	return nil
+}
+
 func (s *userWriterService) SoftDelete(ctx context.Context, id uint64) error {
-	return s.userWriter.SoftDelete(ctx, s.conn.ConnectGormWrite, id)
+	if err := s.userWriter.SoftDelete(ctx, s.conn.ConnectGormWrite, id); err != nil {
+		return err
+	}
+	return s.sessions.RevokeUserSessions(ctx, id)
+}
+
+func (s *userWriterService) UnlockLogin(ctx context.Context, id uint64) error {
+	user, err := s.userReader.GetByID(ctx, s.conn.ConnectGormWrite, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	return s.loginGuard.Unlock(ctx, user.Email)
 }
 
 func (s *userWriterService) HardDelete(ctx context.Context, id uint) error {
+	// Primero las sesiones, mientras el usuario y sus refresh tokens todavía existen.
+	if err := s.sessions.RevokeUserSessions(ctx, uint64(id)); err != nil {
+		return err
+	}
 	return s.userWriter.HardDelete(ctx, s.conn.ConnectGormWrite, id)
 }
+
+func (s *userWriterService) RevokeSessions(ctx context.Context, id uint64) error {
+	if _, err := s.userReader.GetByID(ctx, s.conn.ConnectGormWrite, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	return s.sessions.RevokeUserSessions(ctx, id)
+}
 func (s *userWriterService) CreateWithProductsAndRoles(ctx context.Context, user *models.User, roleIDs []uint64) error {
 	db := s.conn.ConnectGormWrite // ✅ usa Conn (viene del TransactionManager)
 
@@ -0,0 +1,196 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-fiber-core/internal/domain"
+	"go-fiber-core/internal/dtos/connect"
+	"go-fiber-core/internal/models"
+	permissionRepo "go-fiber-core/internal/repositories/permission"
+	userRepo "go-fiber-core/internal/repositories/user"
+	authService "go-fiber-core/internal/services/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// fakeUsers guarda los usuarios en memoria (ignora el *gorm.DB).
+type fakeUsers struct {
+	userRepo.UserReader
+	userRepo.UserWriter
+	users map[uint64]*models.User
+}
+
+func (f *fakeUsers) GetByID(_ context.Context, _ *gorm.DB, id uint64) (*models.User, error) {
+	if u, ok := f.users[id]; ok {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeUsers) GetByEmail(_ context.Context, _ *gorm.DB, email string) (*models.User, error) {
+	for _, u := range f.users {
+		if u.Email == email {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeUsers) Update(_ context.Context, _ *gorm.DB, user *models.User) error {
+	f.users[user.ID] = user
+	return nil
+}
+
+// fakeSessions registra qué sesiones se cerraron.
+type fakeSessions struct {
+	authService.SessionRevoker
+	revokedAll   []uint64
+	keptSessions []string
+}
+
+func (f *fakeSessions) RevokeUserSessions(_ context.Context, userID uint64) error {
+	f.revokedAll = append(f.revokedAll, userID)
+	return nil
+}
+
+func (f *fakeSessions) RevokeOtherSessions(_ context.Context, _ uint64, currentSessionID string) error {
+	f.keptSessions = append(f.keptSessions, currentSessionID)
+	return nil
+}
+
+func newTestUserWriter(t *testing.T) (*userWriterService, *fakeUsers, *fakeSessions) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("Actual-123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	users := &fakeUsers{users: map[uint64]*models.User{
+		7: {ID: 7, Name: "Ana", Email: "ana@test.com", Password: string(hash), IsActive: true},
+		8: {ID: 8, Name: "Beto", Email: "beto@test.com", IsActive: true},
+	}}
+	sessions := &fakeSessions{}
+	// Ana (7) es administradora; Beto (8) solo puede editar usuarios.
+	permissions := fakeUserPermissions{byUser: map[uint64][]string{
+		7: {"users.read", "users.update", "roles.assign"},
+		8: {"users.read", "users.update"},
+	}}
+	s := NewUserWriterService(&connect.ConnectDTO{}, users, users, sessions, nil, permissions).(*userWriterService)
+	return s, users, sessions
+}
+
+// fakeUserPermissions devuelve los permisos de cada usuario.
+type fakeUserPermissions struct {
+	permissionRepo.PermissionReader
+	byUser map[uint64][]string
+}
+
+func (f fakeUserPermissions) GetNamesByUserID(_ context.Context, _ *gorm.DB, userID uint64) ([]string, error) {
+	return f.byUser[userID], nil
+}
+
+func TestChangePassword_KeepsCurrentSession(t *testing.T) {
+	s, users, sessions := newTestUserWriter(t)
+
+	require.NoError(t, s.ChangePassword(context.Background(), 7, "Actual-123", "Nueva-clave-456", "sid-actual"))
+
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(users.users[7].Password), []byte("Nueva-clave-456")))
+	assert.Equal(t, []string{"sid-actual"}, sessions.keptSessions)
+	assert.Empty(t, sessions.revokedAll)
+}
+
+func TestUpdateProfile_EmailRequiresCurrentPassword(t *testing.T) {
+	s, users, _ := newTestUserWriter(t)
+	ctx := context.Background()
+	name, email := "Ana María", "ana.maria@test.com"
+
+	for _, current := range []string{"", "Otra-123"} {
+		_, err := s.UpdateProfile(ctx, 7, &name, &email, current)
+		var validationErr *domain.ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Contains(t, validationErr.Fields, "current_password")
+		assert.Equal(t, "ana@test.com", users.users[7].Email)
+	}
+
+	// El nombre (o el mismo email) no piden la contraseña.
+	same := "ana@test.com"
+	_, err := s.UpdateProfile(ctx, 7, &name, &same, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Ana María", users.users[7].Name)
+
+	updated, err := s.UpdateProfile(ctx, 7, nil, &email, "Actual-123")
+	require.NoError(t, err)
+	assert.Equal(t, email, updated.Email)
+	assert.Nil(t, updated.EmailVerifiedAt)
+}
+
+func TestChangePassword_Rejected(t *testing.T) {
+	cases := map[string]struct {
+		current, password, field string
+	}{
+		"actual incorrecta": {"Otra-123", "Nueva-clave-456", "current_password"},
+		"igual a la actual": {"Actual-123", "Actual-123", "password"},
+		"débil":             {"Actual-123", "abcdefgh", "password"},
+		"corta":             {"Actual-123", "Ab-1", "password"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s, users, sessions := newTestUserWriter(t)
+			before := users.users[7].Password
+
+			err := s.ChangePassword(context.Background(), 7, tc.current, tc.password, "sid-actual")
+
+			var validationErr *domain.ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			assert.Contains(t, validationErr.Fields, tc.field)
+			assert.Equal(t, before, users.users[7].Password)
+			assert.Empty(t, sessions.keptSessions)
+		})
+	}
+}
+
+func TestUpdate_RejectsEmailOfOtherUser(t *testing.T) {
+	s, users, _ := newTestUserWriter(t)
+	email := "beto@test.com"
+
+	_, err := s.Update(context.Background(), 7, UpdateUserDTO{Email: &email})
+
+	var validationErr *domain.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Contains(t, validationErr.Fields, "email")
+	assert.Equal(t, "ana@test.com", users.users[7].Email)
+}
+
+func TestUpdate_PasswordWithoutKeepSessionRevokesAll(t *testing.T) {
+	s, _, sessions := newTestUserWriter(t)
+	password := "Admin-cambia-1"
+
+	_, err := s.Update(context.Background(), 7, UpdateUserDTO{Password: &password})
+
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{7}, sessions.revokedAll)
+	assert.Empty(t, sessions.keptSessions)
+}
+
+func TestUpdateAsActor_RejectsTargetWithMorePermissions(t *testing.T) {
+	s, users, _ := newTestUserWriter(t)
+	ctx := context.Background()
+	name, email := "Ana", "beto.personal@test.com"
+
+	// Beto no puede cambiarle el email a Ana: ella tiene roles.assign y él no.
+	_, err := s.UpdateAsActor(ctx, 8, 7, UpdateUserDTO{Name: &name, Email: &email})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.Equal(t, "ana@test.com", users.users[7].Email)
+
+	// Ana sí puede editar a Beto.
+	name = "Beto"
+	updated, err := s.UpdateAsActor(ctx, 7, 8, UpdateUserDTO{Name: &name, Email: &email})
+	require.NoError(t, err)
+	assert.Equal(t, email, updated.Email)
+
+	_, err = s.UpdateAsActor(ctx, 7, 99, UpdateUserDTO{Name: &name})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
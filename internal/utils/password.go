@@ -1,6 +1,16 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength es el largo mínimo de una contraseña segura.
+const MinPasswordLength = 8
+
+// PasswordStrengthMessage explica los requisitos de IsStrongPassword.
+const PasswordStrengthMessage = "La contraseña debe tener al menos 8 caracteres y combinar al menos tres de: minúsculas, mayúsculas, números y símbolos."
 
 // HashPassword crea un hash bcrypt de la contraseña.
 func HashPassword(password string) (string, error) {
@@ -13,3 +23,30 @@ func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// IsStrongPassword exige MinPasswordLength caracteres y al menos tres clases distintas
+// entre minúsculas, mayúsculas, números y símbolos.
+func IsStrongPassword(password string) bool {
+	var lower, upper, digit, symbol bool
+	length := 0
+	for _, r := range password {
+		length++
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			classes++
+		}
+	}
+	return length >= MinPasswordLength && classes >= 3
+}
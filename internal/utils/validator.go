@@ -100,6 +100,14 @@ func validateRequest(req interface{}) (map[string][]string, bool) {
 	return nil, true
 }
 
+// ValidateStruct valida req con las reglas y traducciones del validador y devuelve los
+// errores por campo (nil si es válido). Sirve para datos que no llegan en el cuerpo de la
+// petición, como las filas de un archivo importado.
+func ValidateStruct(req interface{}) map[string][]string {
+	errors, _ := validateRequest(req)
+	return errors
+}
+
 // Validate es el middleware de Fiber que se usa en las rutas.
 func Validate(req interface{}) fiber.Handler {
 	return func(c *fiber.Ctx) error {